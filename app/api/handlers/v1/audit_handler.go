@@ -0,0 +1,41 @@
+package v1
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/lenguti/jppp/business/core/audit"
+	"github.com/lenguti/jppp/foundation/api"
+)
+
+// ListAuditResponse - represents a client list audit records response.
+type ListAuditResponse struct {
+	Records    []ClientAuditRecord `json:"records"`
+	NextCursor string              `json:"next_cursor,omitempty"`
+}
+
+// ListAudit - invoked by GET /v1/audit.
+func (c *Controller) ListAudit(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	c.logger(ctx).Info().Msg("Listing audit records.")
+
+	page, validated := parsePage(r, audit.SortFields...)
+	if !validated.IsClean() {
+		c.logger(ctx).Err(validated).Msg("Validation input failed.")
+		return api.BadRequestError("Invalid input.", validated, validated.Details())
+	}
+
+	filters, validated := parseFilters(r, audit.FilterFields)
+	if !validated.IsClean() {
+		c.logger(ctx).Err(validated).Msg("Validation input failed.")
+		return api.BadRequestError("Invalid input.", validated, validated.Details())
+	}
+
+	recs, next, err := c.Audit.List(ctx, page, filters...)
+	if err != nil {
+		c.logger(ctx).Err(err).Msg("Unable to list audit records.")
+		return toHTTPError(err)
+	}
+
+	c.logger(ctx).Info().Msg("Successfully listed audit records.")
+	return api.Respond(w, http.StatusOK, ListAuditResponse{Records: toClientAuditRecords(recs), NextCursor: next})
+}
@@ -0,0 +1,42 @@
+package v1
+
+import (
+	"encoding/json"
+
+	"github.com/lenguti/jppp/business/core/audit"
+)
+
+// ClientAuditRecord - represents our client audit record model.
+type ClientAuditRecord struct {
+	ID         string          `json:"id"`
+	Actor      string          `json:"actor"`
+	Action     string          `json:"action"`
+	EntityType string          `json:"entity_type"`
+	EntityID   string          `json:"entity_id"`
+	Before     json.RawMessage `json:"before"`
+	After      json.RawMessage `json:"after"`
+	RequestID  string          `json:"request_id,omitempty"`
+	CreatedAt  int64           `json:"createdAt"`
+}
+
+func toClientAuditRecords(input []audit.Record) []ClientAuditRecord {
+	recs := make([]ClientAuditRecord, 0, len(input))
+	for _, v := range input {
+		recs = append(recs, toClientAuditRecord(v))
+	}
+	return recs
+}
+
+func toClientAuditRecord(input audit.Record) ClientAuditRecord {
+	return ClientAuditRecord{
+		ID:         input.ID.String(),
+		Actor:      input.Actor,
+		Action:     input.Action,
+		EntityType: input.EntityType,
+		EntityID:   input.EntityID.String(),
+		Before:     input.Before,
+		After:      input.After,
+		RequestID:  input.RequestID,
+		CreatedAt:  input.CreatedAt.Unix(),
+	}
+}
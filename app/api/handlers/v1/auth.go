@@ -0,0 +1,31 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/lenguti/jppp/business/core"
+	"github.com/lenguti/jppp/business/core/apikey"
+	"github.com/lenguti/jppp/foundation/api"
+)
+
+// publicRoutes - the routes served without authentication.
+var publicRoutes = []string{"/v1/status"}
+
+// apiKeys - looks api keys up through the api key core.
+type apiKeys struct {
+	core *apikey.Core
+}
+
+// LookupAPIKey - satisfies the api.KeyStore interface.
+func (k apiKeys) LookupAPIKey(ctx context.Context, hash string) (api.Principal, error) {
+	key, err := k.core.GetByHash(ctx, hash)
+	if err != nil {
+		if errors.Is(err, core.ErrNotFound) {
+			return api.Principal{}, api.ErrUnknownAPIKey
+		}
+		return api.Principal{}, fmt.Errorf("lookup api key: %w", err)
+	}
+	return api.Principal{Subject: key.Name, Method: api.AuthMethodAPIKey, Role: string(key.Role)}, nil
+}
@@ -0,0 +1,92 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/lenguti/jppp/business/core"
+	"github.com/lenguti/jppp/foundation/api"
+)
+
+// routePermissions - the permission table of the routes, keyed by method and route pattern.
+// Authenticated routes missing from it are denied to every role.
+var routePermissions = map[string]core.Permission{
+	"GET /metrics": core.PermMetricsRead,
+
+	"POST /v1/cages":                                    core.PermCageCreate,
+	"GET /v1/cages":                                     core.PermCageRead,
+	"GET /v1/cages/:id":                                 core.PermCageRead,
+	"PATCH /v1/cages/:id":                               core.PermCageUpdate,
+	"DELETE /v1/cages/:id":                              core.PermCageDelete,
+	"POST /v1/cages/:id/restore":                        core.PermCageRestore,
+	"PATCH /v1/cages/:id/dinosaurs/:dinoId":             core.PermCageAddDino,
+	"DELETE /v1/cages/:id/dinosaurs/:dinoId":            core.PermCageRemoveDino,
+	"GET /v1/cages/:id/dinosaurs/:dinoId/compatibility": core.PermCageRead,
+	"GET /v1/cages/:id/dinosaurs":                       core.PermDinoRead,
+	"GET /v1/cages/:id/history":                         core.PermCageRead,
+	"GET /v1/dinosaurs/species":                         core.PermDinoRead,
+	"POST /v1/dinosaurs":                                core.PermDinoCreate,
+	"GET /v1/dinosaurs":                                 core.PermDinoRead,
+	"GET /v1/dinosaurs/:id":                             core.PermDinoRead,
+	"PATCH /v1/dinosaurs/:id":                           core.PermDinoUpdate,
+	"DELETE /v1/dinosaurs/:id":                          core.PermDinoDelete,
+	"POST /v1/dinosaurs/:id/restore":                    core.PermDinoRestore,
+	"POST /v1/dinosaurs/:id/transfer":                   core.PermDinoTransfer,
+	"GET /v1/dinosaurs/:id/history":                     core.PermDinoRead,
+	"GET /v1/dinosaurs/:id/eligible-cages":              core.PermCageRead,
+
+	"POST /v1/placements/plan":  core.PermCageRead,
+	"POST /v1/placements/apply": core.PermCageAddDino,
+	"POST /v1/rebalance/plan":   core.PermCageRead,
+	"POST /v1/rebalance/apply":  core.PermDinoTransfer,
+
+	"POST /v1/species":         core.PermSpeciesManage,
+	"GET /v1/species":          core.PermSpeciesRead,
+	"GET /v1/species/:name":    core.PermSpeciesRead,
+	"PATCH /v1/species/:name":  core.PermSpeciesManage,
+	"DELETE /v1/species/:name": core.PermSpeciesManage,
+
+	"GET /v1/audit":  core.PermAuditRead,
+	"GET /v1/events": core.PermEventRead,
+
+	"POST /v1/webhooks":               core.PermWebhookManage,
+	"GET /v1/webhooks":                core.PermWebhookRead,
+	"GET /v1/webhooks/:id":            core.PermWebhookRead,
+	"DELETE /v1/webhooks/:id":         core.PermWebhookManage,
+	"GET /v1/webhooks/:id/deliveries": core.PermWebhookRead,
+}
+
+// authorize - returns a middleware passing the authenticated principal and request id to the cores
+// as the actor of the request and denying requests whose role lacks the permission of the route with a 403.
+func authorize(public ...string) api.Middleware {
+	open := make(map[string]bool, len(public))
+	for _, p := range public {
+		open[p] = true
+	}
+
+	return func(next api.Handler) api.Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			ctx = core.WithRequestID(ctx, api.RequestID(ctx))
+			route := api.Route(ctx)
+			if open[route] {
+				return next(ctx, w, r.WithContext(ctx))
+			}
+
+			p, ok := api.PrincipalFrom(ctx)
+			if !ok {
+				return api.UnauthorizedError("Missing or invalid credentials.", nil, nil)
+			}
+			ctx = core.WithActor(ctx, core.Actor{Subject: p.Subject, Role: core.Role(p.Role)})
+
+			perm, ok := routePermissions[r.Method+" "+route]
+			if !ok {
+				return api.ForbiddenError("Permission denied.", fmt.Errorf("authorize: no permission for route %s %s", r.Method, route), nil)
+			}
+			if err := core.Authorize(ctx, perm); err != nil {
+				return toHTTPError(err)
+			}
+			return next(ctx, w, r.WithContext(ctx))
+		}
+	}
+}
@@ -2,8 +2,8 @@ package v1
 
 import (
 	"context"
-	"errors"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/google/uuid"
@@ -39,26 +39,27 @@ type CreateCageResponse struct {
 
 // CreateCage - invoked by POST /v1/cages.
 func (c *Controller) CreateCage(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
-	c.log.Info().Msg("Creating Cage.")
+	c.logger(ctx).Info().Msg("Creating Cage.")
 
 	var input CreateCageRequest
 	if err := api.Decode(r, &input); err != nil {
-		c.log.Err(err).Msg("Unable to decode create cage request.")
+		c.logger(ctx).Err(err).Msg("Unable to decode create cage request.")
 		return api.BadRequestError("Invalid input.", err, nil)
 	}
 
 	if validated := input.validate(); !validated.IsClean() {
-		c.log.Err(validated).Msg("Validation input failed.")
+		c.logger(ctx).Err(validated).Msg("Validation input failed.")
 		return api.BadRequestError("Invalid input.", validated, validated.Details())
 	}
 
 	cge, err := c.Cage.Create(ctx, toCoreNewCage(input))
 	if err != nil {
-		c.log.Err(err).Msg("Unable to create cage.")
-		return api.InternalServerError("Error.", err, nil)
+		c.logger(ctx).Err(err).Msg("Unable to create cage.")
+		return toHTTPError(err)
 	}
 
-	c.log.Info().Msg("Successfully created Cage.")
+	c.logger(ctx).Info().Msg("Successfully created Cage.")
+	api.SetETag(w, strconv.Itoa(cge.Version))
 	return api.Respond(w, http.StatusCreated, CreateCageResponse{Cage: toClientCage(cge)})
 }
 
@@ -69,55 +70,56 @@ type GetCageResponse struct {
 
 // GetCage - invoked by GET /v1/cages/:id.
 func (c *Controller) GetCage(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
-	c.log.Info().Msg("Fetching Cage.")
+	c.logger(ctx).Info().Msg("Fetching Cage.")
 
 	idStr := api.PathParam(r, idPathParam)
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		c.log.Err(err).Msg("Invalid cage id.")
+		c.logger(ctx).Err(err).Msg("Invalid cage id.")
 		return api.BadRequestError("Invalid id.", err, nil)
 	}
 
 	cge, err := c.Cage.Get(ctx, id)
 	if err != nil {
-		c.log.Err(err).Msg("Unable to fetch cage.")
-		if errors.Is(err, core.ErrNotFound) {
-			return api.NotFoundError("Item not found.", err, nil)
-		}
-		return api.InternalServerError("Error.", err, nil)
+		c.logger(ctx).Err(err).Msg("Unable to fetch cage.")
+		return toHTTPError(err)
 	}
 
-	c.log.Info().Msg("Successfully fetched Cage.")
+	c.logger(ctx).Info().Msg("Successfully fetched Cage.")
+	api.SetETag(w, strconv.Itoa(cge.Version))
 	return api.Respond(w, http.StatusOK, GetCageResponse{Cage: toClientCage(cge)})
 }
 
 // ListCagesResponse - represents a client list cages response.
 type ListCagesResponse struct {
-	Cages []ClientCage `json:"cages"`
+	Cages      []ClientCage `json:"cages"`
+	NextCursor string       `json:"next_cursor,omitempty"`
 }
 
 // ListCages - invoked by GET /v1/cages.
 func (c *Controller) ListCages(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
-	c.log.Info().Msg("Listing Cages.")
+	c.logger(ctx).Info().Msg("Listing Cages.")
+
+	page, validated := parsePage(r, cage.SortFields...)
+	if !validated.IsClean() {
+		c.logger(ctx).Err(validated).Msg("Validation input failed.")
+		return api.BadRequestError("Invalid input.", validated, validated.Details())
+	}
 
-	status := api.QueryParam(r, queryParamStatus)
-	var filters []core.Filter
-	if status != "" {
-		if err := cage.ParseStatus(status); err != nil {
-			c.log.Err(err).Msg("Invalid cage status filter.")
-			return api.BadRequestError("Invalid cage status filter.", err, nil)
-		}
-		filters = append(filters, core.Filter{Key: queryParamStatus, Value: strings.ToUpper(status)})
+	filters, validated := parseFilters(r, cage.FilterFields)
+	if !validated.IsClean() {
+		c.logger(ctx).Err(validated).Msg("Validation input failed.")
+		return api.BadRequestError("Invalid input.", validated, validated.Details())
 	}
 
-	cgs, err := c.Cage.List(ctx, filters...)
+	cgs, next, err := c.Cage.List(ctx, page, filters...)
 	if err != nil {
-		c.log.Err(err).Msg("Unable to list cages.")
-		return api.InternalServerError("Error.", err, nil)
+		c.logger(ctx).Err(err).Msg("Unable to list cages.")
+		return toHTTPError(err)
 	}
 
-	c.log.Info().Msg("Successfully listed Cage.")
-	return api.Respond(w, http.StatusOK, ListCagesResponse{Cages: toClientCages(cgs)})
+	c.logger(ctx).Info().Msg("Successfully listed Cage.")
+	return api.Respond(w, http.StatusOK, ListCagesResponse{Cages: toClientCages(cgs), NextCursor: next})
 }
 
 // UpdateCageRequest - represents input for updating a cage.
@@ -142,39 +144,40 @@ type UpdateCageResponse struct {
 
 // UpdateCage - invoked by PATCH /v1/cages/:id.
 func (c *Controller) UpdateCage(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
-	c.log.Info().Msg("Updating Cage.")
+	c.logger(ctx).Info().Msg("Updating Cage.")
 
 	var input UpdateCageRequest
 	if err := api.Decode(r, &input); err != nil {
-		c.log.Err(err).Msg("Unable to decode update cage request.")
+		c.logger(ctx).Err(err).Msg("Unable to decode update cage request.")
 		return api.BadRequestError("Invalid input.", err, nil)
 	}
 
 	if validated := input.validate(); !validated.IsClean() {
-		c.log.Err(validated).Msg("Validation input failed.")
+		c.logger(ctx).Err(validated).Msg("Validation input failed.")
 		return api.BadRequestError("Invalid input.", validated, validated.Details())
 	}
 
 	idStr := api.PathParam(r, idPathParam)
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		c.log.Err(err).Msg("Invalid cage id.")
+		c.logger(ctx).Err(err).Msg("Invalid cage id.")
 		return api.BadRequestError("Invalid id.", err, nil)
 	}
 
-	cge, err := c.Cage.UpdateStatus(ctx, id, cage.Status(strings.ToUpper(input.Status)))
+	version, err := ifMatchVersion(r)
 	if err != nil {
-		c.log.Err(err).Msg("Unable to update cage.")
-		switch {
-		case errors.Is(err, core.ErrPowerDownCage):
-			return api.BadRequestError(err.Error(), err, nil)
-		case errors.Is(err, core.ErrNotFound):
-			return api.NotFoundError("Item not found.", err, nil)
-		}
-		return api.InternalServerError("Error.", err, nil)
+		c.logger(ctx).Err(err).Msg("Invalid if match header.")
+		return api.PreconditionFailedError(core.ErrPreconditionFailed.Error(), err, nil)
 	}
 
-	c.log.Info().Msg("Successfully updated Cage.")
+	cge, err := c.Cage.UpdateStatus(ctx, id, cage.Status(strings.ToUpper(input.Status)), version)
+	if err != nil {
+		c.logger(ctx).Err(err).Msg("Unable to update cage.")
+		return toHTTPError(err)
+	}
+
+	c.logger(ctx).Info().Msg("Successfully updated Cage.")
+	api.SetETag(w, strconv.Itoa(cge.Version))
 	return api.Respond(w, http.StatusOK, UpdateCageResponse{Cage: toClientCage(cge)})
 }
 
@@ -185,41 +188,99 @@ type AddDinosaurToCageResponse struct {
 
 // AddDinosaurToCage - invoked by PATCH /v1/cages/:id/dinosaurs/:id.
 func (c *Controller) AddDinosaurToCage(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
-	c.log.Info().Msg("Adding Dinosaur to Cage.")
+	c.logger(ctx).Info().Msg("Adding Dinosaur to Cage.")
 
 	idStr := api.PathParam(r, idPathParam)
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		c.log.Err(err).Msg("Invalid cage id.")
+		c.logger(ctx).Err(err).Msg("Invalid cage id.")
 		return api.BadRequestError("Invalid cage id.", err, nil)
 	}
 
 	dinoIdStr := api.PathParam(r, dinoIDPathParam)
 	dinoID, err := uuid.Parse(dinoIdStr)
 	if err != nil {
-		c.log.Err(err).Msg("Invalid dino id.")
+		c.logger(ctx).Err(err).Msg("Invalid dino id.")
 		return api.BadRequestError("Invalid dinosaur id.", err, nil)
 	}
 
-	cge, err := c.Cage.AddDino(ctx, id, dinoID)
+	version, err := ifMatchVersion(r)
+	if err != nil {
+		c.logger(ctx).Err(err).Msg("Invalid if match header.")
+		return api.PreconditionFailedError(core.ErrPreconditionFailed.Error(), err, nil)
+	}
+
+	cge, err := c.Cage.AddDino(ctx, id, dinoID, version)
 	if err != nil {
-		c.log.Err(err).Msg("Unable to add dino to cage.")
-		switch {
-		case errors.Is(err, core.ErrInvalidCagePowerDown),
-			errors.Is(err, core.ErrInvalidCageAtCapacity),
-			errors.Is(err, core.ErrInvalidCageInvalidType),
-			errors.Is(err, core.ErrInvalidCageInvalidSpecies):
-			return api.BadRequestError(err.Error(), err, nil)
-		case errors.Is(err, core.ErrNotFound):
-			return api.NotFoundError("Item not found.", err, nil)
-		}
-		return api.InternalServerError("Error.", err, nil)
-	}
-
-	c.log.Info().Msg("Successfully added Dinosaur to Cage.")
+		c.logger(ctx).Err(err).Msg("Unable to add dino to cage.")
+		c.metrics.addDinoRejected(err)
+		return toHTTPError(err)
+	}
+
+	c.logger(ctx).Info().Msg("Successfully added Dinosaur to Cage.")
+	api.SetETag(w, strconv.Itoa(cge.Version))
 	return api.Respond(w, http.StatusOK, AddDinosaurToCageResponse{Cage: toClientCage(cge)})
 }
 
+// DinosaurCompatibilityResponse - represents a client dino compatibility response.
+type DinosaurCompatibilityResponse struct {
+	Compatible bool              `json:"compatible"`
+	Violations []ClientViolation `json:"violations"`
+}
+
+// DinosaurCompatibility - invoked by GET /v1/cages/:id/dinosaurs/:dinoId/compatibility.
+func (c *Controller) DinosaurCompatibility(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	c.logger(ctx).Info().Msg("Checking Dinosaur compatibility with Cage.")
+
+	idStr := api.PathParam(r, idPathParam)
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.logger(ctx).Err(err).Msg("Invalid cage id.")
+		return api.BadRequestError("Invalid cage id.", err, nil)
+	}
+
+	dinoIdStr := api.PathParam(r, dinoIDPathParam)
+	dinoID, err := uuid.Parse(dinoIdStr)
+	if err != nil {
+		c.logger(ctx).Err(err).Msg("Invalid dino id.")
+		return api.BadRequestError("Invalid dinosaur id.", err, nil)
+	}
+
+	vs, err := c.Cage.CheckPlacement(ctx, id, dinoID)
+	if err != nil {
+		c.logger(ctx).Err(err).Msg("Unable to check dino compatibility.")
+		return toHTTPError(err)
+	}
+
+	c.logger(ctx).Info().Msg("Successfully checked Dinosaur compatibility with Cage.")
+	return api.Respond(w, http.StatusOK, DinosaurCompatibilityResponse{Compatible: len(vs) == 0, Violations: toClientViolations(vs)})
+}
+
+// EligibleCagesResponse - represents a client eligible cages response.
+type EligibleCagesResponse struct {
+	Cages []ClientCage `json:"cages"`
+}
+
+// EligibleCages - invoked by GET /v1/dinosaurs/:id/eligible-cages.
+func (c *Controller) EligibleCages(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	c.logger(ctx).Info().Msg("Listing eligible Cages for Dinosaur.")
+
+	id, err := uuid.Parse(api.PathParam(r, idPathParam))
+	if err != nil {
+		c.logger(ctx).Err(err).Msg("Invalid dino id.")
+		return api.BadRequestError("Invalid dinosaur id.", err, nil)
+	}
+
+	cgs, err := c.Cage.EligibleCages(ctx, id)
+	if err != nil {
+		c.logger(ctx).Err(err).Msg("Unable to list eligible cages.")
+		return toHTTPError(err)
+	}
+
+	c.logger(ctx).Info().Msg("Successfully listed eligible Cages for Dinosaur.")
+	return api.Respond(w, http.StatusOK, EligibleCagesResponse{Cages: toClientCages(cgs)})
+}
+
 // RemoveDinosaurFromCageResponse - represents a client remove dino from cage response.
 type RemoveDinosaurFromCageResponse struct {
 	Cage ClientCage `json:"cage"`
@@ -227,34 +288,101 @@ type RemoveDinosaurFromCageResponse struct {
 
 // RemoveDinosaurFromCage - invoked by DELETE /v1/cages/:id/dinosaurs/:id.
 func (c *Controller) RemoveDinosaurFromCage(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
-	c.log.Info().Msg("Removing Dinosaur from Cage.")
+	c.logger(ctx).Info().Msg("Removing Dinosaur from Cage.")
 
 	idStr := api.PathParam(r, idPathParam)
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		c.log.Err(err).Msg("Invalid cage id.")
+		c.logger(ctx).Err(err).Msg("Invalid cage id.")
 		return api.BadRequestError("Invalid cage id.", err, nil)
 	}
 
 	dinoIdStr := api.PathParam(r, dinoIDPathParam)
 	dinoID, err := uuid.Parse(dinoIdStr)
 	if err != nil {
-		c.log.Err(err).Msg("Invalid dino id.")
+		c.logger(ctx).Err(err).Msg("Invalid dino id.")
 		return api.BadRequestError("Invalid dinosaur id.", err, nil)
 	}
 
-	cge, err := c.Cage.RemoveDino(ctx, id, dinoID)
+	version, err := ifMatchVersion(r)
+	if err != nil {
+		c.logger(ctx).Err(err).Msg("Invalid if match header.")
+		return api.PreconditionFailedError(core.ErrPreconditionFailed.Error(), err, nil)
+	}
+
+	cge, err := c.Cage.RemoveDino(ctx, id, dinoID, version)
 	if err != nil {
-		c.log.Err(err).Msg("Unable to remove dino from cage.")
-		switch {
-		case errors.Is(err, core.ErrInvalidCageInvalidRemoval):
-			return api.BadRequestError(err.Error(), err, nil)
-		case errors.Is(err, core.ErrNotFound):
-			return api.NotFoundError("Item not found.", err, nil)
-		}
-		return api.InternalServerError("Error.", err, nil)
+		c.logger(ctx).Err(err).Msg("Unable to remove dino from cage.")
+		return toHTTPError(err)
 	}
 
-	c.log.Info().Msg("Successfully removed Dinosaur from Cage.")
+	c.logger(ctx).Info().Msg("Successfully removed Dinosaur from Cage.")
+	api.SetETag(w, strconv.Itoa(cge.Version))
 	return api.Respond(w, http.StatusOK, RemoveDinosaurFromCageResponse{Cage: toClientCage(cge)})
 }
+
+// DeleteCageResponse - represents a client delete cage response.
+type DeleteCageResponse struct {
+	Cage ClientCage `json:"cage"`
+}
+
+// DeleteCage - invoked by DELETE /v1/cages/:id.
+func (c *Controller) DeleteCage(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	c.logger(ctx).Info().Msg("Deleting Cage.")
+
+	idStr := api.PathParam(r, idPathParam)
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.logger(ctx).Err(err).Msg("Invalid cage id.")
+		return api.BadRequestError("Invalid id.", err, nil)
+	}
+
+	version, err := ifMatchVersion(r)
+	if err != nil {
+		c.logger(ctx).Err(err).Msg("Invalid if match header.")
+		return api.PreconditionFailedError(core.ErrPreconditionFailed.Error(), err, nil)
+	}
+
+	cge, err := c.Cage.Delete(ctx, id, version)
+	if err != nil {
+		c.logger(ctx).Err(err).Msg("Unable to delete cage.")
+		return toHTTPError(err)
+	}
+
+	c.logger(ctx).Info().Msg("Successfully deleted Cage.")
+	api.SetETag(w, strconv.Itoa(cge.Version))
+	return api.Respond(w, http.StatusOK, DeleteCageResponse{Cage: toClientCage(cge)})
+}
+
+// RestoreCageResponse - represents a client restore cage response.
+type RestoreCageResponse struct {
+	Cage ClientCage `json:"cage"`
+}
+
+// RestoreCage - invoked by POST /v1/cages/:id/restore.
+func (c *Controller) RestoreCage(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	c.logger(ctx).Info().Msg("Restoring Cage.")
+
+	idStr := api.PathParam(r, idPathParam)
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.logger(ctx).Err(err).Msg("Invalid cage id.")
+		return api.BadRequestError("Invalid id.", err, nil)
+	}
+
+	version, err := ifMatchVersion(r)
+	if err != nil {
+		c.logger(ctx).Err(err).Msg("Invalid if match header.")
+		return api.PreconditionFailedError(core.ErrPreconditionFailed.Error(), err, nil)
+	}
+
+	cge, err := c.Cage.Restore(ctx, id, version)
+	if err != nil {
+		c.logger(ctx).Err(err).Msg("Unable to restore cage.")
+		return toHTTPError(err)
+	}
+
+	c.logger(ctx).Info().Msg("Successfully restored Cage.")
+	api.SetETag(w, strconv.Itoa(cge.Version))
+	return api.Respond(w, http.StatusOK, RestoreCageResponse{Cage: toClientCage(cge)})
+}
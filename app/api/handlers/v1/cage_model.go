@@ -13,8 +13,10 @@ type ClientCage struct {
 	Capacity        int    `json:"capacity"`
 	CurrentCapacity int    `json:"currentCapacity"`
 	Status          string `json:"status"`
+	Version         int    `json:"version"`
 	CreatedAt       int64  `json:"createdAt"`
 	UpdatedAt       int64  `json:"updatedAt"`
+	DeletedAt       int64  `json:"deletedAt,omitempty"`
 }
 
 func toCoreNewCage(input CreateCageRequest) cage.NewCage {
@@ -35,13 +37,18 @@ func toClientCages(cages []cage.Cage) []ClientCage {
 }
 
 func toClientCage(input cage.Cage) ClientCage {
-	return ClientCage{
+	cc := ClientCage{
 		ID:              input.ID.String(),
 		Type:            input.Type.String(),
 		Capacity:        input.Capacity,
 		CurrentCapacity: input.CurrentCapacity,
 		Status:          input.Status.String(),
+		Version:         input.Version,
 		CreatedAt:       input.CreatedAt.Unix(),
 		UpdatedAt:       input.UpdatedAt.Unix(),
 	}
+	if input.Deleted() {
+		cc.DeletedAt = input.DeletedAt.Unix()
+	}
+	return cc
 }
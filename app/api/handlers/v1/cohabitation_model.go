@@ -0,0 +1,33 @@
+package v1
+
+import (
+	"github.com/lenguti/jppp/business/core/cohabitation"
+)
+
+// ClientViolation - represents a client cohabitation rule violation.
+type ClientViolation struct {
+	Rule        string   `json:"rule"`
+	Code        string   `json:"code"`
+	Explanation string   `json:"explanation"`
+	DinoIDs     []string `json:"dino_ids,omitempty"`
+}
+
+func toClientViolations(input []cohabitation.Violation) []ClientViolation {
+	vs := make([]ClientViolation, 0, len(input))
+	for _, v := range input {
+		vs = append(vs, toClientViolation(v))
+	}
+	return vs
+}
+
+func toClientViolation(input cohabitation.Violation) ClientViolation {
+	cv := ClientViolation{
+		Rule:        input.Rule,
+		Code:        errorCode(input.Err),
+		Explanation: input.Explanation,
+	}
+	for _, id := range input.DinoIDs {
+		cv.DinoIDs = append(cv.DinoIDs, id.String())
+	}
+	return cv
+}
@@ -1,8 +1,50 @@
 package v1
 
 import (
+	"encoding/hex"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lenguti/jppp/business/core"
+	"github.com/lenguti/jppp/business/core/cohabitation"
+	"github.com/lenguti/jppp/business/core/outbox"
+	"github.com/lenguti/jppp/business/core/species"
+	"github.com/lenguti/jppp/business/core/webhook"
+	"github.com/lenguti/jppp/business/data/db"
+	"github.com/lenguti/jppp/foundation/api"
+	"github.com/lenguti/jppp/foundation/tracing"
+)
+
+// Default db settings, used when the related environment variable is not set.
+const (
+	defaultDBHost         = "db"
+	defaultDBPort         = 5432
+	defaultDBSSLMode      = db.SSLModeDisable
+	defaultDBMaxIdleConns = 10
+	defaultDBMaxOpenConns = 10
+)
+
+// Default tracing settings, used when the related environment variable is not set.
+const (
+	defaultTraceServiceName = "jppp"
+	defaultTraceExporter    = tracing.ExporterNone
+)
+
+// Default events settings, used when the related environment variable is not set.
+const (
+	defaultEventsReplaySize = 1024
+	defaultOutboxPublishers = OutboxPublisherInProcess
+)
+
+// Outbox publishers, the outbox events are relayed to.
+const (
+	// OutboxPublisherInProcess - publishes to the event streams and webhooks of the relaying replica.
+	OutboxPublisherInProcess = "inprocess"
+	// OutboxPublisherFile - appends newline delimited json to OUTBOX_FILE.
+	OutboxPublisherFile = "file"
 )
 
 // Config - represents configurtion for v1 services.
@@ -10,12 +52,68 @@ type Config struct {
 	DBName string
 	DBPass string
 	DBUser string
+
+	DBHost             string
+	DBPort             int
+	DBSSLMode          string
+	DBSSLRootCert      string
+	DBConnectTimeout   time.Duration
+	DBStatementTimeout time.Duration
+	DBMaxIdleConns     int
+	DBMaxOpenConns     int
+	DBConnMaxLifetime  time.Duration
+	DBConnMaxIdleTime  time.Duration
+
+	// MemStore - when set the api runs against an in-memory store and no db is required.
+	MemStore bool
+
+	// AutoMigrate - when set pending migrations are applied on startup.
+	AutoMigrate bool
+
+	TraceServiceName string
+	TraceExporter    string
+
+	// AuthAPIKeys - static api keys, as a map of key hashes to the key name and role, accepted along with the api_key table.
+	AuthAPIKeys map[string]api.Principal
+
+	// AuthJWT* - jwt bearer tokens are accepted when a HS256 secret or a RS256 public key is set.
+	AuthJWTSecret        string
+	AuthJWTPublicKeyFile string
+	AuthJWTIssuer        string
+	AuthJWTAudience      string
+	AuthJWTLeeway        time.Duration
+
+	// EventsReplaySize - the number of latest events kept for clients resuming their event stream.
+	EventsReplaySize int
+
+	// Webhook* - webhook delivery settings, the webhook core defaults apply to unset ones.
+	WebhookMaxAttempts  int
+	WebhookBackoff      time.Duration
+	WebhookMaxBackoff   time.Duration
+	WebhookTimeout      time.Duration
+	WebhookPollInterval time.Duration
+
+	// OutboxPublishers - the publishers the outbox events are relayed to, in order.
+	OutboxPublishers []string
+	// OutboxFile - the file the file publisher appends to.
+	OutboxFile string
+	// Outbox* - outbox relay settings, the outbox core defaults apply to unset ones.
+	OutboxPollInterval time.Duration
+	OutboxBatchSize    int
+
+	// SpeciesCacheTTL - how long the species registry is cached, the species core default applies when unset.
+	SpeciesCacheTTL time.Duration
+
+	// CohabitationRulesFile - the json file the cohabitation rules are read from, in place of the store.
+	CohabitationRulesFile string
+	// CohabitationRulesTTL - how long the cohabitation rules are cached, the cohabitation core default applies when unset.
+	CohabitationRulesTTL time.Duration
 }
 
 // NewConfig - returns an new configurtion initialized with environment variables.
-func NewConfig() (Config, error) {
+func NewConfig(memStore bool) (Config, error) {
 	var (
-		cfg Config
+		cfg = Config{MemStore: memStore}
 		err error
 	)
 	cfg, err = cfg.parseEnv()
@@ -23,6 +121,12 @@ func NewConfig() (Config, error) {
 		return cfg, fmt.Errorf("new config: unable to parse environment config: %w", err)
 	}
 
+	if !cfg.MemStore {
+		if err := cfg.DBConfig().Validate(); err != nil {
+			return cfg, fmt.Errorf("new config: invalid db config: %w", err)
+		}
+	}
+
 	return cfg, nil
 }
 
@@ -33,6 +137,60 @@ func (c Config) parseEnv() (Config, error) {
 		dbPass = os.Getenv("DB_PASS")
 	)
 
+	c.TraceServiceName = envString("OTEL_SERVICE_NAME", defaultTraceServiceName)
+	c.TraceExporter = envString("OTEL_TRACES_EXPORTER", defaultTraceExporter)
+
+	var err error
+	if c.AuthAPIKeys, err = parseAPIKeys(os.Getenv("AUTH_API_KEYS")); err != nil {
+		return c, fmt.Errorf("parse env: %w", err)
+	}
+	c.AuthJWTSecret = os.Getenv("AUTH_JWT_HS256_SECRET")
+	c.AuthJWTPublicKeyFile = os.Getenv("AUTH_JWT_RS256_PUBLIC_KEY_FILE")
+	c.AuthJWTIssuer = os.Getenv("AUTH_JWT_ISSUER")
+	c.AuthJWTAudience = os.Getenv("AUTH_JWT_AUDIENCE")
+	if c.AuthJWTLeeway, err = envDuration("AUTH_JWT_LEEWAY"); err != nil {
+		return c, fmt.Errorf("parse env: %w", err)
+	}
+	if c.EventsReplaySize, err = envInt("EVENTS_REPLAY_SIZE", defaultEventsReplaySize); err != nil {
+		return c, fmt.Errorf("parse env: %w", err)
+	}
+	if c.WebhookMaxAttempts, err = envInt("WEBHOOK_MAX_ATTEMPTS", 0); err != nil {
+		return c, fmt.Errorf("parse env: %w", err)
+	}
+	if c.WebhookBackoff, err = envDuration("WEBHOOK_BACKOFF"); err != nil {
+		return c, fmt.Errorf("parse env: %w", err)
+	}
+	if c.WebhookMaxBackoff, err = envDuration("WEBHOOK_MAX_BACKOFF"); err != nil {
+		return c, fmt.Errorf("parse env: %w", err)
+	}
+	if c.WebhookTimeout, err = envDuration("WEBHOOK_TIMEOUT"); err != nil {
+		return c, fmt.Errorf("parse env: %w", err)
+	}
+	if c.WebhookPollInterval, err = envDuration("WEBHOOK_POLL_INTERVAL"); err != nil {
+		return c, fmt.Errorf("parse env: %w", err)
+	}
+	c.OutboxFile = os.Getenv("OUTBOX_FILE")
+	if c.OutboxPublishers, err = parseOutboxPublishers(envString("OUTBOX_PUBLISHERS", defaultOutboxPublishers), c.OutboxFile); err != nil {
+		return c, fmt.Errorf("parse env: %w", err)
+	}
+	if c.OutboxPollInterval, err = envDuration("OUTBOX_POLL_INTERVAL"); err != nil {
+		return c, fmt.Errorf("parse env: %w", err)
+	}
+	if c.OutboxBatchSize, err = envInt("OUTBOX_BATCH_SIZE", 0); err != nil {
+		return c, fmt.Errorf("parse env: %w", err)
+	}
+	if c.SpeciesCacheTTL, err = envDuration("SPECIES_CACHE_TTL"); err != nil {
+		return c, fmt.Errorf("parse env: %w", err)
+	}
+	c.CohabitationRulesFile = os.Getenv("COHABITATION_RULES_FILE")
+	if c.CohabitationRulesTTL, err = envDuration("COHABITATION_RULES_TTL"); err != nil {
+		return c, fmt.Errorf("parse env: %w", err)
+	}
+
+	if c.MemStore {
+		return c, nil
+	}
+
 	switch "" {
 	case dbUser, dbPass, dbName:
 		return c, fmt.Errorf("parse env: invalid config provided")
@@ -41,5 +199,190 @@ func (c Config) parseEnv() (Config, error) {
 	c.DBName = dbName
 	c.DBPass = dbPass
 	c.DBUser = dbUser
+	c.DBHost = envString("DB_HOST", defaultDBHost)
+	c.DBSSLMode = envString("DB_SSLMODE", defaultDBSSLMode)
+	c.DBSSLRootCert = os.Getenv("DB_SSLROOTCERT")
+
+	if c.DBPort, err = envInt("DB_PORT", defaultDBPort); err != nil {
+		return c, fmt.Errorf("parse env: %w", err)
+	}
+	if c.DBMaxIdleConns, err = envInt("DB_MAX_IDLE_CONNS", defaultDBMaxIdleConns); err != nil {
+		return c, fmt.Errorf("parse env: %w", err)
+	}
+	if c.DBMaxOpenConns, err = envInt("DB_MAX_OPEN_CONNS", defaultDBMaxOpenConns); err != nil {
+		return c, fmt.Errorf("parse env: %w", err)
+	}
+	if c.DBConnectTimeout, err = envDuration("DB_CONNECT_TIMEOUT"); err != nil {
+		return c, fmt.Errorf("parse env: %w", err)
+	}
+	if c.DBStatementTimeout, err = envDuration("DB_STATEMENT_TIMEOUT"); err != nil {
+		return c, fmt.Errorf("parse env: %w", err)
+	}
+	if c.DBConnMaxLifetime, err = envDuration("DB_CONN_MAX_LIFETIME"); err != nil {
+		return c, fmt.Errorf("parse env: %w", err)
+	}
+	if c.DBConnMaxIdleTime, err = envDuration("DB_CONN_MAX_IDLE_TIME"); err != nil {
+		return c, fmt.Errorf("parse env: %w", err)
+	}
 	return c, nil
 }
+
+// DBConfig - returns the db configuration.
+func (c Config) DBConfig() db.Config {
+	return db.Config{
+		User:             c.DBUser,
+		Password:         c.DBPass,
+		Name:             c.DBName,
+		Host:             c.DBHost,
+		Port:             c.DBPort,
+		SSLMode:          c.DBSSLMode,
+		SSLRootCert:      c.DBSSLRootCert,
+		ConnectTimeout:   c.DBConnectTimeout,
+		StatementTimeout: c.DBStatementTimeout,
+		MaxIdleConns:     c.DBMaxIdleConns,
+		MaxOpenConns:     c.DBMaxOpenConns,
+		ConnMaxLifetime:  c.DBConnMaxLifetime,
+		ConnMaxIdleTime:  c.DBConnMaxIdleTime,
+	}
+}
+
+// TracingConfig - returns the tracing configuration.
+func (c Config) TracingConfig() tracing.Config {
+	return tracing.Config{
+		ServiceName: c.TraceServiceName,
+		Exporter:    c.TraceExporter,
+	}
+}
+
+// WebhookConfig - returns the webhook delivery configuration.
+func (c Config) WebhookConfig() webhook.Config {
+	return webhook.Config{
+		MaxAttempts:  c.WebhookMaxAttempts,
+		Backoff:      c.WebhookBackoff,
+		MaxBackoff:   c.WebhookMaxBackoff,
+		Timeout:      c.WebhookTimeout,
+		PollInterval: c.WebhookPollInterval,
+	}
+}
+
+// OutboxConfig - returns the outbox relay configuration.
+func (c Config) OutboxConfig() outbox.Config {
+	return outbox.Config{
+		PollInterval: c.OutboxPollInterval,
+		BatchSize:    c.OutboxBatchSize,
+	}
+}
+
+// SpeciesConfig - returns the species registry configuration.
+func (c Config) SpeciesConfig() species.Config {
+	return species.Config{
+		CacheTTL: c.SpeciesCacheTTL,
+	}
+}
+
+// CohabitationConfig - returns the cohabitation rules configuration.
+func (c Config) CohabitationConfig() cohabitation.Config {
+	return cohabitation.Config{
+		CacheTTL: c.CohabitationRulesTTL,
+	}
+}
+
+// JWTVerifier - returns the jwt verifier, reading the RS256 public key file if any.
+func (c Config) JWTVerifier() (*api.JWTVerifier, error) {
+	v := api.JWTVerifier{
+		HMACSecret: []byte(c.AuthJWTSecret),
+		Issuer:     c.AuthJWTIssuer,
+		Audience:   c.AuthJWTAudience,
+		Leeway:     c.AuthJWTLeeway,
+	}
+	if c.AuthJWTPublicKeyFile != "" {
+		data, err := os.ReadFile(c.AuthJWTPublicKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("jwt verifier: unable to read public key: %w", err)
+		}
+		if v.RSAPublicKey, err = api.ParseRSAPublicKey(data); err != nil {
+			return nil, fmt.Errorf("jwt verifier: %w", err)
+		}
+	}
+	return &v, nil
+}
+
+// parseAPIKeys - parses comma separated name:sha256hex[:role] entries into a map of key hashes to principals.
+// Keys are granted the viewer role unless set.
+func parseAPIKeys(v string) (map[string]api.Principal, error) {
+	keys := map[string]api.Principal{}
+	for _, entry := range strings.Split(v, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.Split(entry, ":")
+		if len(parts) == 2 {
+			parts = append(parts, string(core.RoleViewer))
+		}
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("parse api keys: invalid api key %q, expected name:sha256hex[:role]", parts[0])
+		}
+		name, hash, role := parts[0], strings.ToLower(parts[1]), core.Role(parts[2])
+		if b, err := hex.DecodeString(hash); name == "" || err != nil || len(b) != 32 {
+			return nil, fmt.Errorf("parse api keys: invalid api key %q, expected name:sha256hex[:role]", name)
+		}
+		if !role.Valid() {
+			return nil, fmt.Errorf("parse api keys: invalid role %q for api key %q", role, name)
+		}
+		keys[hash] = api.Principal{Subject: name, Role: string(role)}
+	}
+	return keys, nil
+}
+
+// parseOutboxPublishers - parses comma separated outbox publisher names. The file publisher requires a file.
+func parseOutboxPublishers(v, file string) ([]string, error) {
+	var pubs []string
+	for _, name := range strings.Split(v, ",") {
+		name = strings.TrimSpace(name)
+		switch name {
+		case "":
+			continue
+		case OutboxPublisherInProcess:
+		case OutboxPublisherFile:
+			if file == "" {
+				return nil, fmt.Errorf("parse outbox publishers: OUTBOX_FILE is required by the %s publisher", name)
+			}
+		default:
+			return nil, fmt.Errorf("parse outbox publishers: unknown publisher %q", name)
+		}
+		pubs = append(pubs, name)
+	}
+	return pubs, nil
+}
+
+func envString(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+func envInt(key string, fallback int) (int, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("env int: invalid %s %q", key, v)
+	}
+	return n, nil
+}
+
+func envDuration(key string) (time.Duration, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("env duration: invalid %s %q", key, v)
+	}
+	return d, nil
+}
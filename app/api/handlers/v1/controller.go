@@ -1,51 +1,185 @@
 package v1
 
 import (
+	"context"
 	"fmt"
+	"sync"
 
+	"github.com/lenguti/jppp/business/core"
+	"github.com/lenguti/jppp/business/core/apikey"
+	"github.com/lenguti/jppp/business/core/apikey/stores/apikeydb"
+	"github.com/lenguti/jppp/business/core/audit"
+	"github.com/lenguti/jppp/business/core/audit/stores/auditdb"
 	"github.com/lenguti/jppp/business/core/cage"
 	"github.com/lenguti/jppp/business/core/cage/stores/cagedb"
+	"github.com/lenguti/jppp/business/core/cohabitation"
+	"github.com/lenguti/jppp/business/core/cohabitation/stores/cohabitationdb"
 	"github.com/lenguti/jppp/business/core/dino"
 	"github.com/lenguti/jppp/business/core/dino/stores/dinodb"
+	"github.com/lenguti/jppp/business/core/event"
+	"github.com/lenguti/jppp/business/core/outbox"
+	"github.com/lenguti/jppp/business/core/outbox/stores/outboxdb"
+	"github.com/lenguti/jppp/business/core/placement"
+	"github.com/lenguti/jppp/business/core/placement/stores/placementdb"
+	"github.com/lenguti/jppp/business/core/species"
+	"github.com/lenguti/jppp/business/core/species/stores/speciesdb"
+	"github.com/lenguti/jppp/business/core/webhook"
+	"github.com/lenguti/jppp/business/core/webhook/stores/webhookdb"
 	"github.com/lenguti/jppp/business/data/db"
+	"github.com/lenguti/jppp/business/data/memstore"
 	"github.com/lenguti/jppp/foundation/api"
 	"github.com/rs/zerolog"
 )
 
 // Controller - represents our handler service orchestrator.
 type Controller struct {
-	Cage *cage.Core
-	Dino *dino.Core
+	Cage      *cage.Core
+	Dino      *dino.Core
+	APIKey    *apikey.Core
+	Audit     *audit.Core
+	Placement *placement.Core
+	Webhook   *webhook.Core
+	Outbox    *outbox.Core
+	Species   *species.Core
+	Rules     *cohabitation.Core
 
-	db     *db.DB
-	config Config
-	log    zerolog.Logger
-	router *api.Router
+	db      *db.DB
+	config  Config
+	log     zerolog.Logger
+	metrics *metrics
+	events  *event.Broker
+	router  *api.Router
 }
 
 // NewController - initializes a new controller with all its services.
 func NewController(log zerolog.Logger, cfg Config) (*Controller, error) {
-	ddb, err := db.New(db.Config{
-		User:         cfg.DBUser,
-		Password:     cfg.DBPass,
-		Name:         cfg.DBName,
-		MaxIdleConns: 10,
-		MaxOpenConns: 10,
-	})
+	var (
+		ddb         *db.DB
+		cageStore   cage.Storer
+		dinoStore   dino.Storer
+		apiKeyStore apikey.Storer
+		auditStore  audit.Storer
+		placeStore  placement.Storer
+		hookStore   webhook.Storer
+		boxStore    outbox.Storer
+		specStore   species.Storer
+		ruleStore   cohabitation.Storer
+	)
+	switch {
+	case cfg.MemStore:
+		ms := memstore.New()
+		cageStore = memstore.NewCageStore(ms)
+		dinoStore = memstore.NewDinoStore(ms)
+		apiKeyStore = memstore.NewAPIKeyStore(ms)
+		auditStore = memstore.NewAuditStore(ms)
+		placeStore = memstore.NewPlacementStore(ms)
+		hookStore = memstore.NewWebhookStore(ms)
+		boxStore = memstore.NewOutboxStore(ms)
+		specStore = memstore.NewSpeciesStore(ms)
+		ruleStore = memstore.NewCohabitationStore(ms)
+	default:
+		var err error
+		ddb, err = db.New(cfg.DBConfig())
+		if err != nil {
+			return nil, fmt.Errorf("new controller: unable to initialize new db: %w", err)
+		}
+		if cfg.AutoMigrate {
+			log.Info().Msg("Applying migrations.")
+			if err := ddb.Migrate(context.Background(), db.MigrateUp); err != nil {
+				return nil, fmt.Errorf("new controller: unable to apply migrations: %w", err)
+			}
+		}
+		cageStore = cagedb.NewStore(ddb)
+		dinoStore = dinodb.NewStore(ddb)
+		apiKeyStore = apikeydb.NewStore(ddb)
+		auditStore = auditdb.NewStore(ddb)
+		placeStore = placementdb.NewStore(ddb)
+		hookStore = webhookdb.NewStore(ddb)
+		boxStore = outboxdb.NewStore(ddb)
+		specStore = speciesdb.NewStore(ddb)
+		ruleStore = cohabitationdb.NewStore(ddb)
+	}
+	if cfg.CohabitationRulesFile != "" {
+		ruleStore = cohabitation.NewFileStore(cfg.CohabitationRulesFile)
+	}
+
+	jwt, err := cfg.JWTVerifier()
 	if err != nil {
-		return nil, fmt.Errorf("new controller: unable to initialize new db: %w", err)
+		return nil, fmt.Errorf("new controller: unable to initialize jwt verifier: %w", err)
 	}
 
-	dc := dino.NewCore(dinodb.NewStore(ddb), log)
-	cc := cage.NewCore(cagedb.NewStore(ddb), log, dc)
+	events := event.NewBroker(cfg.EventsReplaySize)
+	wc := webhook.NewCore(hookStore, log, cfg.WebhookConfig())
+	var pubs event.Publishers
+	for _, name := range cfg.OutboxPublishers {
+		switch name {
+		case OutboxPublisherInProcess:
+			pubs = append(pubs, events, wc)
+		case OutboxPublisherFile:
+			pubs = append(pubs, event.NewFilePublisher(cfg.OutboxFile))
+		}
+	}
+	oc := outbox.NewCore(boxStore, log, pubs, cfg.OutboxConfig())
+	sc := species.NewCore(specStore, log, cfg.SpeciesConfig())
+	rc := cohabitation.NewCore(ruleStore, log, sc, cfg.CohabitationConfig())
+	if _, err := rc.Rules(context.Background()); err != nil {
+		return nil, fmt.Errorf("new controller: invalid cohabitation rules: %w", err)
+	}
+	dc := dino.NewCore(dinoStore, log, sc)
+	cc := cage.NewCore(cageStore, log, dc, rc)
+	kc := apikey.NewCore(apiKeyStore, log)
+	ac := audit.NewCore(auditStore, log)
+	pc := placement.NewCore(placeStore, log)
+	m := newMetrics(cc, dc)
+	auth := api.Authenticator{
+		Keys: api.KeyStores{api.StaticKeys(cfg.AuthAPIKeys), apiKeys{core: kc}},
+		JWT:  jwt,
+	}
 
 	return &Controller{
-		Cage: cc,
-		Dino: dc,
+		Cage:      cc,
+		Dino:      dc,
+		APIKey:    kc,
+		Audit:     ac,
+		Placement: pc,
+		Webhook:   wc,
+		Outbox:    oc,
+		Species:   sc,
+		Rules:     rc,
 
-		db:     ddb,
-		config: cfg,
-		log:    log,
-		router: api.NewRouter(),
+		db:      ddb,
+		config:  cfg,
+		log:     log,
+		metrics: m,
+		events:  events,
+		router:  api.NewRouter(api.Trace(), api.RequestIDs(), api.Logger(log), api.Metrics(m.registry), api.Errors(), api.Panics(), api.Auth(auth, publicRoutes...), authorize(publicRoutes...)),
 	}, nil
 }
+
+// Run - runs the background work of the services, the outbox relay and the webhook deliveries, until
+// the context is done.
+func (c *Controller) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		c.Outbox.Run(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		c.Webhook.Run(ctx)
+	}()
+	wg.Wait()
+}
+
+// Close - ends the event streams, which would otherwise hold the server open on shutdown.
+func (c *Controller) Close() {
+	if c.events != nil {
+		c.events.Close()
+	}
+}
+
+// logger - returns the request scoped logger, falling back to the controller logger.
+func (c *Controller) logger(ctx context.Context) *zerolog.Logger {
+	return core.Logger(ctx, c.log)
+}
@@ -4,30 +4,32 @@ import (
 	"context"
 	"errors"
 	"net/http"
-	"strings"
+	"strconv"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/lenguti/jppp/business/core"
 	"github.com/lenguti/jppp/business/core/dino"
+	"github.com/lenguti/jppp/business/core/species"
 	"github.com/lenguti/jppp/foundation/api"
 )
 
-// CreateDinoRequest - represents input for creating a new dinosaur.
+// CreateDinoRequest - represents input for creating a new dinosaur. HatchedAt is an optional unix time.
 type CreateDinoRequest struct {
-	Name    string `json:"name"`
-	Species string `json:"species"`
-	Diet    string `json:"diet"`
+	Name      string `json:"name"`
+	Species   string `json:"species"`
+	Diet      string `json:"diet"`
+	HatchedAt int64  `json:"hatchedAt"`
 }
 
-func (cdr *CreateDinoRequest) validate() *api.ValidationError {
+func (cdr *CreateDinoRequest) validate(sp species.Species, known bool) *api.ValidationError {
 	e := api.NewValidationError()
 
 	if cdr.Name == "" {
 		e.Add("name", "is required")
 	}
 
-	dinoDiet, err := dino.ParseSpecies(cdr.Species)
-	if err != nil {
+	if !known {
 		e.Add("species", "is invalid")
 	}
 
@@ -35,10 +37,14 @@ func (cdr *CreateDinoRequest) validate() *api.ValidationError {
 		e.Add("diet", "is invalid")
 	}
 
-	if dinoDiet != dino.Diet(cdr.Diet) {
+	if sp.Diet != cdr.Diet {
 		e.Add("species diet", "is invalid")
 	}
 
+	if cdr.HatchedAt < 0 || cdr.HatchedAt > time.Now().Unix() {
+		e.Add("hatchedAt", "is invalid")
+	}
+
 	return e
 }
 
@@ -49,26 +55,33 @@ type CreateDinoResponse struct {
 
 // CreateCage - invoked by POST /v1/dinosaurs.
 func (c *Controller) CreateDino(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
-	c.log.Info().Msg("Creating Dino.")
+	c.logger(ctx).Info().Msg("Creating Dino.")
 
 	var input CreateDinoRequest
 	if err := api.Decode(r, &input); err != nil {
-		c.log.Err(err).Msg("Unable to decode create dino request.")
+		c.logger(ctx).Err(err).Msg("Unable to decode create dino request.")
 		return api.BadRequestError("Invalid input.", err, nil)
 	}
 
-	if validated := input.validate(); !validated.IsClean() {
-		c.log.Err(validated).Msg("Validation input failed.")
+	sp, err := c.Dino.ParseSpecies(ctx, input.Species)
+	if err != nil && !errors.Is(err, core.ErrNotFound) {
+		c.logger(ctx).Err(err).Msg("Unable to look up species.")
+		return toHTTPError(err)
+	}
+
+	if validated := input.validate(sp, err == nil); !validated.IsClean() {
+		c.logger(ctx).Err(validated).Msg("Validation input failed.")
 		return api.BadRequestError("Invalid input.", validated, validated.Details())
 	}
 
 	d, err := c.Dino.Create(ctx, toCoreNewDino(input))
 	if err != nil {
-		c.log.Err(err).Msg("Unable to create dino.")
-		return api.InternalServerError("Error.", err, nil)
+		c.logger(ctx).Err(err).Msg("Unable to create dino.")
+		return toHTTPError(err)
 	}
 
-	c.log.Info().Msg("Successfully created Dino.")
+	c.logger(ctx).Info().Msg("Successfully created Dino.")
+	api.SetETag(w, strconv.Itoa(d.Version))
 	return api.Respond(w, http.StatusCreated, CreateDinoResponse{Dinosaur: toClientDino(d)})
 }
 
@@ -79,17 +92,23 @@ type ListDinoSpeciesResponse struct {
 
 // ListDinoSpecies - invoked by GET /v1/dinosaures/species.
 func (c *Controller) ListDinoSpecies(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
-	c.log.Info().Msg("Listing dino species.")
+	c.logger(ctx).Info().Msg("Listing dino species.")
 
-	out := make([]ClientDinoSpecies, 0, len(dino.DinoSpeciesMapping))
-	for species, diet := range dino.DinoSpeciesMapping {
+	ss, err := c.Species.All(ctx)
+	if err != nil {
+		c.logger(ctx).Err(err).Msg("Unable to list dino species.")
+		return toHTTPError(err)
+	}
+
+	out := make([]ClientDinoSpecies, 0, len(ss))
+	for _, s := range ss {
 		out = append(out, ClientDinoSpecies{
-			Species: species,
-			Diet:    diet.String(),
+			Species: s.Name,
+			Diet:    s.Diet,
 		})
 	}
 
-	c.log.Info().Msg("Successfully listed dino species.")
+	c.logger(ctx).Info().Msg("Successfully listed dino species.")
 	return api.Respond(w, http.StatusOK, ListDinoSpeciesResponse{DinoSpecies: out})
 }
 
@@ -100,45 +119,56 @@ type GetDinoResponse struct {
 
 // GetDino - invoked by GET /v1/dinosaurs/:id.
 func (c *Controller) GetDino(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
-	c.log.Info().Msg("Fetching Dino.")
+	c.logger(ctx).Info().Msg("Fetching Dino.")
 
 	idStr := api.PathParam(r, idPathParam)
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		c.log.Err(err).Msg("Invalid dino id.")
+		c.logger(ctx).Err(err).Msg("Invalid dino id.")
 		return api.BadRequestError("Invalid id.", err, nil)
 	}
 
 	d, err := c.Dino.Get(ctx, id)
 	if err != nil {
-		c.log.Err(err).Msg("Unable to fetch dino.")
-		if errors.Is(err, core.ErrNotFound) {
-			return api.NotFoundError("Item not found.", err, nil)
-		}
-		return api.InternalServerError("Error.", err, nil)
+		c.logger(ctx).Err(err).Msg("Unable to fetch dino.")
+		return toHTTPError(err)
 	}
 
-	c.log.Info().Msg("Successfully fetched Dino.")
+	c.logger(ctx).Info().Msg("Successfully fetched Dino.")
+	api.SetETag(w, strconv.Itoa(d.Version))
 	return api.Respond(w, http.StatusOK, GetDinoResponse{Dinosaur: toClientDino(d)})
 }
 
 // ListDinosResponse - represents a client list dinos response.
 type ListDinosResponse struct {
-	Dinosaurs []ClientDino `json:"dinosaurs"`
+	Dinosaurs  []ClientDino `json:"dinosaurs"`
+	NextCursor string       `json:"next_cursor,omitempty"`
 }
 
 // ListDinos - invoked by GET /v1/dinosaurs.
 func (c *Controller) ListDinos(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
-	c.log.Info().Msg("Listing Dinos.")
+	c.logger(ctx).Info().Msg("Listing Dinos.")
+
+	page, validated := parsePage(r, dino.SortFields...)
+	if !validated.IsClean() {
+		c.logger(ctx).Err(validated).Msg("Validation input failed.")
+		return api.BadRequestError("Invalid input.", validated, validated.Details())
+	}
 
-	ds, err := c.Dino.List(ctx)
+	filters, validated := parseFilters(r, dino.FilterFields)
+	if !validated.IsClean() {
+		c.logger(ctx).Err(validated).Msg("Validation input failed.")
+		return api.BadRequestError("Invalid input.", validated, validated.Details())
+	}
+
+	ds, next, err := c.Dino.List(ctx, page, filters...)
 	if err != nil {
-		c.log.Err(err).Msg("Unable to list dinos.")
-		return api.InternalServerError("Error.", err, nil)
+		c.logger(ctx).Err(err).Msg("Unable to list dinos.")
+		return toHTTPError(err)
 	}
 
-	c.log.Info().Msg("Successfully listed Dinos.")
-	return api.Respond(w, http.StatusOK, ListDinosResponse{Dinosaurs: toClientDinos(ds)})
+	c.logger(ctx).Info().Msg("Successfully listed Dinos.")
+	return api.Respond(w, http.StatusOK, ListDinosResponse{Dinosaurs: toClientDinos(ds), NextCursor: next})
 }
 
 // UpdateDinoRequest - represents input for updating a dinosaur.
@@ -163,67 +193,222 @@ type UpdateDinoResponse struct {
 
 // UpdateDino - invoked by PATCH /v1/dinosaurs/:id.
 func (c *Controller) UpdateDino(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
-	c.log.Info().Msg("Updating Dinosaur.")
+	c.logger(ctx).Info().Msg("Updating Dinosaur.")
 
 	var input UpdateDinoRequest
 	if err := api.Decode(r, &input); err != nil {
-		c.log.Err(err).Msg("Unable to decode update dino request.")
+		c.logger(ctx).Err(err).Msg("Unable to decode update dino request.")
 		return api.BadRequestError("Invalid input.", err, nil)
 	}
 
 	if validated := input.validate(); !validated.IsClean() {
-		c.log.Err(validated).Msg("Validation input failed.")
+		c.logger(ctx).Err(validated).Msg("Validation input failed.")
 		return api.BadRequestError("Invalid input.", validated, validated.Details())
 	}
 
 	idStr := api.PathParam(r, idPathParam)
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		c.log.Err(err).Msg("Invalid dino id.")
+		c.logger(ctx).Err(err).Msg("Invalid dino id.")
 		return api.BadRequestError("Invalid id.", err, nil)
 	}
 
-	d, err := c.Dino.UpdateName(ctx, id, input.Name)
+	version, err := ifMatchVersion(r)
 	if err != nil {
-		c.log.Err(err).Msg("Unable to update dino.")
-		if errors.Is(err, core.ErrNotFound) {
-			return api.NotFoundError("Item not found.", err, nil)
-		}
-		return api.InternalServerError("Error.", err, nil)
+		c.logger(ctx).Err(err).Msg("Invalid if match header.")
+		return api.PreconditionFailedError(core.ErrPreconditionFailed.Error(), err, nil)
+	}
+
+	d, err := c.Dino.UpdateName(ctx, id, input.Name, version)
+	if err != nil {
+		c.logger(ctx).Err(err).Msg("Unable to update dino.")
+		return toHTTPError(err)
 	}
 
-	c.log.Info().Msg("Successfully updated Dinosaur.")
+	c.logger(ctx).Info().Msg("Successfully updated Dinosaur.")
+	api.SetETag(w, strconv.Itoa(d.Version))
 	return api.Respond(w, http.StatusOK, UpdateDinoResponse{Dinosaur: toClientDino(d)})
 }
 
 // ListCageDinosaursResponse - represents a client list cage dinosaurs response.
 type ListCageDinosaursResponse struct {
-	Dinosaurs []ClientDino `json:"dinosaurs"`
+	Dinosaurs  []ClientDino `json:"dinosaurs"`
+	NextCursor string       `json:"next_cursor,omitempty"`
 }
 
 // ListCageDinosaurs - invoked by GET /v1/cages/:id/dinosaurs.
 func (c *Controller) ListCageDinosaurs(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
-	c.log.Info().Msg("Listing Dinosaurs for Cage.")
+	c.logger(ctx).Info().Msg("Listing Dinosaurs for Cage.")
 
 	cageIDStr := api.PathParam(r, idPathParam)
 	cageID, err := uuid.Parse(cageIDStr)
 	if err != nil {
-		c.log.Err(err).Msg("Invalid cage id.")
+		c.logger(ctx).Err(err).Msg("Invalid cage id.")
 		return api.BadRequestError("Invalid cage id.", err, nil)
 	}
 
-	species := api.QueryParam(r, queryParamSpecies)
-	var filters []core.Filter
-	if species != "" {
-		filters = append(filters, core.Filter{Key: queryParamSpecies, Value: strings.Title(species)})
+	page, validated := parsePage(r, dino.SortFields...)
+	if !validated.IsClean() {
+		c.logger(ctx).Err(validated).Msg("Validation input failed.")
+		return api.BadRequestError("Invalid input.", validated, validated.Details())
+	}
+
+	filters, validated := parseFilters(r, dino.FilterFields)
+	if !validated.IsClean() {
+		c.logger(ctx).Err(validated).Msg("Validation input failed.")
+		return api.BadRequestError("Invalid input.", validated, validated.Details())
+	}
+
+	dns, next, err := c.Dino.ListByCageID(ctx, cageID, page, filters...)
+	if err != nil {
+		c.logger(ctx).Err(err).Msg("Unable to list dinosaurs for Cage.")
+		return toHTTPError(err)
+	}
+
+	c.logger(ctx).Info().Msg("Successfully listed Dinosaurs for Cage.")
+	return api.Respond(w, http.StatusOK, ListCageDinosaursResponse{Dinosaurs: toClientDinos(dns), NextCursor: next})
+}
+
+// TransferDinoRequest - represents input for moving a dinosaur between cages.
+type TransferDinoRequest struct {
+	FromCageID string `json:"from_cage_id"`
+	ToCageID   string `json:"to_cage_id"`
+}
+
+func (tdr *TransferDinoRequest) validate() *api.ValidationError {
+	e := api.NewValidationError()
+
+	if tdr.FromCageID != "" {
+		if _, err := uuid.Parse(tdr.FromCageID); err != nil {
+			e.Add("from_cage_id", "is invalid")
+		}
+	}
+
+	if _, err := uuid.Parse(tdr.ToCageID); err != nil {
+		e.Add("to_cage_id", "is invalid")
+	}
+
+	return e
+}
+
+// TransferDinoResponse - represents a client transfer dino response.
+type TransferDinoResponse struct {
+	Dinosaur ClientDino `json:"dinosaur"`
+	From     ClientCage `json:"from"`
+	To       ClientCage `json:"to"`
+}
+
+// TransferDino - invoked by POST /v1/dinosaurs/:id/transfer.
+func (c *Controller) TransferDino(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	c.logger(ctx).Info().Msg("Transferring Dinosaur.")
+
+	var input TransferDinoRequest
+	if err := api.Decode(r, &input); err != nil {
+		c.logger(ctx).Err(err).Msg("Unable to decode transfer dino request.")
+		return api.BadRequestError("Invalid input.", err, nil)
+	}
+
+	if validated := input.validate(); !validated.IsClean() {
+		c.logger(ctx).Err(validated).Msg("Validation input failed.")
+		return api.BadRequestError("Invalid input.", validated, validated.Details())
+	}
+
+	idStr := api.PathParam(r, idPathParam)
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.logger(ctx).Err(err).Msg("Invalid dino id.")
+		return api.BadRequestError("Invalid id.", err, nil)
+	}
+
+	var fromID uuid.UUID
+	if input.FromCageID != "" {
+		fromID = uuid.MustParse(input.FromCageID)
+	} else {
+		d, err := c.Dino.Get(ctx, id)
+		if err != nil {
+			c.logger(ctx).Err(err).Msg("Unable to fetch dino.")
+			return toHTTPError(err)
+		}
+		fromID = d.CageID
+	}
+
+	t, err := c.Cage.TransferDino(ctx, fromID, uuid.MustParse(input.ToCageID), id)
+	if err != nil {
+		c.logger(ctx).Err(err).Msg("Unable to transfer dino.")
+		return toHTTPError(err)
+	}
+
+	c.logger(ctx).Info().Msg("Successfully transferred Dinosaur.")
+	api.SetETag(w, strconv.Itoa(t.Dino.Version))
+	return api.Respond(w, http.StatusOK, TransferDinoResponse{
+		Dinosaur: toClientDino(t.Dino),
+		From:     toClientCage(t.From),
+		To:       toClientCage(t.To),
+	})
+}
+
+// DeleteDinoResponse - represents a client delete dino response.
+type DeleteDinoResponse struct {
+	Dinosaur ClientDino `json:"dinosaur"`
+}
+
+// DeleteDino - invoked by DELETE /v1/dinosaurs/:id.
+func (c *Controller) DeleteDino(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	c.logger(ctx).Info().Msg("Deleting Dinosaur.")
+
+	idStr := api.PathParam(r, idPathParam)
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.logger(ctx).Err(err).Msg("Invalid dino id.")
+		return api.BadRequestError("Invalid id.", err, nil)
+	}
+
+	version, err := ifMatchVersion(r)
+	if err != nil {
+		c.logger(ctx).Err(err).Msg("Invalid if match header.")
+		return api.PreconditionFailedError(core.ErrPreconditionFailed.Error(), err, nil)
+	}
+
+	d, err := c.Dino.Delete(ctx, id, version)
+	if err != nil {
+		c.logger(ctx).Err(err).Msg("Unable to delete dino.")
+		return toHTTPError(err)
+	}
+
+	c.logger(ctx).Info().Msg("Successfully deleted Dinosaur.")
+	api.SetETag(w, strconv.Itoa(d.Version))
+	return api.Respond(w, http.StatusOK, DeleteDinoResponse{Dinosaur: toClientDino(d)})
+}
+
+// RestoreDinoResponse - represents a client restore dino response.
+type RestoreDinoResponse struct {
+	Dinosaur ClientDino `json:"dinosaur"`
+}
+
+// RestoreDino - invoked by POST /v1/dinosaurs/:id/restore.
+func (c *Controller) RestoreDino(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	c.logger(ctx).Info().Msg("Restoring Dinosaur.")
+
+	idStr := api.PathParam(r, idPathParam)
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.logger(ctx).Err(err).Msg("Invalid dino id.")
+		return api.BadRequestError("Invalid id.", err, nil)
+	}
+
+	version, err := ifMatchVersion(r)
+	if err != nil {
+		c.logger(ctx).Err(err).Msg("Invalid if match header.")
+		return api.PreconditionFailedError(core.ErrPreconditionFailed.Error(), err, nil)
 	}
 
-	dns, err := c.Dino.ListByCageID(ctx, cageID, filters...)
+	d, err := c.Dino.Restore(ctx, id, version)
 	if err != nil {
-		c.log.Err(err).Msg("Unable to list dinosaurs for Cage.")
-		return api.InternalServerError("Error.", err, nil)
+		c.logger(ctx).Err(err).Msg("Unable to restore dino.")
+		return toHTTPError(err)
 	}
 
-	c.log.Info().Msg("Successfully listed Dinosaurs for Cage.")
-	return api.Respond(w, http.StatusOK, ListCageDinosaursResponse{Dinosaurs: toClientDinos(dns)})
+	c.logger(ctx).Info().Msg("Successfully restored Dinosaur.")
+	api.SetETag(w, strconv.Itoa(d.Version))
+	return api.Respond(w, http.StatusOK, RestoreDinoResponse{Dinosaur: toClientDino(d)})
 }
@@ -2,6 +2,7 @@ package v1
 
 import (
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/lenguti/jppp/business/core/dino"
@@ -14,8 +15,11 @@ type ClientDino struct {
 	Name      string `json:"name"`
 	Species   string `json:"species"`
 	Diet      string `json:"diet"`
+	HatchedAt int64  `json:"hatchedAt,omitempty"`
+	Version   int    `json:"version"`
 	CreatedAt int64  `json:"createdAt"`
 	UpdatedAt int64  `json:"updatedAt"`
+	DeletedAt int64  `json:"deletedAt,omitempty"`
 }
 
 func toCoreNewDino(input CreateDinoRequest) dino.NewDino {
@@ -24,6 +28,9 @@ func toCoreNewDino(input CreateDinoRequest) dino.NewDino {
 		Species: strings.Title(input.Species),
 		Diet:    dino.Diet(strings.ToUpper(input.Diet)),
 	}
+	if input.HatchedAt != 0 {
+		newDino.HatchedAt = time.Unix(input.HatchedAt, 0).UTC()
+	}
 	return newDino
 }
 
@@ -47,11 +54,18 @@ func toClientDino(input dino.Dinosaur) ClientDino {
 		Name:      input.Name,
 		Species:   input.Species,
 		Diet:      input.Diet.String(),
+		Version:   input.Version,
 		CreatedAt: input.CreatedAt.Unix(),
 		UpdatedAt: input.UpdatedAt.Unix(),
 	}
 	if input.CageID != uuid.Nil {
 		cd.CageID = input.CageID.String()
 	}
+	if !input.HatchedAt.IsZero() {
+		cd.HatchedAt = input.HatchedAt.Unix()
+	}
+	if input.Deleted() {
+		cd.DeletedAt = input.DeletedAt.Unix()
+	}
 	return cd
 }
@@ -0,0 +1,113 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/lenguti/jppp/business/core"
+	"github.com/lenguti/jppp/business/core/cage"
+	"github.com/lenguti/jppp/business/core/cohabitation"
+	"github.com/lenguti/jppp/foundation/api"
+)
+
+// Stable error codes returned for business rule violations, clients may branch on them.
+const (
+	codeCageOccupied        = "CAGE_OCCUPIED"
+	codeCagePoweredDown     = "CAGE_POWERED_DOWN"
+	codeCageAtCapacity      = "CAGE_AT_CAPACITY"
+	codeDietMismatch        = "DIET_MISMATCH"
+	codeSpeciesConflict     = "SPECIES_CONFLICT"
+	codeSpeciesIncompatible = "SPECIES_INCOMPATIBLE"
+	codeJuvenileSeparation  = "JUVENILE_SEPARATION"
+	codeSolitarySpecies     = "SOLITARY_SPECIES"
+	codeCageEmpty           = "CAGE_EMPTY"
+	codeDinoAlreadyCaged    = "DINO_ALREADY_CAGED"
+	codeDinoNotInCage       = "DINO_NOT_IN_CAGE"
+	codeTransferSameCage    = "TRANSFER_SAME_CAGE"
+	codeCageNotEmpty        = "CAGE_NOT_EMPTY"
+	codeDinoCaged           = "DINO_CAGED"
+	codeSpeciesExists       = "SPECIES_EXISTS"
+	codeSpeciesInUse        = "SPECIES_IN_USE"
+	codeInvalidCursor       = "INVALID_CURSOR"
+	codeInvalidFilter       = "INVALID_FILTER"
+)
+
+// coreErrorStatus - represents the http status and code a core error is returned with.
+type coreErrorStatus struct {
+	status int
+	code   string
+}
+
+// coreErrors - maps every core error to its http status and code. Rules that depend on the
+// current state of an item are conflicts, rules the request can never satisfy are unprocessable.
+var coreErrors = map[core.Error]coreErrorStatus{
+	core.ErrPowerDownCage:                  {http.StatusConflict, codeCageOccupied},
+	core.ErrInvalidCagePowerDown:           {http.StatusConflict, codeCagePoweredDown},
+	core.ErrInvalidCageAtCapacity:          {http.StatusConflict, codeCageAtCapacity},
+	core.ErrInvalidCageInvalidType:         {http.StatusUnprocessableEntity, codeDietMismatch},
+	core.ErrInvalidCageInvalidSpecies:      {http.StatusConflict, codeSpeciesConflict},
+	core.ErrInvalidCageIncompatibleSpecies: {http.StatusConflict, codeSpeciesIncompatible},
+	core.ErrInvalidCageJuvenile:            {http.StatusConflict, codeJuvenileSeparation},
+	core.ErrInvalidCageSolitary:            {http.StatusConflict, codeSolitarySpecies},
+	core.ErrInvalidCageInvalidRemoval:      {http.StatusConflict, codeCageEmpty},
+	core.ErrInvalidCageDinoCaged:           {http.StatusConflict, codeDinoAlreadyCaged},
+	core.ErrInvalidCageDinoNotCaged:        {http.StatusConflict, codeDinoNotInCage},
+	core.ErrInvalidTransferSameCage:        {http.StatusUnprocessableEntity, codeTransferSameCage},
+	core.ErrInvalidCageNotEmpty:            {http.StatusConflict, codeCageNotEmpty},
+	core.ErrInvalidDinoCaged:               {http.StatusConflict, codeDinoCaged},
+	core.ErrInvalidSpeciesExists:           {http.StatusConflict, codeSpeciesExists},
+	core.ErrInvalidSpeciesInUse:            {http.StatusConflict, codeSpeciesInUse},
+	core.ErrPreconditionFailed:             {http.StatusPreconditionFailed, api.PreconditionFailed},
+	core.ErrConflict:                       {http.StatusConflict, api.Conflict},
+	core.ErrInvalidCursor:                  {http.StatusBadRequest, codeInvalidCursor},
+	core.ErrInvalidFilter:                  {http.StatusBadRequest, codeInvalidFilter},
+	core.ErrNotFound:                       {http.StatusNotFound, api.NotFound},
+	core.ErrForbidden:                      {http.StatusForbidden, api.Forbidden},
+}
+
+// errorCode - returns the code a core error is returned with.
+func errorCode(err core.Error) string {
+	if s, ok := coreErrors[err]; ok {
+		return s.code
+	}
+	return api.InternalServer
+}
+
+// toHTTPError - returns the api error for an error returned by the core, using the status and
+// code of the first core error in its chain and an internal server error otherwise.
+// Permission errors carry the permission the request lacks in their details and cohabitation rule
+// errors every violated rule. Errors refusing an assignment of a placement plan carry its dino and cage.
+func toHTTPError(err error) api.HTTPError {
+	var pe *core.PermissionError
+	if errors.As(err, &pe) {
+		return api.ForbiddenError("Permission denied.", err, map[string]any{"permission": string(pe.Permission), "role": string(pe.Role)})
+	}
+
+	details := map[string]any{}
+	var ae *cage.AssignmentError
+	if errors.As(err, &ae) {
+		details["dino_id"] = ae.Assignment.DinoID.String()
+		details["cage_id"] = ae.Assignment.CageID.String()
+	}
+	var me *cage.MoveError
+	if errors.As(err, &me) {
+		details["dino_id"] = me.Move.DinoID.String()
+		details["from_cage_id"] = me.Move.From.String()
+		details["to_cage_id"] = me.Move.To.String()
+	}
+	var re *cohabitation.RuleError
+	if errors.As(err, &re) {
+		details["violations"] = toClientViolations(re.Violations)
+	}
+	if len(details) == 0 {
+		details = nil
+	}
+
+	var ce core.Error
+	if errors.As(err, &ce) {
+		if s, ok := coreErrors[ce]; ok {
+			return api.CodedError(s.status, s.code, ce.Error(), err, details)
+		}
+	}
+	return api.InternalServerError("Error.", err, nil)
+}
@@ -0,0 +1,117 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lenguti/jppp/business/core/event"
+	"github.com/lenguti/jppp/foundation/api"
+)
+
+const (
+	// lastEventIDHeader - sent by reconnecting clients with the id of the last event they received.
+	lastEventIDHeader = "Last-Event-ID"
+
+	// eventsHeartbeat - how often a comment is sent on idle streams so proxies keep them open.
+	eventsHeartbeat = 15 * time.Second
+
+	// truncatedEvent - sent first when some of the events after Last-Event-ID can no longer be replayed,
+	// telling the client to refetch the state it holds.
+	truncatedEvent = "stream.truncated"
+)
+
+// parseEventFilter - returns the filter requested through the type, entity_type and cage_id query params,
+// which take comma separated values but for cage_id.
+func parseEventFilter(r *http.Request) (event.Filter, *api.ValidationError) {
+	e := api.NewValidationError()
+	split := func(v string) []string {
+		if v == "" {
+			return nil
+		}
+		return strings.Split(v, ",")
+	}
+
+	f := event.Filter{
+		Types:       split(api.QueryParam(r, "type")),
+		EntityTypes: split(api.QueryParam(r, "entity_type")),
+	}
+	if v := api.QueryParam(r, "cage_id"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			e.Add("cage_id", "is invalid")
+		}
+		f.CageID = id
+	}
+	return f, e
+}
+
+// StreamEvents - invoked by GET /v1/events, streaming park changes as server-sent events.
+func (c *Controller) StreamEvents(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	c.logger(ctx).Info().Msg("Streaming events.")
+
+	f, validated := parseEventFilter(r)
+	if !validated.IsClean() {
+		c.logger(ctx).Err(validated).Msg("Validation input failed.")
+		return api.BadRequestError("Invalid input.", validated, validated.Details())
+	}
+
+	var lastID uint64
+	if v := r.Header.Get(lastEventIDHeader); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			c.logger(ctx).Err(err).Msg("Invalid last event id.")
+			return api.BadRequestError("Invalid Last-Event-ID.", err, nil)
+		}
+		lastID = id
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return api.InternalServerError("Error.", fmt.Errorf("stream events: response writer does not support flushing"), nil)
+	}
+
+	sub, err := c.events.Subscribe(lastID, f)
+	if err != nil {
+		c.logger(ctx).Err(err).Msg("Unable to subscribe to events.")
+		return api.ServiceUnavailableError("Shutting down.", err, nil)
+	}
+	defer sub.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	if sub.Truncated {
+		fmt.Fprintf(w, "event: %s\ndata: {}\n\n", truncatedEvent)
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(eventsHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+		case e, ok := <-sub.C:
+			if !ok {
+				c.logger(ctx).Info().Msg("Event stream ended.")
+				return nil
+			}
+			data, err := json.Marshal(toClientEvent(e))
+			if err != nil {
+				c.logger(ctx).Err(err).Msg("Unable to encode event.")
+				return nil
+			}
+			fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data)
+		}
+		flusher.Flush()
+	}
+}
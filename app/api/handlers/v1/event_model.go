@@ -0,0 +1,36 @@
+package v1
+
+import (
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"github.com/lenguti/jppp/business/core/event"
+)
+
+// ClientEvent - represents our client event model, sent as the data of server-sent events.
+type ClientEvent struct {
+	ID         uint64          `json:"id"`
+	Type       string          `json:"type"`
+	EntityType string          `json:"entity_type"`
+	EntityID   string          `json:"entity_id"`
+	CageID     string          `json:"cage_id,omitempty"`
+	Data       json.RawMessage `json:"data"`
+	RequestID  string          `json:"request_id,omitempty"`
+	CreatedAt  int64           `json:"createdAt"`
+}
+
+func toClientEvent(input event.Event) ClientEvent {
+	ce := ClientEvent{
+		ID:         input.ID,
+		Type:       input.Type,
+		EntityType: input.EntityType,
+		EntityID:   input.EntityID.String(),
+		Data:       input.Data,
+		RequestID:  input.RequestID,
+		CreatedAt:  input.CreatedAt.Unix(),
+	}
+	if input.CageID != uuid.Nil {
+		ce.CageID = input.CageID.String()
+	}
+	return ce
+}
@@ -0,0 +1,117 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/lenguti/jppp/business/core"
+	"github.com/lenguti/jppp/business/core/cage"
+	"github.com/lenguti/jppp/business/core/dino"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+// collectTimeout - bounds the queries run on every scrape to compute the business gauges.
+const collectTimeout = 5 * time.Second
+
+// metrics - represents the prometheus metrics exposed by the api.
+type metrics struct {
+	registry          *prometheus.Registry
+	addDinoRejections *prometheus.CounterVec
+}
+
+func newMetrics(cc *cage.Core, dc *dino.Core) *metrics {
+	m := metrics{
+		registry: prometheus.NewRegistry(),
+		addDinoRejections: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "jppp_add_dino_rejections_total",
+			Help: "Number of dinosaurs that could not be added to a cage, by the rule that rejected them.",
+		}, []string{"rule"}),
+	}
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.addDinoRejections,
+		newParkCollector(cc, dc),
+	)
+	return &m
+}
+
+// addDinoRejected - counts a failed add dino attempt under the code of the core error that caused it.
+func (m *metrics) addDinoRejected(err error) {
+	if m == nil {
+		return
+	}
+	var ce core.Error
+	if !errors.As(err, &ce) {
+		return
+	}
+	if s, ok := coreErrors[ce]; ok {
+		m.addDinoRejections.WithLabelValues(s.code).Inc()
+	}
+}
+
+// parkCollector - collects the state of the cages and dinos from the cores on every scrape.
+type parkCollector struct {
+	cage *cage.Core
+	dino *dino.Core
+
+	cages     *prometheus.Desc
+	capacity  *prometheus.Desc
+	occupied  *prometheus.Desc
+	occupancy *prometheus.Desc
+	dinos     *prometheus.Desc
+}
+
+func newParkCollector(cc *cage.Core, dc *dino.Core) *parkCollector {
+	cageLabels := []string{"type", "status"}
+	return &parkCollector{
+		cage:      cc,
+		dino:      dc,
+		cages:     prometheus.NewDesc("jppp_cages", "Number of cages.", cageLabels, nil),
+		capacity:  prometheus.NewDesc("jppp_cage_capacity", "Total capacity of the cages.", cageLabels, nil),
+		occupied:  prometheus.NewDesc("jppp_cage_occupied", "Number of dinosaurs held by the cages.", cageLabels, nil),
+		occupancy: prometheus.NewDesc("jppp_cage_occupancy_ratio", "Ratio of the capacity of the cages in use.", cageLabels, nil),
+		dinos:     prometheus.NewDesc("jppp_dinosaurs", "Number of dinosaurs.", []string{"species", "diet"}, nil),
+	}
+}
+
+// Describe - satisfies the prometheus.Collector interface.
+func (pc *parkCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- pc.cages
+	ch <- pc.capacity
+	ch <- pc.occupied
+	ch <- pc.occupancy
+	ch <- pc.dinos
+}
+
+// Collect - satisfies the prometheus.Collector interface.
+func (pc *parkCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), collectTimeout)
+	defer cancel()
+
+	cageSums, err := pc.cage.Summarize(ctx)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(pc.cages, err)
+	}
+	for _, s := range cageSums {
+		lvs := []string{s.Type.String(), s.Status.String()}
+		ch <- prometheus.MustNewConstMetric(pc.cages, prometheus.GaugeValue, float64(s.Cages), lvs...)
+		ch <- prometheus.MustNewConstMetric(pc.capacity, prometheus.GaugeValue, float64(s.Capacity), lvs...)
+		ch <- prometheus.MustNewConstMetric(pc.occupied, prometheus.GaugeValue, float64(s.Occupied), lvs...)
+		var ratio float64
+		if s.Capacity > 0 {
+			ratio = float64(s.Occupied) / float64(s.Capacity)
+		}
+		ch <- prometheus.MustNewConstMetric(pc.occupancy, prometheus.GaugeValue, ratio, lvs...)
+	}
+
+	dinoSums, err := pc.dino.Summarize(ctx)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(pc.dinos, err)
+	}
+	for _, s := range dinoSums {
+		ch <- prometheus.MustNewConstMetric(pc.dinos, prometheus.GaugeValue, float64(s.Dinosaurs), s.Species, s.Diet.String())
+	}
+}
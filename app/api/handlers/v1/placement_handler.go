@@ -0,0 +1,132 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lenguti/jppp/business/core"
+	"github.com/lenguti/jppp/business/core/placement"
+	"github.com/lenguti/jppp/foundation/api"
+)
+
+// ListHistoryResponse - represents a client list cage or dinosaur history response.
+type ListHistoryResponse struct {
+	Placements []ClientPlacement `json:"placements"`
+	NextCursor string            `json:"next_cursor,omitempty"`
+}
+
+// historyQuery - represents the parsed query of a history route. At is set when the
+// placements ongoing at a point in time are requested instead of a page.
+type historyQuery struct {
+	page    core.Page
+	filters []core.Filter
+	at      *time.Time
+}
+
+// parseHistoryQuery - returns the page, filters and ?at= point in time requested from a history route.
+func parseHistoryQuery(r *http.Request) (historyQuery, *api.ValidationError) {
+	page, validated := parsePage(r, placement.SortFields...)
+	if !validated.IsClean() {
+		return historyQuery{}, validated
+	}
+
+	filters, validated := parseFilters(r, placement.FilterFields, queryParamAt)
+	if !validated.IsClean() {
+		return historyQuery{}, validated
+	}
+
+	hq := historyQuery{page: page, filters: filters}
+	if v := api.QueryParam(r, queryParamAt); v != "" {
+		at, err := parseTimestamp(v)
+		if err != nil {
+			validated.Add(queryParamAt, "must be a unix or RFC 3339 timestamp")
+			return historyQuery{}, validated
+		}
+		hq.at = &at
+	}
+	return hq, validated
+}
+
+// parseTimestamp - parses a unix timestamp in seconds or a RFC 3339 one.
+func parseTimestamp(v string) (time.Time, error) {
+	if sec, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return time.Unix(sec, 0).UTC(), nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parse timestamp: %w", err)
+	}
+	return t, nil
+}
+
+// ListCageHistory - invoked by GET /v1/cages/:id/history.
+func (c *Controller) ListCageHistory(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	c.logger(ctx).Info().Msg("Listing Cage history.")
+
+	id, err := uuid.Parse(api.PathParam(r, idPathParam))
+	if err != nil {
+		c.logger(ctx).Err(err).Msg("Invalid cage id.")
+		return api.BadRequestError("Invalid cage id.", err, nil)
+	}
+
+	hq, validated := parseHistoryQuery(r)
+	if !validated.IsClean() {
+		c.logger(ctx).Err(validated).Msg("Validation input failed.")
+		return api.BadRequestError("Invalid input.", validated, validated.Details())
+	}
+
+	var (
+		ps   []placement.Placement
+		next string
+	)
+	if hq.at != nil {
+		ps, err = c.Placement.CageRoster(ctx, id, *hq.at)
+	} else {
+		ps, next, err = c.Placement.CageHistory(ctx, id, hq.page, hq.filters...)
+	}
+	if err != nil {
+		c.logger(ctx).Err(err).Msg("Unable to list cage history.")
+		return toHTTPError(err)
+	}
+
+	c.logger(ctx).Info().Msg("Successfully listed Cage history.")
+	return api.Respond(w, http.StatusOK, ListHistoryResponse{Placements: toClientPlacements(ps), NextCursor: next})
+}
+
+// ListDinoHistory - invoked by GET /v1/dinosaurs/:id/history.
+func (c *Controller) ListDinoHistory(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	c.logger(ctx).Info().Msg("Listing Dino history.")
+
+	id, err := uuid.Parse(api.PathParam(r, idPathParam))
+	if err != nil {
+		c.logger(ctx).Err(err).Msg("Invalid dino id.")
+		return api.BadRequestError("Invalid id.", err, nil)
+	}
+
+	hq, validated := parseHistoryQuery(r)
+	if !validated.IsClean() {
+		c.logger(ctx).Err(validated).Msg("Validation input failed.")
+		return api.BadRequestError("Invalid input.", validated, validated.Details())
+	}
+
+	var (
+		ps   []placement.Placement
+		next string
+	)
+	if hq.at != nil {
+		ps, err = c.Placement.DinoPlacement(ctx, id, *hq.at)
+	} else {
+		ps, next, err = c.Placement.DinoHistory(ctx, id, hq.page, hq.filters...)
+	}
+	if err != nil {
+		c.logger(ctx).Err(err).Msg("Unable to list dino history.")
+		return toHTTPError(err)
+	}
+
+	c.logger(ctx).Info().Msg("Successfully listed Dino history.")
+	return api.Respond(w, http.StatusOK, ListHistoryResponse{Placements: toClientPlacements(ps), NextCursor: next})
+}
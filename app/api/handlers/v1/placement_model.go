@@ -0,0 +1,33 @@
+package v1
+
+import "github.com/lenguti/jppp/business/core/placement"
+
+// ClientPlacement - represents our client placement model, the stay of a dinosaur in a cage.
+type ClientPlacement struct {
+	ID         string `json:"id"`
+	CageID     string `json:"cage_id"`
+	DinoID     string `json:"dinosaur_id"`
+	AssignedAt int64  `json:"assignedAt"`
+	ReleasedAt int64  `json:"releasedAt,omitempty"`
+}
+
+func toClientPlacements(input []placement.Placement) []ClientPlacement {
+	ps := make([]ClientPlacement, 0, len(input))
+	for _, v := range input {
+		ps = append(ps, toClientPlacement(v))
+	}
+	return ps
+}
+
+func toClientPlacement(input placement.Placement) ClientPlacement {
+	cp := ClientPlacement{
+		ID:         input.ID.String(),
+		CageID:     input.CageID.String(),
+		DinoID:     input.DinoID.String(),
+		AssignedAt: input.AssignedAt.Unix(),
+	}
+	if input.Released() {
+		cp.ReleasedAt = input.ReleasedAt.Unix()
+	}
+	return cp
+}
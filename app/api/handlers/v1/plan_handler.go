@@ -0,0 +1,128 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/lenguti/jppp/foundation/api"
+)
+
+// PlanPlacementsRequest - represents input for planning the placement of dinosaurs, either the
+// listed ones or every dinosaur not in a cage.
+type PlanPlacementsRequest struct {
+	DinoIDs    []string `json:"dino_ids"`
+	AllUncaged bool     `json:"all_uncaged"`
+}
+
+func (ppr *PlanPlacementsRequest) validate() *api.ValidationError {
+	e := api.NewValidationError()
+
+	if ppr.AllUncaged == (len(ppr.DinoIDs) > 0) {
+		e.Add("dino_ids", "either dino_ids or all_uncaged is required")
+	}
+
+	for i, id := range ppr.DinoIDs {
+		if _, err := uuid.Parse(id); err != nil {
+			e.Add(fmt.Sprintf("dino_ids[%d]", i), "is invalid")
+		}
+	}
+
+	return e
+}
+
+// PlanPlacementsResponse - represents a client plan placements response.
+type PlanPlacementsResponse struct {
+	Plan ClientPlan `json:"plan"`
+}
+
+// PlanPlacements - invoked by POST /v1/placements/plan.
+func (c *Controller) PlanPlacements(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	c.logger(ctx).Info().Msg("Planning Dinosaur placements.")
+
+	var input PlanPlacementsRequest
+	if err := api.Decode(r, &input); err != nil {
+		c.logger(ctx).Err(err).Msg("Unable to decode plan placements request.")
+		return api.BadRequestError("Invalid input.", err, nil)
+	}
+
+	if validated := input.validate(); !validated.IsClean() {
+		c.logger(ctx).Err(validated).Msg("Validation input failed.")
+		return api.BadRequestError("Invalid input.", validated, validated.Details())
+	}
+
+	ids := make([]uuid.UUID, 0, len(input.DinoIDs))
+	for _, id := range input.DinoIDs {
+		ids = append(ids, uuid.MustParse(id))
+	}
+
+	plan, err := c.Cage.PlanPlacements(ctx, ids)
+	if err != nil {
+		c.logger(ctx).Err(err).Msg("Unable to plan placements.")
+		return toHTTPError(err)
+	}
+
+	c.logger(ctx).Info().Fields(map[string]any{"assignments": len(plan.Assignments), "unplaced": len(plan.Unplaced)}).Msg("Successfully planned Dinosaur placements.")
+	return api.Respond(w, http.StatusOK, PlanPlacementsResponse{Plan: toClientPlan(plan)})
+}
+
+// ApplyPlacementsRequest - represents input for applying a reviewed placement plan.
+type ApplyPlacementsRequest struct {
+	Plan ClientPlan `json:"plan"`
+}
+
+func (apr *ApplyPlacementsRequest) validate() *api.ValidationError {
+	e := api.NewValidationError()
+
+	if len(apr.Plan.PowerUps) == 0 && len(apr.Plan.Assignments) == 0 {
+		e.Add("plan", "is empty")
+	}
+
+	for i, id := range apr.Plan.PowerUps {
+		if _, err := uuid.Parse(id); err != nil {
+			e.Add(fmt.Sprintf("plan.power_ups[%d]", i), "is invalid")
+		}
+	}
+
+	for i, a := range apr.Plan.Assignments {
+		if _, err := uuid.Parse(a.DinoID); err != nil {
+			e.Add(fmt.Sprintf("plan.assignments[%d].dino_id", i), "is invalid")
+		}
+		if _, err := uuid.Parse(a.CageID); err != nil {
+			e.Add(fmt.Sprintf("plan.assignments[%d].cage_id", i), "is invalid")
+		}
+	}
+
+	return e
+}
+
+// ApplyPlacementsResponse - represents a client apply placements response.
+type ApplyPlacementsResponse struct {
+	Cages []ClientCage `json:"cages"`
+}
+
+// ApplyPlacements - invoked by POST /v1/placements/apply.
+func (c *Controller) ApplyPlacements(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	c.logger(ctx).Info().Msg("Applying Dinosaur placements.")
+
+	var input ApplyPlacementsRequest
+	if err := api.Decode(r, &input); err != nil {
+		c.logger(ctx).Err(err).Msg("Unable to decode apply placements request.")
+		return api.BadRequestError("Invalid input.", err, nil)
+	}
+
+	if validated := input.validate(); !validated.IsClean() {
+		c.logger(ctx).Err(validated).Msg("Validation input failed.")
+		return api.BadRequestError("Invalid input.", validated, validated.Details())
+	}
+
+	cgs, err := c.Cage.ApplyPlan(ctx, toCorePlan(input.Plan))
+	if err != nil {
+		c.logger(ctx).Err(err).Msg("Unable to apply placements.")
+		return toHTTPError(err)
+	}
+
+	c.logger(ctx).Info().Msg("Successfully applied Dinosaur placements.")
+	return api.Respond(w, http.StatusOK, ApplyPlacementsResponse{Cages: toClientCages(cgs)})
+}
@@ -0,0 +1,69 @@
+package v1
+
+import (
+	"github.com/google/uuid"
+	"github.com/lenguti/jppp/business/core/cage"
+)
+
+// ClientPlan - represents a client placement plan.
+type ClientPlan struct {
+	PowerUps    []string           `json:"power_ups"`
+	Assignments []ClientAssignment `json:"assignments"`
+	Unplaced    []ClientUnplaced   `json:"unplaced"`
+}
+
+// ClientAssignment - represents a client dinosaur placed into a cage by a plan.
+type ClientAssignment struct {
+	DinoID string `json:"dino_id"`
+	CageID string `json:"cage_id"`
+}
+
+// ClientUnplaced - represents a client dinosaur a plan found no cage for.
+type ClientUnplaced struct {
+	DinoID string `json:"dino_id"`
+	Reason string `json:"reason"`
+}
+
+func toClientPlan(input cage.Plan) ClientPlan {
+	cp := ClientPlan{
+		PowerUps:    toClientIDs(input.PowerUps),
+		Assignments: make([]ClientAssignment, 0, len(input.Assignments)),
+		Unplaced:    make([]ClientUnplaced, 0, len(input.Unplaced)),
+	}
+	for _, a := range input.Assignments {
+		cp.Assignments = append(cp.Assignments, ClientAssignment{DinoID: a.DinoID.String(), CageID: a.CageID.String()})
+	}
+	for _, u := range input.Unplaced {
+		cp.Unplaced = append(cp.Unplaced, ClientUnplaced{DinoID: u.DinoID.String(), Reason: u.Reason})
+	}
+	return cp
+}
+
+// toCorePlan - returns the core plan of a validated client plan. Unplaced dinos are left out, as
+// applying a plan ignores them.
+func toCorePlan(input ClientPlan) cage.Plan {
+	p := cage.Plan{
+		PowerUps:    toCoreIDs(input.PowerUps),
+		Assignments: make([]cage.Assignment, 0, len(input.Assignments)),
+	}
+	for _, a := range input.Assignments {
+		p.Assignments = append(p.Assignments, cage.Assignment{DinoID: uuid.MustParse(a.DinoID), CageID: uuid.MustParse(a.CageID)})
+	}
+	return p
+}
+
+func toClientIDs(ids []uuid.UUID) []string {
+	out := make([]string, 0, len(ids))
+	for _, id := range ids {
+		out = append(out, id.String())
+	}
+	return out
+}
+
+func toCoreIDs(ids []string) []uuid.UUID {
+	out := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		out = append(out, uuid.MustParse(id))
+	}
+	return out
+}
@@ -0,0 +1,138 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/lenguti/jppp/business/core/cage"
+	"github.com/lenguti/jppp/foundation/api"
+)
+
+// PlanRebalanceRequest - represents input for planning a rebalance of dinosaurs across cages.
+type PlanRebalanceRequest struct {
+	Strategy     string  `json:"strategy"`
+	MaxOccupancy float64 `json:"max_occupancy"`
+}
+
+func (prr *PlanRebalanceRequest) validate() *api.ValidationError {
+	e := api.NewValidationError()
+
+	switch prr.Strategy {
+	case cage.StrategyConsolidate:
+		if prr.MaxOccupancy != 0 {
+			e.Add("max_occupancy", "is only valid when spreading")
+		}
+	case cage.StrategySpread:
+		if prr.MaxOccupancy <= 0 || prr.MaxOccupancy > 1 {
+			e.Add("max_occupancy", "must be greater than 0 and at most 1")
+		}
+	case "":
+		e.Add("strategy", "is required")
+	default:
+		e.Add("strategy", "is invalid")
+	}
+
+	return e
+}
+
+// PlanRebalanceResponse - represents a client plan rebalance response.
+type PlanRebalanceResponse struct {
+	Rebalance ClientRebalance `json:"rebalance"`
+}
+
+// PlanRebalance - invoked by POST /v1/rebalance/plan.
+func (c *Controller) PlanRebalance(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	c.logger(ctx).Info().Msg("Planning cage rebalance.")
+
+	var input PlanRebalanceRequest
+	if err := api.Decode(r, &input); err != nil {
+		c.logger(ctx).Err(err).Msg("Unable to decode plan rebalance request.")
+		return api.BadRequestError("Invalid input.", err, nil)
+	}
+
+	if validated := input.validate(); !validated.IsClean() {
+		c.logger(ctx).Err(validated).Msg("Validation input failed.")
+		return api.BadRequestError("Invalid input.", validated, validated.Details())
+	}
+
+	rb, err := c.Cage.PlanRebalance(ctx, cage.RebalanceOptions{Strategy: input.Strategy, MaxOccupancy: input.MaxOccupancy})
+	if err != nil {
+		c.logger(ctx).Err(err).Msg("Unable to plan rebalance.")
+		return toHTTPError(err)
+	}
+
+	c.logger(ctx).Info().Fields(map[string]any{"strategy": input.Strategy, "moves": len(rb.Moves)}).Msg("Successfully planned cage rebalance.")
+	return api.Respond(w, http.StatusOK, PlanRebalanceResponse{Rebalance: toClientRebalance(rb)})
+}
+
+// ApplyRebalanceRequest - represents input for applying a reviewed rebalance.
+type ApplyRebalanceRequest struct {
+	Rebalance ClientRebalance `json:"rebalance"`
+}
+
+func (arr *ApplyRebalanceRequest) validate() *api.ValidationError {
+	e := api.NewValidationError()
+
+	rb := arr.Rebalance
+	if len(rb.PowerUps) == 0 && len(rb.Moves) == 0 && len(rb.PowerDowns) == 0 {
+		e.Add("rebalance", "is empty")
+	}
+
+	for i, id := range rb.PowerUps {
+		if _, err := uuid.Parse(id); err != nil {
+			e.Add(fmt.Sprintf("rebalance.power_ups[%d]", i), "is invalid")
+		}
+	}
+
+	for i, m := range rb.Moves {
+		if _, err := uuid.Parse(m.DinoID); err != nil {
+			e.Add(fmt.Sprintf("rebalance.moves[%d].dino_id", i), "is invalid")
+		}
+		if _, err := uuid.Parse(m.FromCageID); err != nil {
+			e.Add(fmt.Sprintf("rebalance.moves[%d].from_cage_id", i), "is invalid")
+		}
+		if _, err := uuid.Parse(m.ToCageID); err != nil {
+			e.Add(fmt.Sprintf("rebalance.moves[%d].to_cage_id", i), "is invalid")
+		}
+	}
+
+	for i, id := range rb.PowerDowns {
+		if _, err := uuid.Parse(id); err != nil {
+			e.Add(fmt.Sprintf("rebalance.power_downs[%d]", i), "is invalid")
+		}
+	}
+
+	return e
+}
+
+// ApplyRebalanceResponse - represents a client apply rebalance response.
+type ApplyRebalanceResponse struct {
+	Cages []ClientCage `json:"cages"`
+}
+
+// ApplyRebalance - invoked by POST /v1/rebalance/apply.
+func (c *Controller) ApplyRebalance(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	c.logger(ctx).Info().Msg("Applying cage rebalance.")
+
+	var input ApplyRebalanceRequest
+	if err := api.Decode(r, &input); err != nil {
+		c.logger(ctx).Err(err).Msg("Unable to decode apply rebalance request.")
+		return api.BadRequestError("Invalid input.", err, nil)
+	}
+
+	if validated := input.validate(); !validated.IsClean() {
+		c.logger(ctx).Err(validated).Msg("Validation input failed.")
+		return api.BadRequestError("Invalid input.", validated, validated.Details())
+	}
+
+	cgs, err := c.Cage.ApplyRebalance(ctx, toCoreRebalance(input.Rebalance))
+	if err != nil {
+		c.logger(ctx).Err(err).Msg("Unable to apply rebalance.")
+		return toHTTPError(err)
+	}
+
+	c.logger(ctx).Info().Msg("Successfully applied cage rebalance.")
+	return api.Respond(w, http.StatusOK, ApplyRebalanceResponse{Cages: toClientCages(cgs)})
+}
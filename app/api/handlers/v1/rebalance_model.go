@@ -0,0 +1,50 @@
+package v1
+
+import (
+	"github.com/google/uuid"
+	"github.com/lenguti/jppp/business/core/cage"
+)
+
+// ClientRebalance - represents a client rebalance of dinosaurs across cages.
+type ClientRebalance struct {
+	PowerUps   []string     `json:"power_ups"`
+	Moves      []ClientMove `json:"moves"`
+	PowerDowns []string     `json:"power_downs"`
+	Cages      []ClientCage `json:"cages,omitempty"`
+	Over       []string     `json:"over,omitempty"`
+}
+
+// ClientMove - represents a client dinosaur moved between cages by a rebalance.
+type ClientMove struct {
+	DinoID     string `json:"dino_id"`
+	FromCageID string `json:"from_cage_id"`
+	ToCageID   string `json:"to_cage_id"`
+}
+
+func toClientRebalance(input cage.Rebalance) ClientRebalance {
+	cr := ClientRebalance{
+		PowerUps:   toClientIDs(input.PowerUps),
+		Moves:      make([]ClientMove, 0, len(input.Moves)),
+		PowerDowns: toClientIDs(input.PowerDowns),
+		Cages:      toClientCages(input.Cages),
+		Over:       toClientIDs(input.Over),
+	}
+	for _, m := range input.Moves {
+		cr.Moves = append(cr.Moves, ClientMove{DinoID: m.DinoID.String(), FromCageID: m.From.String(), ToCageID: m.To.String()})
+	}
+	return cr
+}
+
+// toCoreRebalance - returns the core rebalance of a validated client rebalance. The projected cages
+// are left out, as applying a rebalance ignores them.
+func toCoreRebalance(input ClientRebalance) cage.Rebalance {
+	rb := cage.Rebalance{
+		PowerUps:   toCoreIDs(input.PowerUps),
+		Moves:      make([]cage.Move, 0, len(input.Moves)),
+		PowerDowns: toCoreIDs(input.PowerDowns),
+	}
+	for _, m := range input.Moves {
+		rb.Moves = append(rb.Moves, cage.Move{DinoID: uuid.MustParse(m.DinoID), From: uuid.MustParse(m.FromCageID), To: uuid.MustParse(m.ToCageID)})
+	}
+	return rb
+}
@@ -0,0 +1,202 @@
+package v1
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/lenguti/jppp/business/core/species"
+	"github.com/lenguti/jppp/foundation/api"
+)
+
+// CreateSpeciesRequest - represents input for registering a new species.
+type CreateSpeciesRequest struct {
+	Name        string `json:"name"`
+	Diet        string `json:"diet"`
+	SizeClass   string `json:"size_class"`
+	Temperament string `json:"temperament"`
+	Herd        bool   `json:"herd"`
+	Solitary    bool   `json:"solitary"`
+}
+
+func (csr *CreateSpeciesRequest) validate() *api.ValidationError {
+	e := api.NewValidationError()
+
+	if strings.TrimSpace(csr.Name) == "" {
+		e.Add("name", "is required")
+	}
+
+	if err := species.ParseDiet(csr.Diet); err != nil {
+		e.Add("diet", "is invalid")
+	}
+
+	if err := species.ParseSizeClass(csr.SizeClass); err != nil {
+		e.Add("size_class", "is invalid")
+	}
+
+	if err := species.ParseTemperament(csr.Temperament); err != nil {
+		e.Add("temperament", "is invalid")
+	}
+
+	if csr.Herd && csr.Solitary {
+		e.Add("solitary", "cannot be set along with herd")
+	}
+
+	return e
+}
+
+// CreateSpeciesResponse - represents a client create species response.
+type CreateSpeciesResponse struct {
+	Species ClientSpecies `json:"species"`
+}
+
+// CreateSpecies - invoked by POST /v1/species.
+func (c *Controller) CreateSpecies(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	c.logger(ctx).Info().Msg("Creating Species.")
+
+	var input CreateSpeciesRequest
+	if err := api.Decode(r, &input); err != nil {
+		c.logger(ctx).Err(err).Msg("Unable to decode create species request.")
+		return api.BadRequestError("Invalid input.", err, nil)
+	}
+
+	if validated := input.validate(); !validated.IsClean() {
+		c.logger(ctx).Err(validated).Msg("Validation input failed.")
+		return api.BadRequestError("Invalid input.", validated, validated.Details())
+	}
+
+	s, err := c.Species.Create(ctx, toCoreNewSpecies(input))
+	if err != nil {
+		c.logger(ctx).Err(err).Msg("Unable to create species.")
+		return toHTTPError(err)
+	}
+
+	c.logger(ctx).Info().Msg("Successfully created Species.")
+	return api.Respond(w, http.StatusCreated, CreateSpeciesResponse{Species: toClientSpecies(s)})
+}
+
+// GetSpeciesResponse - represents a client get species response.
+type GetSpeciesResponse struct {
+	Species ClientSpecies `json:"species"`
+}
+
+// GetSpecies - invoked by GET /v1/species/:name.
+func (c *Controller) GetSpecies(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	c.logger(ctx).Info().Msg("Fetching Species.")
+
+	s, err := c.Species.Get(ctx, api.PathParam(r, namePathParam))
+	if err != nil {
+		c.logger(ctx).Err(err).Msg("Unable to fetch species.")
+		return toHTTPError(err)
+	}
+
+	c.logger(ctx).Info().Msg("Successfully fetched Species.")
+	return api.Respond(w, http.StatusOK, GetSpeciesResponse{Species: toClientSpecies(s)})
+}
+
+// ListSpeciesResponse - represents a client list species response.
+type ListSpeciesResponse struct {
+	Species    []ClientSpecies `json:"species"`
+	NextCursor string          `json:"next_cursor,omitempty"`
+}
+
+// ListSpecies - invoked by GET /v1/species.
+func (c *Controller) ListSpecies(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	c.logger(ctx).Info().Msg("Listing Species.")
+
+	page, validated := parsePage(r, species.SortFields...)
+	if !validated.IsClean() {
+		c.logger(ctx).Err(validated).Msg("Validation input failed.")
+		return api.BadRequestError("Invalid input.", validated, validated.Details())
+	}
+
+	filters, validated := parseFilters(r, species.FilterFields)
+	if !validated.IsClean() {
+		c.logger(ctx).Err(validated).Msg("Validation input failed.")
+		return api.BadRequestError("Invalid input.", validated, validated.Details())
+	}
+
+	ss, next, err := c.Species.List(ctx, page, filters...)
+	if err != nil {
+		c.logger(ctx).Err(err).Msg("Unable to list species.")
+		return toHTTPError(err)
+	}
+
+	c.logger(ctx).Info().Msg("Successfully listed Species.")
+	return api.Respond(w, http.StatusOK, ListSpeciesResponse{Species: toClientSpeciesList(ss), NextCursor: next})
+}
+
+// UpdateSpeciesRequest - represents input for updating a species, omitted fields are left as is.
+type UpdateSpeciesRequest struct {
+	SizeClass   *string `json:"size_class"`
+	Temperament *string `json:"temperament"`
+	Herd        *bool   `json:"herd"`
+	Solitary    *bool   `json:"solitary"`
+}
+
+func (usr *UpdateSpeciesRequest) validate() *api.ValidationError {
+	e := api.NewValidationError()
+
+	if usr.SizeClass != nil {
+		if err := species.ParseSizeClass(*usr.SizeClass); err != nil {
+			e.Add("size_class", "is invalid")
+		}
+	}
+
+	if usr.Temperament != nil {
+		if err := species.ParseTemperament(*usr.Temperament); err != nil {
+			e.Add("temperament", "is invalid")
+		}
+	}
+
+	return e
+}
+
+// UpdateSpeciesResponse - represents a client update species response.
+type UpdateSpeciesResponse struct {
+	Species ClientSpecies `json:"species"`
+}
+
+// UpdateSpecies - invoked by PATCH /v1/species/:name.
+func (c *Controller) UpdateSpecies(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	c.logger(ctx).Info().Msg("Updating Species.")
+
+	var input UpdateSpeciesRequest
+	if err := api.Decode(r, &input); err != nil {
+		c.logger(ctx).Err(err).Msg("Unable to decode update species request.")
+		return api.BadRequestError("Invalid input.", err, nil)
+	}
+
+	if validated := input.validate(); !validated.IsClean() {
+		c.logger(ctx).Err(validated).Msg("Validation input failed.")
+		return api.BadRequestError("Invalid input.", validated, validated.Details())
+	}
+
+	s, err := c.Species.Update(ctx, api.PathParam(r, namePathParam), toCoreUpdateSpecies(input))
+	if err != nil {
+		c.logger(ctx).Err(err).Msg("Unable to update species.")
+		return toHTTPError(err)
+	}
+
+	c.logger(ctx).Info().Msg("Successfully updated Species.")
+	return api.Respond(w, http.StatusOK, UpdateSpeciesResponse{Species: toClientSpecies(s)})
+}
+
+// DeleteSpeciesResponse - represents a client delete species response.
+type DeleteSpeciesResponse struct {
+	Species ClientSpecies `json:"species"`
+}
+
+// DeleteSpecies - invoked by DELETE /v1/species/:name.
+func (c *Controller) DeleteSpecies(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	c.logger(ctx).Info().Msg("Deleting Species.")
+
+	s, err := c.Species.Delete(ctx, api.PathParam(r, namePathParam))
+	if err != nil {
+		c.logger(ctx).Err(err).Msg("Unable to delete species.")
+		return toHTTPError(err)
+	}
+
+	c.logger(ctx).Info().Msg("Successfully deleted Species.")
+	return api.Respond(w, http.StatusOK, DeleteSpeciesResponse{Species: toClientSpecies(s)})
+}
@@ -0,0 +1,60 @@
+package v1
+
+import (
+	"github.com/lenguti/jppp/business/core/species"
+)
+
+// ClientSpecies - represents a client species entity.
+type ClientSpecies struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Diet        string `json:"diet"`
+	SizeClass   string `json:"size_class"`
+	Temperament string `json:"temperament"`
+	Herd        bool   `json:"herd"`
+	Solitary    bool   `json:"solitary"`
+	CreatedAt   int64  `json:"createdAt"`
+	UpdatedAt   int64  `json:"updatedAt"`
+}
+
+func toCoreNewSpecies(input CreateSpeciesRequest) species.NewSpecies {
+	return species.NewSpecies{
+		Name:        input.Name,
+		Diet:        input.Diet,
+		SizeClass:   input.SizeClass,
+		Temperament: input.Temperament,
+		Herd:        input.Herd,
+		Solitary:    input.Solitary,
+	}
+}
+
+func toCoreUpdateSpecies(input UpdateSpeciesRequest) species.UpdateSpecies {
+	return species.UpdateSpecies{
+		SizeClass:   input.SizeClass,
+		Temperament: input.Temperament,
+		Herd:        input.Herd,
+		Solitary:    input.Solitary,
+	}
+}
+
+func toClientSpeciesList(input []species.Species) []ClientSpecies {
+	ss := make([]ClientSpecies, 0, len(input))
+	for _, v := range input {
+		ss = append(ss, toClientSpecies(v))
+	}
+	return ss
+}
+
+func toClientSpecies(input species.Species) ClientSpecies {
+	return ClientSpecies{
+		ID:          input.ID.String(),
+		Name:        input.Name,
+		Diet:        input.Diet,
+		SizeClass:   input.SizeClass,
+		Temperament: input.Temperament,
+		Herd:        input.Herd,
+		Solitary:    input.Solitary,
+		CreatedAt:   input.CreatedAt.Unix(),
+		UpdatedAt:   input.UpdatedAt.Unix(),
+	}
+}
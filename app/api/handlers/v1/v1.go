@@ -4,46 +4,194 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
 
+	"github.com/lenguti/jppp/business/core"
 	"github.com/lenguti/jppp/foundation/api"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 const (
 	idPathParam     = "id"
 	dinoIDPathParam = "dinoId"
+	namePathParam   = "name"
 )
 
 const (
-	queryParamStatus  = "status"
-	queryParamSpecies = "species"
+	queryParamLimit  = "limit"
+	queryParamCursor = "cursor"
+	queryParamSort   = "sort"
+
+	queryParamIncludeDeleted = "include_deleted"
+	queryParamAt             = "at"
 )
 
+// filterParam - matches filter query params such as "capacity" or "capacity[gte]".
+var filterParam = regexp.MustCompile(`^([A-Za-z_]+)(?:\[([a-z]+)\])?$`)
+
 // Routes - route definitions for v1.
 func (c *Controller) Routes() *api.Router {
 	const version = "v1"
 
 	c.router.Handle(http.MethodGet, version, "/status", c.status)
+	c.router.HandleHTTP(http.MethodGet, "/metrics", promhttp.HandlerFor(c.metrics.registry, promhttp.HandlerOpts{}))
 
 	c.router.Handle(http.MethodPost, version, "/cages", c.CreateCage)
 	c.router.Handle(http.MethodGet, version, "/cages", c.ListCages)
 	c.router.Handle(http.MethodGet, version, "/cages/:id", c.GetCage)
 	c.router.Handle(http.MethodPatch, version, "/cages/:id", c.UpdateCage)
+	c.router.Handle(http.MethodDelete, version, "/cages/:id", c.DeleteCage)
+	c.router.Handle(http.MethodPost, version, "/cages/:id/restore", c.RestoreCage)
 	c.router.Handle(http.MethodPatch, version, "/cages/:id/dinosaurs/:dinoId", c.AddDinosaurToCage)
 	c.router.Handle(http.MethodDelete, version, "/cages/:id/dinosaurs/:dinoId", c.RemoveDinosaurFromCage)
+	c.router.Handle(http.MethodGet, version, "/cages/:id/dinosaurs/:dinoId/compatibility", c.DinosaurCompatibility)
 	c.router.Handle(http.MethodGet, version, "/cages/:id/dinosaurs", c.ListCageDinosaurs)
+	c.router.Handle(http.MethodGet, version, "/cages/:id/history", c.ListCageHistory)
 
 	c.router.Handle(http.MethodGet, version, "/dinosaurs/species", c.ListDinoSpecies)
 	c.router.Handle(http.MethodPost, version, "/dinosaurs", c.CreateDino)
 	c.router.Handle(http.MethodGet, version, "/dinosaurs", c.ListDinos)
 	c.router.Handle(http.MethodGet, version, "/dinosaurs/:id", c.GetDino)
 	c.router.Handle(http.MethodPatch, version, "/dinosaurs/:id", c.UpdateDino)
+	c.router.Handle(http.MethodDelete, version, "/dinosaurs/:id", c.DeleteDino)
+	c.router.Handle(http.MethodPost, version, "/dinosaurs/:id/restore", c.RestoreDino)
+	c.router.Handle(http.MethodPost, version, "/dinosaurs/:id/transfer", c.TransferDino)
+	c.router.Handle(http.MethodGet, version, "/dinosaurs/:id/history", c.ListDinoHistory)
+	c.router.Handle(http.MethodGet, version, "/dinosaurs/:id/eligible-cages", c.EligibleCages)
+
+	c.router.Handle(http.MethodPost, version, "/placements/plan", c.PlanPlacements)
+	c.router.Handle(http.MethodPost, version, "/placements/apply", c.ApplyPlacements)
+	c.router.Handle(http.MethodPost, version, "/rebalance/plan", c.PlanRebalance)
+	c.router.Handle(http.MethodPost, version, "/rebalance/apply", c.ApplyRebalance)
+
+	c.router.Handle(http.MethodPost, version, "/species", c.CreateSpecies)
+	c.router.Handle(http.MethodGet, version, "/species", c.ListSpecies)
+	c.router.Handle(http.MethodGet, version, "/species/:name", c.GetSpecies)
+	c.router.Handle(http.MethodPatch, version, "/species/:name", c.UpdateSpecies)
+	c.router.Handle(http.MethodDelete, version, "/species/:name", c.DeleteSpecies)
+
+	c.router.Handle(http.MethodGet, version, "/audit", c.ListAudit)
+	c.router.Handle(http.MethodGet, version, "/events", c.StreamEvents)
+
+	c.router.Handle(http.MethodPost, version, "/webhooks", c.CreateWebhook)
+	c.router.Handle(http.MethodGet, version, "/webhooks", c.ListWebhooks)
+	c.router.Handle(http.MethodGet, version, "/webhooks/:id", c.GetWebhook)
+	c.router.Handle(http.MethodDelete, version, "/webhooks/:id", c.DeleteWebhook)
+	c.router.Handle(http.MethodGet, version, "/webhooks/:id/deliveries", c.ListWebhookDeliveries)
 
 	return c.router
 }
 
+// parsePage - returns the page requested through the limit, cursor and sort query params.
+func parsePage(r *http.Request, sortFields ...string) (core.Page, *api.ValidationError) {
+	e := api.NewValidationError()
+	page := core.Page{Limit: core.DefaultLimit, Cursor: api.QueryParam(r, queryParamCursor)}
+
+	if v := api.QueryParam(r, queryParamLimit); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > core.MaxLimit {
+			e.Add(queryParamLimit, fmt.Sprintf("must be between 1 and %d", core.MaxLimit))
+		}
+		page.Limit = limit
+	}
+
+	s, err := core.ParseSort(api.QueryParam(r, queryParamSort), sortFields...)
+	if err != nil {
+		e.Add(queryParamSort, "is invalid")
+	}
+	page.Sort = s
+
+	return page, e
+}
+
+// parseFilters - returns the filters requested through query params such as
+// ?capacity[gte]=5&type[in]=CARNIVORE,HERBIVORE validated against the fields.
+// Soft deleted items are filtered out unless ?include_deleted=true is passed, provided the
+// fields can be soft deleted. Params the route reads itself are passed as reserved.
+func parseFilters(r *http.Request, fields core.Fields, reserved ...string) ([]core.Filter, *api.ValidationError) {
+	e := api.NewValidationError()
+	q := r.URL.Query()
+
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var filters []core.Filter
+	for _, k := range keys {
+		switch k {
+		case queryParamLimit, queryParamCursor, queryParamSort:
+			continue
+		case queryParamIncludeDeleted:
+			if _, err := strconv.ParseBool(q.Get(k)); err != nil {
+				e.Add(k, "is invalid")
+			}
+			continue
+		}
+
+		if isReserved(k, reserved) {
+			continue
+		}
+
+		m := filterParam.FindStringSubmatch(k)
+		if m == nil {
+			e.Add(k, "is not a valid filter")
+			continue
+		}
+
+		for _, v := range q[k] {
+			f, err := fields.Parse(m[1], core.Operator(m[2]), v)
+			if err != nil {
+				e.Add(k, "is invalid")
+				continue
+			}
+			filters = append(filters, f)
+		}
+	}
+
+	if _, ok := fields[core.FieldDeletedAt]; !ok {
+		return filters, e
+	}
+	if include, _ := strconv.ParseBool(q.Get(queryParamIncludeDeleted)); !include {
+		filters = append(filters, core.NotDeleted)
+	}
+
+	return filters, e
+}
+
+func isReserved(k string, reserved []string) bool {
+	for _, v := range reserved {
+		if v == k {
+			return true
+		}
+	}
+	return false
+}
+
+// ifMatchVersion - returns the version expected by the If-Match header, zero when no precondition is set.
+func ifMatchVersion(r *http.Request) (int, error) {
+	tag, err := api.IfMatch(r)
+	if err != nil {
+		return 0, fmt.Errorf("if match version: %w", err)
+	}
+	if tag == "" {
+		return 0, nil
+	}
+	v, err := strconv.Atoi(tag)
+	if err != nil || v <= 0 {
+		return 0, fmt.Errorf("if match version: invalid version %s", tag)
+	}
+	return v, nil
+}
+
 func (c *Controller) status(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
-	if err := c.db.Connect(); err != nil {
-		return fmt.Errorf("status: unable to connect to db: %w", err)
+	if c.db != nil {
+		if err := c.db.Connect(); err != nil {
+			return fmt.Errorf("status: unable to connect to db: %w", err)
+		}
 	}
 	w.WriteHeader(http.StatusOK)
 	_, _ = w.Write([]byte(`{"status": "ok"}`))
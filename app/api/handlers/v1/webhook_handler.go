@@ -0,0 +1,187 @@
+package v1
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/lenguti/jppp/business/core/event"
+	"github.com/lenguti/jppp/business/core/webhook"
+	"github.com/lenguti/jppp/foundation/api"
+)
+
+// CreateWebhookRequest - represents input for creating a new webhook. Empty types subscribe to every
+// event type and a secret is generated when none is sent.
+type CreateWebhookRequest struct {
+	URL    string   `json:"url"`
+	Types  []string `json:"types"`
+	Secret string   `json:"secret"`
+}
+
+func (cwr *CreateWebhookRequest) validate() *api.ValidationError {
+	e := api.NewValidationError()
+	if err := webhook.ValidateURL(cwr.URL); err != nil {
+		e.Add("url", "must be an absolute http or https url")
+	}
+
+	for _, t := range cwr.Types {
+		if !event.ValidType(t) {
+			e.Add("types", "is invalid")
+			break
+		}
+	}
+
+	return e
+}
+
+// CreateWebhookResponse - represents a client create webhook response, the only one carrying its secret.
+type CreateWebhookResponse struct {
+	Webhook ClientWebhook `json:"webhook"`
+}
+
+// CreateWebhook - invoked by POST /v1/webhooks.
+func (c *Controller) CreateWebhook(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	c.logger(ctx).Info().Msg("Creating Webhook.")
+
+	var input CreateWebhookRequest
+	if err := api.Decode(r, &input); err != nil {
+		c.logger(ctx).Err(err).Msg("Unable to decode create webhook request.")
+		return api.BadRequestError("Invalid input.", err, nil)
+	}
+
+	if validated := input.validate(); !validated.IsClean() {
+		c.logger(ctx).Err(validated).Msg("Validation input failed.")
+		return api.BadRequestError("Invalid input.", validated, validated.Details())
+	}
+
+	wh, err := c.Webhook.Create(ctx, toCoreNewWebhook(input))
+	if err != nil {
+		c.logger(ctx).Err(err).Msg("Unable to create webhook.")
+		return toHTTPError(err)
+	}
+
+	c.logger(ctx).Info().Msg("Successfully created Webhook.")
+	cw := toClientWebhook(wh)
+	cw.Secret = wh.Secret
+	return api.Respond(w, http.StatusCreated, CreateWebhookResponse{Webhook: cw})
+}
+
+// GetWebhookResponse - represents a client get webhook response.
+type GetWebhookResponse struct {
+	Webhook ClientWebhook `json:"webhook"`
+}
+
+// GetWebhook - invoked by GET /v1/webhooks/:id.
+func (c *Controller) GetWebhook(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	c.logger(ctx).Info().Msg("Fetching Webhook.")
+
+	id, err := uuid.Parse(api.PathParam(r, idPathParam))
+	if err != nil {
+		c.logger(ctx).Err(err).Msg("Invalid webhook id.")
+		return api.BadRequestError("Invalid id.", err, nil)
+	}
+
+	wh, err := c.Webhook.Get(ctx, id)
+	if err != nil {
+		c.logger(ctx).Err(err).Msg("Unable to fetch webhook.")
+		return toHTTPError(err)
+	}
+
+	c.logger(ctx).Info().Msg("Successfully fetched Webhook.")
+	return api.Respond(w, http.StatusOK, GetWebhookResponse{Webhook: toClientWebhook(wh)})
+}
+
+// ListWebhooksResponse - represents a client list webhooks response.
+type ListWebhooksResponse struct {
+	Webhooks   []ClientWebhook `json:"webhooks"`
+	NextCursor string          `json:"next_cursor,omitempty"`
+}
+
+// ListWebhooks - invoked by GET /v1/webhooks.
+func (c *Controller) ListWebhooks(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	c.logger(ctx).Info().Msg("Listing Webhooks.")
+
+	page, validated := parsePage(r, webhook.SortFields...)
+	if !validated.IsClean() {
+		c.logger(ctx).Err(validated).Msg("Validation input failed.")
+		return api.BadRequestError("Invalid input.", validated, validated.Details())
+	}
+
+	filters, validated := parseFilters(r, webhook.FilterFields)
+	if !validated.IsClean() {
+		c.logger(ctx).Err(validated).Msg("Validation input failed.")
+		return api.BadRequestError("Invalid input.", validated, validated.Details())
+	}
+
+	whs, next, err := c.Webhook.List(ctx, page, filters...)
+	if err != nil {
+		c.logger(ctx).Err(err).Msg("Unable to list webhooks.")
+		return toHTTPError(err)
+	}
+
+	c.logger(ctx).Info().Msg("Successfully listed Webhooks.")
+	return api.Respond(w, http.StatusOK, ListWebhooksResponse{Webhooks: toClientWebhooks(whs), NextCursor: next})
+}
+
+// DeleteWebhookResponse - represents a client delete webhook response.
+type DeleteWebhookResponse struct {
+	Webhook ClientWebhook `json:"webhook"`
+}
+
+// DeleteWebhook - invoked by DELETE /v1/webhooks/:id.
+func (c *Controller) DeleteWebhook(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	c.logger(ctx).Info().Msg("Deleting Webhook.")
+
+	id, err := uuid.Parse(api.PathParam(r, idPathParam))
+	if err != nil {
+		c.logger(ctx).Err(err).Msg("Invalid webhook id.")
+		return api.BadRequestError("Invalid id.", err, nil)
+	}
+
+	wh, err := c.Webhook.Delete(ctx, id)
+	if err != nil {
+		c.logger(ctx).Err(err).Msg("Unable to delete webhook.")
+		return toHTTPError(err)
+	}
+
+	c.logger(ctx).Info().Msg("Successfully deleted Webhook.")
+	return api.Respond(w, http.StatusOK, DeleteWebhookResponse{Webhook: toClientWebhook(wh)})
+}
+
+// ListDeliveriesResponse - represents a client list webhook deliveries response.
+type ListDeliveriesResponse struct {
+	Deliveries []ClientDelivery `json:"deliveries"`
+	NextCursor string           `json:"next_cursor,omitempty"`
+}
+
+// ListWebhookDeliveries - invoked by GET /v1/webhooks/:id/deliveries.
+func (c *Controller) ListWebhookDeliveries(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	c.logger(ctx).Info().Msg("Listing Webhook deliveries.")
+
+	id, err := uuid.Parse(api.PathParam(r, idPathParam))
+	if err != nil {
+		c.logger(ctx).Err(err).Msg("Invalid webhook id.")
+		return api.BadRequestError("Invalid id.", err, nil)
+	}
+
+	page, validated := parsePage(r, webhook.SortFields...)
+	if !validated.IsClean() {
+		c.logger(ctx).Err(validated).Msg("Validation input failed.")
+		return api.BadRequestError("Invalid input.", validated, validated.Details())
+	}
+
+	filters, validated := parseFilters(r, webhook.DeliveryFilterFields)
+	if !validated.IsClean() {
+		c.logger(ctx).Err(validated).Msg("Validation input failed.")
+		return api.BadRequestError("Invalid input.", validated, validated.Details())
+	}
+
+	ds, next, err := c.Webhook.ListDeliveries(ctx, id, page, filters...)
+	if err != nil {
+		c.logger(ctx).Err(err).Msg("Unable to list webhook deliveries.")
+		return toHTTPError(err)
+	}
+
+	c.logger(ctx).Info().Msg("Successfully listed Webhook deliveries.")
+	return api.Respond(w, http.StatusOK, ListDeliveriesResponse{Deliveries: toClientDeliveries(ds), NextCursor: next})
+}
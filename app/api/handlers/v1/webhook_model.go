@@ -0,0 +1,91 @@
+package v1
+
+import (
+	"encoding/json"
+
+	"github.com/lenguti/jppp/business/core/webhook"
+)
+
+// ClientWebhook - represents a client webhook entity. The secret is only returned on creation.
+type ClientWebhook struct {
+	ID        string   `json:"id"`
+	URL       string   `json:"url"`
+	Types     []string `json:"types"`
+	Secret    string   `json:"secret,omitempty"`
+	CreatedAt int64    `json:"createdAt"`
+	DeletedAt int64    `json:"deletedAt,omitempty"`
+}
+
+// ClientDelivery - represents a client webhook delivery entity.
+type ClientDelivery struct {
+	ID            string          `json:"id"`
+	WebhookID     string          `json:"webhook_id"`
+	EventType     string          `json:"event_type"`
+	Payload       json.RawMessage `json:"payload"`
+	Status        string          `json:"status"`
+	Attempts      int             `json:"attempts"`
+	NextAttemptAt int64           `json:"nextAttemptAt,omitempty"`
+	ResponseCode  int             `json:"response_code,omitempty"`
+	Error         string          `json:"error,omitempty"`
+	CreatedAt     int64           `json:"createdAt"`
+	UpdatedAt     int64           `json:"updatedAt"`
+}
+
+func toCoreNewWebhook(input CreateWebhookRequest) webhook.NewWebhook {
+	return webhook.NewWebhook{
+		URL:    input.URL,
+		Types:  input.Types,
+		Secret: input.Secret,
+	}
+}
+
+func toClientWebhooks(input []webhook.Webhook) []ClientWebhook {
+	ws := make([]ClientWebhook, 0, len(input))
+	for _, v := range input {
+		ws = append(ws, toClientWebhook(v))
+	}
+	return ws
+}
+
+func toClientWebhook(input webhook.Webhook) ClientWebhook {
+	cw := ClientWebhook{
+		ID:        input.ID.String(),
+		URL:       input.URL,
+		Types:     input.Types,
+		CreatedAt: input.CreatedAt.Unix(),
+	}
+	if cw.Types == nil {
+		cw.Types = []string{}
+	}
+	if input.Deleted() {
+		cw.DeletedAt = input.DeletedAt.Unix()
+	}
+	return cw
+}
+
+func toClientDeliveries(input []webhook.Delivery) []ClientDelivery {
+	ds := make([]ClientDelivery, 0, len(input))
+	for _, v := range input {
+		ds = append(ds, toClientDelivery(v))
+	}
+	return ds
+}
+
+func toClientDelivery(input webhook.Delivery) ClientDelivery {
+	cd := ClientDelivery{
+		ID:           input.ID.String(),
+		WebhookID:    input.WebhookID.String(),
+		EventType:    input.EventType,
+		Payload:      input.Payload,
+		Status:       input.Status,
+		Attempts:     input.Attempts,
+		ResponseCode: input.ResponseCode,
+		Error:        input.Error,
+		CreatedAt:    input.CreatedAt.Unix(),
+		UpdatedAt:    input.UpdatedAt.Unix(),
+	}
+	if input.Status == webhook.StatusPending {
+		cd.NextAttemptAt = input.NextAttemptAt.Unix()
+	}
+	return cd
+}
@@ -0,0 +1,121 @@
+package v1_tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "github.com/lenguti/jppp/app/api/handlers/v1"
+	"github.com/lenguti/jppp/business/core/audit"
+	"github.com/lenguti/jppp/foundation/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// snapshot - holds the fields of an audited entity the tests look at.
+type snapshot struct {
+	CageID          string `json:"cage_id"`
+	CurrentCapacity int    `json:"currentCapacity"`
+	Version         int    `json:"version"`
+}
+
+func TestAudit(t *testing.T) {
+	ctrl := newTestController(t)
+	router := ctrl.Routes()
+
+	do := func(t *testing.T, method, path, body string, header http.Header) *httptest.ResponseRecorder {
+		t.Helper()
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(method, path, strings.NewReader(body))
+		r.Header.Set(api.APIKeyHeader, testAPIKey)
+		for k, v := range header {
+			r.Header[k] = v
+		}
+		router.ServeHTTP(w, r)
+		return w
+	}
+	list := func(t *testing.T, query string) v1.ListAuditResponse {
+		t.Helper()
+		w := do(t, http.MethodGet, "/v1/audit?"+query, "", nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var resp v1.ListAuditResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		return resp
+	}
+
+	w := do(t, http.MethodPost, "/v1/cages", `{"type":"CARNIVORE","capacity":2,"status":"ACTIVE"}`, http.Header{http.CanonicalHeaderKey(api.RequestIDHeader): {"req-cage"}})
+	require.Equal(t, http.StatusCreated, w.Code)
+	var cr v1.CreateCageResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&cr))
+
+	w = do(t, http.MethodPost, "/v1/dinosaurs", `{"name":"Rex","species":"Tyrannosaurus","diet":"CARNIVORE"}`, nil)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var dr v1.CreateDinoResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&dr))
+
+	w = do(t, http.MethodPatch, fmt.Sprintf("/v1/cages/%s/dinosaurs/%s", cr.Cage.ID, dr.Dinosaur.ID), "", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	t.Run("records cage changes with actor and request id", func(t *testing.T) {
+		// Execute.
+		resp := list(t, "entity_id="+cr.Cage.ID)
+
+		// Validate.
+		require.Len(t, resp.Records, 2)
+		byAction := map[string]v1.ClientAuditRecord{}
+		for _, rec := range resp.Records {
+			byAction[rec.Action] = rec
+		}
+		created, added := byAction[audit.ActionCageCreate], byAction[audit.ActionCageAddDino]
+		assert.Equal(t, audit.ActionCageCreate, created.Action)
+		assert.Equal(t, audit.EntityCage, created.EntityType)
+		assert.Equal(t, "test", created.Actor)
+		assert.Equal(t, "req-cage", created.RequestID)
+		assert.JSONEq(t, "null", string(created.Before))
+
+		var before, after snapshot
+		require.NoError(t, json.Unmarshal(added.Before, &before))
+		require.NoError(t, json.Unmarshal(added.After, &after))
+		assert.Equal(t, 0, before.CurrentCapacity)
+		assert.Equal(t, 1, after.CurrentCapacity)
+		assert.Equal(t, before.Version+1, after.Version)
+	})
+
+	t.Run("records the dino side of a placement", func(t *testing.T) {
+		// Execute.
+		resp := list(t, "entity_id="+dr.Dinosaur.ID+"&action="+audit.ActionCageAddDino)
+
+		// Validate.
+		require.Len(t, resp.Records, 1)
+		var after snapshot
+		require.NoError(t, json.Unmarshal(resp.Records[0].After, &after))
+		assert.Equal(t, cr.Cage.ID, after.CageID)
+	})
+
+	t.Run("filters by actor and time range", func(t *testing.T) {
+		// Setup.
+		now := time.Now().Unix()
+
+		// Execute.
+		mine := list(t, fmt.Sprintf("actor=test&createdAt[gte]=%d", now-60))
+		others := list(t, "actor=someone")
+		future := list(t, fmt.Sprintf("createdAt[gt]=%d", now+60))
+
+		// Validate.
+		assert.Len(t, mine.Records, 4)
+		assert.Empty(t, others.Records)
+		assert.Empty(t, future.Records)
+	})
+
+	t.Run("rejects unknown filters", func(t *testing.T) {
+		// Execute.
+		w := do(t, http.MethodGet, "/v1/audit?deletedAt[null]=true", "", nil)
+
+		// Validate.
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -0,0 +1,135 @@
+package v1_tests
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	v1 "github.com/lenguti/jppp/app/api/handlers/v1"
+	"github.com/lenguti/jppp/business/core"
+	"github.com/lenguti/jppp/business/core/apikey"
+	"github.com/lenguti/jppp/foundation/api"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testAPIKey    = "jppp_test"
+	testJWTSecret = "secret"
+)
+
+// newTestController - returns a memstore backed controller accepting testAPIKey, granted the admin role, and
+// HS256 jwts signed with testJWTSecret. The options may change the config before it is used.
+func newTestController(t *testing.T, opts ...func(cfg *v1.Config)) *v1.Controller {
+	t.Helper()
+	cfg := v1.Config{
+		MemStore:        true,
+		AuthAPIKeys:     map[string]api.Principal{api.HashAPIKey(testAPIKey): {Subject: "test", Role: string(core.RoleAdmin)}},
+		AuthJWTSecret:   testJWTSecret,
+		AuthJWTIssuer:   "https://auth.jppp.test",
+		AuthJWTAudience: "jppp",
+
+		EventsReplaySize: 16,
+		OutboxPublishers: []string{v1.OutboxPublisherInProcess},
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	ctrl, err := v1.NewController(zerolog.Nop(), cfg)
+	require.NoError(t, err)
+	return ctrl
+}
+
+// signHS256 - returns a HS256 jwt holding the claims.
+func signHS256(t *testing.T, secret string, claims map[string]any) string {
+	t.Helper()
+	enc := func(v any) string {
+		b, err := json.Marshal(v)
+		require.NoError(t, err)
+		return base64.RawURLEncoding.EncodeToString(b)
+	}
+	signed := enc(map[string]string{"alg": "HS256", "typ": "JWT"}) + "." + enc(claims)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(signed))
+	return signed + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestAuth(t *testing.T) {
+	ctrl := newTestController(t)
+	router := ctrl.Routes()
+
+	key, hash, err := api.NewAPIKey()
+	require.NoError(t, err)
+	stored, err := ctrl.APIKey.Create(context.Background(), apikey.NewAPIKey{Name: "ops", Hash: hash})
+	require.NoError(t, err)
+
+	validClaims := func() map[string]any {
+		return map[string]any{
+			"sub":  "alan.grant",
+			"role": "viewer",
+			"iss":  "https://auth.jppp.test",
+			"aud":  []string{"jppp", "other"},
+			"exp":  time.Now().Add(time.Hour).Unix(),
+		}
+	}
+
+	for _, tc := range []struct {
+		name    string
+		path    string
+		headers map[string]string
+		status  int
+	}{
+		{name: "status is public", path: "/v1/status", status: http.StatusOK},
+		{name: "healthcheck is public", path: "/healthcheck", status: http.StatusOK},
+		{name: "missing credentials", path: "/v1/cages", status: http.StatusUnauthorized},
+		{name: "metrics require credentials", path: "/metrics", status: http.StatusUnauthorized},
+		{name: "static api key", path: "/v1/cages", headers: map[string]string{api.APIKeyHeader: testAPIKey}, status: http.StatusOK},
+		{name: "stored api key", path: "/v1/cages", headers: map[string]string{api.APIKeyHeader: key}, status: http.StatusOK},
+		{name: "unknown api key", path: "/v1/cages", headers: map[string]string{api.APIKeyHeader: "jppp_nope"}, status: http.StatusUnauthorized},
+		{name: "jwt", path: "/v1/cages", headers: map[string]string{"Authorization": "Bearer " + signHS256(t, testJWTSecret, validClaims())}, status: http.StatusOK},
+		{name: "jwt with bad signature", path: "/v1/cages", headers: map[string]string{"Authorization": "Bearer " + signHS256(t, "other", validClaims())}, status: http.StatusUnauthorized},
+		{name: "not a bearer token", path: "/v1/cages", headers: map[string]string{"Authorization": "Basic Zm9vOmJhcg=="}, status: http.StatusUnauthorized},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Setup.
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			for k, v := range tc.headers {
+				r.Header.Set(k, v)
+			}
+
+			// Execute.
+			router.ServeHTTP(w, r)
+
+			// Validate.
+			assert.Equal(t, tc.status, w.Code)
+			if tc.status == http.StatusUnauthorized {
+				var resp api.HTTPError
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+				assert.Equal(t, api.Unauthorized, resp.Err.Code)
+				assert.NotEmpty(t, w.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+
+	t.Run("revoked api key", func(t *testing.T) {
+		// Setup.
+		require.NoError(t, ctrl.APIKey.Revoke(context.Background(), stored.ID))
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/v1/cages", nil)
+		r.Header.Set(api.APIKeyHeader, key)
+
+		// Execute.
+		router.ServeHTTP(w, r)
+
+		// Validate.
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
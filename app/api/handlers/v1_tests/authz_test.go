@@ -0,0 +1,128 @@
+package v1_tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lenguti/jppp/business/core"
+	"github.com/lenguti/jppp/foundation/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthorization(t *testing.T) {
+	router := newTestController(t).Routes()
+
+	token := func(role core.Role) string {
+		return signHS256(t, testJWTSecret, map[string]any{
+			"sub":  string(role) + "@jppp.test",
+			"role": string(role),
+			"iss":  "https://auth.jppp.test",
+			"aud":  "jppp",
+			"exp":  time.Now().Add(time.Hour).Unix(),
+		})
+	}
+
+	// Unknown ids, so allowed requests fail further down without changing anything.
+	id, dinoID := uuid.NewString(), uuid.NewString()
+	transfer := fmt.Sprintf(`{"from_cage_id":%q,"to_cage_id":%q}`, uuid.NewString(), uuid.NewString())
+	plan := fmt.Sprintf(`{"plan":{"assignments":[{"dino_id":%q,"cage_id":%q}]}}`, dinoID, id)
+	rebalance := fmt.Sprintf(`{"rebalance":{"moves":[{"dino_id":%q,"from_cage_id":%q,"to_cage_id":%q}]}}`, dinoID, id, uuid.NewString())
+
+	// Routes along with the permissions they check, in order, and the least privileged role allowed through.
+	routes := []struct {
+		method string
+		path   string
+		body   string
+		perms  []core.Permission
+		min    core.Role
+	}{
+		{http.MethodGet, "/metrics", "", []core.Permission{core.PermMetricsRead}, core.RoleViewer},
+		{http.MethodPost, "/v1/cages", "{}", []core.Permission{core.PermCageCreate}, core.RoleSupervisor},
+		{http.MethodGet, "/v1/cages", "", []core.Permission{core.PermCageRead}, core.RoleViewer},
+		{http.MethodGet, "/v1/cages/" + id, "", []core.Permission{core.PermCageRead}, core.RoleViewer},
+		{http.MethodPatch, "/v1/cages/" + id, `{"status":"ACTIVE"}`, []core.Permission{core.PermCageUpdate}, core.RoleKeeper},
+		{http.MethodPatch, "/v1/cages/" + id, `{"status":"DOWN"}`, []core.Permission{core.PermCageUpdate, core.PermCagePowerDown}, core.RoleSupervisor},
+		{http.MethodDelete, "/v1/cages/" + id, "", []core.Permission{core.PermCageDelete}, core.RoleSupervisor},
+		{http.MethodPost, "/v1/cages/" + id + "/restore", "", []core.Permission{core.PermCageRestore}, core.RoleSupervisor},
+		{http.MethodPatch, "/v1/cages/" + id + "/dinosaurs/" + dinoID, "", []core.Permission{core.PermCageAddDino}, core.RoleKeeper},
+		{http.MethodDelete, "/v1/cages/" + id + "/dinosaurs/" + dinoID, "", []core.Permission{core.PermCageRemoveDino}, core.RoleKeeper},
+		{http.MethodGet, "/v1/cages/" + id + "/dinosaurs", "", []core.Permission{core.PermDinoRead}, core.RoleViewer},
+		{http.MethodGet, "/v1/cages/" + id + "/dinosaurs/" + dinoID + "/compatibility", "", []core.Permission{core.PermCageRead}, core.RoleViewer},
+		{http.MethodGet, "/v1/dinosaurs/species", "", []core.Permission{core.PermDinoRead}, core.RoleViewer},
+		{http.MethodPost, "/v1/dinosaurs", "{}", []core.Permission{core.PermDinoCreate}, core.RoleKeeper},
+		{http.MethodGet, "/v1/dinosaurs", "", []core.Permission{core.PermDinoRead}, core.RoleViewer},
+		{http.MethodGet, "/v1/dinosaurs/" + dinoID, "", []core.Permission{core.PermDinoRead}, core.RoleViewer},
+		{http.MethodPatch, "/v1/dinosaurs/" + dinoID, `{"name":"Rex"}`, []core.Permission{core.PermDinoUpdate}, core.RoleKeeper},
+		{http.MethodDelete, "/v1/dinosaurs/" + dinoID, "", []core.Permission{core.PermDinoDelete}, core.RoleSupervisor},
+		{http.MethodPost, "/v1/dinosaurs/" + dinoID + "/restore", "", []core.Permission{core.PermDinoRestore}, core.RoleSupervisor},
+		{http.MethodPost, "/v1/dinosaurs/" + dinoID + "/transfer", transfer, []core.Permission{core.PermDinoTransfer}, core.RoleKeeper},
+		{http.MethodGet, "/v1/cages/" + id + "/history", "", []core.Permission{core.PermCageRead}, core.RoleViewer},
+		{http.MethodGet, "/v1/dinosaurs/" + dinoID + "/history", "", []core.Permission{core.PermDinoRead}, core.RoleViewer},
+		{http.MethodGet, "/v1/dinosaurs/" + dinoID + "/eligible-cages", "", []core.Permission{core.PermCageRead}, core.RoleViewer},
+		{http.MethodPost, "/v1/placements/plan", `{"dino_ids":["` + dinoID + `"]}`, []core.Permission{core.PermCageRead}, core.RoleViewer},
+		{http.MethodPost, "/v1/placements/apply", plan, []core.Permission{core.PermCageAddDino}, core.RoleKeeper},
+		{http.MethodPost, "/v1/rebalance/plan", `{"strategy":"consolidate"}`, []core.Permission{core.PermCageRead}, core.RoleViewer},
+		{http.MethodPost, "/v1/rebalance/apply", rebalance, []core.Permission{core.PermDinoTransfer}, core.RoleKeeper},
+		{http.MethodGet, "/v1/audit", "", []core.Permission{core.PermAuditRead}, core.RoleSupervisor},
+		{http.MethodPost, "/v1/webhooks", "{}", []core.Permission{core.PermWebhookManage}, core.RoleAdmin},
+		{http.MethodGet, "/v1/webhooks", "", []core.Permission{core.PermWebhookRead}, core.RoleSupervisor},
+		{http.MethodGet, "/v1/webhooks/" + id, "", []core.Permission{core.PermWebhookRead}, core.RoleSupervisor},
+		{http.MethodDelete, "/v1/webhooks/" + id, "", []core.Permission{core.PermWebhookManage}, core.RoleAdmin},
+		{http.MethodGet, "/v1/webhooks/" + id + "/deliveries", "", []core.Permission{core.PermWebhookRead}, core.RoleSupervisor},
+		{http.MethodPost, "/v1/species", "{}", []core.Permission{core.PermSpeciesManage}, core.RoleSupervisor},
+		{http.MethodGet, "/v1/species", "", []core.Permission{core.PermSpeciesRead}, core.RoleViewer},
+		{http.MethodGet, "/v1/species/Unknownsaurus", "", []core.Permission{core.PermSpeciesRead}, core.RoleViewer},
+		{http.MethodPatch, "/v1/species/Unknownsaurus", `{"herd":true}`, []core.Permission{core.PermSpeciesManage}, core.RoleSupervisor},
+		{http.MethodDelete, "/v1/species/Unknownsaurus", "", []core.Permission{core.PermSpeciesManage}, core.RoleSupervisor},
+	}
+	// Roles from the least to the most privileged.
+	roles := []core.Role{"", core.RoleViewer, core.RoleKeeper, core.RoleSupervisor, core.RoleAdmin}
+	rank := func(role core.Role) int {
+		for i, r := range roles {
+			if r == role {
+				return i
+			}
+		}
+		return -1
+	}
+
+	for _, rt := range routes {
+		for _, role := range roles {
+			allowed := rank(role) >= rank(rt.min)
+			var denied core.Permission
+			for _, p := range rt.perms {
+				if !role.Can(p) {
+					denied = p
+					break
+				}
+			}
+			t.Run(fmt.Sprintf("%s %s %s as %q", rt.method, rt.path, rt.body, role), func(t *testing.T) {
+				// Setup.
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest(rt.method, rt.path, strings.NewReader(rt.body))
+				r.Header.Set("Authorization", "Bearer "+token(role))
+
+				// Execute.
+				router.ServeHTTP(w, r)
+
+				// Validate.
+				if allowed {
+					assert.NotEqual(t, http.StatusForbidden, w.Code)
+					return
+				}
+				require.Equal(t, http.StatusForbidden, w.Code)
+				var resp api.HTTPError
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+				assert.Equal(t, api.Forbidden, resp.Err.Code)
+				assert.Equal(t, string(denied), resp.Err.Details["permission"])
+			})
+		}
+	}
+}
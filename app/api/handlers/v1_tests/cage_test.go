@@ -3,6 +3,7 @@ package v1_tests
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -15,13 +16,23 @@ import (
 	v1 "github.com/lenguti/jppp/app/api/handlers/v1"
 	"github.com/lenguti/jppp/business/core"
 	"github.com/lenguti/jppp/business/core/cage"
+	"github.com/lenguti/jppp/business/core/cohabitation"
 	"github.com/lenguti/jppp/business/core/dino"
+	"github.com/lenguti/jppp/business/core/species"
+	"github.com/lenguti/jppp/business/data/memstore"
 	"github.com/lenguti/jppp/foundation/api"
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
+// newRules - returns a cohabitation core applying the default rules to the default species.
+func newRules(log zerolog.Logger) *cohabitation.Core {
+	ms := memstore.New()
+	sc := species.NewCore(memstore.NewSpeciesStore(ms), log, species.Config{})
+	return cohabitation.NewCore(memstore.NewCohabitationStore(ms), log, sc, cohabitation.Config{})
+}
+
 func TestCreateCage(t *testing.T) {
 	ctx := context.Background()
 
@@ -78,6 +89,86 @@ func TestCreateCage(t *testing.T) {
 	})
 }
 
+func TestListCages(t *testing.T) {
+	ctx := context.Background()
+	log := zerolog.New(os.Stdout).With().Timestamp().Logger()
+
+	ms := memstore.New()
+	ctrl := v1.Controller{
+		Cage: cage.NewCore(memstore.NewCageStore(ms), log, nil, nil),
+	}
+	for _, capacity := range []int{2, 5, 8} {
+		_, err := ctrl.Cage.Create(ctx, cage.NewCage{Type: cage.CageTypeHerbivore, Capacity: capacity, Status: cage.CageStatusActive})
+		require.NoError(t, err)
+	}
+
+	t.Run("list cages with filters", func(t *testing.T) {
+		// Setup.
+		w := httptest.NewRecorder()
+		r, err := http.NewRequestWithContext(ctx, http.MethodGet, "/v1/cages?capacity[gte]=5&type[in]=carnivore,herbivore", nil)
+		require.NoError(t, err)
+
+		// Execute.
+		err = ctrl.ListCages(ctx, w, r)
+
+		// Validate.
+		require.NoError(t, err)
+		var resp v1.ListCagesResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		require.Len(t, resp.Cages, 2)
+		for _, c := range resp.Cages {
+			assert.GreaterOrEqual(t, c.Capacity, 5)
+		}
+	})
+
+	t.Run("list cages unknown filter", func(t *testing.T) {
+		// Setup.
+		w := httptest.NewRecorder()
+		r, err := http.NewRequestWithContext(ctx, http.MethodGet, "/v1/cages?color=red&capacity[prefix]=1", nil)
+		require.NoError(t, err)
+
+		// Execute.
+		err = ctrl.ListCages(ctx, w, r)
+
+		// Validate.
+		require.Error(t, err)
+		tErr, ok := err.(api.HTTPError)
+		require.True(t, ok)
+		require.Equal(t, http.StatusBadRequest, tErr.Err.StatusCode)
+		assert.Contains(t, tErr.Err.Details, "color")
+		assert.Contains(t, tErr.Err.Details, "capacity[prefix]")
+	})
+
+	t.Run("list cages forged cursor", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			cursor string
+		}{
+			{"invalid id", `{"s":"createdAt","v":"1690000000","id":"nope"}`},
+			{"invalid time", `{"s":"createdAt","v":"yesterday","id":"6f1f6bde-92c4-4d7a-a3ec-3a1e8a1b4c2d"}`},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				// Setup.
+				w := httptest.NewRecorder()
+				cursor := base64.RawURLEncoding.EncodeToString([]byte(tt.cursor))
+				r, err := http.NewRequestWithContext(ctx, http.MethodGet, "/v1/cages?limit=1&cursor="+cursor, nil)
+				require.NoError(t, err)
+
+				// Execute.
+				err = ctrl.ListCages(ctx, w, r)
+
+				// Validate.
+				require.Error(t, err)
+				tErr, ok := err.(api.HTTPError)
+				require.True(t, ok)
+				require.Equal(t, http.StatusBadRequest, tErr.Err.StatusCode)
+				assert.Equal(t, "INVALID_CURSOR", tErr.Err.Code)
+			})
+		}
+	})
+}
+
 func TestUpdateCage(t *testing.T) {
 	ctx := context.Background()
 
@@ -107,6 +198,71 @@ func TestUpdateCage(t *testing.T) {
 	})
 }
 
+func TestCageVersioning(t *testing.T) {
+	ctx := context.Background()
+	log := zerolog.New(os.Stdout).With().Timestamp().Logger()
+
+	ms := memstore.New()
+	ctrl := v1.Controller{
+		Cage: cage.NewCore(memstore.NewCageStore(ms), log, dino.NewCore(memstore.NewDinoStore(ms), log, species.NewCore(memstore.NewSpeciesStore(ms), log, species.Config{})), newRules(log)),
+	}
+	cge, err := ctrl.Cage.Create(ctx, cage.NewCage{Type: cage.CageTypeHerbivore, Capacity: 2, Status: cage.CageStatusActive})
+	require.NoError(t, err)
+	ctx = httptreemux.AddParamsToContext(ctx, map[string]string{"id": cge.ID.String()})
+
+	t.Run("get cage etag", func(t *testing.T) {
+		// Setup.
+		w := httptest.NewRecorder()
+		r, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("/v1/cages/%s", cge.ID), nil)
+		require.NoError(t, err)
+
+		// Execute.
+		err = ctrl.GetCage(ctx, w, r)
+
+		// Validate.
+		require.NoError(t, err)
+		assert.Equal(t, `"1"`, w.Header().Get("ETag"))
+	})
+
+	t.Run("update cage matching if match", func(t *testing.T) {
+		// Setup.
+		bs, err := json.Marshal(v1.UpdateCageRequest{Status: cage.CageStatusDown})
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		r, err := http.NewRequestWithContext(ctx, http.MethodPatch, fmt.Sprintf("/v1/cages/%s", cge.ID), bytes.NewBuffer(bs))
+		require.NoError(t, err)
+		r.Header.Set("If-Match", `"1"`)
+
+		// Execute.
+		err = ctrl.UpdateCage(ctx, w, r)
+
+		// Validate.
+		require.NoError(t, err)
+		assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+	})
+
+	t.Run("update cage stale if match", func(t *testing.T) {
+		// Setup.
+		bs, err := json.Marshal(v1.UpdateCageRequest{Status: cage.CageStatusActive})
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		r, err := http.NewRequestWithContext(ctx, http.MethodPatch, fmt.Sprintf("/v1/cages/%s", cge.ID), bytes.NewBuffer(bs))
+		require.NoError(t, err)
+		r.Header.Set("If-Match", `"1"`)
+
+		// Execute.
+		err = ctrl.UpdateCage(ctx, w, r)
+
+		// Validate.
+		require.Error(t, err)
+		tErr, ok := err.(api.HTTPError)
+		require.True(t, ok)
+		require.Equal(t, http.StatusPreconditionFailed, tErr.Err.StatusCode)
+	})
+}
+
 func TestAddDinoToCage(t *testing.T) {
 	ctx := context.Background()
 	log := zerolog.New(os.Stdout).With().Timestamp().Logger()
@@ -126,7 +282,7 @@ func TestAddDinoToCage(t *testing.T) {
 						Status: cage.CageStatusDown,
 					}, nil
 				},
-			}, log, nil),
+			}, log, nil, nil),
 		}
 
 		w := httptest.NewRecorder()
@@ -141,7 +297,8 @@ func TestAddDinoToCage(t *testing.T) {
 		tErr, ok := err.(api.HTTPError)
 		require.True(t, ok)
 		fmt.Println(tErr)
-		require.Equal(t, http.StatusBadRequest, tErr.Err.StatusCode)
+		require.Equal(t, http.StatusConflict, tErr.Err.StatusCode)
+		assert.Equal(t, "CAGE_POWERED_DOWN", tErr.Err.Code)
 		assert.Equal(t, core.ErrInvalidCagePowerDown.Error(), tErr.Error())
 	})
 
@@ -157,7 +314,7 @@ func TestAddDinoToCage(t *testing.T) {
 						CurrentCapacity: 5,
 					}, nil
 				},
-			}, log, nil),
+			}, log, nil, nil),
 		}
 
 		w := httptest.NewRecorder()
@@ -172,7 +329,8 @@ func TestAddDinoToCage(t *testing.T) {
 		tErr, ok := err.(api.HTTPError)
 		require.True(t, ok)
 		fmt.Println(tErr)
-		require.Equal(t, http.StatusBadRequest, tErr.Err.StatusCode)
+		require.Equal(t, http.StatusConflict, tErr.Err.StatusCode)
+		assert.Equal(t, "CAGE_AT_CAPACITY", tErr.Err.Code)
 		assert.Equal(t, core.ErrInvalidCageAtCapacity.Error(), tErr.Error())
 	})
 
@@ -194,11 +352,22 @@ func TestAddDinoToCage(t *testing.T) {
 				dino.NewCore(&mockDinoStore{
 					getFunc: func() (dino.Dinosaur, error) {
 						return dino.Dinosaur{
-							ID:   dinoID,
-							Diet: dino.DietTypeCarnivore,
+							ID:      dinoID,
+							Diet:    dino.DietTypeCarnivore,
+							Species: dino.DinoSpeciesVelociraptor,
 						}, nil
 					},
-				}, log),
+					listByCageFunc: func() ([]dino.Dinosaur, error) {
+						return []dino.Dinosaur{
+							{
+								ID:      uuid.New(),
+								Diet:    dino.DietTypeHerbivore,
+								Species: dino.DinoSpeciesBrachiosaurus,
+							},
+						}, nil
+					},
+				}, log, nil),
+				newRules(log),
 			),
 		}
 
@@ -214,7 +383,8 @@ func TestAddDinoToCage(t *testing.T) {
 		tErr, ok := err.(api.HTTPError)
 		require.True(t, ok)
 		fmt.Println(tErr)
-		require.Equal(t, http.StatusBadRequest, tErr.Err.StatusCode)
+		require.Equal(t, http.StatusUnprocessableEntity, tErr.Err.StatusCode)
+		assert.Equal(t, "DIET_MISMATCH", tErr.Err.Code)
 		assert.Equal(t, core.ErrInvalidCageInvalidType.Error(), tErr.Error())
 	})
 
@@ -244,18 +414,19 @@ func TestAddDinoToCage(t *testing.T) {
 					listByCageFunc: func() ([]dino.Dinosaur, error) {
 						return []dino.Dinosaur{
 							{
-								ID:      dinoID,
+								ID:      uuid.New(),
 								Diet:    dino.DietTypeCarnivore,
 								Species: dino.DinoSpeciesTyrannosaurus,
 							},
 							{
-								ID:      dinoID,
+								ID:      uuid.New(),
 								Diet:    dino.DietTypeCarnivore,
 								Species: dino.DinoSpeciesTyrannosaurus,
 							},
 						}, nil
 					},
-				}, log),
+				}, log, nil),
+				newRules(log),
 			),
 		}
 
@@ -271,9 +442,44 @@ func TestAddDinoToCage(t *testing.T) {
 		tErr, ok := err.(api.HTTPError)
 		require.True(t, ok)
 		fmt.Println(tErr)
-		require.Equal(t, http.StatusBadRequest, tErr.Err.StatusCode)
+		require.Equal(t, http.StatusConflict, tErr.Err.StatusCode)
+		assert.Equal(t, "SPECIES_CONFLICT", tErr.Err.Code)
 		assert.Equal(t, core.ErrInvalidCageInvalidSpecies.Error(), tErr.Error())
 	})
+
+	t.Run("add dino to cage success", func(t *testing.T) {
+		// Setup.
+		ms := memstore.New()
+		cs, ds := memstore.NewCageStore(ms), memstore.NewDinoStore(ms)
+		require.NoError(t, cs.Create(ctx, cage.Cage{
+			ID:       cageID,
+			Status:   cage.CageStatusActive,
+			Capacity: 5,
+			Type:     cage.CageTypeCarnivore,
+		}))
+		require.NoError(t, ds.Create(ctx, dino.Dinosaur{
+			ID:      dinoID,
+			Diet:    dino.DietTypeCarnivore,
+			Species: dino.DinoSpeciesVelociraptor,
+		}))
+		ctrl := v1.Controller{
+			Cage: cage.NewCore(cs, log, dino.NewCore(ds, log, nil), newRules(log)),
+		}
+
+		w := httptest.NewRecorder()
+		r, err := http.NewRequestWithContext(ctx, http.MethodPatch, fmt.Sprintf("/v1/cages/%s/dinosaurs/%s", cageID, dinoID), nil)
+		require.NoError(t, err)
+
+		// Execute.
+		err = ctrl.AddDinosaurToCage(ctx, w, r)
+
+		// Validate.
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp v1.AddDinosaurToCageResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.Equal(t, 1, resp.Cage.CurrentCapacity)
+	})
 }
 
 func TestRemoveDinoFromCage(t *testing.T) {
@@ -295,7 +501,7 @@ func TestRemoveDinoFromCage(t *testing.T) {
 						CurrentCapacity: 0,
 					}, nil
 				},
-			}, log, nil),
+			}, log, nil, nil),
 		}
 
 		w := httptest.NewRecorder()
@@ -310,7 +516,116 @@ func TestRemoveDinoFromCage(t *testing.T) {
 		tErr, ok := err.(api.HTTPError)
 		require.True(t, ok)
 		fmt.Println(tErr)
-		require.Equal(t, http.StatusBadRequest, tErr.Err.StatusCode)
+		require.Equal(t, http.StatusConflict, tErr.Err.StatusCode)
+		assert.Equal(t, "CAGE_EMPTY", tErr.Err.Code)
 		assert.Equal(t, core.ErrInvalidCageInvalidRemoval.Error(), tErr.Error())
 	})
 }
+
+func TestDeleteCage(t *testing.T) {
+	ctx := context.Background()
+	log := zerolog.New(os.Stdout).With().Timestamp().Logger()
+
+	ms := memstore.New()
+	ctrl := v1.Controller{
+		Cage: cage.NewCore(memstore.NewCageStore(ms), log, dino.NewCore(memstore.NewDinoStore(ms), log, species.NewCore(memstore.NewSpeciesStore(ms), log, species.Config{})), newRules(log)),
+	}
+	cge, err := ctrl.Cage.Create(ctx, cage.NewCage{Type: cage.CageTypeHerbivore, Capacity: 2, Status: cage.CageStatusActive})
+	require.NoError(t, err)
+	ctx = httptreemux.AddParamsToContext(ctx, map[string]string{"id": cge.ID.String()})
+
+	t.Run("delete cage not empty error", func(t *testing.T) {
+		// Setup.
+		ms := memstore.New()
+		cs, ds := memstore.NewCageStore(ms), memstore.NewDinoStore(ms)
+		require.NoError(t, cs.Create(ctx, cage.Cage{ID: cge.ID, Status: cage.CageStatusActive, Capacity: 2, CurrentCapacity: 1, Version: 1}))
+		ctrl := v1.Controller{
+			Cage: cage.NewCore(cs, log, dino.NewCore(ds, log, nil), newRules(log)),
+		}
+
+		w := httptest.NewRecorder()
+		r, err := http.NewRequestWithContext(ctx, http.MethodDelete, fmt.Sprintf("/v1/cages/%s", cge.ID), nil)
+		require.NoError(t, err)
+
+		// Execute.
+		err = ctrl.DeleteCage(ctx, w, r)
+
+		// Validate.
+		require.Error(t, err)
+		tErr, ok := err.(api.HTTPError)
+		require.True(t, ok)
+		require.Equal(t, http.StatusConflict, tErr.Err.StatusCode)
+		assert.Equal(t, "CAGE_NOT_EMPTY", tErr.Err.Code)
+		assert.Equal(t, core.ErrInvalidCageNotEmpty.Error(), tErr.Error())
+	})
+
+	t.Run("delete cage success", func(t *testing.T) {
+		// Setup.
+		w := httptest.NewRecorder()
+		r, err := http.NewRequestWithContext(ctx, http.MethodDelete, fmt.Sprintf("/v1/cages/%s", cge.ID), nil)
+		require.NoError(t, err)
+		r.Header.Set("If-Match", `"1"`)
+
+		// Execute.
+		err = ctrl.DeleteCage(ctx, w, r)
+
+		// Validate.
+		require.NoError(t, err)
+		var resp v1.DeleteCageResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.NotZero(t, resp.Cage.DeletedAt)
+		assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+	})
+
+	t.Run("get deleted cage not found", func(t *testing.T) {
+		// Setup.
+		w := httptest.NewRecorder()
+		r, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("/v1/cages/%s", cge.ID), nil)
+		require.NoError(t, err)
+
+		// Execute.
+		err = ctrl.GetCage(ctx, w, r)
+
+		// Validate.
+		require.Error(t, err)
+		tErr, ok := err.(api.HTTPError)
+		require.True(t, ok)
+		require.Equal(t, http.StatusNotFound, tErr.Err.StatusCode)
+	})
+
+	t.Run("list cages include deleted", func(t *testing.T) {
+		for query, want := range map[string]int{"": 0, "?include_deleted=true": 1} {
+			// Setup.
+			w := httptest.NewRecorder()
+			r, err := http.NewRequestWithContext(ctx, http.MethodGet, "/v1/cages"+query, nil)
+			require.NoError(t, err)
+
+			// Execute.
+			err = ctrl.ListCages(ctx, w, r)
+
+			// Validate.
+			require.NoError(t, err)
+			var resp v1.ListCagesResponse
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+			assert.Len(t, resp.Cages, want, query)
+		}
+	})
+
+	t.Run("restore cage success", func(t *testing.T) {
+		// Setup.
+		w := httptest.NewRecorder()
+		r, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("/v1/cages/%s/restore", cge.ID), nil)
+		require.NoError(t, err)
+		r.Header.Set("If-Match", `"2"`)
+
+		// Execute.
+		err = ctrl.RestoreCage(ctx, w, r)
+
+		// Validate.
+		require.NoError(t, err)
+		var resp v1.RestoreCageResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.Zero(t, resp.Cage.DeletedAt)
+		assert.Equal(t, 3, resp.Cage.Version)
+	})
+}
@@ -0,0 +1,201 @@
+package v1_tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "github.com/lenguti/jppp/app/api/handlers/v1"
+	"github.com/lenguti/jppp/foundation/api"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testRules = `[
+	{"kind": "diet"},
+	{"kind": "carnivore_species", "pairs": [["Tyrannosaurus", "Megalosaurus"]]},
+	{"kind": "incompatible_species", "pairs": [["Triceratops", "Stegosaurus"]]},
+	{"kind": "juvenile", "max_age": "8760h"},
+	{"kind": "solitary"}
+]`
+
+// writeRules - returns the path of a temporary rules file holding the rules.
+func writeRules(t *testing.T, rules string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "rules.json")
+	require.NoError(t, os.WriteFile(path, []byte(rules), 0o600))
+	return path
+}
+
+func TestCohabitation(t *testing.T) {
+	path := writeRules(t, testRules)
+	router := newTestController(t, func(cfg *v1.Config) { cfg.CohabitationRulesFile = path }).Routes()
+
+	do := func(t *testing.T, method, path, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(method, path, strings.NewReader(body))
+		r.Header.Set(api.APIKeyHeader, testAPIKey)
+		router.ServeHTTP(w, r)
+		return w
+	}
+	newCage := func(t *testing.T, typ string) string {
+		t.Helper()
+		w := do(t, http.MethodPost, "/v1/cages", fmt.Sprintf(`{"type":%q,"capacity":5,"status":"ACTIVE"}`, typ))
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		var resp v1.CreateCageResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		return resp.Cage.ID
+	}
+	newDino := func(t *testing.T, species, diet string, hatchedAt int64) string {
+		t.Helper()
+		w := do(t, http.MethodPost, "/v1/dinosaurs", fmt.Sprintf(`{"name":"Dino","species":%q,"diet":%q,"hatchedAt":%d}`, species, diet, hatchedAt))
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		var resp v1.CreateDinoResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		return resp.Dinosaur.ID
+	}
+	add := func(t *testing.T, cageID, dinoID string) *httptest.ResponseRecorder {
+		t.Helper()
+		return do(t, http.MethodPatch, "/v1/cages/"+cageID+"/dinosaurs/"+dinoID, "")
+	}
+	compatibility := func(t *testing.T, cageID, dinoID string) v1.DinosaurCompatibilityResponse {
+		t.Helper()
+		w := do(t, http.MethodGet, "/v1/cages/"+cageID+"/dinosaurs/"+dinoID+"/compatibility", "")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var resp v1.DinosaurCompatibilityResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		return resp
+	}
+
+	carnivores := newCage(t, "CARNIVORE")
+	rex := newDino(t, "Tyrannosaurus", "CARNIVORE", 0)
+	require.Equal(t, http.StatusOK, add(t, carnivores, rex).Code)
+
+	herbivores := newCage(t, "HERBIVORE")
+	horns := newDino(t, "Triceratops", "HERBIVORE", 0)
+	require.Equal(t, http.StatusOK, add(t, herbivores, horns).Code)
+
+	t.Run("tolerated carnivores share a cage", func(t *testing.T) {
+		// Setup.
+		megalo := newDino(t, "Megalosaurus", "CARNIVORE", 0)
+
+		// Execute.
+		resp := compatibility(t, carnivores, megalo)
+		w := add(t, carnivores, megalo)
+
+		// Validate.
+		assert.True(t, resp.Compatible)
+		assert.Empty(t, resp.Violations)
+		assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	})
+
+	t.Run("dry run explains every violated rule", func(t *testing.T) {
+		// Setup.
+		blue := newDino(t, "Velociraptor", "CARNIVORE", 0)
+
+		// Execute.
+		resp := compatibility(t, herbivores, blue)
+
+		// Validate.
+		assert.False(t, resp.Compatible)
+		require.Len(t, resp.Violations, 2)
+		assert.Equal(t, "diet", resp.Violations[0].Rule)
+		assert.Equal(t, "DIET_MISMATCH", resp.Violations[0].Code)
+		assert.Equal(t, "carnivore_species", resp.Violations[1].Rule)
+		assert.Equal(t, "SPECIES_CONFLICT", resp.Violations[1].Code)
+		assert.Contains(t, resp.Violations[1].Explanation, "Triceratops")
+		assert.Equal(t, []string{horns}, resp.Violations[1].DinoIDs)
+	})
+
+	t.Run("incompatible species", func(t *testing.T) {
+		// Setup.
+		stego := newDino(t, "Stegosaurus", "HERBIVORE", 0)
+
+		// Execute.
+		w := add(t, herbivores, stego)
+
+		// Validate.
+		require.Equal(t, http.StatusConflict, w.Code)
+		var resp struct {
+			Err struct {
+				Code    string `json:"code"`
+				Details struct {
+					Violations []v1.ClientViolation `json:"violations"`
+				} `json:"details"`
+			} `json:"error"`
+		}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.Equal(t, "SPECIES_INCOMPATIBLE", resp.Err.Code)
+		require.Len(t, resp.Err.Details.Violations, 1)
+		assert.Equal(t, "Dino the Stegosaurus cannot share a cage with Triceratops.", resp.Err.Details.Violations[0].Explanation)
+	})
+
+	t.Run("juveniles are kept apart from adults", func(t *testing.T) {
+		// Setup.
+		calf := newDino(t, "Triceratops", "HERBIVORE", time.Now().Add(-30*24*time.Hour).Unix())
+
+		// Execute.
+		resp := compatibility(t, herbivores, calf)
+
+		// Validate.
+		require.Len(t, resp.Violations, 1)
+		assert.Equal(t, "juvenile", resp.Violations[0].Rule)
+		assert.Equal(t, "JUVENILE_SEPARATION", resp.Violations[0].Code)
+	})
+
+	t.Run("transfers apply the rules", func(t *testing.T) {
+		// Setup.
+		other := newCage(t, "CARNIVORE")
+		blue := newDino(t, "Velociraptor", "CARNIVORE", 0)
+		require.Equal(t, http.StatusOK, add(t, other, blue).Code)
+
+		// Execute.
+		w := do(t, http.MethodPost, "/v1/dinosaurs/"+blue+"/transfer", fmt.Sprintf(`{"from_cage_id":%q,"to_cage_id":%q}`, other, carnivores))
+
+		// Validate.
+		require.Equal(t, http.StatusConflict, w.Code)
+		var resp api.HTTPError
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.Equal(t, "SPECIES_CONFLICT", resp.Err.Code)
+		assert.Contains(t, resp.Err.Details, "violations")
+	})
+
+	t.Run("eligible cages are ranked by free capacity", func(t *testing.T) {
+		// Setup.
+		empty := newCage(t, "CARNIVORE")
+		megalo := newDino(t, "Megalosaurus", "CARNIVORE", 0)
+
+		// Execute.
+		w := do(t, http.MethodGet, "/v1/dinosaurs/"+megalo+"/eligible-cages", "")
+
+		// Validate.
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var resp v1.EligibleCagesResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		ids := make([]string, 0, len(resp.Cages))
+		for _, cg := range resp.Cages {
+			ids = append(ids, cg.ID)
+		}
+		assert.Equal(t, []string{empty, carnivores}, ids)
+	})
+
+	t.Run("invalid rules are refused on startup", func(t *testing.T) {
+		// Setup.
+		path := writeRules(t, `[{"kind": "juvenile"}]`)
+
+		// Execute.
+		_, err := v1.NewController(zerolog.Nop(), v1.Config{MemStore: true, CohabitationRulesFile: path})
+
+		// Validate.
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "max_age")
+	})
+}
@@ -4,19 +4,30 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"testing"
 
+	"github.com/dimfeld/httptreemux"
+	"github.com/google/uuid"
 	v1 "github.com/lenguti/jppp/app/api/handlers/v1"
+	"github.com/lenguti/jppp/business/core"
+	"github.com/lenguti/jppp/business/core/cage"
 	"github.com/lenguti/jppp/business/core/dino"
+	"github.com/lenguti/jppp/business/core/species"
+	"github.com/lenguti/jppp/business/data/memstore"
 	"github.com/lenguti/jppp/foundation/api"
+	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
 func TestCreateDino(t *testing.T) {
 	ctx := context.Background()
+	ms := memstore.New()
+	dc := dino.NewCore(memstore.NewDinoStore(ms), zerolog.Nop(), species.NewCore(memstore.NewSpeciesStore(ms), zerolog.Nop(), species.Config{}))
 
 	t.Run("create dino invalid name", func(t *testing.T) {
 		// Setup.
@@ -25,7 +36,7 @@ func TestCreateDino(t *testing.T) {
 			Species: dino.DinoSpeciesSpinosaurus,
 			Diet:    dino.DietTypeCarnivore,
 		}
-		ctrl := v1.Controller{}
+		ctrl := v1.Controller{Dino: dc}
 
 		bs, err := json.Marshal(input)
 		require.NoError(t, err)
@@ -52,7 +63,7 @@ func TestCreateDino(t *testing.T) {
 			Species: "Gorilla",
 			Diet:    dino.DietTypeCarnivore,
 		}
-		ctrl := v1.Controller{}
+		ctrl := v1.Controller{Dino: dc}
 
 		bs, err := json.Marshal(input)
 		require.NoError(t, err)
@@ -79,7 +90,7 @@ func TestCreateDino(t *testing.T) {
 			Species: dino.DinoSpeciesAnkylosaurus,
 			Diet:    "Fruitivore",
 		}
-		ctrl := v1.Controller{}
+		ctrl := v1.Controller{Dino: dc}
 
 		bs, err := json.Marshal(input)
 		require.NoError(t, err)
@@ -106,7 +117,7 @@ func TestCreateDino(t *testing.T) {
 			Species: dino.DinoSpeciesAnkylosaurus,
 			Diet:    dino.DietTypeCarnivore,
 		}
-		ctrl := v1.Controller{}
+		ctrl := v1.Controller{Dino: dc}
 
 		bs, err := json.Marshal(input)
 		require.NoError(t, err)
@@ -155,3 +166,121 @@ func TestUpdateDino(t *testing.T) {
 		assert.Contains(t, tErr.Err.Details, "name")
 	})
 }
+
+func TestDeleteDino(t *testing.T) {
+	ctx := context.Background()
+	log := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	dinoID := uuid.New()
+	ctx = httptreemux.AddParamsToContext(ctx, map[string]string{"id": dinoID.String()})
+
+	t.Run("delete caged dino error", func(t *testing.T) {
+		// Setup.
+		ds := memstore.NewDinoStore(memstore.New())
+		require.NoError(t, ds.Create(ctx, dino.Dinosaur{ID: dinoID, CageID: uuid.New(), Version: 1}))
+		ctrl := v1.Controller{
+			Dino: dino.NewCore(ds, log, nil),
+		}
+
+		w := httptest.NewRecorder()
+		r, err := http.NewRequestWithContext(ctx, http.MethodDelete, fmt.Sprintf("/v1/dinosaurs/%s", dinoID), nil)
+		require.NoError(t, err)
+
+		// Execute.
+		err = ctrl.DeleteDino(ctx, w, r)
+
+		// Validate.
+		require.Error(t, err)
+		tErr, ok := err.(api.HTTPError)
+		require.True(t, ok)
+		require.Equal(t, http.StatusConflict, tErr.Err.StatusCode)
+		assert.Equal(t, "DINO_CAGED", tErr.Err.Code)
+		assert.Equal(t, core.ErrInvalidDinoCaged.Error(), tErr.Error())
+	})
+
+	t.Run("delete dino success", func(t *testing.T) {
+		// Setup.
+		ds := memstore.NewDinoStore(memstore.New())
+		require.NoError(t, ds.Create(ctx, dino.Dinosaur{ID: dinoID, Version: 1}))
+		ctrl := v1.Controller{
+			Dino: dino.NewCore(ds, log, nil),
+		}
+
+		w := httptest.NewRecorder()
+		r, err := http.NewRequestWithContext(ctx, http.MethodDelete, fmt.Sprintf("/v1/dinosaurs/%s", dinoID), nil)
+		require.NoError(t, err)
+
+		// Execute.
+		err = ctrl.DeleteDino(ctx, w, r)
+
+		// Validate.
+		require.NoError(t, err)
+		var resp v1.DeleteDinoResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.NotZero(t, resp.Dinosaur.DeletedAt)
+
+		_, err = ctrl.Dino.Get(ctx, dinoID)
+		assert.ErrorIs(t, err, core.ErrNotFound)
+	})
+}
+
+func TestTransferDino(t *testing.T) {
+	ctx := context.Background()
+	log := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	cageID, dinoID := uuid.New(), uuid.New()
+	ctx = httptreemux.AddParamsToContext(ctx, map[string]string{"id": dinoID.String()})
+
+	t.Run("transfer dino same cage error", func(t *testing.T) {
+		// Setup.
+		ms := memstore.New()
+		ctrl := v1.Controller{
+			Cage: cage.NewCore(memstore.NewCageStore(ms), log, dino.NewCore(memstore.NewDinoStore(ms), log, species.NewCore(memstore.NewSpeciesStore(ms), log, species.Config{})), newRules(log)),
+		}
+
+		bs, err := json.Marshal(v1.TransferDinoRequest{FromCageID: cageID.String(), ToCageID: cageID.String()})
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		r, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("/v1/dinosaurs/%s/transfer", dinoID), bytes.NewBuffer(bs))
+		require.NoError(t, err)
+
+		// Execute.
+		err = ctrl.TransferDino(ctx, w, r)
+
+		// Validate.
+		require.Error(t, err)
+		tErr, ok := err.(api.HTTPError)
+		require.True(t, ok)
+		require.Equal(t, http.StatusUnprocessableEntity, tErr.Err.StatusCode)
+		assert.Equal(t, "TRANSFER_SAME_CAGE", tErr.Err.Code)
+		assert.Equal(t, core.ErrInvalidTransferSameCage.Error(), tErr.Error())
+	})
+
+	t.Run("transfer uncaged dino without source cage", func(t *testing.T) {
+		// Setup.
+		ms := memstore.New()
+		ds := memstore.NewDinoStore(ms)
+		require.NoError(t, ds.Create(ctx, dino.Dinosaur{ID: dinoID, Version: 1}))
+		dc := dino.NewCore(ds, log, species.NewCore(memstore.NewSpeciesStore(ms), log, species.Config{}))
+		ctrl := v1.Controller{
+			Dino: dc,
+			Cage: cage.NewCore(memstore.NewCageStore(ms), log, dc, newRules(log)),
+		}
+
+		bs, err := json.Marshal(v1.TransferDinoRequest{ToCageID: cageID.String()})
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		r, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("/v1/dinosaurs/%s/transfer", dinoID), bytes.NewBuffer(bs))
+		require.NoError(t, err)
+
+		// Execute.
+		err = ctrl.TransferDino(ctx, w, r)
+
+		// Validate.
+		require.Error(t, err)
+		tErr, ok := err.(api.HTTPError)
+		require.True(t, ok)
+		require.Equal(t, http.StatusConflict, tErr.Err.StatusCode)
+		assert.Equal(t, "DINO_NOT_IN_CAGE", tErr.Err.Code)
+	})
+}
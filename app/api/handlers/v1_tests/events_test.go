@@ -0,0 +1,142 @@
+package v1_tests
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	v1 "github.com/lenguti/jppp/app/api/handlers/v1"
+	"github.com/lenguti/jppp/business/core/cage"
+	"github.com/lenguti/jppp/business/core/dino"
+	"github.com/lenguti/jppp/business/core/event"
+	"github.com/lenguti/jppp/foundation/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sseEvent - represents a server-sent event as read off the wire.
+type sseEvent struct {
+	id    string
+	event string
+	data  v1.ClientEvent
+}
+
+// readEvents - reads n events off the stream, skipping comments.
+func readEvents(t *testing.T, sc *bufio.Scanner, n int) []sseEvent {
+	t.Helper()
+	var (
+		evs []sseEvent
+		cur sseEvent
+	)
+	for len(evs) < n && sc.Scan() {
+		line := sc.Text()
+		switch {
+		case line == "":
+			if cur.event != "" {
+				evs = append(evs, cur)
+			}
+			cur = sseEvent{}
+		case strings.HasPrefix(line, "id: "):
+			cur.id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: "):
+			cur.event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &cur.data))
+		}
+	}
+	require.Len(t, evs, n)
+	return evs
+}
+
+func TestEvents(t *testing.T) {
+	ctx := context.Background()
+	ctrl := newTestController(t)
+	router := ctrl.Routes()
+	srv := httptest.NewServer(router)
+	t.Cleanup(srv.Close)
+	t.Cleanup(ctrl.Close)
+
+	stream := func(t *testing.T, query, lastID string) *bufio.Scanner {
+		t.Helper()
+		sctx, cancel := context.WithCancel(ctx)
+		t.Cleanup(cancel)
+		r, err := http.NewRequestWithContext(sctx, http.MethodGet, srv.URL+"/v1/events"+query, nil)
+		require.NoError(t, err)
+		r.Header.Set(api.APIKeyHeader, testAPIKey)
+		if lastID != "" {
+			r.Header.Set("Last-Event-ID", lastID)
+		}
+		resp, err := http.DefaultClient.Do(r)
+		require.NoError(t, err)
+		t.Cleanup(func() { resp.Body.Close() })
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+		return bufio.NewScanner(resp.Body)
+	}
+
+	all := stream(t, "", "")
+	dinos := stream(t, "?entity_type=dinosaur", "")
+
+	cge, err := ctrl.Cage.Create(ctx, cage.NewCage{Type: cage.CageTypeHerbivore, Capacity: 2, Status: cage.CageStatusActive})
+	require.NoError(t, err)
+	d, err := ctrl.Dino.Create(ctx, dino.NewDino{Name: "Cera", Species: dino.DinoSpeciesTriceratops, Diet: dino.DietTypeHerbivore})
+	require.NoError(t, err)
+	_, err = ctrl.Cage.AddDino(ctx, cge.ID, d.ID, 0)
+	require.NoError(t, err)
+
+	// The events reach the streams once relayed from the outbox.
+	n, err := ctrl.Outbox.Relay(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 4, n)
+
+	t.Run("streams every change", func(t *testing.T) {
+		// Execute.
+		evs := readEvents(t, all, 4)
+
+		// Validate.
+		var types []string
+		for _, e := range evs {
+			types = append(types, e.event)
+		}
+		assert.Equal(t, []string{event.TypeCageCreated, event.TypeDinoCreated, event.TypeCageOccupancyChanged, event.TypeDinoCaged}, types)
+		assert.Equal(t, "1", evs[0].id)
+		assert.Equal(t, cge.ID.String(), evs[3].data.CageID)
+		assert.Equal(t, d.ID.String(), evs[3].data.EntityID)
+	})
+
+	t.Run("filters by entity type", func(t *testing.T) {
+		// Execute.
+		evs := readEvents(t, dinos, 2)
+
+		// Validate.
+		assert.Equal(t, event.TypeDinoCreated, evs[0].event)
+		assert.Equal(t, event.TypeDinoCaged, evs[1].event)
+	})
+
+	t.Run("resumes after the last event id", func(t *testing.T) {
+		// Execute.
+		evs := readEvents(t, stream(t, "?cage_id="+cge.ID.String(), "1"), 2)
+
+		// Validate.
+		assert.Equal(t, "3", evs[0].id)
+		assert.Equal(t, event.TypeCageOccupancyChanged, evs[0].event)
+		assert.Equal(t, event.TypeDinoCaged, evs[1].event)
+	})
+
+	t.Run("invalid cage id", func(t *testing.T) {
+		// Setup.
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/v1/events?cage_id=nope", nil)
+		r.Header.Set(api.APIKeyHeader, testAPIKey)
+
+		// Execute.
+		router.ServeHTTP(w, r)
+
+		// Validate.
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -0,0 +1,107 @@
+package v1_tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	v1 "github.com/lenguti/jppp/app/api/handlers/v1"
+	"github.com/lenguti/jppp/business/core/cage"
+	"github.com/lenguti/jppp/business/core/dino"
+	"github.com/lenguti/jppp/foundation/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistory(t *testing.T) {
+	ctx := context.Background()
+	ctrl := newTestController(t)
+	router := ctrl.Routes()
+
+	list := func(t *testing.T, path string) (int, v1.ListHistoryResponse) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		r.Header.Set(api.APIKeyHeader, testAPIKey)
+		router.ServeHTTP(w, r)
+		var resp v1.ListHistoryResponse
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		}
+		return w.Code, resp
+	}
+
+	before := time.Now().Add(-time.Minute).Unix()
+	from, err := ctrl.Cage.Create(ctx, cage.NewCage{Type: cage.CageTypeHerbivore, Capacity: 2, Status: cage.CageStatusActive})
+	require.NoError(t, err)
+	to, err := ctrl.Cage.Create(ctx, cage.NewCage{Type: cage.CageTypeHerbivore, Capacity: 2, Status: cage.CageStatusActive})
+	require.NoError(t, err)
+	d, err := ctrl.Dino.Create(ctx, dino.NewDino{Name: "Littlefoot", Species: dino.DinoSpeciesBrachiosaurus, Diet: dino.DietTypeHerbivore})
+	require.NoError(t, err)
+	_, err = ctrl.Cage.AddDino(ctx, from.ID, d.ID, 0)
+	require.NoError(t, err)
+	_, err = ctrl.Cage.TransferDino(ctx, from.ID, to.ID, d.ID)
+	require.NoError(t, err)
+
+	t.Run("dino history lists every cage it lived in", func(t *testing.T) {
+		// Execute.
+		code, resp := list(t, fmt.Sprintf("/v1/dinosaurs/%s/history", d.ID))
+
+		// Validate.
+		require.Equal(t, http.StatusOK, code)
+		require.Len(t, resp.Placements, 2)
+		cages := map[string]v1.ClientPlacement{}
+		for _, p := range resp.Placements {
+			cages[p.CageID] = p
+		}
+		assert.NotZero(t, cages[from.ID.String()].ReleasedAt)
+		assert.Zero(t, cages[to.ID.String()].ReleasedAt)
+	})
+
+	t.Run("cage history filters ongoing placements", func(t *testing.T) {
+		// Execute.
+		code, released := list(t, fmt.Sprintf("/v1/cages/%s/history?releasedAt[null]=false", from.ID))
+		_, ongoing := list(t, fmt.Sprintf("/v1/cages/%s/history?releasedAt[null]=true", from.ID))
+
+		// Validate.
+		require.Equal(t, http.StatusOK, code)
+		require.Len(t, released.Placements, 1)
+		assert.Equal(t, d.ID.String(), released.Placements[0].DinoID)
+		assert.Empty(t, ongoing.Placements)
+	})
+
+	t.Run("roster at a point in time", func(t *testing.T) {
+		// Execute.
+		_, past := list(t, fmt.Sprintf("/v1/cages/%s/history?at=%d", to.ID, before))
+		_, now := list(t, fmt.Sprintf("/v1/cages/%s/history?at=%s", to.ID, time.Now().Add(time.Minute).Format(time.RFC3339)))
+
+		// Validate.
+		assert.Empty(t, past.Placements)
+		require.Len(t, now.Placements, 1)
+		assert.Equal(t, d.ID.String(), now.Placements[0].DinoID)
+	})
+
+	t.Run("invalid at", func(t *testing.T) {
+		// Execute.
+		code, _ := list(t, fmt.Sprintf("/v1/dinosaurs/%s/history?at=yesterday", d.ID))
+
+		// Validate.
+		assert.Equal(t, http.StatusBadRequest, code)
+	})
+
+	t.Run("at is only read by history routes", func(t *testing.T) {
+		// Execute.
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/v1/cages?at="+strconv.FormatInt(before, 10), nil)
+		r.Header.Set(api.APIKeyHeader, testAPIKey)
+		router.ServeHTTP(w, r)
+
+		// Validate.
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
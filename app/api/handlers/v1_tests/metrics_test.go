@@ -0,0 +1,62 @@
+package v1_tests
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lenguti/jppp/business/core/cage"
+	"github.com/lenguti/jppp/business/core/dino"
+	"github.com/lenguti/jppp/foundation/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics(t *testing.T) {
+	ctx := context.Background()
+	ctrl := newTestController(t)
+	router := ctrl.Routes()
+
+	cge, err := ctrl.Cage.Create(ctx, cage.NewCage{Type: cage.CageTypeCarnivore, Capacity: 4, Status: cage.CageStatusActive})
+	require.NoError(t, err)
+	rex, err := ctrl.Dino.Create(ctx, dino.NewDino{Name: "Rex", Species: dino.DinoSpeciesTyrannosaurus, Diet: dino.DietTypeCarnivore})
+	require.NoError(t, err)
+	blue, err := ctrl.Dino.Create(ctx, dino.NewDino{Name: "Blue", Species: dino.DinoSpeciesVelociraptor, Diet: dino.DietTypeCarnivore})
+	require.NoError(t, err)
+
+	for _, d := range []dino.Dinosaur{rex, blue} {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPatch, fmt.Sprintf("/v1/cages/%s/dinosaurs/%s", cge.ID, d.ID), nil)
+		r.Header.Set(api.APIKeyHeader, testAPIKey)
+		router.ServeHTTP(w, r)
+	}
+
+	t.Run("exposes http and business metrics", func(t *testing.T) {
+		// Setup.
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		r.Header.Set(api.APIKeyHeader, testAPIKey)
+
+		// Execute.
+		router.ServeHTTP(w, r)
+
+		// Validate.
+		require.Equal(t, http.StatusOK, w.Code)
+		body, err := io.ReadAll(w.Body)
+		require.NoError(t, err)
+		for _, want := range []string{
+			`http_requests_total{method="PATCH",route="/v1/cages/:id/dinosaurs/:dinoId",status="200"} 1`,
+			`http_requests_total{method="PATCH",route="/v1/cages/:id/dinosaurs/:dinoId",status="409"} 1`,
+			`http_request_duration_seconds_count{method="PATCH",route="/v1/cages/:id/dinosaurs/:dinoId",status="200"} 1`,
+			`jppp_add_dino_rejections_total{rule="SPECIES_CONFLICT"} 1`,
+			`jppp_cages{status="ACTIVE",type="CARNIVORE"} 1`,
+			`jppp_cage_occupancy_ratio{status="ACTIVE",type="CARNIVORE"} 0.25`,
+			`jppp_dinosaurs{diet="CARNIVORE",species="Velociraptor"} 1`,
+		} {
+			assert.Contains(t, string(body), want)
+		}
+	})
+}
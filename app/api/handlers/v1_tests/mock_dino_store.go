@@ -18,6 +18,6 @@ func (mds *mockDinoStore) Get(ctx context.Context, id string) (dino.Dinosaur, er
 	return mds.getFunc()
 }
 
-func (mds *mockDinoStore) ListByCage(ctx context.Context, cageID string, filters ...core.Filter) ([]dino.Dinosaur, error) {
+func (mds *mockDinoStore) ListByCage(ctx context.Context, cageID string, page core.Page, filters ...core.Filter) ([]dino.Dinosaur, error) {
 	return mds.listByCageFunc()
 }
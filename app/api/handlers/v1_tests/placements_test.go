@@ -0,0 +1,122 @@
+package v1_tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	v1 "github.com/lenguti/jppp/app/api/handlers/v1"
+	"github.com/lenguti/jppp/foundation/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlacements(t *testing.T) {
+	router := newTestController(t).Routes()
+
+	do := func(t *testing.T, method, path, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(method, path, strings.NewReader(body))
+		r.Header.Set(api.APIKeyHeader, testAPIKey)
+		router.ServeHTTP(w, r)
+		return w
+	}
+	newCage := func(t *testing.T, typ, status string, capacity int) string {
+		t.Helper()
+		w := do(t, http.MethodPost, "/v1/cages", fmt.Sprintf(`{"type":%q,"capacity":%d,"status":%q}`, typ, capacity, status))
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		var resp v1.CreateCageResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		return resp.Cage.ID
+	}
+	newDino := func(t *testing.T, name, species, diet string) string {
+		t.Helper()
+		w := do(t, http.MethodPost, "/v1/dinosaurs", fmt.Sprintf(`{"name":%q,"species":%q,"diet":%q}`, name, species, diet))
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		var resp v1.CreateDinoResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		return resp.Dinosaur.ID
+	}
+	plan := func(t *testing.T, body string) v1.ClientPlan {
+		t.Helper()
+		w := do(t, http.MethodPost, "/v1/placements/plan", body)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var resp v1.PlanPlacementsResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		return resp.Plan
+	}
+	apply := func(t *testing.T, p v1.ClientPlan) *httptest.ResponseRecorder {
+		t.Helper()
+		b, err := json.Marshal(v1.ApplyPlacementsRequest{Plan: p})
+		require.NoError(t, err)
+		return do(t, http.MethodPost, "/v1/placements/apply", string(b))
+	}
+
+	t.Run("plan and apply", func(t *testing.T) {
+		// Setup.
+		down := newCage(t, "CARNIVORE", "DOWN", 2)
+		blue := newDino(t, "Blue", "Velociraptor", "CARNIVORE")
+		delta := newDino(t, "Delta", "Velociraptor", "CARNIVORE")
+
+		// Execute.
+		p := plan(t, fmt.Sprintf(`{"dino_ids":[%q,%q]}`, blue, delta))
+		w := apply(t, p)
+
+		// Validate.
+		assert.Equal(t, []string{down}, p.PowerUps)
+		assert.ElementsMatch(t, []v1.ClientAssignment{{DinoID: blue, CageID: down}, {DinoID: delta, CageID: down}}, p.Assignments)
+		assert.Empty(t, p.Unplaced)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var resp v1.ApplyPlacementsResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		require.Len(t, resp.Cages, 1)
+		assert.Equal(t, "ACTIVE", resp.Cages[0].Status)
+		assert.Equal(t, 2, resp.Cages[0].CurrentCapacity)
+	})
+
+	t.Run("refused assignment", func(t *testing.T) {
+		// Setup.
+		herbivores := newCage(t, "HERBIVORE", "ACTIVE", 2)
+		rexy := newDino(t, "Rexy", "Tyrannosaurus", "CARNIVORE")
+		p := v1.ClientPlan{Assignments: []v1.ClientAssignment{{DinoID: rexy, CageID: herbivores}}}
+
+		// Execute.
+		w := apply(t, p)
+
+		// Validate.
+		require.Equal(t, http.StatusUnprocessableEntity, w.Code, w.Body.String())
+		var resp api.HTTPError
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.Equal(t, "DIET_MISMATCH", resp.Err.Code)
+		assert.Equal(t, rexy, resp.Err.Details["dino_id"])
+		assert.Equal(t, herbivores, resp.Err.Details["cage_id"])
+		assert.Contains(t, resp.Err.Details, "violations")
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		tests := []struct {
+			name string
+			path string
+			body string
+		}{
+			{"plan without dinos", "/v1/placements/plan", `{}`},
+			{"plan with dinos and all uncaged", "/v1/placements/plan", `{"dino_ids":["6f1f6bde-92c4-4d7a-a3ec-3a1e8a1b4c2d"],"all_uncaged":true}`},
+			{"plan with invalid dino id", "/v1/placements/plan", `{"dino_ids":["nope"]}`},
+			{"apply empty plan", "/v1/placements/apply", `{"plan":{}}`},
+			{"apply invalid cage id", "/v1/placements/apply", `{"plan":{"power_ups":["nope"]}}`},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				// Execute.
+				w := do(t, http.MethodPost, tt.path, tt.body)
+
+				// Validate.
+				assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+			})
+		}
+	})
+}
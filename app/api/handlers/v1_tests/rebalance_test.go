@@ -0,0 +1,134 @@
+package v1_tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	v1 "github.com/lenguti/jppp/app/api/handlers/v1"
+	"github.com/lenguti/jppp/foundation/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRebalance(t *testing.T) {
+	router := newTestController(t).Routes()
+
+	do := func(t *testing.T, method, path, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(method, path, strings.NewReader(body))
+		r.Header.Set(api.APIKeyHeader, testAPIKey)
+		router.ServeHTTP(w, r)
+		return w
+	}
+	newCage := func(t *testing.T, typ string, capacity int) string {
+		t.Helper()
+		w := do(t, http.MethodPost, "/v1/cages", fmt.Sprintf(`{"type":%q,"capacity":%d,"status":"ACTIVE"}`, typ, capacity))
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		var resp v1.CreateCageResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		return resp.Cage.ID
+	}
+	newCagedDino := func(t *testing.T, cageID, name, species, diet string) string {
+		t.Helper()
+		w := do(t, http.MethodPost, "/v1/dinosaurs", fmt.Sprintf(`{"name":%q,"species":%q,"diet":%q}`, name, species, diet))
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		var resp v1.CreateDinoResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		w = do(t, http.MethodPatch, "/v1/cages/"+cageID+"/dinosaurs/"+resp.Dinosaur.ID, "")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		return resp.Dinosaur.ID
+	}
+	apply := func(t *testing.T, rb v1.ClientRebalance) *httptest.ResponseRecorder {
+		t.Helper()
+		b, err := json.Marshal(v1.ApplyRebalanceRequest{Rebalance: rb})
+		require.NoError(t, err)
+		return do(t, http.MethodPost, "/v1/rebalance/apply", string(b))
+	}
+
+	t.Run("consolidate and apply", func(t *testing.T) {
+		// Setup.
+		first := newCage(t, "CARNIVORE", 2)
+		second := newCage(t, "CARNIVORE", 2)
+		newCagedDino(t, first, "Blue", "Velociraptor", "CARNIVORE")
+		newCagedDino(t, second, "Delta", "Velociraptor", "CARNIVORE")
+
+		// Execute.
+		w := do(t, http.MethodPost, "/v1/rebalance/plan", `{"strategy":"consolidate"}`)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var plan v1.PlanRebalanceResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&plan))
+		w = apply(t, plan.Rebalance)
+
+		// Validate.
+		rb := plan.Rebalance
+		assert.Empty(t, rb.PowerUps)
+		require.Len(t, rb.Moves, 1)
+		assert.ElementsMatch(t, []string{first, second}, []string{rb.Moves[0].FromCageID, rb.Moves[0].ToCageID})
+		assert.Equal(t, []string{rb.Moves[0].FromCageID}, rb.PowerDowns)
+		assert.Len(t, rb.Cages, 2)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var resp v1.ApplyRebalanceResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		require.Len(t, resp.Cages, 2)
+		for _, cge := range resp.Cages {
+			if cge.ID == rb.Moves[0].FromCageID {
+				assert.Equal(t, "DOWN", cge.Status)
+				assert.Equal(t, 0, cge.CurrentCapacity)
+			} else {
+				assert.Equal(t, "ACTIVE", cge.Status)
+				assert.Equal(t, 2, cge.CurrentCapacity)
+			}
+		}
+	})
+
+	t.Run("refused move", func(t *testing.T) {
+		// Setup.
+		rexes := newCage(t, "CARNIVORE", 1)
+		herbivores := newCage(t, "HERBIVORE", 2)
+		rexy := newCagedDino(t, rexes, "Rexy", "Tyrannosaurus", "CARNIVORE")
+		rb := v1.ClientRebalance{Moves: []v1.ClientMove{{DinoID: rexy, FromCageID: rexes, ToCageID: herbivores}}}
+
+		// Execute.
+		w := apply(t, rb)
+
+		// Validate.
+		require.Equal(t, http.StatusUnprocessableEntity, w.Code, w.Body.String())
+		var resp api.HTTPError
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.Equal(t, "DIET_MISMATCH", resp.Err.Code)
+		assert.Equal(t, rexy, resp.Err.Details["dino_id"])
+		assert.Equal(t, rexes, resp.Err.Details["from_cage_id"])
+		assert.Equal(t, herbivores, resp.Err.Details["to_cage_id"])
+		assert.Contains(t, resp.Err.Details, "violations")
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		tests := []struct {
+			name string
+			path string
+			body string
+		}{
+			{"plan without strategy", "/v1/rebalance/plan", `{}`},
+			{"plan with unknown strategy", "/v1/rebalance/plan", `{"strategy":"shuffle"}`},
+			{"plan spread without max occupancy", "/v1/rebalance/plan", `{"strategy":"spread"}`},
+			{"plan spread over full occupancy", "/v1/rebalance/plan", `{"strategy":"spread","max_occupancy":1.5}`},
+			{"plan consolidate with max occupancy", "/v1/rebalance/plan", `{"strategy":"consolidate","max_occupancy":0.5}`},
+			{"apply empty rebalance", "/v1/rebalance/apply", `{"rebalance":{}}`},
+			{"apply invalid cage id", "/v1/rebalance/apply", `{"rebalance":{"power_downs":["nope"]}}`},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				// Execute.
+				w := do(t, http.MethodPost, tt.path, tt.body)
+
+				// Validate.
+				assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+			})
+		}
+	})
+}
@@ -0,0 +1,150 @@
+package v1_tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	v1 "github.com/lenguti/jppp/app/api/handlers/v1"
+	"github.com/lenguti/jppp/foundation/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpecies(t *testing.T) {
+	router := newTestController(t).Routes()
+
+	do := func(t *testing.T, method, path, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(method, path, strings.NewReader(body))
+		r.Header.Set(api.APIKeyHeader, testAPIKey)
+		router.ServeHTTP(w, r)
+		return w
+	}
+	errCode := func(t *testing.T, w *httptest.ResponseRecorder) string {
+		t.Helper()
+		var resp api.HTTPError
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		return resp.Err.Code
+	}
+
+	t.Run("invalid species", func(t *testing.T) {
+		// Execute.
+		w := do(t, http.MethodPost, "/v1/species", `{"name":"Dilophosaurus","diet":"FRUITIVORE","size_class":"TINY","temperament":"DOCILE","herd":true,"solitary":true}`)
+
+		// Validate.
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		var resp api.HTTPError
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.Contains(t, resp.Err.Details, "diet")
+		assert.Contains(t, resp.Err.Details, "size_class")
+	})
+
+	t.Run("default species are registered", func(t *testing.T) {
+		// Execute.
+		w := do(t, http.MethodGet, "/v1/species/tyrannosaurus", "")
+
+		// Validate.
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var resp v1.GetSpeciesResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.Equal(t, "Tyrannosaurus", resp.Species.Name)
+		assert.Equal(t, "CARNIVORE", resp.Species.Diet)
+	})
+
+	w := do(t, http.MethodPost, "/v1/species", `{"name":"dilophosaurus","diet":"carnivore","size_class":"medium","temperament":"aggressive","solitary":true}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var cr v1.CreateSpeciesResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&cr))
+	assert.Equal(t, "Dilophosaurus", cr.Species.Name)
+	assert.Equal(t, "MEDIUM", cr.Species.SizeClass)
+	assert.True(t, cr.Species.Solitary)
+
+	t.Run("duplicate species", func(t *testing.T) {
+		// Execute.
+		w := do(t, http.MethodPost, "/v1/species", `{"name":"Dilophosaurus","diet":"CARNIVORE","size_class":"MEDIUM","temperament":"AGGRESSIVE"}`)
+
+		// Validate.
+		require.Equal(t, http.StatusConflict, w.Code)
+		assert.Equal(t, "SPECIES_EXISTS", errCode(t, w))
+	})
+
+	t.Run("new species is listed", func(t *testing.T) {
+		// Execute.
+		w := do(t, http.MethodGet, "/v1/species?diet=CARNIVORE&sort=name", "")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var resp v1.ListSpeciesResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		w = do(t, http.MethodGet, "/v1/dinosaurs/species", "")
+
+		// Validate.
+		require.NotEmpty(t, resp.Species)
+		assert.Equal(t, "Dilophosaurus", resp.Species[0].Name)
+		for _, s := range resp.Species {
+			assert.Equal(t, "CARNIVORE", s.Diet)
+		}
+		require.Equal(t, http.StatusOK, w.Code)
+		var dr v1.ListDinoSpeciesResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&dr))
+		assert.Contains(t, dr.DinoSpecies, v1.ClientDinoSpecies{Species: "Dilophosaurus", Diet: "CARNIVORE"})
+	})
+
+	t.Run("update species", func(t *testing.T) {
+		// Execute.
+		w := do(t, http.MethodPatch, "/v1/species/Dilophosaurus", `{"temperament":"TERRITORIAL"}`)
+
+		// Validate.
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var resp v1.UpdateSpeciesResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.Equal(t, "TERRITORIAL", resp.Species.Temperament)
+		assert.True(t, resp.Species.Solitary)
+	})
+
+	w = do(t, http.MethodPost, "/v1/dinosaurs", `{"name":"Spitter","species":"Dilophosaurus","diet":"CARNIVORE"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var dino v1.CreateDinoResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&dino))
+	assert.Equal(t, "Dilophosaurus", dino.Dinosaur.Species)
+
+	t.Run("species in use", func(t *testing.T) {
+		// Execute.
+		w := do(t, http.MethodDelete, "/v1/species/Dilophosaurus", "")
+
+		// Validate.
+		require.Equal(t, http.StatusConflict, w.Code)
+		assert.Equal(t, "SPECIES_IN_USE", errCode(t, w))
+	})
+
+	t.Run("deleted dinosaurs keep their species in use", func(t *testing.T) {
+		// Setup.
+		w := do(t, http.MethodDelete, "/v1/dinosaurs/"+dino.Dinosaur.ID, "")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		// Execute.
+		w = do(t, http.MethodDelete, "/v1/species/Dilophosaurus", "")
+
+		// Validate.
+		require.Equal(t, http.StatusConflict, w.Code)
+		assert.Equal(t, "SPECIES_IN_USE", errCode(t, w))
+	})
+
+	t.Run("delete species", func(t *testing.T) {
+		// Setup.
+		w := do(t, http.MethodPost, "/v1/species", `{"name":"Compsognathus","diet":"CARNIVORE","size_class":"SMALL","temperament":"SKITTISH","herd":true}`)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+		// Execute.
+		w = do(t, http.MethodDelete, "/v1/species/Compsognathus", "")
+
+		// Validate.
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		w = do(t, http.MethodGet, "/v1/species/Compsognathus", "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		w = do(t, http.MethodPost, "/v1/dinosaurs", `{"name":"Compy","species":"Compsognathus","diet":"CARNIVORE"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
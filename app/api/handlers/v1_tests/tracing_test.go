@@ -0,0 +1,100 @@
+package v1_tests
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/lenguti/jppp/business/core/cage"
+	"github.com/lenguti/jppp/foundation/api"
+	"github.com/lenguti/jppp/foundation/tracing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	spanExporterOnce sync.Once
+	spanExporter     *tracetest.InMemoryExporter
+)
+
+// installSpanExporter - installs a tracer provider recording spans in memory. It is only installed
+// once, as the tracers of the packages keep delegating to the first global provider.
+func installSpanExporter() *tracetest.InMemoryExporter {
+	spanExporterOnce.Do(func() {
+		spanExporter = tracetest.NewInMemoryExporter()
+		tracing.Install("test", sdktrace.WithSyncer(spanExporter))
+	})
+	return spanExporter
+}
+
+func TestTracing(t *testing.T) {
+	exp := installSpanExporter()
+
+	ctrl := newTestController(t)
+	router := ctrl.Routes()
+
+	cge, err := ctrl.Cage.Create(context.Background(), cage.NewCage{Type: cage.CageTypeHerbivore, Capacity: 2, Status: cage.CageStatusActive})
+	require.NoError(t, err)
+
+	t.Run("continues the incoming trace", func(t *testing.T) {
+		// Setup.
+		exp.Reset()
+		const (
+			traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+			spanID  = "00f067aa0ba902b7"
+		)
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/v1/cages/%s", cge.ID), nil)
+		r.Header.Set(api.APIKeyHeader, testAPIKey)
+		r.Header.Set("traceparent", fmt.Sprintf("00-%s-%s-01", traceID, spanID))
+
+		// Execute.
+		router.ServeHTTP(w, r)
+
+		// Validate.
+		require.Equal(t, http.StatusOK, w.Code)
+		spans := exp.GetSpans()
+		server := findSpan(t, spans, "GET /v1/cages/:id")
+		assert.Equal(t, trace.SpanKindServer, server.SpanKind)
+		assert.Equal(t, traceID, server.SpanContext.TraceID().String())
+		assert.Equal(t, spanID, server.Parent.SpanID().String())
+		assert.True(t, server.Parent.IsRemote())
+
+		child := findSpan(t, spans, "cage.Get")
+		assert.Equal(t, server.SpanContext.SpanID(), child.Parent.SpanID())
+	})
+
+	t.Run("starts a new trace without traceparent", func(t *testing.T) {
+		// Setup.
+		exp.Reset()
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/v1/cages/8d0e4bb8-1f0c-4a43-bd8d-67e0c2e1b4b0", nil)
+		r.Header.Set(api.APIKeyHeader, testAPIKey)
+
+		// Execute.
+		router.ServeHTTP(w, r)
+
+		// Validate.
+		require.Equal(t, http.StatusNotFound, w.Code)
+		server := findSpan(t, exp.GetSpans(), "GET /v1/cages/:id")
+		assert.False(t, server.Parent.IsValid())
+		assert.True(t, server.SpanContext.IsValid())
+	})
+}
+
+func findSpan(t *testing.T, spans tracetest.SpanStubs, name string) tracetest.SpanStub {
+	t.Helper()
+	for _, s := range spans {
+		if s.Name == name {
+			return s
+		}
+	}
+	require.Failf(t, "span not found", "no span named %s", name)
+	return tracetest.SpanStub{}
+}
@@ -0,0 +1,173 @@
+package v1_tests
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	v1 "github.com/lenguti/jppp/app/api/handlers/v1"
+	"github.com/lenguti/jppp/business/core/event"
+	"github.com/lenguti/jppp/business/core/webhook"
+	"github.com/lenguti/jppp/foundation/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhooks(t *testing.T) {
+	ctrl := newTestController(t)
+	router := ctrl.Routes()
+
+	do := func(t *testing.T, method, path, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(method, path, strings.NewReader(body))
+		r.Header.Set(api.APIKeyHeader, testAPIKey)
+		router.ServeHTTP(w, r)
+		return w
+	}
+	deliveries := func(t *testing.T, id, query string) []v1.ClientDelivery {
+		t.Helper()
+		w := do(t, http.MethodGet, "/v1/webhooks/"+id+"/deliveries?"+query, "")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var resp v1.ListDeliveriesResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		return resp.Deliveries
+	}
+
+	// The vet scheduler only accepts deliveries once it is up.
+	var (
+		mu       sync.Mutex
+		up       bool
+		received []*http.Request
+		bodies   [][]byte
+	)
+	vet := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if !up {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		received, bodies = append(received, r), append(bodies, body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer vet.Close()
+
+	t.Run("invalid webhook", func(t *testing.T) {
+		// Execute.
+		w := do(t, http.MethodPost, "/v1/webhooks", `{"url":"ftp://vet.jppp.test","types":["cage.exploded"]}`)
+
+		// Validate.
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		var resp api.HTTPError
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.Contains(t, resp.Err.Details, "url")
+		assert.Contains(t, resp.Err.Details, "types")
+	})
+
+	w := do(t, http.MethodPost, "/v1/webhooks", `{"url":"`+vet.URL+`","types":["cage.status_changed"],"secret":"s3cr3t"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var cr v1.CreateWebhookResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&cr))
+	assert.Equal(t, "s3cr3t", cr.Webhook.Secret)
+
+	w = do(t, http.MethodPost, "/v1/cages", `{"type":"CARNIVORE","capacity":2,"status":"ACTIVE"}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var cage v1.CreateCageResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&cage))
+	w = do(t, http.MethodPatch, "/v1/cages/"+cage.Cage.ID, `{"status":"DOWN"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	t.Run("secret is not returned", func(t *testing.T) {
+		// Execute.
+		w := do(t, http.MethodGet, "/v1/webhooks/"+cr.Webhook.ID, "")
+
+		// Validate.
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp v1.GetWebhookResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.Equal(t, vet.URL, resp.Webhook.URL)
+		assert.Equal(t, []string{event.TypeCageStatusChanged}, resp.Webhook.Types)
+		assert.Empty(t, resp.Webhook.Secret)
+	})
+
+	t.Run("failed attempt is logged and retried", func(t *testing.T) {
+		// Execute.
+		_, err := ctrl.Outbox.Relay(context.Background())
+		require.NoError(t, err)
+		n, err := ctrl.Webhook.Deliver(context.Background())
+
+		// Validate.
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+		ds := deliveries(t, cr.Webhook.ID, "status=pending")
+		require.Len(t, ds, 1)
+		assert.Equal(t, event.TypeCageStatusChanged, ds[0].EventType)
+		assert.Equal(t, 1, ds[0].Attempts)
+		assert.Equal(t, http.StatusServiceUnavailable, ds[0].ResponseCode)
+		assert.NotEmpty(t, ds[0].Error)
+		assert.Greater(t, ds[0].NextAttemptAt, time.Now().Unix())
+	})
+
+	t.Run("delivery is signed", func(t *testing.T) {
+		// Setup.
+		mu.Lock()
+		up = true
+		mu.Unlock()
+
+		// Execute.
+		w := do(t, http.MethodPatch, "/v1/cages/"+cage.Cage.ID, `{"status":"ACTIVE"}`)
+		require.Equal(t, http.StatusOK, w.Code)
+		_, err := ctrl.Outbox.Relay(context.Background())
+		require.NoError(t, err)
+		_, err = ctrl.Webhook.Deliver(context.Background())
+
+		// Validate.
+		require.NoError(t, err)
+		mu.Lock()
+		defer mu.Unlock()
+		require.Len(t, received, 1)
+		var p webhook.Payload
+		require.NoError(t, json.Unmarshal(bodies[0], &p))
+		assert.Equal(t, event.TypeCageStatusChanged, p.Type)
+		assert.Equal(t, cage.Cage.ID, p.EntityID)
+		assert.Equal(t, received[0].Header.Get(webhook.DeliveryHeader), p.ID)
+
+		sig := received[0].Header.Get(webhook.SignatureHeader)
+		parts := strings.SplitN(sig, ",", 2)
+		require.Len(t, parts, 2)
+		unix, err := strconv.ParseInt(strings.TrimPrefix(parts[0], "t="), 10, 64)
+		require.NoError(t, err)
+		assert.Equal(t, "v1="+webhook.Sign("s3cr3t", time.Unix(unix, 0), bodies[0]), parts[1])
+
+		ds := deliveries(t, cr.Webhook.ID, "status=succeeded")
+		require.Len(t, ds, 1)
+		assert.Equal(t, http.StatusOK, ds[0].ResponseCode)
+		assert.Zero(t, ds[0].NextAttemptAt)
+	})
+
+	t.Run("delete cancels pending deliveries", func(t *testing.T) {
+		// Execute.
+		w := do(t, http.MethodDelete, "/v1/webhooks/"+cr.Webhook.ID, "")
+
+		// Validate.
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Len(t, deliveries(t, cr.Webhook.ID, "status=canceled"), 1)
+		assert.Equal(t, http.StatusNotFound, do(t, http.MethodGet, "/v1/webhooks/"+cr.Webhook.ID, "").Code)
+
+		w = do(t, http.MethodGet, "/v1/webhooks?include_deleted=true", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp v1.ListWebhooksResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		require.Len(t, resp.Webhooks, 1)
+		assert.NotZero(t, resp.Webhooks[0].DeletedAt)
+	})
+}
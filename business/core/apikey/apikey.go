@@ -0,0 +1,62 @@
+package apikey
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lenguti/jppp/business/core"
+)
+
+// Create - will create a new api key from the hash of the key, granted the viewer role unless set.
+func (c *Core) Create(ctx context.Context, nk NewAPIKey) (APIKey, error) {
+	ctx, span := tracer.Start(ctx, "apikey.Create")
+	defer span.End()
+
+	if nk.Role == "" {
+		nk.Role = core.RoleViewer
+	}
+	if !nk.Role.Valid() {
+		return APIKey{}, fmt.Errorf("create: unknown role %q", nk.Role)
+	}
+
+	k := APIKey{
+		ID:        uuid.New(),
+		Name:      nk.Name,
+		Hash:      nk.Hash,
+		Role:      nk.Role,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := c.store.Create(ctx, k); err != nil {
+		return APIKey{}, fmt.Errorf("create: failed to create api key: %w", err)
+	}
+	return k, nil
+}
+
+// GetByHash - will fetch an api key by its hash. Revoked keys are not found.
+func (c *Core) GetByHash(ctx context.Context, hash string) (APIKey, error) {
+	ctx, span := tracer.Start(ctx, "apikey.GetByHash")
+	defer span.End()
+
+	k, err := c.store.GetByHash(ctx, hash)
+	if err != nil {
+		return APIKey{}, fmt.Errorf("get by hash: failed to fetch api key: %w", err)
+	}
+	if k.Revoked() {
+		return APIKey{}, fmt.Errorf("get by hash: api key revoked: %w", core.ErrNotFound)
+	}
+	return k, nil
+}
+
+// Revoke - will revoke the api key, which can no longer be used to authenticate.
+func (c *Core) Revoke(ctx context.Context, id uuid.UUID) error {
+	ctx, span := tracer.Start(ctx, "apikey.Revoke")
+	defer span.End()
+
+	if err := c.store.Revoke(ctx, id.String(), time.Now().UTC()); err != nil {
+		return fmt.Errorf("revoke: failed to revoke api key: %w", err)
+	}
+	c.logger(ctx).Info().Str("api_key_id", id.String()).Msg("Revoked api key.")
+	return nil
+}
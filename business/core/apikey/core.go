@@ -0,0 +1,40 @@
+package apikey
+
+import (
+	"context"
+	"time"
+
+	"github.com/lenguti/jppp/business/core"
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel"
+)
+
+var tracer = otel.Tracer("github.com/lenguti/jppp/business/core/apikey")
+
+// Storer - represents the data layer behavior for api keys.
+//
+// Keys are only ever stored and looked up by their hash. GetByHash returns revoked keys.
+type Storer interface {
+	Create(ctx context.Context, k APIKey) error
+	GetByHash(ctx context.Context, hash string) (APIKey, error)
+	Revoke(ctx context.Context, id string, ts time.Time) error
+}
+
+// Core - represents the core business logic for api keys.
+type Core struct {
+	store Storer
+	log   zerolog.Logger
+}
+
+// NewCore - returns a new api key core with all its components initialized.
+func NewCore(store Storer, log zerolog.Logger) *Core {
+	return &Core{
+		store: store,
+		log:   log,
+	}
+}
+
+// logger - returns the request scoped logger, falling back to the core logger.
+func (c *Core) logger(ctx context.Context) *zerolog.Logger {
+	return core.Logger(ctx, c.log)
+}
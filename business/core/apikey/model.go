@@ -0,0 +1,30 @@
+package apikey
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lenguti/jppp/business/core"
+)
+
+// APIKey - represents a business domain api key. Only the hash of the key is known.
+type APIKey struct {
+	ID        uuid.UUID
+	Name      string
+	Hash      string
+	Role      core.Role
+	CreatedAt time.Time
+	RevokedAt time.Time
+}
+
+// NewAPIKey - represents the input for creating an api key.
+type NewAPIKey struct {
+	Name string
+	Hash string
+	Role core.Role
+}
+
+// Revoked - reports whether the key has been revoked.
+func (k APIKey) Revoked() bool {
+	return !k.RevokedAt.IsZero()
+}
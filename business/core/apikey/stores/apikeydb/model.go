@@ -0,0 +1,47 @@
+package apikeydb
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lenguti/jppp/business/core"
+	"github.com/lenguti/jppp/business/core/apikey"
+)
+
+type dbAPIKey struct {
+	ID        string `db:"id"`
+	Name      string `db:"name"`
+	KeyHash   string `db:"key_hash"`
+	Role      string `db:"role"`
+	CreatedAt int64  `db:"created_at"`
+	RevokedAt *int64 `db:"revoked_at"`
+}
+
+func toDBAPIKey(k apikey.APIKey) dbAPIKey {
+	dbk := dbAPIKey{
+		ID:        k.ID.String(),
+		Name:      k.Name,
+		KeyHash:   k.Hash,
+		Role:      string(k.Role),
+		CreatedAt: k.CreatedAt.Unix(),
+	}
+	if !k.RevokedAt.IsZero() {
+		ts := k.RevokedAt.Unix()
+		dbk.RevokedAt = &ts
+	}
+	return dbk
+}
+
+func toCoreAPIKey(dbk dbAPIKey) apikey.APIKey {
+	k := apikey.APIKey{
+		ID:        uuid.MustParse(dbk.ID),
+		Name:      dbk.Name,
+		Hash:      dbk.KeyHash,
+		Role:      core.Role(dbk.Role),
+		CreatedAt: time.Unix(dbk.CreatedAt, 0),
+	}
+	if dbk.RevokedAt != nil {
+		k.RevokedAt = time.Unix(*dbk.RevokedAt, 0)
+	}
+	return k
+}
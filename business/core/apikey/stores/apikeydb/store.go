@@ -0,0 +1,83 @@
+package apikeydb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lenguti/jppp/business/core"
+	"github.com/lenguti/jppp/business/core/apikey"
+	"github.com/lenguti/jppp/business/data/db"
+)
+
+// Store - manages the set of apis for api key database access.
+type Store struct {
+	db *db.DB
+}
+
+// NewStore - constructs the api for data access.
+func NewStore(db *db.DB) *Store {
+	return &Store{
+		db: db,
+	}
+}
+
+// Create - will insert a new api key record.
+func (s *Store) Create(ctx context.Context, k apikey.APIKey) error {
+	const q = `
+	INSERT INTO api_key (
+		id,
+		name,
+		key_hash,
+		role,
+		created_at
+	) VALUES (
+		:id,
+		:name,
+		:key_hash,
+		:role,
+		:created_at
+	)
+	`
+	if err := s.db.Exec(ctx, q, toDBAPIKey(k)); err != nil {
+		return fmt.Errorf("create: failed to create api key: %w", err)
+	}
+	return nil
+}
+
+// GetByHash - will fetch an api key by the hash of the key.
+func (s *Store) GetByHash(ctx context.Context, hash string) (apikey.APIKey, error) {
+	const q = `
+	SELECT *
+	FROM api_key
+	WHERE key_hash = $1
+	`
+	var out dbAPIKey
+	if err := s.db.Get(ctx, &out, q, hash); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return apikey.APIKey{}, core.ErrNotFound
+		}
+		return apikey.APIKey{}, fmt.Errorf("get by hash: failed to fetch api key: %w", err)
+	}
+	return toCoreAPIKey(out), nil
+}
+
+// Revoke - will revoke the api key, unless it already is.
+func (s *Store) Revoke(ctx context.Context, id string, ts time.Time) error {
+	const q = `
+	UPDATE api_key
+	SET revoked_at = :revoked_at
+	WHERE id = :id
+	AND revoked_at IS NULL
+	`
+	n, err := s.db.ExecAffected(ctx, q, map[string]any{"revoked_at": ts.Unix(), "id": id})
+	if err != nil {
+		return fmt.Errorf("revoke: failed to revoke api key: %w", err)
+	}
+	if n != 1 {
+		return core.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lenguti/jppp/business/core"
+)
+
+// List - will list a page of audit records along with the cursor of the next page, if any.
+func (c *Core) List(ctx context.Context, page core.Page, filters ...core.Filter) ([]Record, string, error) {
+	ctx, span := tracer.Start(ctx, "audit.List")
+	defer span.End()
+
+	if err := core.Authorize(ctx, core.PermAuditRead); err != nil {
+		return nil, "", fmt.Errorf("list: %w", err)
+	}
+
+	c.logger(ctx).Info().Fields(map[string]any{"filters": filters, "sort": page.Sort.String(), "limit": page.Limit}).Msg("Listing audit records.")
+	recs, err := c.store.List(ctx, page.Peek(), filters...)
+	if err != nil {
+		return nil, "", fmt.Errorf("list: failed to list audit records: %w", err)
+	}
+
+	recs, next := core.NextPage(page, recs, func(r Record) (string, string) {
+		return r.sortValue(), r.ID.String()
+	})
+	return recs, next, nil
+}
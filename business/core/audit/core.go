@@ -0,0 +1,40 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/lenguti/jppp/business/core"
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel"
+)
+
+var tracer = otel.Tracer("github.com/lenguti/jppp/business/core/audit")
+
+// Storer - represents the data layer behavior for audit records.
+//
+// Records are written by the cage and dino stores along with the change they describe and are
+// never updated nor deleted.
+//
+// List returns at most page.Limit records ordered by page.Sort and then id, starting after page.Cursor.
+type Storer interface {
+	List(ctx context.Context, page core.Page, filters ...core.Filter) ([]Record, error)
+}
+
+// Core - represents the core business logic for audit records.
+type Core struct {
+	store Storer
+	log   zerolog.Logger
+}
+
+// NewCore - returns a new audit core with all its components initialized.
+func NewCore(store Storer, log zerolog.Logger) *Core {
+	return &Core{
+		store: store,
+		log:   log,
+	}
+}
+
+// logger - returns the request scoped logger, falling back to the core logger.
+func (c *Core) logger(ctx context.Context) *zerolog.Logger {
+	return core.Logger(ctx, c.log)
+}
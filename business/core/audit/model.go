@@ -0,0 +1,109 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lenguti/jppp/business/core"
+)
+
+// Audited entity types.
+const (
+	EntityCage = "cage"
+	EntityDino = "dinosaur"
+)
+
+// Audited actions. Actions touching several entities, such as adding a dino to a cage, are
+// recorded once per entity.
+const (
+	ActionCageCreate       = "cage.create"
+	ActionCageUpdateStatus = "cage.update_status"
+	ActionCageAddDino      = "cage.add_dino"
+	ActionCageRemoveDino   = "cage.remove_dino"
+	ActionCageDelete       = "cage.delete"
+	ActionCageRestore      = "cage.restore"
+	ActionDinoCreate       = "dino.create"
+	ActionDinoUpdateName   = "dino.update_name"
+	ActionDinoTransfer     = "dino.transfer"
+	ActionDinoDelete       = "dino.delete"
+	ActionDinoRestore      = "dino.restore"
+)
+
+// SystemActor - the actor of changes made outside of a request, such as through the cli.
+const SystemActor = "system"
+
+// Record - represents a business domain audit record of a change to an entity.
+type Record struct {
+	ID         uuid.UUID
+	Actor      string
+	Action     string
+	EntityType string
+	EntityID   uuid.UUID
+	Before     json.RawMessage
+	After      json.RawMessage
+	RequestID  string
+	CreatedAt  time.Time
+}
+
+// SortFields - the fields audit records can be sorted by.
+var SortFields = []string{core.SortCreatedAt}
+
+// FilterFields - the fields audit records can be filtered by.
+var FilterFields = core.Fields{
+	"entity_id":   {Kind: core.KindString, Normalize: normalizeID},
+	"entity_type": {Kind: core.KindString},
+	"actor":       {Kind: core.KindString},
+	"action":      {Kind: core.KindString},
+	"request_id":  {Kind: core.KindString},
+	"createdAt":   {Kind: core.KindInt},
+}
+
+// NewRecord - returns the record of the action on the entity by the actor and request carried by the
+// context. A nil before or after is recorded as null, such as before a creation.
+func NewRecord(ctx context.Context, action, entityType string, entityID uuid.UUID, before, after any, ts time.Time) (Record, error) {
+	actor := SystemActor
+	if a, ok := core.ActorFrom(ctx); ok {
+		actor = a.Subject
+	}
+
+	r := Record{
+		ID:         uuid.New(),
+		Actor:      actor,
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		RequestID:  core.RequestID(ctx),
+		CreatedAt:  ts,
+	}
+	var err error
+	if r.Before, err = marshal(before); err != nil {
+		return Record{}, fmt.Errorf("new record: unable to marshal before: %w", err)
+	}
+	if r.After, err = marshal(after); err != nil {
+		return Record{}, fmt.Errorf("new record: unable to marshal after: %w", err)
+	}
+	return r, nil
+}
+
+func marshal(v any) (json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
+
+func (r Record) sortValue() string {
+	return strconv.FormatInt(r.CreatedAt.Unix(), 10)
+}
+
+func normalizeID(v string) (string, error) {
+	id, err := uuid.Parse(v)
+	if err != nil {
+		return "", fmt.Errorf("normalize id: %w", err)
+	}
+	return id.String(), nil
+}
@@ -0,0 +1,57 @@
+package auditdb
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lenguti/jppp/business/core/audit"
+)
+
+type dbRecord struct {
+	ID         string `db:"id"`
+	Actor      string `db:"actor"`
+	Action     string `db:"action"`
+	EntityType string `db:"entity_type"`
+	EntityID   string `db:"entity_id"`
+	Before     []byte `db:"before"`
+	After      []byte `db:"after"`
+	RequestID  string `db:"request_id"`
+	CreatedAt  int64  `db:"created_at"`
+}
+
+func toDBRecord(r audit.Record) dbRecord {
+	return dbRecord{
+		ID:         r.ID.String(),
+		Actor:      r.Actor,
+		Action:     r.Action,
+		EntityType: r.EntityType,
+		EntityID:   r.EntityID.String(),
+		Before:     r.Before,
+		After:      r.After,
+		RequestID:  r.RequestID,
+		CreatedAt:  r.CreatedAt.Unix(),
+	}
+}
+
+func toCoreRecords(dbRecords []dbRecord) []audit.Record {
+	recs := make([]audit.Record, 0, len(dbRecords))
+	for _, v := range dbRecords {
+		recs = append(recs, toCoreRecord(v))
+	}
+	return recs
+}
+
+func toCoreRecord(dbr dbRecord) audit.Record {
+	return audit.Record{
+		ID:         uuid.MustParse(dbr.ID),
+		Actor:      dbr.Actor,
+		Action:     dbr.Action,
+		EntityType: dbr.EntityType,
+		EntityID:   uuid.MustParse(dbr.EntityID),
+		Before:     json.RawMessage(dbr.Before),
+		After:      json.RawMessage(dbr.After),
+		RequestID:  dbr.RequestID,
+		CreatedAt:  time.Unix(dbr.CreatedAt, 0),
+	}
+}
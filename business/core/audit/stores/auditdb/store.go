@@ -0,0 +1,154 @@
+package auditdb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lenguti/jppp/business/core"
+	"github.com/lenguti/jppp/business/core/audit"
+	"github.com/lenguti/jppp/business/core/event"
+	"github.com/lenguti/jppp/business/core/outbox/stores/outboxdb"
+	"github.com/lenguti/jppp/business/data/db"
+)
+
+// Store - manages the set of apis for audit record database access.
+type Store struct {
+	db     *db.DB
+	outbox *outboxdb.Store
+}
+
+// NewStore - constructs the api for data access.
+func NewStore(db *db.DB) *Store {
+	return &Store{
+		db:     db,
+		outbox: outboxdb.NewStore(db),
+	}
+}
+
+// Insert - will insert the audit records within the tx of the change they describe and append the
+// events describing it to the outbox, so they are relayed if and only if the change is committed.
+func (s *Store) Insert(ctx context.Context, tx *sqlx.Tx, recs ...audit.Record) error {
+	const q = `
+	INSERT INTO audit_log (
+		id,
+		actor,
+		action,
+		entity_type,
+		entity_id,
+		before,
+		after,
+		request_id,
+		created_at
+	) VALUES (
+		:id,
+		:actor,
+		:action,
+		:entity_type,
+		:entity_id,
+		:before,
+		:after,
+		:request_id,
+		:created_at
+	)
+	`
+	for _, r := range recs {
+		if _, err := s.db.NamedExecTx(ctx, tx, q, toDBRecord(r)); err != nil {
+			return fmt.Errorf("insert: failed to insert audit record: %w", err)
+		}
+	}
+
+	evs, err := event.FromRecords(recs...)
+	if err != nil {
+		return fmt.Errorf("insert: %w", err)
+	}
+	if err := s.outbox.Append(ctx, tx, evs...); err != nil {
+		return fmt.Errorf("insert: %w", err)
+	}
+	return nil
+}
+
+// ExecOne - executes the named statement and inserts the audit records of the change in a single tx,
+// reporting a conflict when the statement did not affect exactly one row.
+func (s *Store) ExecOne(ctx context.Context, q string, data any, recs ...audit.Record) error {
+	return s.db.WithTx(ctx, func(tx *sqlx.Tx) error {
+		n, err := s.db.NamedExecTx(ctx, tx, q, data)
+		if err != nil {
+			return fmt.Errorf("exec one: %w", err)
+		}
+		if n != 1 {
+			return core.ErrConflict
+		}
+		if err := s.Insert(ctx, tx, recs...); err != nil {
+			return fmt.Errorf("exec one: %w", err)
+		}
+		return nil
+	})
+}
+
+// List - will list a page of audit records.
+func (s *Store) List(ctx context.Context, page core.Page, filters ...core.Filter) ([]audit.Record, error) {
+	q, vals, err := listClauseBuilder(page, filters...)
+	if err != nil {
+		return nil, fmt.Errorf("list: failed to build query: %w", err)
+	}
+	var out []dbRecord
+	if err := s.db.List(ctx, &out, q, vals...); err != nil {
+		return nil, fmt.Errorf("list: failed to list audit records: %w", err)
+	}
+	return toCoreRecords(out), nil
+}
+
+func listClauseBuilder(page core.Page, filters ...core.Filter) (string, []string, error) {
+	const q = `
+	SELECT *
+	FROM audit_log
+	`
+
+	filterMap := map[string]string{
+		"entity_id":   "entity_id",
+		"entity_type": "entity_type",
+		"actor":       "actor",
+		"action":      "action",
+		"request_id":  "request_id",
+		"createdAt":   "created_at",
+	}
+
+	sortMap := map[string]string{
+		core.SortCreatedAt: "created_at",
+	}
+
+	conds, vals, err := db.FilterClause(filters, filterMap, 1)
+	if err != nil {
+		return "", nil, fmt.Errorf("list clause builder: %w", err)
+	}
+
+	field := page.Sort.Field
+	if field == "" {
+		field = core.SortCreatedAt
+	}
+	column, ok := sortMap[field]
+	if !ok {
+		return "", nil, fmt.Errorf("list clause builder: invalid sort field %s", field)
+	}
+
+	cond, tail, pageVals, err := db.PageClause(page, column, len(vals)+1)
+	if err != nil {
+		return "", nil, fmt.Errorf("list clause builder: %w", err)
+	}
+	if cond != "" {
+		conds = append(conds, cond)
+		vals = append(vals, pageVals...)
+	}
+
+	var b strings.Builder
+	b.WriteString(q)
+	if len(conds) > 0 {
+		b.WriteString("WHERE ")
+		b.WriteString(strings.Join(conds, "\n\tAND "))
+		b.WriteString("\n\t")
+	}
+	b.WriteString(tail)
+	return b.String(), vals, nil
+}
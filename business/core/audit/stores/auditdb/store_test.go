@@ -0,0 +1,63 @@
+package auditdb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lenguti/jppp/business/core"
+	"github.com/lenguti/jppp/business/data/dbtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListClauseBuilder(t *testing.T) {
+	t.Run("no filters", func(t *testing.T) {
+		want := `
+	SELECT *
+	FROM audit_log
+	ORDER BY created_at ASC, id ASC
+	`
+		var wantVals []string
+		got, gotVals, err := listClauseBuilder(core.Page{})
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+		assert.Equal(t, wantVals, gotVals)
+	})
+
+	t.Run("entity and time range filters", func(t *testing.T) {
+		want := `
+	SELECT *
+	FROM audit_log
+	WHERE entity_id = $1
+	AND created_at >= $2
+	AND created_at < $3
+	ORDER BY created_at ASC, id ASC
+	`
+
+		wantVals := []string{"9c0b5d2e-8a47-4b1c-9f0e-3d4c5b6a7e8f", "100", "200"}
+		got, gotVals, err := listClauseBuilder(core.Page{},
+			core.Filter{Key: "entity_id", Value: "9c0b5d2e-8a47-4b1c-9f0e-3d4c5b6a7e8f"},
+			core.Filter{Key: "createdAt", Op: core.OpGte, Value: "100"},
+			core.Filter{Key: "createdAt", Op: core.OpLt, Value: "200"},
+		)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+		assert.Equal(t, wantVals, gotVals)
+	})
+
+	t.Run("unknown filter", func(t *testing.T) {
+		_, _, err := listClauseBuilder(core.Page{}, core.Filter{Key: "deletedAt", Op: core.OpNull, Value: "true"})
+		assert.ErrorIs(t, err, core.ErrInvalidFilter)
+	})
+}
+
+func TestClosedDB(t *testing.T) {
+	// Setup.
+	s := NewStore(dbtest.Closed(t))
+
+	// Execute.
+	err := s.ExecOne(context.Background(), `UPDATE cage SET status = :status`, map[string]any{"status": "DOWN"})
+
+	// Validate.
+	assert.ErrorContains(t, err, "begin tx")
+}
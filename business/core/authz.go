@@ -0,0 +1,138 @@
+package core
+
+import (
+	"context"
+	"fmt"
+)
+
+// Role - represents a staff role, each role holding the permissions of the ones below it.
+type Role string
+
+// Roles, from the least to the most privileged.
+const (
+	RoleViewer     Role = "viewer"
+	RoleKeeper     Role = "keeper"
+	RoleSupervisor Role = "supervisor"
+	RoleAdmin      Role = "admin"
+)
+
+// roleRanks - the privilege level of each role, unknown roles have none.
+var roleRanks = map[Role]int{
+	RoleViewer:     1,
+	RoleKeeper:     2,
+	RoleSupervisor: 3,
+	RoleAdmin:      4,
+}
+
+// Valid - reports whether the role is a known one.
+func (r Role) Valid() bool {
+	_, ok := roleRanks[r]
+	return ok
+}
+
+// Can - reports whether the role holds the permission.
+func (r Role) Can(p Permission) bool {
+	min, ok := permissionRoles[p]
+	return ok && roleRanks[r] >= roleRanks[min]
+}
+
+// Permission - represents an operation a role may be allowed to perform.
+type Permission string
+
+// Permissions covering every route and core mutation.
+const (
+	PermCageRead       Permission = "cage:read"
+	PermCageCreate     Permission = "cage:create"
+	PermCageUpdate     Permission = "cage:update"
+	PermCagePowerDown  Permission = "cage:power_down"
+	PermCageAddDino    Permission = "cage:add_dino"
+	PermCageRemoveDino Permission = "cage:remove_dino"
+	PermCageDelete     Permission = "cage:delete"
+	PermCageRestore    Permission = "cage:restore"
+	PermDinoRead       Permission = "dino:read"
+	PermDinoCreate     Permission = "dino:create"
+	PermDinoUpdate     Permission = "dino:update"
+	PermDinoTransfer   Permission = "dino:transfer"
+	PermDinoDelete     Permission = "dino:delete"
+	PermDinoRestore    Permission = "dino:restore"
+	PermMetricsRead    Permission = "metrics:read"
+	PermAuditRead      Permission = "audit:read"
+	PermEventRead      Permission = "event:read"
+	PermWebhookRead    Permission = "webhook:read"
+	PermWebhookManage  Permission = "webhook:manage"
+	PermSpeciesRead    Permission = "species:read"
+	PermSpeciesManage  Permission = "species:manage"
+)
+
+// permissionRoles - the permission table, holding the least privileged role granted each permission.
+var permissionRoles = map[Permission]Role{
+	PermCageRead:       RoleViewer,
+	PermDinoRead:       RoleViewer,
+	PermMetricsRead:    RoleViewer,
+	PermEventRead:      RoleViewer,
+	PermSpeciesRead:    RoleViewer,
+	PermCageUpdate:     RoleKeeper,
+	PermCageAddDino:    RoleKeeper,
+	PermCageRemoveDino: RoleKeeper,
+	PermDinoCreate:     RoleKeeper,
+	PermDinoUpdate:     RoleKeeper,
+	PermDinoTransfer:   RoleKeeper,
+	PermCageCreate:     RoleSupervisor,
+	PermCagePowerDown:  RoleSupervisor,
+	PermCageDelete:     RoleSupervisor,
+	PermCageRestore:    RoleSupervisor,
+	PermDinoDelete:     RoleSupervisor,
+	PermDinoRestore:    RoleSupervisor,
+	PermSpeciesManage:  RoleSupervisor,
+	PermAuditRead:      RoleSupervisor,
+	PermWebhookRead:    RoleSupervisor,
+	PermWebhookManage:  RoleAdmin,
+}
+
+// ErrForbidden represents an actor lacking the permission for an operation.
+const ErrForbidden = Error("permission denied")
+
+// PermissionError - represents a denied operation along with the permission it requires.
+type PermissionError struct {
+	Permission Permission
+	Role       Role
+}
+
+// Error - satisfies the error interface.
+func (e *PermissionError) Error() string {
+	return fmt.Sprintf("%s: role %q lacks %s", ErrForbidden, e.Role, e.Permission)
+}
+
+// Unwrap - exposes ErrForbidden to errors.Is and errors.As.
+func (e *PermissionError) Unwrap() error {
+	return ErrForbidden
+}
+
+// Actor - represents the staff member on whose behalf an operation runs.
+type Actor struct {
+	Subject string
+	Role    Role
+}
+
+type actorKey struct{}
+
+// WithActor - returns a copy of the context carrying the actor.
+func WithActor(ctx context.Context, a Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, a)
+}
+
+// ActorFrom - returns the actor carried by the context, if any.
+func ActorFrom(ctx context.Context) (Actor, bool) {
+	a, ok := ctx.Value(actorKey{}).(Actor)
+	return a, ok
+}
+
+// Authorize - returns a PermissionError when the actor carried by the context lacks the permission.
+// Contexts carrying no actor belong to trusted internal callers, such as the cli, and are allowed.
+func Authorize(ctx context.Context, p Permission) error {
+	a, ok := ActorFrom(ctx)
+	if !ok || a.Role.Can(p) {
+		return nil
+	}
+	return &PermissionError{Permission: p, Role: a.Role}
+}
@@ -0,0 +1,63 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthorize(t *testing.T) {
+	t.Run("every permission is granted to admins", func(t *testing.T) {
+		for p := range permissionRoles {
+			assert.True(t, RoleAdmin.Can(p), p)
+		}
+	})
+
+	t.Run("unknown roles hold no permission", func(t *testing.T) {
+		assert.False(t, Role("janitor").Valid())
+		assert.False(t, Role("janitor").Can(PermCageRead))
+	})
+
+	t.Run("trusted callers without an actor", func(t *testing.T) {
+		// Execute.
+		err := Authorize(context.Background(), PermCagePowerDown)
+
+		// Validate.
+		assert.NoError(t, err)
+	})
+
+	for _, tc := range []struct {
+		role    Role
+		perm    Permission
+		allowed bool
+	}{
+		{RoleViewer, PermCageRead, true},
+		{RoleViewer, PermCageAddDino, false},
+		{RoleKeeper, PermCageAddDino, true},
+		{RoleKeeper, PermCageRemoveDino, true},
+		{RoleKeeper, PermCagePowerDown, false},
+		{RoleSupervisor, PermCagePowerDown, true},
+		{RoleAdmin, PermCagePowerDown, true},
+	} {
+		t.Run(string(tc.role)+" "+string(tc.perm), func(t *testing.T) {
+			// Setup.
+			ctx := WithActor(context.Background(), Actor{Subject: "alan", Role: tc.role})
+
+			// Execute.
+			err := Authorize(ctx, tc.perm)
+
+			// Validate.
+			if tc.allowed {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, ErrForbidden)
+			var pe *PermissionError
+			if assert.True(t, errors.As(err, &pe)) {
+				assert.Equal(t, tc.perm, pe.Permission)
+			}
+		})
+	}
+}
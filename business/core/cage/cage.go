@@ -7,10 +7,20 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/lenguti/jppp/business/core"
+	"github.com/lenguti/jppp/business/core/audit"
+	"github.com/lenguti/jppp/business/core/cohabitation"
+	"github.com/lenguti/jppp/business/core/dino"
 )
 
 // Create - will create a new cage.
 func (c *Core) Create(ctx context.Context, nc NewCage) (Cage, error) {
+	ctx, span := tracer.Start(ctx, "cage.Create")
+	defer span.End()
+
+	if err := core.Authorize(ctx, core.PermCageCreate); err != nil {
+		return Cage{}, fmt.Errorf("create: %w", err)
+	}
+
 	now := time.Now().UTC()
 	cg := Cage{
 		ID:              uuid.New(),
@@ -18,41 +28,86 @@ func (c *Core) Create(ctx context.Context, nc NewCage) (Cage, error) {
 		Capacity:        nc.Capacity,
 		CurrentCapacity: 0,
 		Status:          nc.Status,
+		Version:         1,
 		CreatedAt:       now,
 		UpdatedAt:       now,
 	}
-	if err := c.store.Create(ctx, cg); err != nil {
+	rec, err := audit.NewRecord(ctx, audit.ActionCageCreate, audit.EntityCage, cg.ID, nil, cg, now)
+	if err != nil {
+		return Cage{}, fmt.Errorf("create: %w", err)
+	}
+	if err := c.store.Create(ctx, cg, rec); err != nil {
 		return Cage{}, fmt.Errorf("create: failed to create cage: %w", err)
 	}
 	return cg, nil
 }
 
-// Get - will featch a cage by its id.
+// Get - will featch a cage by its id. Soft deleted cages are not found.
 func (c *Core) Get(ctx context.Context, id uuid.UUID) (Cage, error) {
+	ctx, span := tracer.Start(ctx, "cage.Get")
+	defer span.End()
+
 	cg, err := c.store.Get(ctx, id.String())
 	if err != nil {
 		return Cage{}, fmt.Errorf("get: failed to fetch cage: %w", err)
 	}
+	if cg.Deleted() {
+		return Cage{}, fmt.Errorf("get: cage deleted: %w", core.ErrNotFound)
+	}
 	return cg, nil
 }
 
-// List - will list all cages.
-func (c *Core) List(ctx context.Context, filters ...core.Filter) ([]Cage, error) {
-	c.log.Info().Fields(map[string]any{"filters": filters}).Msg("Listing cages.")
-	cgs, err := c.store.List(ctx, filters...)
+// List - will list a page of cages along with the cursor of the next page, if any.
+func (c *Core) List(ctx context.Context, page core.Page, filters ...core.Filter) ([]Cage, string, error) {
+	ctx, span := tracer.Start(ctx, "cage.List")
+	defer span.End()
+
+	c.logger(ctx).Info().Fields(map[string]any{"filters": filters, "sort": page.Sort.String(), "limit": page.Limit}).Msg("Listing cages.")
+	cgs, err := c.store.List(ctx, page.Peek(), filters...)
+	if err != nil {
+		return nil, "", fmt.Errorf("list: failed to list cages: %w", err)
+	}
+	cgs, next := core.NextPage(page, cgs, func(cg Cage) (string, string) {
+		return cg.sortValue(page.Sort.Field), cg.ID.String()
+	})
+	return cgs, next, nil
+}
+
+// Summarize - will count the cages, their capacity and occupancy by type and status.
+func (c *Core) Summarize(ctx context.Context) ([]Summary, error) {
+	ctx, span := tracer.Start(ctx, "cage.Summarize")
+	defer span.End()
+
+	sums, err := c.store.Summarize(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("list: failed to list cages: %w", err)
+		return nil, fmt.Errorf("summarize: failed to summarize cages: %w", err)
 	}
-	return cgs, nil
+	return sums, nil
 }
 
-// UpdateStatus - will update the status of the provided cage.
-func (c *Core) UpdateStatus(ctx context.Context, id uuid.UUID, status Status) (Cage, error) {
+// UpdateStatus - will update the status of the provided cage, powering it down requires cage:power_down.
+// A non zero version must match the current cage version.
+func (c *Core) UpdateStatus(ctx context.Context, id uuid.UUID, status Status, version int) (Cage, error) {
+	ctx, span := tracer.Start(ctx, "cage.UpdateStatus")
+	defer span.End()
+
+	perm := core.PermCageUpdate
+	if status == CageStatusDown {
+		perm = core.PermCagePowerDown
+	}
+	if err := core.Authorize(ctx, perm); err != nil {
+		return Cage{}, fmt.Errorf("update status: %w", err)
+	}
+
 	cge, err := c.Get(ctx, id)
 	if err != nil {
 		return Cage{}, fmt.Errorf("update status: unable to fetch cage: %w", err)
 	}
 
+	if version != 0 && cge.Version != version {
+		return Cage{}, core.ErrPreconditionFailed
+	}
+
 	if cge.Status == status {
 		return cge, nil
 	}
@@ -61,10 +116,16 @@ func (c *Core) UpdateStatus(ctx context.Context, id uuid.UUID, status Status) (C
 		return Cage{}, core.ErrPowerDownCage
 	}
 
+	before := cge
 	now := time.Now().UTC()
 	cge.Status = status
+	cge.Version++
 	cge.UpdatedAt = now
-	if err := c.store.UpdateStatus(ctx, cge.ID.String(), cge.Status.String(), cge.UpdatedAt); err != nil {
+	rec, err := audit.NewRecord(ctx, audit.ActionCageUpdateStatus, audit.EntityCage, cge.ID, before, cge, now)
+	if err != nil {
+		return Cage{}, fmt.Errorf("update status: %w", err)
+	}
+	if err := c.store.UpdateStatus(ctx, cge.ID.String(), cge.Status.String(), cge.Version, cge.UpdatedAt, rec); err != nil {
 		return Cage{}, fmt.Errorf("update status: failed to update cage: %w", err)
 	}
 
@@ -72,18 +133,26 @@ func (c *Core) UpdateStatus(ctx context.Context, id uuid.UUID, status Status) (C
 }
 
 // AddDino - will add the provided dino to the provided cage and upate the current capacity.
-func (c *Core) AddDino(ctx context.Context, id uuid.UUID, dinoID uuid.UUID) (Cage, error) {
+// A non zero version must match the current cage version.
+func (c *Core) AddDino(ctx context.Context, id uuid.UUID, dinoID uuid.UUID, version int) (Cage, error) {
+	ctx, span := tracer.Start(ctx, "cage.AddDino")
+	defer span.End()
+
+	if err := core.Authorize(ctx, core.PermCageAddDino); err != nil {
+		return Cage{}, fmt.Errorf("add dino: %w", err)
+	}
+
 	cge, err := c.Get(ctx, id)
 	if err != nil {
 		return Cage{}, fmt.Errorf("add dino: unable to fetch cage: %w", err)
 	}
 
-	if cge.Status == CageStatusDown {
-		return Cage{}, core.ErrInvalidCagePowerDown
+	if version != 0 && cge.Version != version {
+		return Cage{}, core.ErrPreconditionFailed
 	}
 
-	if cge.CurrentCapacity >= cge.Capacity {
-		return Cage{}, core.ErrInvalidCageAtCapacity
+	if err := checkCapacity(cge); err != nil {
+		return Cage{}, err
 	}
 
 	d, err := c.dino.Get(ctx, dinoID)
@@ -91,27 +160,24 @@ func (c *Core) AddDino(ctx context.Context, id uuid.UUID, dinoID uuid.UUID) (Cag
 		return Cage{}, fmt.Errorf("add dino: unable to fetch dino: %w", err)
 	}
 
-	if cge.Type != Type(d.Diet) {
-		return Cage{}, core.ErrInvalidCageInvalidType
+	if d.CageID != uuid.Nil {
+		return Cage{}, core.ErrInvalidCageDinoCaged
 	}
 
-	if cge.Type == CageTypeCarnivore && cge.CurrentCapacity > 0 {
-		cagedDinos, err := c.dino.ListByCageID(ctx, id)
-		if err != nil {
-			return Cage{}, fmt.Errorf("add dino: unable to list dinos for cage: %w", err)
-		}
-
-		for _, dno := range cagedDinos {
-			if dno.Species != d.Species {
-				return Cage{}, core.ErrInvalidCageInvalidSpecies
-			}
-		}
+	if err := c.checkCohabitation(ctx, cge, d); err != nil {
+		return Cage{}, err
 	}
 
+	before := cge
 	now := time.Now().UTC()
 	cge.CurrentCapacity++
+	cge.Version++
 	cge.UpdatedAt = now
-	if err := c.store.AddDino(ctx, cge, d.ID.String()); err != nil {
+	recs, err := placementRecords(ctx, audit.ActionCageAddDino, now, []Cage{before}, []Cage{cge}, d, cge.ID)
+	if err != nil {
+		return Cage{}, fmt.Errorf("add dino: %w", err)
+	}
+	if err := c.store.AddDino(ctx, cge, d.ID.String(), recs...); err != nil {
 		return Cage{}, fmt.Errorf("add dino: failed to add dino to cage: %w", err)
 	}
 
@@ -119,12 +185,24 @@ func (c *Core) AddDino(ctx context.Context, id uuid.UUID, dinoID uuid.UUID) (Cag
 }
 
 // RemoveDino - will remove the provided dino from the provided cage and upate the current capacity.
-func (c *Core) RemoveDino(ctx context.Context, id uuid.UUID, dinoID uuid.UUID) (Cage, error) {
+// A non zero version must match the current cage version.
+func (c *Core) RemoveDino(ctx context.Context, id uuid.UUID, dinoID uuid.UUID, version int) (Cage, error) {
+	ctx, span := tracer.Start(ctx, "cage.RemoveDino")
+	defer span.End()
+
+	if err := core.Authorize(ctx, core.PermCageRemoveDino); err != nil {
+		return Cage{}, fmt.Errorf("remove dino: %w", err)
+	}
+
 	cge, err := c.Get(ctx, id)
 	if err != nil {
 		return Cage{}, fmt.Errorf("remove dino: unable to fetch cage: %w", err)
 	}
 
+	if version != 0 && cge.Version != version {
+		return Cage{}, core.ErrPreconditionFailed
+	}
+
 	if cge.CurrentCapacity == 0 {
 		return Cage{}, core.ErrInvalidCageInvalidRemoval
 	}
@@ -134,12 +212,238 @@ func (c *Core) RemoveDino(ctx context.Context, id uuid.UUID, dinoID uuid.UUID) (
 		return Cage{}, fmt.Errorf("remove dino: unable to fetch dino: %w", err)
 	}
 
+	if d.CageID != cge.ID {
+		return Cage{}, core.ErrInvalidCageDinoNotCaged
+	}
+
+	before := cge
 	now := time.Now().UTC()
 	cge.CurrentCapacity--
+	cge.Version++
 	cge.UpdatedAt = now
-	if err := c.store.RemoveDino(ctx, cge, d.ID.String()); err != nil {
+	recs, err := placementRecords(ctx, audit.ActionCageRemoveDino, now, []Cage{before}, []Cage{cge}, d, uuid.Nil)
+	if err != nil {
+		return Cage{}, fmt.Errorf("remove dino: %w", err)
+	}
+	if err := c.store.RemoveDino(ctx, cge, d.ID.String(), recs...); err != nil {
 		return Cage{}, fmt.Errorf("remove dino: failed to remove dino from cage: %w", err)
 	}
 
 	return cge, nil
 }
+
+// TransferDino - will move the provided dino from one cage to another, applying every
+// AddDino rule to the destination cage and updating both cages and the dino at once.
+func (c *Core) TransferDino(ctx context.Context, fromID, toID, dinoID uuid.UUID) (Transfer, error) {
+	ctx, span := tracer.Start(ctx, "cage.TransferDino")
+	defer span.End()
+
+	if err := core.Authorize(ctx, core.PermDinoTransfer); err != nil {
+		return Transfer{}, fmt.Errorf("transfer dino: %w", err)
+	}
+
+	if fromID == toID {
+		return Transfer{}, core.ErrInvalidTransferSameCage
+	}
+
+	if fromID == uuid.Nil {
+		return Transfer{}, core.ErrInvalidCageDinoNotCaged
+	}
+
+	from, err := c.Get(ctx, fromID)
+	if err != nil {
+		return Transfer{}, fmt.Errorf("transfer dino: unable to fetch source cage: %w", err)
+	}
+
+	to, err := c.Get(ctx, toID)
+	if err != nil {
+		return Transfer{}, fmt.Errorf("transfer dino: unable to fetch destination cage: %w", err)
+	}
+
+	if err := checkCapacity(to); err != nil {
+		return Transfer{}, err
+	}
+
+	d, err := c.dino.Get(ctx, dinoID)
+	if err != nil {
+		return Transfer{}, fmt.Errorf("transfer dino: unable to fetch dino: %w", err)
+	}
+
+	if d.CageID != from.ID {
+		return Transfer{}, core.ErrInvalidCageDinoNotCaged
+	}
+
+	if err := c.checkCohabitation(ctx, to, d); err != nil {
+		return Transfer{}, err
+	}
+
+	befores := []Cage{from, to}
+	now := time.Now().UTC()
+	from.CurrentCapacity--
+	from.Version++
+	from.UpdatedAt = now
+	to.CurrentCapacity++
+	to.Version++
+	to.UpdatedAt = now
+	recs, err := placementRecords(ctx, audit.ActionDinoTransfer, now, befores, []Cage{from, to}, d, to.ID)
+	if err != nil {
+		return Transfer{}, fmt.Errorf("transfer dino: %w", err)
+	}
+	if err := c.store.TransferDino(ctx, from, to, d.ID.String(), recs...); err != nil {
+		return Transfer{}, fmt.Errorf("transfer dino: failed to transfer dino: %w", err)
+	}
+
+	d.CageID = to.ID
+	d.Version++
+	d.UpdatedAt = now
+	return Transfer{
+		From: from,
+		To:   to,
+		Dino: d,
+	}, nil
+}
+
+// Delete - will soft delete the provided cage, which must not hold any dinos.
+// A non zero version must match the current cage version.
+func (c *Core) Delete(ctx context.Context, id uuid.UUID, version int) (Cage, error) {
+	ctx, span := tracer.Start(ctx, "cage.Delete")
+	defer span.End()
+
+	if err := core.Authorize(ctx, core.PermCageDelete); err != nil {
+		return Cage{}, fmt.Errorf("delete: %w", err)
+	}
+
+	cge, err := c.Get(ctx, id)
+	if err != nil {
+		return Cage{}, fmt.Errorf("delete: unable to fetch cage: %w", err)
+	}
+
+	if version != 0 && cge.Version != version {
+		return Cage{}, core.ErrPreconditionFailed
+	}
+
+	if cge.CurrentCapacity > 0 {
+		return Cage{}, core.ErrInvalidCageNotEmpty
+	}
+
+	before := cge
+	now := time.Now().UTC()
+	cge.Version++
+	cge.UpdatedAt = now
+	cge.DeletedAt = now
+	rec, err := audit.NewRecord(ctx, audit.ActionCageDelete, audit.EntityCage, cge.ID, before, cge, now)
+	if err != nil {
+		return Cage{}, fmt.Errorf("delete: %w", err)
+	}
+	if err := c.store.Delete(ctx, cge.ID.String(), cge.Version, now, rec); err != nil {
+		return Cage{}, fmt.Errorf("delete: failed to delete cage: %w", err)
+	}
+
+	return cge, nil
+}
+
+// Restore - will restore a soft deleted cage. Restoring a cage that is not deleted is a no-op.
+// A non zero version must match the current cage version.
+func (c *Core) Restore(ctx context.Context, id uuid.UUID, version int) (Cage, error) {
+	ctx, span := tracer.Start(ctx, "cage.Restore")
+	defer span.End()
+
+	if err := core.Authorize(ctx, core.PermCageRestore); err != nil {
+		return Cage{}, fmt.Errorf("restore: %w", err)
+	}
+
+	cge, err := c.store.Get(ctx, id.String())
+	if err != nil {
+		return Cage{}, fmt.Errorf("restore: unable to fetch cage: %w", err)
+	}
+
+	if version != 0 && cge.Version != version {
+		return Cage{}, core.ErrPreconditionFailed
+	}
+
+	if !cge.Deleted() {
+		return cge, nil
+	}
+
+	before := cge
+	now := time.Now().UTC()
+	cge.Version++
+	cge.UpdatedAt = now
+	cge.DeletedAt = time.Time{}
+	rec, err := audit.NewRecord(ctx, audit.ActionCageRestore, audit.EntityCage, cge.ID, before, cge, now)
+	if err != nil {
+		return Cage{}, fmt.Errorf("restore: %w", err)
+	}
+	if err := c.store.Restore(ctx, cge.ID.String(), cge.Version, now, rec); err != nil {
+		return Cage{}, fmt.Errorf("restore: failed to restore cage: %w", err)
+	}
+
+	return cge, nil
+}
+
+// placementRecords - returns the audit records of moving the dino into the cage, or out of any
+// when cageID is nil: one per changed cage followed by one for the dino.
+func placementRecords(ctx context.Context, action string, ts time.Time, before, after []Cage, d dino.Dinosaur, cageID uuid.UUID) ([]audit.Record, error) {
+	recs := make([]audit.Record, 0, len(after)+1)
+	for i := range after {
+		rec, err := audit.NewRecord(ctx, action, audit.EntityCage, after[i].ID, before[i], after[i], ts)
+		if err != nil {
+			return nil, fmt.Errorf("placement records: %w", err)
+		}
+		recs = append(recs, rec)
+	}
+
+	moved := d
+	moved.CageID = cageID
+	moved.Version++
+	moved.UpdatedAt = ts
+	rec, err := audit.NewRecord(ctx, action, audit.EntityDino, d.ID, d, moved, ts)
+	if err != nil {
+		return nil, fmt.Errorf("placement records: %w", err)
+	}
+	return append(recs, rec), nil
+}
+
+// checkCapacity - validates the cage is powered and has room for one more dino.
+func checkCapacity(cge Cage) error {
+	if cge.Status == CageStatusDown {
+		return core.ErrInvalidCagePowerDown
+	}
+
+	if cge.CurrentCapacity >= cge.Capacity {
+		return core.ErrInvalidCageAtCapacity
+	}
+
+	return nil
+}
+
+// checkCohabitation - validates the dino against the cohabitation rules, returning a
+// cohabitation.RuleError holding every rule it violates.
+func (c *Core) checkCohabitation(ctx context.Context, cge Cage, d dino.Dinosaur) error {
+	vs, err := c.violations(ctx, cge, d)
+	if err != nil {
+		return fmt.Errorf("check cohabitation: %w", err)
+	}
+	if len(vs) > 0 {
+		return &cohabitation.RuleError{Violations: vs}
+	}
+	return nil
+}
+
+// violations - returns the cohabitation rules the dino would violate once in the cage.
+func (c *Core) violations(ctx context.Context, cge Cage, d dino.Dinosaur) ([]cohabitation.Violation, error) {
+	var residents []dino.Dinosaur
+	if cge.CurrentCapacity > 0 {
+		var err error
+		residents, _, err = c.dino.ListByCageID(ctx, cge.ID, core.Page{})
+		if err != nil {
+			return nil, fmt.Errorf("violations: unable to list dinos for cage: %w", err)
+		}
+	}
+
+	vs, err := c.rules.Check(ctx, cge.Type.String(), d, residents)
+	if err != nil {
+		return nil, fmt.Errorf("violations: %w", err)
+	}
+	return vs, nil
+}
@@ -0,0 +1,678 @@
+package cage_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/lenguti/jppp/business/core"
+	"github.com/lenguti/jppp/business/core/cage"
+	"github.com/lenguti/jppp/business/core/cohabitation"
+	"github.com/lenguti/jppp/business/core/dino"
+	"github.com/lenguti/jppp/business/core/species"
+	"github.com/lenguti/jppp/business/data/memstore"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newCores - returns a cage core and the dino core it places, backed by a new in-memory store.
+func newCores(t *testing.T) (*cage.Core, *dino.Core) {
+	t.Helper()
+	log := zerolog.Nop()
+	ms := memstore.New()
+	sc := species.NewCore(memstore.NewSpeciesStore(ms), log, species.Config{})
+	dc := dino.NewCore(memstore.NewDinoStore(ms), log, sc)
+	rc := cohabitation.NewCore(memstore.NewCohabitationStore(ms), log, sc, cohabitation.Config{})
+	return cage.NewCore(memstore.NewCageStore(ms), log, dc, rc), dc
+}
+
+func TestAddDinoConcurrent(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("parallel adds never exceed capacity", func(t *testing.T) {
+		// Setup.
+		const (
+			capacity = 10
+			attempts = 300
+		)
+		cc, dc := newCores(t)
+
+		cge, err := cc.Create(ctx, cage.NewCage{Type: cage.CageTypeHerbivore, Capacity: capacity, Status: cage.CageStatusActive})
+		require.NoError(t, err)
+
+		dinoIDs := make([]uuid.UUID, 0, attempts)
+		for i := 0; i < attempts; i++ {
+			d, err := dc.Create(ctx, dino.NewDino{Name: "Littlefoot", Species: dino.DinoSpeciesBrachiosaurus, Diet: dino.DietTypeHerbivore})
+			require.NoError(t, err)
+			dinoIDs = append(dinoIDs, d.ID)
+		}
+
+		// Execute.
+		var (
+			wg    sync.WaitGroup
+			mu    sync.Mutex
+			added int
+		)
+		for _, id := range dinoIDs {
+			wg.Add(1)
+			go func(id uuid.UUID) {
+				defer wg.Done()
+				_, err := cc.AddDino(ctx, cge.ID, id, 0)
+				switch {
+				case err == nil:
+					mu.Lock()
+					added++
+					mu.Unlock()
+				case errors.Is(err, core.ErrConflict), errors.Is(err, core.ErrInvalidCageAtCapacity):
+				default:
+					t.Errorf("unexpected error: %v", err)
+				}
+			}(id)
+		}
+		wg.Wait()
+
+		// Validate.
+		got, err := cc.Get(ctx, cge.ID)
+		require.NoError(t, err)
+		caged, _, err := dc.ListByCageID(ctx, cge.ID, core.Page{})
+		require.NoError(t, err)
+		assert.LessOrEqual(t, got.CurrentCapacity, capacity)
+		assert.Equal(t, added, got.CurrentCapacity)
+		assert.Len(t, caged, got.CurrentCapacity)
+	})
+
+	t.Run("parallel adds never mix carnivore species", func(t *testing.T) {
+		// Setup.
+		const attempts = 200
+		cc, dc := newCores(t)
+
+		cge, err := cc.Create(ctx, cage.NewCage{Type: cage.CageTypeCarnivore, Capacity: attempts, Status: cage.CageStatusActive})
+		require.NoError(t, err)
+
+		species := []string{dino.DinoSpeciesTyrannosaurus, dino.DinoSpeciesVelociraptor}
+		dinoIDs := make([]uuid.UUID, 0, attempts)
+		for i := 0; i < attempts; i++ {
+			d, err := dc.Create(ctx, dino.NewDino{Name: "Blue", Species: species[i%len(species)], Diet: dino.DietTypeCarnivore})
+			require.NoError(t, err)
+			dinoIDs = append(dinoIDs, d.ID)
+		}
+
+		// Execute.
+		var wg sync.WaitGroup
+		for _, id := range dinoIDs {
+			wg.Add(1)
+			go func(id uuid.UUID) {
+				defer wg.Done()
+				_, _ = cc.AddDino(ctx, cge.ID, id, 0)
+			}(id)
+		}
+		wg.Wait()
+
+		// Validate.
+		caged, _, err := dc.ListByCageID(ctx, cge.ID, core.Page{})
+		require.NoError(t, err)
+		require.NotEmpty(t, caged)
+		for _, d := range caged {
+			assert.Equal(t, caged[0].Species, d.Species)
+		}
+	})
+}
+
+func TestTransferDino(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("transfer dino success", func(t *testing.T) {
+		// Setup.
+		cc, dc := newCores(t)
+		from, err := cc.Create(ctx, cage.NewCage{Type: cage.CageTypeCarnivore, Capacity: 2, Status: cage.CageStatusActive})
+		require.NoError(t, err)
+		to, err := cc.Create(ctx, cage.NewCage{Type: cage.CageTypeCarnivore, Capacity: 2, Status: cage.CageStatusActive})
+		require.NoError(t, err)
+		d, err := dc.Create(ctx, dino.NewDino{Name: "Blue", Species: dino.DinoSpeciesVelociraptor, Diet: dino.DietTypeCarnivore})
+		require.NoError(t, err)
+		_, err = cc.AddDino(ctx, from.ID, d.ID, 0)
+		require.NoError(t, err)
+
+		// Execute.
+		got, err := cc.TransferDino(ctx, from.ID, to.ID, d.ID)
+
+		// Validate.
+		require.NoError(t, err)
+		assert.Equal(t, 0, got.From.CurrentCapacity)
+		assert.Equal(t, 1, got.To.CurrentCapacity)
+		assert.Equal(t, to.ID, got.Dino.CageID)
+		storedDino, err := dc.Get(ctx, d.ID)
+		require.NoError(t, err)
+		assert.Equal(t, to.ID, storedDino.CageID)
+		assert.Equal(t, got.Dino.Version, storedDino.Version)
+	})
+
+	t.Run("transfer dino destination species conflict", func(t *testing.T) {
+		// Setup.
+		cc, dc := newCores(t)
+		from, err := cc.Create(ctx, cage.NewCage{Type: cage.CageTypeCarnivore, Capacity: 2, Status: cage.CageStatusActive})
+		require.NoError(t, err)
+		to, err := cc.Create(ctx, cage.NewCage{Type: cage.CageTypeCarnivore, Capacity: 2, Status: cage.CageStatusActive})
+		require.NoError(t, err)
+		blue, err := dc.Create(ctx, dino.NewDino{Name: "Blue", Species: dino.DinoSpeciesVelociraptor, Diet: dino.DietTypeCarnivore})
+		require.NoError(t, err)
+		rexy, err := dc.Create(ctx, dino.NewDino{Name: "Rexy", Species: dino.DinoSpeciesTyrannosaurus, Diet: dino.DietTypeCarnivore})
+		require.NoError(t, err)
+		_, err = cc.AddDino(ctx, from.ID, blue.ID, 0)
+		require.NoError(t, err)
+		_, err = cc.AddDino(ctx, to.ID, rexy.ID, 0)
+		require.NoError(t, err)
+
+		// Execute.
+		_, err = cc.TransferDino(ctx, from.ID, to.ID, blue.ID)
+
+		// Validate.
+		assert.ErrorIs(t, err, core.ErrInvalidCageInvalidSpecies)
+		storedDino, err := dc.Get(ctx, blue.ID)
+		require.NoError(t, err)
+		assert.Equal(t, from.ID, storedDino.CageID)
+	})
+
+	t.Run("transfer dino not in source cage", func(t *testing.T) {
+		// Setup.
+		cc, dc := newCores(t)
+		from, err := cc.Create(ctx, cage.NewCage{Type: cage.CageTypeHerbivore, Capacity: 2, Status: cage.CageStatusActive})
+		require.NoError(t, err)
+		to, err := cc.Create(ctx, cage.NewCage{Type: cage.CageTypeHerbivore, Capacity: 2, Status: cage.CageStatusActive})
+		require.NoError(t, err)
+		d, err := dc.Create(ctx, dino.NewDino{Name: "Littlefoot", Species: dino.DinoSpeciesBrachiosaurus, Diet: dino.DietTypeHerbivore})
+		require.NoError(t, err)
+
+		// Execute.
+		_, err = cc.TransferDino(ctx, from.ID, to.ID, d.ID)
+
+		// Validate.
+		assert.ErrorIs(t, err, core.ErrInvalidCageDinoNotCaged)
+	})
+}
+
+func TestCheckPlacement(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("every violated rule is returned", func(t *testing.T) {
+		// Setup.
+		cc, dc := newCores(t)
+		full, err := cc.Create(ctx, cage.NewCage{Type: cage.CageTypeHerbivore, Capacity: 1, Status: cage.CageStatusActive})
+		require.NoError(t, err)
+		other, err := cc.Create(ctx, cage.NewCage{Type: cage.CageTypeCarnivore, Capacity: 1, Status: cage.CageStatusActive})
+		require.NoError(t, err)
+		foot, err := dc.Create(ctx, dino.NewDino{Name: "Littlefoot", Species: dino.DinoSpeciesBrachiosaurus, Diet: dino.DietTypeHerbivore})
+		require.NoError(t, err)
+		blue, err := dc.Create(ctx, dino.NewDino{Name: "Blue", Species: dino.DinoSpeciesVelociraptor, Diet: dino.DietTypeCarnivore})
+		require.NoError(t, err)
+		_, err = cc.AddDino(ctx, full.ID, foot.ID, 0)
+		require.NoError(t, err)
+		_, err = cc.AddDino(ctx, other.ID, blue.ID, 0)
+		require.NoError(t, err)
+
+		// Execute.
+		vs, err := cc.CheckPlacement(ctx, full.ID, blue.ID)
+
+		// Validate.
+		require.NoError(t, err)
+		rules := make([]string, 0, len(vs))
+		for _, v := range vs {
+			rules = append(rules, v.Rule)
+		}
+		assert.Equal(t, []string{cage.RuleCapacity, cage.RuleCaged, cohabitation.KindDiet, cohabitation.KindCarnivoreSpecies}, rules)
+		got, err := cc.Get(ctx, full.ID)
+		require.NoError(t, err)
+		assert.Equal(t, full.Version+1, got.Version)
+	})
+
+	t.Run("powered down cage", func(t *testing.T) {
+		// Setup.
+		cc, dc := newCores(t)
+		down, err := cc.Create(ctx, cage.NewCage{Type: cage.CageTypeHerbivore, Capacity: 1, Status: cage.CageStatusDown})
+		require.NoError(t, err)
+		d, err := dc.Create(ctx, dino.NewDino{Name: "Littlefoot", Species: dino.DinoSpeciesBrachiosaurus, Diet: dino.DietTypeHerbivore})
+		require.NoError(t, err)
+
+		// Execute.
+		vs, err := cc.CheckPlacement(ctx, down.ID, d.ID)
+
+		// Validate.
+		require.NoError(t, err)
+		require.Len(t, vs, 1)
+		assert.Equal(t, cage.RulePower, vs[0].Rule)
+		assert.Equal(t, core.ErrInvalidCagePowerDown, vs[0].Err)
+	})
+}
+
+func TestEligibleCages(t *testing.T) {
+	ctx := context.Background()
+	cc, dc := newCores(t)
+
+	newCage := func(t *testing.T, typ cage.Type, capacity int, status cage.Status) cage.Cage {
+		t.Helper()
+		cge, err := cc.Create(ctx, cage.NewCage{Type: typ, Capacity: capacity, Status: status})
+		require.NoError(t, err)
+		return cge
+	}
+	newDino := func(t *testing.T, name, sp string, cageID uuid.UUID) dino.Dinosaur {
+		t.Helper()
+		d, err := dc.Create(ctx, dino.NewDino{Name: name, Species: sp, Diet: dino.DietTypeCarnivore})
+		require.NoError(t, err)
+		if cageID != uuid.Nil {
+			_, err = cc.AddDino(ctx, cageID, d.ID, 0)
+			require.NoError(t, err)
+		}
+		return d
+	}
+
+	small := newCage(t, cage.CageTypeCarnivore, 2, cage.CageStatusActive)
+	large := newCage(t, cage.CageTypeCarnivore, 5, cage.CageStatusActive)
+	rexes := newCage(t, cage.CageTypeCarnivore, 5, cage.CageStatusActive)
+	full := newCage(t, cage.CageTypeCarnivore, 1, cage.CageStatusActive)
+	newCage(t, cage.CageTypeCarnivore, 9, cage.CageStatusDown)
+	newCage(t, cage.CageTypeHerbivore, 9, cage.CageStatusActive)
+	newDino(t, "Rexy", dino.DinoSpeciesTyrannosaurus, rexes.ID)
+	newDino(t, "Charlie", dino.DinoSpeciesVelociraptor, full.ID)
+	newDino(t, "Delta", dino.DinoSpeciesVelociraptor, large.ID)
+
+	t.Run("ranked by free capacity", func(t *testing.T) {
+		// Setup.
+		blue := newDino(t, "Blue", dino.DinoSpeciesVelociraptor, uuid.Nil)
+
+		// Execute.
+		cgs, err := cc.EligibleCages(ctx, blue.ID)
+
+		// Validate.
+		require.NoError(t, err)
+		require.Len(t, cgs, 2)
+		assert.Equal(t, large.ID, cgs[0].ID)
+		assert.Equal(t, small.ID, cgs[1].ID)
+	})
+
+	t.Run("caged dino is checked as a transfer", func(t *testing.T) {
+		// Setup.
+		echo := newDino(t, "Echo", dino.DinoSpeciesVelociraptor, small.ID)
+
+		// Execute.
+		cgs, err := cc.EligibleCages(ctx, echo.ID)
+
+		// Validate.
+		require.NoError(t, err)
+		require.Len(t, cgs, 1)
+		assert.Equal(t, large.ID, cgs[0].ID)
+	})
+}
+
+func TestPlacementPlan(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func(t *testing.T) (*cage.Core, *dino.Core, map[string]cage.Cage, map[string]dino.Dinosaur) {
+		t.Helper()
+		cc, dc := newCores(t)
+		cages := map[string]cage.Cage{}
+		for name, nc := range map[string]cage.NewCage{
+			"herbivores": {Type: cage.CageTypeHerbivore, Capacity: 2, Status: cage.CageStatusActive},
+			"rexes":      {Type: cage.CageTypeCarnivore, Capacity: 1, Status: cage.CageStatusActive},
+			"large":      {Type: cage.CageTypeCarnivore, Capacity: 3, Status: cage.CageStatusDown},
+			"small":      {Type: cage.CageTypeCarnivore, Capacity: 2, Status: cage.CageStatusDown},
+		} {
+			cge, err := cc.Create(ctx, nc)
+			require.NoError(t, err)
+			cages[name] = cge
+		}
+		dinos := map[string]dino.Dinosaur{}
+		for name, nd := range map[string]dino.NewDino{
+			"Rexy":       {Species: dino.DinoSpeciesTyrannosaurus, Diet: dino.DietTypeCarnivore},
+			"Rexy II":    {Species: dino.DinoSpeciesTyrannosaurus, Diet: dino.DietTypeCarnivore},
+			"Blue":       {Species: dino.DinoSpeciesVelociraptor, Diet: dino.DietTypeCarnivore},
+			"Charlie":    {Species: dino.DinoSpeciesVelociraptor, Diet: dino.DietTypeCarnivore},
+			"Littlefoot": {Species: dino.DinoSpeciesBrachiosaurus, Diet: dino.DietTypeHerbivore},
+			"Ducky":      {Species: dino.DinoSpeciesBrachiosaurus, Diet: dino.DietTypeHerbivore},
+			"Cera":       {Species: dino.DinoSpeciesTriceratops, Diet: dino.DietTypeHerbivore},
+		} {
+			nd.Name = name
+			d, err := dc.Create(ctx, nd)
+			require.NoError(t, err)
+			dinos[name] = d
+		}
+		cge, err := cc.AddDino(ctx, cages["rexes"].ID, dinos["Rexy"].ID, 0)
+		require.NoError(t, err)
+		cages["rexes"] = cge
+		return cc, dc, cages, dinos
+	}
+
+	t.Run("plan every uncaged dino", func(t *testing.T) {
+		// Setup.
+		cc, _, cages, dinos := setup(t)
+
+		// Execute.
+		plan, err := cc.PlanPlacements(ctx, nil)
+
+		// Validate.
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{cages["large"].ID, cages["small"].ID}, plan.PowerUps)
+		got := map[uuid.UUID]uuid.UUID{}
+		for _, a := range plan.Assignments {
+			got[a.DinoID] = a.CageID
+		}
+		assert.Equal(t, map[uuid.UUID]uuid.UUID{
+			dinos["Rexy II"].ID:    cages["large"].ID,
+			dinos["Blue"].ID:       cages["small"].ID,
+			dinos["Charlie"].ID:    cages["small"].ID,
+			dinos["Littlefoot"].ID: cages["herbivores"].ID,
+			dinos["Ducky"].ID:      cages["herbivores"].ID,
+		}, got)
+		require.Len(t, plan.Unplaced, 1)
+		assert.Equal(t, dinos["Cera"].ID, plan.Unplaced[0].DinoID)
+	})
+
+	t.Run("plan the given dinos", func(t *testing.T) {
+		// Setup.
+		cc, _, cages, dinos := setup(t)
+
+		// Execute.
+		plan, err := cc.PlanPlacements(ctx, []uuid.UUID{dinos["Cera"].ID, dinos["Rexy"].ID, dinos["Cera"].ID})
+
+		// Validate.
+		require.NoError(t, err)
+		assert.Empty(t, plan.PowerUps)
+		assert.Equal(t, []cage.Assignment{{DinoID: dinos["Cera"].ID, CageID: cages["herbivores"].ID}}, plan.Assignments)
+		require.Len(t, plan.Unplaced, 1)
+		assert.Equal(t, dinos["Rexy"].ID, plan.Unplaced[0].DinoID)
+	})
+
+	t.Run("apply a plan", func(t *testing.T) {
+		// Setup.
+		cc, dc, cages, dinos := setup(t)
+		plan, err := cc.PlanPlacements(ctx, nil)
+		require.NoError(t, err)
+
+		// Execute.
+		got, err := cc.ApplyPlan(ctx, plan)
+
+		// Validate.
+		require.NoError(t, err)
+		require.Len(t, got, 3)
+		for _, name := range []string{"large", "small", "herbivores"} {
+			cge, err := cc.Get(ctx, cages[name].ID)
+			require.NoError(t, err)
+			assert.Equal(t, cage.Status(cage.CageStatusActive), cge.Status)
+			assert.Contains(t, got, cge)
+		}
+		for _, a := range plan.Assignments {
+			d, err := dc.Get(ctx, a.DinoID)
+			require.NoError(t, err)
+			assert.Equal(t, a.CageID, d.CageID)
+		}
+		uncaged, err := dc.Get(ctx, dinos["Cera"].ID)
+		require.NoError(t, err)
+		assert.Equal(t, uuid.Nil, uncaged.CageID)
+	})
+
+	t.Run("stale plan is not applied", func(t *testing.T) {
+		// Setup.
+		cc, dc, cages, dinos := setup(t)
+		plan, err := cc.PlanPlacements(ctx, nil)
+		require.NoError(t, err)
+		_, err = cc.AddDino(ctx, cages["herbivores"].ID, dinos["Cera"].ID, 0)
+		require.NoError(t, err)
+
+		// Execute.
+		_, err = cc.ApplyPlan(ctx, plan)
+
+		// Validate.
+		var ae *cage.AssignmentError
+		require.ErrorAs(t, err, &ae)
+		assert.Equal(t, cages["herbivores"].ID, ae.Assignment.CageID)
+		assert.ErrorIs(t, err, core.ErrInvalidCageAtCapacity)
+		large, err := cc.Get(ctx, cages["large"].ID)
+		require.NoError(t, err)
+		assert.Equal(t, cages["large"], large)
+		rexy, err := dc.Get(ctx, dinos["Rexy II"].ID)
+		require.NoError(t, err)
+		assert.Equal(t, uuid.Nil, rexy.CageID)
+	})
+
+	t.Run("assignments are checked against each other", func(t *testing.T) {
+		// Setup.
+		cc, _, cages, dinos := setup(t)
+		plan := cage.Plan{
+			PowerUps: []uuid.UUID{cages["large"].ID},
+			Assignments: []cage.Assignment{
+				{DinoID: dinos["Blue"].ID, CageID: cages["large"].ID},
+				{DinoID: dinos["Rexy II"].ID, CageID: cages["large"].ID},
+			},
+		}
+
+		// Execute.
+		_, err := cc.ApplyPlan(ctx, plan)
+
+		// Validate.
+		assert.ErrorIs(t, err, core.ErrInvalidCageInvalidSpecies)
+		large, err := cc.Get(ctx, cages["large"].ID)
+		require.NoError(t, err)
+		assert.Equal(t, cage.Status(cage.CageStatusDown), large.Status)
+	})
+}
+
+func TestRebalance(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func(t *testing.T) (*cage.Core, *dino.Core, map[string]cage.Cage, map[string]dino.Dinosaur) {
+		t.Helper()
+		cc, dc := newCores(t)
+		cages := map[string]cage.Cage{}
+		for _, nc := range []struct {
+			name string
+			cage.NewCage
+		}{
+			{"raptors", cage.NewCage{Type: cage.CageTypeCarnivore, Capacity: 3, Status: cage.CageStatusActive}},
+			{"raptors II", cage.NewCage{Type: cage.CageTypeCarnivore, Capacity: 3, Status: cage.CageStatusActive}},
+			{"rexes", cage.NewCage{Type: cage.CageTypeCarnivore, Capacity: 2, Status: cage.CageStatusActive}},
+			{"herbivores", cage.NewCage{Type: cage.CageTypeHerbivore, Capacity: 4, Status: cage.CageStatusActive}},
+			{"herbivores II", cage.NewCage{Type: cage.CageTypeHerbivore, Capacity: 2, Status: cage.CageStatusActive}},
+			{"empty", cage.NewCage{Type: cage.CageTypeHerbivore, Capacity: 2, Status: cage.CageStatusActive}},
+			{"down", cage.NewCage{Type: cage.CageTypeCarnivore, Capacity: 4, Status: cage.CageStatusDown}},
+		} {
+			cge, err := cc.Create(ctx, nc.NewCage)
+			require.NoError(t, err)
+			cages[nc.name] = cge
+		}
+		dinos := map[string]dino.Dinosaur{}
+		for _, nd := range []struct {
+			name  string
+			cage  string
+			input dino.NewDino
+		}{
+			{"Blue", "raptors", dino.NewDino{Species: dino.DinoSpeciesVelociraptor, Diet: dino.DietTypeCarnivore}},
+			{"Charlie", "raptors", dino.NewDino{Species: dino.DinoSpeciesVelociraptor, Diet: dino.DietTypeCarnivore}},
+			{"Delta", "raptors II", dino.NewDino{Species: dino.DinoSpeciesVelociraptor, Diet: dino.DietTypeCarnivore}},
+			{"Rexy", "rexes", dino.NewDino{Species: dino.DinoSpeciesTyrannosaurus, Diet: dino.DietTypeCarnivore}},
+			{"Littlefoot", "herbivores", dino.NewDino{Species: dino.DinoSpeciesBrachiosaurus, Diet: dino.DietTypeHerbivore}},
+			{"Ducky", "herbivores", dino.NewDino{Species: dino.DinoSpeciesBrachiosaurus, Diet: dino.DietTypeHerbivore}},
+			{"Cera", "herbivores", dino.NewDino{Species: dino.DinoSpeciesTriceratops, Diet: dino.DietTypeHerbivore}},
+			{"Spike", "herbivores II", dino.NewDino{Species: dino.DinoSpeciesStegosaurus, Diet: dino.DietTypeHerbivore}},
+		} {
+			nd.input.Name = nd.name
+			d, err := dc.Create(ctx, nd.input)
+			require.NoError(t, err)
+			cge, err := cc.AddDino(ctx, cages[nd.cage].ID, d.ID, 0)
+			require.NoError(t, err)
+			cages[nd.cage] = cge
+			d.CageID = cge.ID
+			dinos[nd.name] = d
+		}
+		return cc, dc, cages, dinos
+	}
+
+	t.Run("consolidate", func(t *testing.T) {
+		// Setup.
+		cc, _, cages, dinos := setup(t)
+
+		// Execute.
+		rb, err := cc.PlanRebalance(ctx, cage.RebalanceOptions{Strategy: cage.StrategyConsolidate})
+
+		// Validate.
+		require.NoError(t, err)
+		assert.Empty(t, rb.PowerUps)
+		assert.Equal(t, []cage.Move{
+			{DinoID: dinos["Spike"].ID, From: cages["herbivores II"].ID, To: cages["herbivores"].ID},
+			{DinoID: dinos["Delta"].ID, From: cages["raptors II"].ID, To: cages["raptors"].ID},
+		}, rb.Moves)
+		assert.ElementsMatch(t, []uuid.UUID{cages["raptors II"].ID, cages["herbivores II"].ID, cages["empty"].ID}, rb.PowerDowns)
+		assert.Len(t, rb.Cages, 5)
+		for _, cge := range rb.Cages {
+			switch cge.ID {
+			case cages["raptors"].ID:
+				assert.Equal(t, 3, cge.CurrentCapacity)
+			case cages["herbivores"].ID:
+				assert.Equal(t, 4, cge.CurrentCapacity)
+			default:
+				assert.Equal(t, 0, cge.CurrentCapacity)
+				assert.Equal(t, cage.Status(cage.CageStatusDown), cge.Status)
+			}
+		}
+		assert.Empty(t, rb.Over)
+	})
+
+	t.Run("spread", func(t *testing.T) {
+		// Setup.
+		cc, _, cages, _ := setup(t)
+
+		// Execute.
+		rb, err := cc.PlanRebalance(ctx, cage.RebalanceOptions{Strategy: cage.StrategySpread, MaxOccupancy: 0.5})
+
+		// Validate.
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{cages["down"].ID}, rb.PowerUps)
+		got := [][2]uuid.UUID{}
+		for _, m := range rb.Moves {
+			got = append(got, [2]uuid.UUID{m.From, m.To})
+		}
+		assert.ElementsMatch(t, [][2]uuid.UUID{
+			{cages["raptors"].ID, cages["down"].ID},
+			{cages["herbivores"].ID, cages["empty"].ID},
+		}, got)
+		assert.Empty(t, rb.PowerDowns)
+		assert.Len(t, rb.Cages, 4)
+		assert.Empty(t, rb.Over)
+	})
+
+	t.Run("spread reports cages left over the maximum", func(t *testing.T) {
+		// Setup.
+		cc, _, cages, _ := setup(t)
+
+		// Execute.
+		rb, err := cc.PlanRebalance(ctx, cage.RebalanceOptions{Strategy: cage.StrategySpread, MaxOccupancy: 0.25})
+
+		// Validate.
+		require.NoError(t, err)
+		assert.Contains(t, rb.Over, cages["raptors"].ID)
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		// Setup.
+		cc, _, _, _ := setup(t)
+
+		for _, opts := range []cage.RebalanceOptions{
+			{Strategy: "shuffle"},
+			{Strategy: cage.StrategySpread},
+			{Strategy: cage.StrategySpread, MaxOccupancy: 1.5},
+		} {
+			// Execute.
+			_, err := cc.PlanRebalance(ctx, opts)
+
+			// Validate.
+			assert.Error(t, err, opts)
+		}
+	})
+
+	t.Run("apply a rebalance", func(t *testing.T) {
+		// Setup.
+		cc, dc, cages, dinos := setup(t)
+		rb, err := cc.PlanRebalance(ctx, cage.RebalanceOptions{Strategy: cage.StrategyConsolidate})
+		require.NoError(t, err)
+
+		// Execute.
+		got, err := cc.ApplyRebalance(ctx, rb)
+
+		// Validate.
+		require.NoError(t, err)
+		require.Len(t, got, 5)
+		for _, projected := range rb.Cages {
+			cge, err := cc.Get(ctx, projected.ID)
+			require.NoError(t, err)
+			assert.Equal(t, projected.Status, cge.Status)
+			assert.Equal(t, projected.CurrentCapacity, cge.CurrentCapacity)
+			assert.Equal(t, projected.Version, cge.Version)
+		}
+		delta, err := dc.Get(ctx, dinos["Delta"].ID)
+		require.NoError(t, err)
+		assert.Equal(t, cages["raptors"].ID, delta.CageID)
+	})
+
+	t.Run("stale rebalance is not applied", func(t *testing.T) {
+		// Setup.
+		cc, dc, cages, dinos := setup(t)
+		rb, err := cc.PlanRebalance(ctx, cage.RebalanceOptions{Strategy: cage.StrategyConsolidate})
+		require.NoError(t, err)
+		echo, err := dc.Create(ctx, dino.NewDino{Name: "Echo", Species: dino.DinoSpeciesVelociraptor, Diet: dino.DietTypeCarnivore})
+		require.NoError(t, err)
+		_, err = cc.AddDino(ctx, cages["raptors"].ID, echo.ID, 0)
+		require.NoError(t, err)
+
+		// Execute.
+		_, err = cc.ApplyRebalance(ctx, rb)
+
+		// Validate.
+		var me *cage.MoveError
+		require.ErrorAs(t, err, &me)
+		assert.Equal(t, dinos["Delta"].ID, me.Move.DinoID)
+		assert.ErrorIs(t, err, core.ErrInvalidCageAtCapacity)
+		source, err := cc.Get(ctx, cages["raptors II"].ID)
+		require.NoError(t, err)
+		assert.Equal(t, cages["raptors II"], source)
+	})
+
+	t.Run("moves are checked against each other", func(t *testing.T) {
+		// Setup.
+		cc, dc, cages, dinos := setup(t)
+		rb := cage.Rebalance{
+			PowerUps: []uuid.UUID{cages["down"].ID},
+			Moves: []cage.Move{
+				{DinoID: dinos["Delta"].ID, From: cages["raptors II"].ID, To: cages["down"].ID},
+				{DinoID: dinos["Rexy"].ID, From: cages["rexes"].ID, To: cages["down"].ID},
+			},
+		}
+
+		// Execute.
+		_, err := cc.ApplyRebalance(ctx, rb)
+
+		// Validate.
+		assert.ErrorIs(t, err, core.ErrInvalidCageInvalidSpecies)
+		down, err := cc.Get(ctx, cages["down"].ID)
+		require.NoError(t, err)
+		assert.Equal(t, cages["down"], down)
+		delta, err := dc.Get(ctx, dinos["Delta"].ID)
+		require.NoError(t, err)
+		assert.Equal(t, cages["raptors II"].ID, delta.CageID)
+	})
+
+	t.Run("occupied cage is not powered down", func(t *testing.T) {
+		// Setup.
+		cc, _, cages, _ := setup(t)
+		rb := cage.Rebalance{PowerDowns: []uuid.UUID{cages["empty"].ID, cages["rexes"].ID}}
+
+		// Execute.
+		_, err := cc.ApplyRebalance(ctx, rb)
+
+		// Validate.
+		assert.ErrorIs(t, err, core.ErrPowerDownCage)
+		empty, err := cc.Get(ctx, cages["empty"].ID)
+		require.NoError(t, err)
+		assert.Equal(t, cage.Status(cage.CageStatusActive), empty.Status)
+	})
+}
@@ -5,18 +5,46 @@ import (
 	"time"
 
 	"github.com/lenguti/jppp/business/core"
+	"github.com/lenguti/jppp/business/core/audit"
+	"github.com/lenguti/jppp/business/core/cohabitation"
 	"github.com/lenguti/jppp/business/core/dino"
 	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel"
 )
 
+var tracer = otel.Tracer("github.com/lenguti/jppp/business/core/cage")
+
 // Storer - represents the data layer behavior for cages.
+//
+// Mutations receive the cage version as it should be after the change and must only
+// apply it when the stored cage is still at the preceding version, returning
+// core.ErrConflict otherwise. They also receive the audit records of the change, which
+// must be written atomically with it along with the events describing it, appended to the outbox.
+//
+// AddDino, RemoveDino and TransferDino also open and close the placements of the dino,
+// as of the updated time of the cages, so its history can be queried.
+//
+// Apply applies a batch of steps in order, all of them or none, each step following the rules
+// of UpdateStatus, AddDino or TransferDino.
+//
+// Delete soft deletes an empty cage and Restore undoes it. Get returns soft deleted
+// cages, List only does when not filtered out by core.NotDeleted.
+//
+// Summarize groups the cages that are not soft deleted by type and status.
+//
+// List returns at most page.Limit cages ordered by page.Sort and then id, starting after page.Cursor.
 type Storer interface {
-	Create(ctx context.Context, c Cage) error
+	Create(ctx context.Context, c Cage, recs ...audit.Record) error
 	Get(ctx context.Context, id string) (Cage, error)
-	List(ctx context.Context, filters ...core.Filter) ([]Cage, error)
-	UpdateStatus(ctx context.Context, id, status string, ts time.Time) error
-	AddDino(ctx context.Context, c Cage, dinoID string) error
-	RemoveDino(ctx context.Context, c Cage, dinoID string) error
+	List(ctx context.Context, page core.Page, filters ...core.Filter) ([]Cage, error)
+	UpdateStatus(ctx context.Context, id, status string, version int, ts time.Time, recs ...audit.Record) error
+	AddDino(ctx context.Context, c Cage, dinoID string, recs ...audit.Record) error
+	RemoveDino(ctx context.Context, c Cage, dinoID string, recs ...audit.Record) error
+	TransferDino(ctx context.Context, from, to Cage, dinoID string, recs ...audit.Record) error
+	Apply(ctx context.Context, steps []Step, recs ...audit.Record) error
+	Delete(ctx context.Context, id string, version int, ts time.Time, recs ...audit.Record) error
+	Restore(ctx context.Context, id string, version int, ts time.Time, recs ...audit.Record) error
+	Summarize(ctx context.Context) ([]Summary, error)
 }
 
 // Core - represents the core business logic for cages.
@@ -24,13 +52,21 @@ type Core struct {
 	store Storer
 	log   zerolog.Logger
 	dino  *dino.Core
+	rules *cohabitation.Core
 }
 
-// NewCore - returns a new cage core with all its components initialized.
-func NewCore(store Storer, log zerolog.Logger, dc *dino.Core) *Core {
+// NewCore - returns a new cage core with all its components initialized. Dinos are placed
+// according to the rule set of the cohabitation core.
+func NewCore(store Storer, log zerolog.Logger, dc *dino.Core, rc *cohabitation.Core) *Core {
 	return &Core{
 		store: store,
 		log:   log,
 		dino:  dc,
+		rules: rc,
 	}
 }
+
+// logger - returns the request scoped logger, falling back to the core logger.
+func (c *Core) logger(ctx context.Context) *zerolog.Logger {
+	return core.Logger(ctx, c.log)
+}
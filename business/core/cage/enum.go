@@ -31,6 +31,13 @@ func ParseType(v string) error {
 	return nil
 }
 
+func normalizeType(v string) (string, error) {
+	if err := ParseType(v); err != nil {
+		return "", err
+	}
+	return strings.ToUpper(v), nil
+}
+
 // Status - represents cage status enum.
 type Status string
 
@@ -56,3 +63,10 @@ func ParseStatus(v string) error {
 	}
 	return nil
 }
+
+func normalizeStatus(v string) (string, error) {
+	if err := ParseStatus(v); err != nil {
+		return "", err
+	}
+	return strings.ToUpper(v), nil
+}
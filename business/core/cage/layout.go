@@ -0,0 +1,147 @@
+package cage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lenguti/jppp/business/core"
+	"github.com/lenguti/jppp/business/core/cohabitation"
+	"github.com/lenguti/jppp/business/core/dino"
+)
+
+// layout - represents cages along with their residents as a batch of changes leaves them, so each
+// change can be checked against the ones before it. ids holds the cages in the order they were loaded.
+type layout struct {
+	ids       []uuid.UUID
+	cages     map[uuid.UUID]Cage
+	residents map[uuid.UUID][]dino.Dinosaur
+}
+
+func newLayout() *layout {
+	return &layout{
+		cages:     map[uuid.UUID]Cage{},
+		residents: map[uuid.UUID][]dino.Dinosaur{},
+	}
+}
+
+// clone - returns a copy of the layout, which can be changed without changing the layout.
+func (l *layout) clone() *layout {
+	out := newLayout()
+	out.ids = append(out.ids, l.ids...)
+	for id, cge := range l.cages {
+		out.cages[id] = cge
+	}
+	for id, ds := range l.residents {
+		out.residents[id] = append([]dino.Dinosaur(nil), ds...)
+	}
+	return out
+}
+
+// loadAll - loads every cage that is not soft deleted, in creation order, along with the dinos in them.
+func (l *layout) loadAll(ctx context.Context, c *Core) error {
+	cgs, err := c.store.List(ctx, core.Page{}, core.NotDeleted)
+	if err != nil {
+		return fmt.Errorf("load all: failed to list cages: %w", err)
+	}
+	caged, _, err := c.dino.List(ctx, core.Page{}, core.Filter{Key: "cage_id", Op: core.OpNull, Value: "false"}, core.NotDeleted)
+	if err != nil {
+		return fmt.Errorf("load all: failed to list caged dinos: %w", err)
+	}
+	for _, cge := range cgs {
+		l.ids = append(l.ids, cge.ID)
+		l.cages[cge.ID] = cge
+	}
+	for _, d := range caged {
+		l.residents[d.CageID] = append(l.residents[d.CageID], d)
+	}
+	return nil
+}
+
+// load - loads the cage along with the dinos in it, unless already loaded.
+func (l *layout) load(ctx context.Context, c *Core, id uuid.UUID) error {
+	if _, ok := l.cages[id]; ok {
+		return nil
+	}
+	cge, err := c.Get(ctx, id)
+	if err != nil {
+		return fmt.Errorf("load: unable to fetch cage: %w", err)
+	}
+	if cge.CurrentCapacity > 0 {
+		residents, _, err := c.dino.ListByCageID(ctx, id, core.Page{})
+		if err != nil {
+			return fmt.Errorf("load: unable to list dinos for cage: %w", err)
+		}
+		l.residents[id] = residents
+	}
+	l.ids = append(l.ids, id)
+	l.cages[id] = cge
+	return nil
+}
+
+// violations - returns the cohabitation rules the dino would violate joining the cage as the layout
+// leaves it.
+func (l *layout) violations(ctx context.Context, c *Core, id uuid.UUID, d dino.Dinosaur) ([]cohabitation.Violation, error) {
+	vs, err := c.rules.Check(ctx, l.cages[id].Type.String(), d, l.residents[id])
+	if err != nil {
+		return nil, fmt.Errorf("violations: %w", err)
+	}
+	return vs, nil
+}
+
+// fits - reports whether the cage has room for the dino and the dino satisfies its cohabitation
+// rules, regardless of the cage power state.
+func (l *layout) fits(ctx context.Context, c *Core, id uuid.UUID, d dino.Dinosaur) (bool, error) {
+	if l.cages[id].Free() == 0 {
+		return false, nil
+	}
+	vs, err := l.violations(ctx, c, id, d)
+	if err != nil {
+		return false, fmt.Errorf("fits: %w", err)
+	}
+	return len(vs) == 0, nil
+}
+
+// setStatus - sets the status of the cage, returning the cage before and after.
+func (l *layout) setStatus(id uuid.UUID, status Status, ts time.Time) (Cage, Cage) {
+	before := l.cages[id]
+	after := before
+	after.Status = status
+	after.Version++
+	after.UpdatedAt = ts
+	l.cages[id] = after
+	return before, after
+}
+
+// add - moves the dino into the cage, returning the cage before and after.
+func (l *layout) add(id uuid.UUID, d dino.Dinosaur, ts time.Time) (Cage, Cage) {
+	before := l.cages[id]
+	after := before
+	after.CurrentCapacity++
+	after.Version++
+	after.UpdatedAt = ts
+	l.cages[id] = after
+	d.CageID = id
+	l.residents[id] = append(l.residents[id], d)
+	return before, after
+}
+
+// move - moves the dino between cages, returning both cages before and after.
+func (l *layout) move(from, to uuid.UUID, d dino.Dinosaur, ts time.Time) ([]Cage, []Cage) {
+	before := l.cages[from]
+	after := before
+	after.CurrentCapacity--
+	after.Version++
+	after.UpdatedAt = ts
+	l.cages[from] = after
+	residents := make([]dino.Dinosaur, 0, len(l.residents[from]))
+	for _, r := range l.residents[from] {
+		if r.ID != d.ID {
+			residents = append(residents, r)
+		}
+	}
+	l.residents[from] = residents
+	toBefore, toAfter := l.add(to, d, ts)
+	return []Cage{before, toBefore}, []Cage{after, toAfter}
+}
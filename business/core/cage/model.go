@@ -1,20 +1,61 @@
 package cage
 
 import (
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lenguti/jppp/business/core"
+	"github.com/lenguti/jppp/business/core/dino"
 )
 
-// Cage - represents a business domain cage.
+// Cage - represents a business domain cage. It is marshaled as is into audit records.
 type Cage struct {
-	ID              uuid.UUID
-	Type            Type
-	Capacity        int
-	CurrentCapacity int
-	Status          Status
-	CreatedAt       time.Time
-	UpdatedAt       time.Time
+	ID              uuid.UUID `json:"id"`
+	Type            Type      `json:"type"`
+	Capacity        int       `json:"capacity"`
+	CurrentCapacity int       `json:"currentCapacity"`
+	Status          Status    `json:"status"`
+	Version         int       `json:"version"`
+	CreatedAt       time.Time `json:"createdAt"`
+	UpdatedAt       time.Time `json:"updatedAt"`
+	DeletedAt       time.Time `json:"deletedAt"`
+}
+
+// SortFields - the fields cages can be sorted by.
+var SortFields = []string{core.SortCreatedAt, core.SortUpdatedAt}
+
+// FilterFields - the fields cages can be filtered by.
+var FilterFields = core.Fields{
+	"type":            {Kind: core.KindString, Normalize: normalizeType},
+	"status":          {Kind: core.KindString, Normalize: normalizeStatus},
+	"capacity":        {Kind: core.KindInt},
+	"currentCapacity": {Kind: core.KindInt},
+	"createdAt":       {Kind: core.KindInt},
+	"updatedAt":       {Kind: core.KindInt},
+	"deletedAt":       {Kind: core.KindInt},
+}
+
+// Deleted - reports whether the cage has been soft deleted.
+func (c Cage) Deleted() bool {
+	return !c.DeletedAt.IsZero()
+}
+
+// Free - returns the number of dinos the cage has room for.
+func (c Cage) Free() int {
+	if c.CurrentCapacity >= c.Capacity {
+		return 0
+	}
+	return c.Capacity - c.CurrentCapacity
+}
+
+func (c Cage) sortValue(field string) string {
+	switch field {
+	case core.SortUpdatedAt:
+		return strconv.FormatInt(c.UpdatedAt.Unix(), 10)
+	default:
+		return strconv.FormatInt(c.CreatedAt.Unix(), 10)
+	}
 }
 
 // NewCage - represents fields needed to create a new cage.
@@ -23,3 +64,29 @@ type NewCage struct {
 	Capacity int
 	Status   Status
 }
+
+// Summary - represents the number of cages of a type and status along with their
+// total capacity and the number of dinos they hold.
+type Summary struct {
+	Type     Type
+	Status   Status
+	Cages    int
+	Capacity int
+	Occupied int
+}
+
+// Step - represents one change of a batch applied by Storer.Apply, holding the cages it touches as
+// they should be after it. A step sets the status of Cage when DinoID is nil and moves the dino into
+// Cage otherwise, out of From when it is set.
+type Step struct {
+	Cage   Cage
+	DinoID uuid.UUID
+	From   *Cage
+}
+
+// Transfer - represents the state of both cages and the dino after a transfer.
+type Transfer struct {
+	From Cage
+	To   Cage
+	Dino dino.Dinosaur
+}
@@ -0,0 +1,117 @@
+package cage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/lenguti/jppp/business/core"
+	"github.com/lenguti/jppp/business/core/cohabitation"
+)
+
+// Placement rules checked ahead of the cohabitation rules.
+const (
+	RulePower    = "power"
+	RuleCapacity = "capacity"
+	RuleCaged    = "caged"
+)
+
+// CheckPlacement - will run every AddDino check of the dino joining the cage, returning all the
+// rules it would violate rather than the first. Nothing is changed.
+func (c *Core) CheckPlacement(ctx context.Context, id uuid.UUID, dinoID uuid.UUID) ([]cohabitation.Violation, error) {
+	ctx, span := tracer.Start(ctx, "cage.CheckPlacement")
+	defer span.End()
+
+	if err := core.Authorize(ctx, core.PermCageRead); err != nil {
+		return nil, fmt.Errorf("check placement: %w", err)
+	}
+
+	cge, err := c.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("check placement: unable to fetch cage: %w", err)
+	}
+
+	d, err := c.dino.Get(ctx, dinoID)
+	if err != nil {
+		return nil, fmt.Errorf("check placement: unable to fetch dino: %w", err)
+	}
+
+	vs := cageViolations(cge)
+	if d.CageID != uuid.Nil {
+		vs = append(vs, cohabitation.Violation{
+			Rule:        RuleCaged,
+			Err:         core.ErrInvalidCageDinoCaged,
+			Explanation: fmt.Sprintf("%s is already in cage %s and must be transferred instead.", d.Name, d.CageID),
+		})
+	}
+
+	rvs, err := c.violations(ctx, cge, d)
+	if err != nil {
+		return nil, fmt.Errorf("check placement: %w", err)
+	}
+	return append(vs, rvs...), nil
+}
+
+// EligibleCages - will list every cage that would accept the dino, ranked by free capacity and
+// then by creation. A dino already in a cage is checked as a transfer, so its own cage is left out.
+// Nothing is changed.
+func (c *Core) EligibleCages(ctx context.Context, dinoID uuid.UUID) ([]Cage, error) {
+	ctx, span := tracer.Start(ctx, "cage.EligibleCages")
+	defer span.End()
+
+	if err := core.Authorize(ctx, core.PermCageRead); err != nil {
+		return nil, fmt.Errorf("eligible cages: %w", err)
+	}
+
+	d, err := c.dino.Get(ctx, dinoID)
+	if err != nil {
+		return nil, fmt.Errorf("eligible cages: unable to fetch dino: %w", err)
+	}
+
+	l := newLayout()
+	if err := l.loadAll(ctx, c); err != nil {
+		return nil, fmt.Errorf("eligible cages: %w", err)
+	}
+
+	out := make([]Cage, 0, len(l.ids))
+	for _, id := range l.ids {
+		cge := l.cages[id]
+		if id == d.CageID || cge.Status != CageStatusActive {
+			continue
+		}
+		ok, err := l.fits(ctx, c, id, d)
+		if err != nil {
+			return nil, fmt.Errorf("eligible cages: %w", err)
+		}
+		if ok {
+			out = append(out, cge)
+		}
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Free() > out[j].Free()
+	})
+	return out, nil
+}
+
+// cageViolations - returns the violations of the cage power state and capacity, the rules
+// checkCapacity enforces.
+func cageViolations(cge Cage) []cohabitation.Violation {
+	var vs []cohabitation.Violation
+	if cge.Status == CageStatusDown {
+		vs = append(vs, cohabitation.Violation{
+			Rule:        RulePower,
+			Err:         core.ErrInvalidCagePowerDown,
+			Explanation: "The cage is powered down and cannot take dinosaurs.",
+		})
+	}
+	if cge.Free() == 0 {
+		vs = append(vs, cohabitation.Violation{
+			Rule:        RuleCapacity,
+			Err:         core.ErrInvalidCageAtCapacity,
+			Explanation: fmt.Sprintf("The cage is at capacity, holding %d of %d dinosaurs.", cge.CurrentCapacity, cge.Capacity),
+		})
+	}
+	return vs
+}
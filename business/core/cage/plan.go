@@ -0,0 +1,265 @@
+package cage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lenguti/jppp/business/core"
+	"github.com/lenguti/jppp/business/core/audit"
+	"github.com/lenguti/jppp/business/core/cohabitation"
+	"github.com/lenguti/jppp/business/core/dino"
+)
+
+// Assignment - represents a dino placed into a cage by a plan.
+type Assignment struct {
+	DinoID uuid.UUID
+	CageID uuid.UUID
+}
+
+// Unplaced - represents a dino a plan found no cage for, along with why.
+type Unplaced struct {
+	DinoID uuid.UUID
+	Reason string
+}
+
+// Plan - represents dinos placed into cages, proposed for review before being applied. The cages
+// of PowerUps are powered up ahead of the assignments, which are applied in order.
+type Plan struct {
+	PowerUps    []uuid.UUID
+	Assignments []Assignment
+	Unplaced    []Unplaced
+}
+
+// AssignmentError - represents an assignment of a plan refused by the AddDino rules.
+type AssignmentError struct {
+	Assignment Assignment
+	Err        error
+}
+
+// Error - satisfies the error interface.
+func (e *AssignmentError) Error() string {
+	return fmt.Sprintf("assignment of dino %s to cage %s: %s", e.Assignment.DinoID, e.Assignment.CageID, e.Err)
+}
+
+// Unwrap - exposes the error refusing the assignment to errors.Is and errors.As.
+func (e *AssignmentError) Unwrap() error {
+	return e.Err
+}
+
+// PlanPlacements - will plan the placement of the dinos, or of every dino not in a cage when none
+// are given, following the AddDino rules. Each dino goes to the fullest active cage already holding
+// dinos it can share with, then to the empty active cage with the most room and, when no active
+// cage would accept it, to the powered down cage with the most room, which the plan powers up.
+// Carnivores are placed first and dinos of a species together. Nothing is changed.
+func (c *Core) PlanPlacements(ctx context.Context, dinoIDs []uuid.UUID) (Plan, error) {
+	ctx, span := tracer.Start(ctx, "cage.PlanPlacements")
+	defer span.End()
+
+	if err := core.Authorize(ctx, core.PermCageRead); err != nil {
+		return Plan{}, fmt.Errorf("plan placements: %w", err)
+	}
+
+	var plan Plan
+	ds, err := c.uncaged(ctx, dinoIDs, &plan)
+	if err != nil {
+		return Plan{}, fmt.Errorf("plan placements: %w", err)
+	}
+
+	l := newLayout()
+	if err := l.loadAll(ctx, c); err != nil {
+		return Plan{}, fmt.Errorf("plan placements: %w", err)
+	}
+
+	sort.SliceStable(ds, func(i, j int) bool {
+		if ds[i].Diet != ds[j].Diet {
+			return ds[i].Diet == dino.DietTypeCarnivore
+		}
+		return ds[i].Species < ds[j].Species
+	})
+	now := time.Now().UTC()
+	for _, d := range ds {
+		id, err := c.bestCage(ctx, l, d)
+		if err != nil {
+			return Plan{}, fmt.Errorf("plan placements: %w", err)
+		}
+		if id == uuid.Nil {
+			plan.Unplaced = append(plan.Unplaced, Unplaced{
+				DinoID: d.ID,
+				Reason: fmt.Sprintf("No cage, active or powered down, has room for %s under the cohabitation rules.", d.Name),
+			})
+			continue
+		}
+		if l.cages[id].Status == CageStatusDown {
+			l.setStatus(id, CageStatusActive, now)
+			plan.PowerUps = append(plan.PowerUps, id)
+		}
+		l.add(id, d, now)
+		plan.Assignments = append(plan.Assignments, Assignment{DinoID: d.ID, CageID: id})
+	}
+	return plan, nil
+}
+
+// ApplyPlan - will apply a reviewed plan at once: its cages are powered up and its dinos placed in
+// order, each following the AddDino rules as the assignments before it leave the cages. Should a rule
+// refuse an assignment, or the cages have changed since they were checked, none of the plan is applied.
+// Returns the cages the plan changed, as it leaves them.
+func (c *Core) ApplyPlan(ctx context.Context, plan Plan) ([]Cage, error) {
+	ctx, span := tracer.Start(ctx, "cage.ApplyPlan")
+	defer span.End()
+
+	if err := core.Authorize(ctx, core.PermCageAddDino); err != nil {
+		return nil, fmt.Errorf("apply plan: %w", err)
+	}
+	if len(plan.PowerUps) > 0 {
+		if err := core.Authorize(ctx, core.PermCageUpdate); err != nil {
+			return nil, fmt.Errorf("apply plan: %w", err)
+		}
+	}
+
+	var (
+		l     = newLayout()
+		now   = time.Now().UTC()
+		steps []Step
+		recs  []audit.Record
+	)
+	for _, id := range plan.PowerUps {
+		if err := l.load(ctx, c, id); err != nil {
+			return nil, fmt.Errorf("apply plan: %w", err)
+		}
+		if l.cages[id].Status == CageStatusActive {
+			continue
+		}
+		before, after := l.setStatus(id, CageStatusActive, now)
+		rec, err := audit.NewRecord(ctx, audit.ActionCageUpdateStatus, audit.EntityCage, id, before, after, now)
+		if err != nil {
+			return nil, fmt.Errorf("apply plan: %w", err)
+		}
+		steps = append(steps, Step{Cage: after})
+		recs = append(recs, rec)
+	}
+
+	placed := map[uuid.UUID]bool{}
+	for _, a := range plan.Assignments {
+		d, err := c.checkAssignment(ctx, l, a, placed)
+		if err != nil {
+			return nil, &AssignmentError{Assignment: a, Err: err}
+		}
+		before, after := l.add(a.CageID, d, now)
+		prs, err := placementRecords(ctx, audit.ActionCageAddDino, now, []Cage{before}, []Cage{after}, d, a.CageID)
+		if err != nil {
+			return nil, fmt.Errorf("apply plan: %w", err)
+		}
+		steps = append(steps, Step{Cage: after, DinoID: d.ID})
+		recs = append(recs, prs...)
+		placed[d.ID] = true
+	}
+
+	if len(steps) == 0 {
+		return nil, nil
+	}
+	if err := c.store.Apply(ctx, steps, recs...); err != nil {
+		return nil, fmt.Errorf("apply plan: failed to apply plan: %w", err)
+	}
+
+	out := make([]Cage, 0, len(l.ids))
+	for _, id := range l.ids {
+		out = append(out, l.cages[id])
+	}
+	return out, nil
+}
+
+// uncaged - returns the dinos to place, or every dino not in a cage when no ids are given. Dinos
+// already in a cage are recorded as unplaced in the plan.
+func (c *Core) uncaged(ctx context.Context, ids []uuid.UUID, plan *Plan) ([]dino.Dinosaur, error) {
+	if len(ids) == 0 {
+		ds, _, err := c.dino.List(ctx, core.Page{}, core.Filter{Key: "cage_id", Op: core.OpNull, Value: "true"}, core.NotDeleted)
+		if err != nil {
+			return nil, fmt.Errorf("uncaged: failed to list dinos: %w", err)
+		}
+		return ds, nil
+	}
+
+	seen := map[uuid.UUID]bool{}
+	ds := make([]dino.Dinosaur, 0, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		d, err := c.dino.Get(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("uncaged: unable to fetch dino: %w", err)
+		}
+		if d.CageID != uuid.Nil {
+			plan.Unplaced = append(plan.Unplaced, Unplaced{DinoID: d.ID, Reason: fmt.Sprintf("%s is already in cage %s.", d.Name, d.CageID)})
+			continue
+		}
+		ds = append(ds, d)
+	}
+	return ds, nil
+}
+
+// bestCage - returns the cage the dino fits best as the layout leaves the cages, nil when none fits.
+// Active cages holding dinos come first, the fullest first, then empty active cages and last powered
+// down cages, the ones with the most room first. Ties keep the layout order.
+func (c *Core) bestCage(ctx context.Context, l *layout, d dino.Dinosaur) (uuid.UUID, error) {
+	rank := func(cge Cage) (int, int) {
+		switch {
+		case cge.Status == CageStatusDown:
+			return 2, -cge.Free()
+		case cge.CurrentCapacity == 0:
+			return 1, -cge.Free()
+		}
+		return 0, cge.Free()
+	}
+
+	best := uuid.Nil
+	var bestGroup, bestScore int
+	for _, id := range l.ids {
+		ok, err := l.fits(ctx, c, id, d)
+		if err != nil {
+			return uuid.Nil, fmt.Errorf("best cage: %w", err)
+		}
+		if !ok {
+			continue
+		}
+		group, score := rank(l.cages[id])
+		if best == uuid.Nil || group < bestGroup || (group == bestGroup && score < bestScore) {
+			best, bestGroup, bestScore = id, group, score
+		}
+	}
+	return best, nil
+}
+
+// checkAssignment - validates the assignment against the AddDino rules as the layout leaves the
+// cage, returning the dino. placed holds the dinos placed by the assignments before it.
+func (c *Core) checkAssignment(ctx context.Context, l *layout, a Assignment, placed map[uuid.UUID]bool) (dino.Dinosaur, error) {
+	if err := l.load(ctx, c, a.CageID); err != nil {
+		return dino.Dinosaur{}, fmt.Errorf("check assignment: %w", err)
+	}
+
+	if err := checkCapacity(l.cages[a.CageID]); err != nil {
+		return dino.Dinosaur{}, err
+	}
+
+	d, err := c.dino.Get(ctx, a.DinoID)
+	if err != nil {
+		return dino.Dinosaur{}, fmt.Errorf("check assignment: unable to fetch dino: %w", err)
+	}
+
+	if d.CageID != uuid.Nil || placed[d.ID] {
+		return dino.Dinosaur{}, core.ErrInvalidCageDinoCaged
+	}
+
+	vs, err := l.violations(ctx, c, a.CageID, d)
+	if err != nil {
+		return dino.Dinosaur{}, fmt.Errorf("check assignment: %w", err)
+	}
+	if len(vs) > 0 {
+		return dino.Dinosaur{}, &cohabitation.RuleError{Violations: vs}
+	}
+	return d, nil
+}
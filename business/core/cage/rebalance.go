@@ -0,0 +1,409 @@
+package cage
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lenguti/jppp/business/core"
+	"github.com/lenguti/jppp/business/core/audit"
+	"github.com/lenguti/jppp/business/core/cohabitation"
+	"github.com/lenguti/jppp/business/core/dino"
+)
+
+// Rebalance strategies.
+const (
+	StrategyConsolidate = "consolidate"
+	StrategySpread      = "spread"
+)
+
+// RebalanceOptions - represents how dinos are rebalanced across cages. Consolidating gathers them
+// in the fewest cages and powers down the rest, spreading moves them out of cages holding more than
+// MaxOccupancy, a ratio of their capacity.
+type RebalanceOptions struct {
+	Strategy     string
+	MaxOccupancy float64
+}
+
+// Move - represents a dino moved between cages by a rebalance.
+type Move struct {
+	DinoID uuid.UUID
+	From   uuid.UUID
+	To     uuid.UUID
+}
+
+// Rebalance - represents dinos moved between cages, proposed for review before being applied. The
+// cages of PowerUps are powered up ahead of the moves, which are applied in order, and the cages of
+// PowerDowns powered down after them. Cages holds the projected state of every cage the rebalance
+// changes and Over the cages it could not bring under the maximum occupancy when spreading.
+type Rebalance struct {
+	PowerUps   []uuid.UUID
+	Moves      []Move
+	PowerDowns []uuid.UUID
+	Cages      []Cage
+	Over       []uuid.UUID
+}
+
+// MoveError - represents a move of a rebalance refused by the TransferDino rules.
+type MoveError struct {
+	Move Move
+	Err  error
+}
+
+// Error - satisfies the error interface.
+func (e *MoveError) Error() string {
+	return fmt.Sprintf("move of dino %s from cage %s to cage %s: %s", e.Move.DinoID, e.Move.From, e.Move.To, e.Err)
+}
+
+// Unwrap - exposes the error refusing the move to errors.Is and errors.As.
+func (e *MoveError) Unwrap() error {
+	return e.Err
+}
+
+// PlanRebalance - will plan moving dinos between cages following the AddDino rules. Nothing is changed.
+//
+// Consolidating empties the active cages holding the fewest dinos first, moving each of their dinos
+// into the fullest occupied cage that accepts it, and only when all of them can move. Every active
+// cage left empty is then powered down.
+//
+// Spreading moves dinos out of the active cages holding more than the maximum occupancy into the
+// cage that would be the least occupied once it receives them, without going over the maximum
+// itself. Powered down cages are only powered up when no active cage can take a dino.
+func (c *Core) PlanRebalance(ctx context.Context, opts RebalanceOptions) (Rebalance, error) {
+	ctx, span := tracer.Start(ctx, "cage.PlanRebalance")
+	defer span.End()
+
+	if err := core.Authorize(ctx, core.PermCageRead); err != nil {
+		return Rebalance{}, fmt.Errorf("plan rebalance: %w", err)
+	}
+
+	l := newLayout()
+	if err := l.loadAll(ctx, c); err != nil {
+		return Rebalance{}, fmt.Errorf("plan rebalance: %w", err)
+	}
+	original := l.clone()
+
+	var (
+		rb  Rebalance
+		err error
+	)
+	switch opts.Strategy {
+	case StrategyConsolidate:
+		l, rb, err = c.consolidate(ctx, l)
+	case StrategySpread:
+		if opts.MaxOccupancy <= 0 || opts.MaxOccupancy > 1 {
+			return Rebalance{}, fmt.Errorf("plan rebalance: max occupancy %v out of (0, 1]", opts.MaxOccupancy)
+		}
+		rb, err = c.spread(ctx, l, opts.MaxOccupancy)
+	default:
+		return Rebalance{}, fmt.Errorf("plan rebalance: unknown strategy %q", opts.Strategy)
+	}
+	if err != nil {
+		return Rebalance{}, fmt.Errorf("plan rebalance: %w", err)
+	}
+
+	for _, id := range l.ids {
+		if l.cages[id] != original.cages[id] {
+			rb.Cages = append(rb.Cages, l.cages[id])
+		}
+	}
+	return rb, nil
+}
+
+// consolidate - plans emptying as many cages as possible, returning the layout it leaves.
+func (c *Core) consolidate(ctx context.Context, l *layout) (*layout, Rebalance, error) {
+	donors := make([]uuid.UUID, 0, len(l.ids))
+	for _, id := range l.ids {
+		if cge := l.cages[id]; cge.Status == CageStatusActive && cge.CurrentCapacity > 0 {
+			donors = append(donors, id)
+		}
+	}
+	sort.SliceStable(donors, func(i, j int) bool {
+		a, b := l.cages[donors[i]], l.cages[donors[j]]
+		if a.CurrentCapacity != b.CurrentCapacity {
+			return a.CurrentCapacity < b.CurrentCapacity
+		}
+		return a.Capacity < b.Capacity
+	})
+
+	var (
+		rb       Rebalance
+		now      = time.Now().UTC()
+		received = map[uuid.UUID]bool{}
+	)
+	for _, donor := range donors {
+		if received[donor] {
+			continue
+		}
+		trial := l.clone()
+		moves, ok := []Move(nil), true
+		for _, d := range l.residents[donor] {
+			to, err := c.fullestTarget(ctx, trial, donor, d)
+			if err != nil {
+				return nil, Rebalance{}, fmt.Errorf("consolidate: %w", err)
+			}
+			if to == uuid.Nil {
+				ok = false
+				break
+			}
+			trial.move(donor, to, d, now)
+			moves = append(moves, Move{DinoID: d.ID, From: donor, To: to})
+		}
+		if !ok {
+			continue
+		}
+		l = trial
+		rb.Moves = append(rb.Moves, moves...)
+		for _, m := range moves {
+			received[m.To] = true
+		}
+	}
+
+	for _, id := range l.ids {
+		if cge := l.cages[id]; cge.Status == CageStatusActive && cge.CurrentCapacity == 0 {
+			l.setStatus(id, CageStatusDown, now)
+			rb.PowerDowns = append(rb.PowerDowns, id)
+		}
+	}
+	return l, rb, nil
+}
+
+// fullestTarget - returns the fullest occupied active cage, other than the donor, the dino fits in.
+func (c *Core) fullestTarget(ctx context.Context, l *layout, donor uuid.UUID, d dino.Dinosaur) (uuid.UUID, error) {
+	best := uuid.Nil
+	for _, id := range l.ids {
+		cge := l.cages[id]
+		if id == donor || cge.Status != CageStatusActive || cge.CurrentCapacity == 0 {
+			continue
+		}
+		if best != uuid.Nil && cge.Free() >= l.cages[best].Free() {
+			continue
+		}
+		ok, err := l.fits(ctx, c, id, d)
+		if err != nil {
+			return uuid.Nil, fmt.Errorf("fullest target: %w", err)
+		}
+		if ok {
+			best = id
+		}
+	}
+	return best, nil
+}
+
+// spread - plans moving dinos out of the cages holding more than the maximum occupancy.
+func (c *Core) spread(ctx context.Context, l *layout, maxOccupancy float64) (Rebalance, error) {
+	limit := func(cge Cage) int {
+		return int(math.Floor(maxOccupancy*float64(cge.Capacity) + 1e-9))
+	}
+
+	var (
+		rb  Rebalance
+		now = time.Now().UTC()
+	)
+	for _, from := range append([]uuid.UUID(nil), l.ids...) {
+		if l.cages[from].Status != CageStatusActive {
+			continue
+		}
+		for _, d := range append([]dino.Dinosaur(nil), l.residents[from]...) {
+			if l.cages[from].CurrentCapacity <= limit(l.cages[from]) {
+				break
+			}
+			to, err := c.emptiestTarget(ctx, l, from, d, limit)
+			if err != nil {
+				return Rebalance{}, fmt.Errorf("spread: %w", err)
+			}
+			if to == uuid.Nil {
+				continue
+			}
+			if l.cages[to].Status == CageStatusDown {
+				l.setStatus(to, CageStatusActive, now)
+				rb.PowerUps = append(rb.PowerUps, to)
+			}
+			l.move(from, to, d, now)
+			rb.Moves = append(rb.Moves, Move{DinoID: d.ID, From: from, To: to})
+		}
+		if l.cages[from].CurrentCapacity > limit(l.cages[from]) {
+			rb.Over = append(rb.Over, from)
+		}
+	}
+	return rb, nil
+}
+
+// emptiestTarget - returns the cage, other than the source, the dino fits in that stays within its
+// limit and is the least occupied once it receives the dino. Active cages come before powered down ones.
+func (c *Core) emptiestTarget(ctx context.Context, l *layout, from uuid.UUID, d dino.Dinosaur, limit func(Cage) int) (uuid.UUID, error) {
+	ratio := func(cge Cage) float64 {
+		return float64(cge.CurrentCapacity+1) / float64(cge.Capacity)
+	}
+	better := func(a, b Cage) bool {
+		if a.Status != b.Status {
+			return a.Status == CageStatusActive
+		}
+		return ratio(a) < ratio(b)
+	}
+
+	best := uuid.Nil
+	for _, id := range l.ids {
+		cge := l.cages[id]
+		if id == from || cge.CurrentCapacity+1 > limit(cge) {
+			continue
+		}
+		if best != uuid.Nil && !better(cge, l.cages[best]) {
+			continue
+		}
+		ok, err := l.fits(ctx, c, id, d)
+		if err != nil {
+			return uuid.Nil, fmt.Errorf("emptiest target: %w", err)
+		}
+		if ok {
+			best = id
+		}
+	}
+	return best, nil
+}
+
+// ApplyRebalance - will apply a reviewed rebalance at once: its cages are powered up, its dinos moved
+// in order, each following the TransferDino rules as the moves before it leave the cages, and its
+// cages powered down, which must be empty by then. Should a rule refuse a step, or the cages have
+// changed since they were checked, none of the rebalance is applied. Returns the cages the rebalance
+// changed, as it leaves them.
+func (c *Core) ApplyRebalance(ctx context.Context, rb Rebalance) ([]Cage, error) {
+	ctx, span := tracer.Start(ctx, "cage.ApplyRebalance")
+	defer span.End()
+
+	if len(rb.Moves) > 0 {
+		if err := core.Authorize(ctx, core.PermDinoTransfer); err != nil {
+			return nil, fmt.Errorf("apply rebalance: %w", err)
+		}
+	}
+	if len(rb.PowerUps) > 0 {
+		if err := core.Authorize(ctx, core.PermCageUpdate); err != nil {
+			return nil, fmt.Errorf("apply rebalance: %w", err)
+		}
+	}
+	if len(rb.PowerDowns) > 0 {
+		if err := core.Authorize(ctx, core.PermCagePowerDown); err != nil {
+			return nil, fmt.Errorf("apply rebalance: %w", err)
+		}
+	}
+
+	var (
+		l     = newLayout()
+		now   = time.Now().UTC()
+		steps []Step
+		recs  []audit.Record
+	)
+	setStatus := func(id uuid.UUID, status Status) error {
+		before, after := l.setStatus(id, status, now)
+		rec, err := audit.NewRecord(ctx, audit.ActionCageUpdateStatus, audit.EntityCage, id, before, after, now)
+		if err != nil {
+			return err
+		}
+		steps = append(steps, Step{Cage: after})
+		recs = append(recs, rec)
+		return nil
+	}
+
+	for _, id := range rb.PowerUps {
+		if err := l.load(ctx, c, id); err != nil {
+			return nil, fmt.Errorf("apply rebalance: %w", err)
+		}
+		if l.cages[id].Status == CageStatusActive {
+			continue
+		}
+		if err := setStatus(id, CageStatusActive); err != nil {
+			return nil, fmt.Errorf("apply rebalance: %w", err)
+		}
+	}
+
+	moved := map[uuid.UUID]dino.Dinosaur{}
+	for _, m := range rb.Moves {
+		d, err := c.checkMove(ctx, l, m, moved)
+		if err != nil {
+			return nil, &MoveError{Move: m, Err: err}
+		}
+		before, after := l.move(m.From, m.To, d, now)
+		prs, err := placementRecords(ctx, audit.ActionDinoTransfer, now, before, after, d, m.To)
+		if err != nil {
+			return nil, fmt.Errorf("apply rebalance: %w", err)
+		}
+		from := after[0]
+		steps = append(steps, Step{Cage: after[1], DinoID: d.ID, From: &from})
+		recs = append(recs, prs...)
+		d.CageID = m.To
+		d.Version++
+		d.UpdatedAt = now
+		moved[d.ID] = d
+	}
+
+	for _, id := range rb.PowerDowns {
+		if err := l.load(ctx, c, id); err != nil {
+			return nil, fmt.Errorf("apply rebalance: %w", err)
+		}
+		if l.cages[id].Status == CageStatusDown {
+			continue
+		}
+		if l.cages[id].CurrentCapacity > 0 {
+			return nil, fmt.Errorf("apply rebalance: cage %s: %w", id, core.ErrPowerDownCage)
+		}
+		if err := setStatus(id, CageStatusDown); err != nil {
+			return nil, fmt.Errorf("apply rebalance: %w", err)
+		}
+	}
+
+	if len(steps) == 0 {
+		return nil, nil
+	}
+	if err := c.store.Apply(ctx, steps, recs...); err != nil {
+		return nil, fmt.Errorf("apply rebalance: failed to apply rebalance: %w", err)
+	}
+
+	out := make([]Cage, 0, len(l.ids))
+	for _, id := range l.ids {
+		out = append(out, l.cages[id])
+	}
+	return out, nil
+}
+
+// checkMove - validates the move against the TransferDino rules as the layout leaves the cages,
+// returning the dino. moved holds the dinos moved by the moves before it, as they left them.
+func (c *Core) checkMove(ctx context.Context, l *layout, m Move, moved map[uuid.UUID]dino.Dinosaur) (dino.Dinosaur, error) {
+	if m.From == m.To {
+		return dino.Dinosaur{}, core.ErrInvalidTransferSameCage
+	}
+
+	for _, id := range []uuid.UUID{m.From, m.To} {
+		if err := l.load(ctx, c, id); err != nil {
+			return dino.Dinosaur{}, fmt.Errorf("check move: %w", err)
+		}
+	}
+
+	if err := checkCapacity(l.cages[m.To]); err != nil {
+		return dino.Dinosaur{}, err
+	}
+
+	d, ok := moved[m.DinoID]
+	if !ok {
+		var err error
+		d, err = c.dino.Get(ctx, m.DinoID)
+		if err != nil {
+			return dino.Dinosaur{}, fmt.Errorf("check move: unable to fetch dino: %w", err)
+		}
+	}
+
+	if d.CageID != m.From {
+		return dino.Dinosaur{}, core.ErrInvalidCageDinoNotCaged
+	}
+
+	vs, err := l.violations(ctx, c, m.To, d)
+	if err != nil {
+		return dino.Dinosaur{}, fmt.Errorf("check move: %w", err)
+	}
+	if len(vs) > 0 {
+		return dino.Dinosaur{}, &cohabitation.RuleError{Violations: vs}
+	}
+	return d, nil
+}
@@ -0,0 +1,171 @@
+package cagedb_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/lenguti/jppp/business/core"
+	"github.com/lenguti/jppp/business/core/cage"
+	"github.com/lenguti/jppp/business/core/cage/stores/cagedb"
+	"github.com/lenguti/jppp/business/core/cohabitation"
+	"github.com/lenguti/jppp/business/core/cohabitation/stores/cohabitationdb"
+	"github.com/lenguti/jppp/business/core/dino"
+	"github.com/lenguti/jppp/business/core/dino/stores/dinodb"
+	"github.com/lenguti/jppp/business/core/species"
+	"github.com/lenguti/jppp/business/core/species/stores/speciesdb"
+	"github.com/lenguti/jppp/business/data/db"
+	"github.com/lenguti/jppp/business/data/dbtest"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mismatchedCagesQuery - returns the cages whose current capacity differs from the dinos in them or
+// from their open placements.
+const mismatchedCagesQuery = `
+	SELECT c.id
+	FROM cage c
+	WHERE c.current_capacity <> (SELECT count(*) FROM dinosaur d WHERE d.cage_id = c.id)
+	OR c.current_capacity <> (SELECT count(*) FROM placement p WHERE p.cage_id = c.id AND p.released_at IS NULL)
+	`
+
+func TestConcurrentPlacements(t *testing.T) {
+	ctx := context.Background()
+	log := zerolog.Nop()
+
+	const (
+		capacity = 10
+		attempts = 300
+	)
+
+	setup := func(t *testing.T) (*db.DB, *cage.Core, *dino.Core) {
+		t.Helper()
+		d := dbtest.New(t)
+		sc := species.NewCore(speciesdb.NewStore(d), log, species.Config{})
+		dc := dino.NewCore(dinodb.NewStore(d), log, sc)
+		rc := cohabitation.NewCore(cohabitationdb.NewStore(d), log, sc, cohabitation.Config{})
+		return d, cage.NewCore(cagedb.NewStore(d), log, dc, rc), dc
+	}
+	newDinos := func(t *testing.T, dc *dino.Core) []uuid.UUID {
+		t.Helper()
+		ids := make([]uuid.UUID, 0, attempts)
+		for i := 0; i < attempts; i++ {
+			d, err := dc.Create(ctx, dino.NewDino{Name: "Littlefoot", Species: dino.DinoSpeciesBrachiosaurus, Diet: dino.DietTypeHerbivore})
+			require.NoError(t, err)
+			ids = append(ids, d.ID)
+		}
+		return ids
+	}
+	// race - runs fn for every dino at once, returning how many succeeded. Losers must be refused with
+	// a conflict or a full cage.
+	race := func(t *testing.T, ids []uuid.UUID, fn func(id uuid.UUID) error) int {
+		t.Helper()
+		var (
+			wg  sync.WaitGroup
+			mu  sync.Mutex
+			won int
+		)
+		for _, id := range ids {
+			wg.Add(1)
+			go func(id uuid.UUID) {
+				defer wg.Done()
+				err := fn(id)
+				switch {
+				case err == nil:
+					mu.Lock()
+					won++
+					mu.Unlock()
+				case errors.Is(err, core.ErrConflict), errors.Is(err, core.ErrInvalidCageAtCapacity):
+				default:
+					t.Errorf("unexpected error: %v", err)
+				}
+			}(id)
+		}
+		wg.Wait()
+		return won
+	}
+	validate := func(t *testing.T, d *db.DB, cc *cage.Core, id uuid.UUID, want int) {
+		t.Helper()
+		got, err := cc.Get(ctx, id)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, got.CurrentCapacity, got.Capacity)
+		assert.Equal(t, want, got.CurrentCapacity)
+		var mismatched []string
+		require.NoError(t, d.List(ctx, &mismatched, mismatchedCagesQuery))
+		assert.Empty(t, mismatched)
+	}
+
+	t.Run("parallel adds never exceed capacity", func(t *testing.T) {
+		// Setup.
+		d, cc, dc := setup(t)
+		cge, err := cc.Create(ctx, cage.NewCage{Type: cage.CageTypeHerbivore, Capacity: capacity, Status: cage.CageStatusActive})
+		require.NoError(t, err)
+		ids := newDinos(t, dc)
+
+		// Execute.
+		added := race(t, ids, func(id uuid.UUID) error {
+			_, err := cc.AddDino(ctx, cge.ID, id, 0)
+			return err
+		})
+
+		// Validate.
+		validate(t, d, cc, cge.ID, added)
+	})
+
+	t.Run("parallel transfers never exceed capacity", func(t *testing.T) {
+		// Setup.
+		d, cc, dc := setup(t)
+		to, err := cc.Create(ctx, cage.NewCage{Type: cage.CageTypeHerbivore, Capacity: capacity, Status: cage.CageStatusActive})
+		require.NoError(t, err)
+		ids := newDinos(t, dc)
+		from := map[uuid.UUID]uuid.UUID{}
+		for i := 0; i < attempts; i += capacity {
+			src, err := cc.Create(ctx, cage.NewCage{Type: cage.CageTypeHerbivore, Capacity: capacity, Status: cage.CageStatusActive})
+			require.NoError(t, err)
+			for _, id := range ids[i : i+capacity] {
+				_, err := cc.AddDino(ctx, src.ID, id, 0)
+				require.NoError(t, err)
+				from[id] = src.ID
+			}
+		}
+
+		// Execute.
+		moved := race(t, ids, func(id uuid.UUID) error {
+			_, err := cc.TransferDino(ctx, from[id], to.ID, id)
+			return err
+		})
+
+		// Validate.
+		validate(t, d, cc, to.ID, moved)
+	})
+}
+
+func TestClosedDB(t *testing.T) {
+	ctx := context.Background()
+	s := cagedb.NewStore(dbtest.Closed(t))
+	cge := cage.Cage{ID: uuid.New(), Version: 2}
+	dinoID := uuid.NewString()
+
+	tests := []struct {
+		name string
+		fn   func() error
+	}{
+		{"add dino", func() error { return s.AddDino(ctx, cge, dinoID) }},
+		{"remove dino", func() error { return s.RemoveDino(ctx, cge, dinoID) }},
+		{"transfer dino", func() error { return s.TransferDino(ctx, cge, cge, dinoID) }},
+		{"apply", func() error { return s.Apply(ctx, []cage.Step{{Cage: cge}}) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Execute.
+			var err error
+			require.NotPanics(t, func() { err = tt.fn() })
+
+			// Validate.
+			assert.ErrorContains(t, err, tt.name+": failed to begin tx")
+		})
+	}
+}
@@ -13,20 +13,27 @@ type dbCage struct {
 	Capacity        int    `db:"capacity"`
 	CurrentCapacity int    `db:"current_capacity"`
 	Status          string `db:"status"`
+	Version         int    `db:"version"`
 	CreatedAt       int64  `db:"created_at"`
 	UpdateAt        int64  `db:"updated_at"`
+	DeletedAt       *int64 `db:"deleted_at"`
 }
 
 func toDBCage(c cage.Cage) dbCage {
-	return dbCage{
+	dbc := dbCage{
 		ID:              c.ID.String(),
 		Type:            c.Type.String(),
 		Capacity:        c.Capacity,
 		CurrentCapacity: c.CurrentCapacity,
 		Status:          c.Status.String(),
+		Version:         c.Version,
 		CreatedAt:       c.CreatedAt.Unix(),
 		UpdateAt:        c.UpdatedAt.Unix(),
 	}
+	if !c.DeletedAt.IsZero() {
+		dbc.DeletedAt = toInt64Ptr(c.DeletedAt.Unix())
+	}
+	return dbc
 }
 
 func toCoreCages(dbcages []dbCage) []cage.Cage {
@@ -38,13 +45,44 @@ func toCoreCages(dbcages []dbCage) []cage.Cage {
 }
 
 func toCoreCage(dbc dbCage) cage.Cage {
-	return cage.Cage{
+	c := cage.Cage{
 		ID:              uuid.MustParse(dbc.ID),
 		Type:            cage.Type(dbc.Type),
 		Capacity:        dbc.Capacity,
 		CurrentCapacity: dbc.CurrentCapacity,
 		Status:          cage.Status(dbc.Status),
+		Version:         dbc.Version,
 		CreatedAt:       time.Unix(dbc.CreatedAt, 0),
 		UpdatedAt:       time.Unix(dbc.UpdateAt, 0),
 	}
+	if dbc.DeletedAt != nil {
+		c.DeletedAt = time.Unix(*dbc.DeletedAt, 0)
+	}
+	return c
+}
+
+func toInt64Ptr(v int64) *int64 {
+	return &v
+}
+
+type dbSummary struct {
+	Type     string `db:"type"`
+	Status   string `db:"status"`
+	Cages    int    `db:"cages"`
+	Capacity int    `db:"capacity"`
+	Occupied int    `db:"occupied"`
+}
+
+func toCoreSummaries(dbsums []dbSummary) []cage.Summary {
+	sums := make([]cage.Summary, 0, len(dbsums))
+	for _, v := range dbsums {
+		sums = append(sums, cage.Summary{
+			Type:     cage.Type(v.Type),
+			Status:   cage.Status(v.Status),
+			Cages:    v.Cages,
+			Capacity: v.Capacity,
+			Occupied: v.Occupied,
+		})
+	}
+	return sums
 }
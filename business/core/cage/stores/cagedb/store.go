@@ -8,25 +8,34 @@ import (
 	"strings"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
 	"github.com/lenguti/jppp/business/core"
+	"github.com/lenguti/jppp/business/core/audit"
+	"github.com/lenguti/jppp/business/core/audit/stores/auditdb"
 	"github.com/lenguti/jppp/business/core/cage"
+	"github.com/lenguti/jppp/business/core/placement/stores/placementdb"
 	"github.com/lenguti/jppp/business/data/db"
 )
 
 // Store - manages the set of apis for cage database access.
 type Store struct {
-	db *db.DB
+	db         *db.DB
+	audit      *auditdb.Store
+	placements *placementdb.Store
 }
 
 // NewStore - constructs the api for data access.
 func NewStore(db *db.DB) *Store {
 	return &Store{
-		db: db,
+		db:         db,
+		audit:      auditdb.NewStore(db),
+		placements: placementdb.NewStore(db),
 	}
 }
 
 // Create - will insert a new cage record.
-func (s *Store) Create(ctx context.Context, c cage.Cage) error {
+func (s *Store) Create(ctx context.Context, c cage.Cage, recs ...audit.Record) error {
 	dbCage := toDBCage(c)
 	const q = `
 	INSERT INTO cage (
@@ -35,6 +44,7 @@ func (s *Store) Create(ctx context.Context, c cage.Cage) error {
 		capacity,
 		current_capacity,
 		status,
+		version,
 		created_at,
 		updated_at
 	) VALUES (
@@ -43,31 +53,71 @@ func (s *Store) Create(ctx context.Context, c cage.Cage) error {
 		:capacity,
 		:current_capacity,
 		:status,
+		:version,
 		:created_at,
 		:updated_at
 	)
 	`
-	if err := s.db.Exec(ctx, q, dbCage); err != nil {
+	if err := s.audit.ExecOne(ctx, q, dbCage, recs...); err != nil {
 		return fmt.Errorf("create: failed to create cage: %w", err)
 	}
 	return nil
 }
 
-// UpdateStatus - will update the status of a cage.
-func (s *Store) UpdateStatus(ctx context.Context, id, status string, ts time.Time) error {
+// UpdateStatus - will update the status of a cage, provided it is still at the version preceding the given one.
+func (s *Store) UpdateStatus(ctx context.Context, id, status string, version int, ts time.Time, recs ...audit.Record) error {
 	const q = `
 	UPDATE cage
 	SET
 	status = :status,
+	version = :version,
 	updated_at = :updated_at
 	WHERE id = :id
+	AND version = :version - 1
 	`
-	if err := s.db.Exec(ctx, q, map[string]any{"status": status, "updated_at": ts.Unix(), "id": id}); err != nil {
+	if err := s.audit.ExecOne(ctx, q, map[string]any{"status": status, "version": version, "updated_at": ts.Unix(), "id": id}, recs...); err != nil {
 		return fmt.Errorf("update status: failed to update cage status: %w", err)
 	}
 	return nil
 }
 
+// Delete - will soft delete an empty cage, provided it is still at the version preceding the given one.
+func (s *Store) Delete(ctx context.Context, id string, version int, ts time.Time, recs ...audit.Record) error {
+	const q = `
+	UPDATE cage
+	SET
+	deleted_at = :deleted_at,
+	version = :version,
+	updated_at = :updated_at
+	WHERE id = :id
+	AND version = :version - 1
+	AND current_capacity = 0
+	AND deleted_at IS NULL
+	`
+	if err := s.audit.ExecOne(ctx, q, map[string]any{"deleted_at": ts.Unix(), "version": version, "updated_at": ts.Unix(), "id": id}, recs...); err != nil {
+		return fmt.Errorf("delete: failed to delete cage: %w", err)
+	}
+	return nil
+}
+
+// Restore - will restore a soft deleted cage, provided it is still at the version preceding the given one.
+func (s *Store) Restore(ctx context.Context, id string, version int, ts time.Time, recs ...audit.Record) error {
+	const q = `
+	UPDATE cage
+	SET
+	deleted_at = NULL,
+	version = :version,
+	updated_at = :updated_at
+	WHERE id = :id
+	AND version = :version - 1
+	AND deleted_at IS NOT NULL
+	`
+	if err := s.audit.ExecOne(ctx, q, map[string]any{"version": version, "updated_at": ts.Unix(), "id": id}, recs...); err != nil {
+		return fmt.Errorf("restore: failed to restore cage: %w", err)
+	}
+	return nil
+}
+
 // Get - will fetch a cage by its id.
 func (s *Store) Get(ctx context.Context, id string) (cage.Cage, error) {
 	const q = `
@@ -85,63 +135,237 @@ func (s *Store) Get(ctx context.Context, id string) (cage.Cage, error) {
 	return toCoreCage(out), nil
 }
 
-// AddDino - will update the cage current capacity, updated ts and the dinos cage identifier.
-func (s *Store) AddDino(ctx context.Context, c cage.Cage, dinoID string) error {
-	dbCage := toDBCage(c)
-	const cageQuery = `
+// addDinoCageQuery - takes a spot in a cage that is still active, has room and is still at the
+// version the caller observed. Every add and removal bumps the version, so the dinos the caller
+// checked the cohabitation rules against are still the ones in the cage.
+const addDinoCageQuery = `
+	UPDATE cage
+	SET
+	current_capacity = current_capacity + 1,
+	version = version + 1,
+	updated_at = $1
+	WHERE id = $2
+	AND status = $3
+	AND version = $4
+	AND current_capacity < capacity
+	`
+
+// removeDinoCageQuery - frees a spot in a cage that is still at the version the caller observed.
+const removeDinoCageQuery = `
 	UPDATE cage
 	SET
-	current_capacity = $1,
+	current_capacity = current_capacity - 1,
+	version = version + 1,
+	updated_at = $1
+	WHERE id = $2
+	AND version = $3
+	AND current_capacity > 0
+	`
+
+// addDinoQuery - moves a dino that is not in a cage, nor deleted, into a cage.
+const addDinoQuery = `
+	UPDATE dinosaur
+	SET
+	cage_id = $1,
+	version = version + 1,
 	updated_at = $2
 	WHERE id = $3
+	AND cage_id IS NULL
+	AND deleted_at IS NULL
 	`
-	const dinoQuery = `
+
+// transferDinoQuery - moves a dino that is still in the source cage into another cage.
+const transferDinoQuery = `
 	UPDATE dinosaur
 	SET
 	cage_id = $1,
+	version = version + 1,
+	updated_at = $2
+	WHERE id = $3
+	AND cage_id = $4
+	`
+
+// updateStatusCageQuery - sets the status of a cage that is still at the version the caller observed.
+const updateStatusCageQuery = `
+	UPDATE cage
+	SET
+	status = $1,
+	version = version + 1,
 	updated_at = $2
 	WHERE id = $3
+	AND version = $4
 	`
-	tx := s.db.BeginTx(ctx)
+
+// AddDino - will update the cage current capacity, updated ts and the dinos cage identifier.
+// The cage is only updated if it is still active, has room and is still at the version the
+// caller observed.
+func (s *Store) AddDino(ctx context.Context, c cage.Cage, dinoID string, recs ...audit.Record) error {
+	tx, err := s.db.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("add dino: failed to begin tx: %w", err)
+	}
 	defer tx.Rollback()
-	tx.MustExecContext(ctx, cageQuery, dbCage.CurrentCapacity, dbCage.UpdateAt, dbCage.ID)
-	tx.MustExecContext(ctx, dinoQuery, dbCage.ID, dbCage.UpdateAt, dinoID)
+	if err := s.addDino(ctx, tx, c, dinoID); err != nil {
+		return fmt.Errorf("add dino: %w", err)
+	}
+	if err := s.audit.Insert(ctx, tx, recs...); err != nil {
+		return fmt.Errorf("add dino: %w", err)
+	}
 	if err := s.db.CommitTx(tx); err != nil {
 		return fmt.Errorf("add dino: failed to commit tx: %w", err)
 	}
 	return nil
 }
 
-// RemoveDino - will update the cage current capacity, updated ts and the dinos cage identifier
-func (s *Store) RemoveDino(ctx context.Context, c cage.Cage, dinoID string) error {
+// RemoveDino - will update the cage current capacity, updated ts and the dinos cage identifier.
+// The cage is only updated if it is still at the version the caller observed and the dino is still in it.
+func (s *Store) RemoveDino(ctx context.Context, c cage.Cage, dinoID string, recs ...audit.Record) error {
 	dbCage := toDBCage(c)
-	const cageQuery = `
-	UPDATE cage
-	SET
-	current_capacity = $1,
-	updated_at = $2
-	WHERE id = $3
-	`
 	const dinoQuery = `
 	UPDATE dinosaur
 	SET
 	cage_id = NULL,
+	version = version + 1,
 	updated_at = $1
 	WHERE id = $2
+	AND cage_id = $3
 	`
-	tx := s.db.BeginTx(ctx)
+	tx, err := s.db.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("remove dino: failed to begin tx: %w", err)
+	}
 	defer tx.Rollback()
-	tx.MustExecContext(ctx, cageQuery, dbCage.CurrentCapacity, dbCage.UpdateAt, dbCage.ID)
-	tx.MustExecContext(ctx, dinoQuery, dbCage.UpdateAt, dinoID)
+	if err := s.execOne(ctx, tx, removeDinoCageQuery, dbCage.UpdateAt, dbCage.ID, dbCage.Version-1); err != nil {
+		return fmt.Errorf("remove dino: failed to update cage: %w", err)
+	}
+	if err := s.execOne(ctx, tx, dinoQuery, dbCage.UpdateAt, dinoID, dbCage.ID); err != nil {
+		return fmt.Errorf("remove dino: failed to update dino: %w", err)
+	}
+	if err := s.placements.Release(ctx, tx, dbCage.ID, dinoID, c.UpdatedAt); err != nil {
+		return fmt.Errorf("remove dino: %w", err)
+	}
+	if err := s.audit.Insert(ctx, tx, recs...); err != nil {
+		return fmt.Errorf("remove dino: %w", err)
+	}
 	if err := s.db.CommitTx(tx); err != nil {
 		return fmt.Errorf("remove dino: failed to commit tx: %w", err)
 	}
 	return nil
 }
 
-// List - will list all cages.
-func (s *Store) List(ctx context.Context, filters ...core.Filter) ([]cage.Cage, error) {
-	q, vals := listClauseBuilder(filters...)
+// TransferDino - will move the dino between cages, updating both cages and the dino in a single tx.
+// Cages are updated in id order so concurrent transfers between the same cages cannot deadlock.
+func (s *Store) TransferDino(ctx context.Context, from, to cage.Cage, dinoID string, recs ...audit.Record) error {
+	tx, err := s.db.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("transfer dino: failed to begin tx: %w", err)
+	}
+	defer tx.Rollback()
+	if err := s.transferDino(ctx, tx, from, to, dinoID); err != nil {
+		return fmt.Errorf("transfer dino: %w", err)
+	}
+	if err := s.audit.Insert(ctx, tx, recs...); err != nil {
+		return fmt.Errorf("transfer dino: %w", err)
+	}
+	if err := s.db.CommitTx(tx); err != nil {
+		return fmt.Errorf("transfer dino: failed to commit tx: %w", err)
+	}
+	return nil
+}
+
+// Apply - will apply the steps in order within a single tx, rolling all of them back when one fails.
+// Steps touch cages in the order given, so concurrent batches over the same cages may deadlock, in
+// which case the database aborts one of them.
+func (s *Store) Apply(ctx context.Context, steps []cage.Step, recs ...audit.Record) error {
+	tx, err := s.db.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("apply: failed to begin tx: %w", err)
+	}
+	defer tx.Rollback()
+	for i, st := range steps {
+		switch {
+		case st.DinoID == uuid.Nil:
+			err = s.updateStatus(ctx, tx, st.Cage)
+		case st.From == nil:
+			err = s.addDino(ctx, tx, st.Cage, st.DinoID.String())
+		default:
+			err = s.transferDino(ctx, tx, *st.From, st.Cage, st.DinoID.String())
+		}
+		if err != nil {
+			return fmt.Errorf("apply: step %d: %w", i+1, err)
+		}
+	}
+	if err := s.audit.Insert(ctx, tx, recs...); err != nil {
+		return fmt.Errorf("apply: %w", err)
+	}
+	if err := s.db.CommitTx(tx); err != nil {
+		return fmt.Errorf("apply: failed to commit tx: %w", err)
+	}
+	return nil
+}
+
+// updateStatus - sets the status of the cage within the tx, provided it is still at the version
+// preceding the given one.
+func (s *Store) updateStatus(ctx context.Context, tx *sqlx.Tx, c cage.Cage) error {
+	dbCage := toDBCage(c)
+	if err := s.execOne(ctx, tx, updateStatusCageQuery, dbCage.Status, dbCage.UpdateAt, dbCage.ID, dbCage.Version-1); err != nil {
+		return fmt.Errorf("update status: failed to update cage: %w", err)
+	}
+	return nil
+}
+
+// addDino - takes a spot in the cage for the dino within the tx and opens its placement.
+func (s *Store) addDino(ctx context.Context, tx *sqlx.Tx, c cage.Cage, dinoID string) error {
+	dbCage := toDBCage(c)
+	if err := s.execOne(ctx, tx, addDinoCageQuery, dbCage.UpdateAt, dbCage.ID, cage.CageStatusActive, dbCage.Version-1); err != nil {
+		return fmt.Errorf("add dino: failed to update cage: %w", err)
+	}
+	if err := s.execOne(ctx, tx, addDinoQuery, dbCage.ID, dbCage.UpdateAt, dinoID); err != nil {
+		return fmt.Errorf("add dino: failed to update dino: %w", err)
+	}
+	if err := s.placements.Assign(ctx, tx, dbCage.ID, dinoID, c.UpdatedAt); err != nil {
+		return fmt.Errorf("add dino: %w", err)
+	}
+	return nil
+}
+
+// transferDino - moves the dino between cages within the tx, closing its placement in the source
+// cage and opening one in the destination. Cages are updated in id order.
+func (s *Store) transferDino(ctx context.Context, tx *sqlx.Tx, from, to cage.Cage, dinoID string) error {
+	dbFrom, dbTo := toDBCage(from), toDBCage(to)
+	release := func() error {
+		return s.execOne(ctx, tx, removeDinoCageQuery, dbFrom.UpdateAt, dbFrom.ID, dbFrom.Version-1)
+	}
+	take := func() error {
+		return s.execOne(ctx, tx, addDinoCageQuery, dbTo.UpdateAt, dbTo.ID, cage.CageStatusActive, dbTo.Version-1)
+	}
+	steps := []func() error{release, take}
+	if dbTo.ID < dbFrom.ID {
+		steps = []func() error{take, release}
+	}
+	for _, step := range steps {
+		if err := step(); err != nil {
+			return fmt.Errorf("transfer dino: failed to update cage: %w", err)
+		}
+	}
+	if err := s.execOne(ctx, tx, transferDinoQuery, dbTo.ID, dbTo.UpdateAt, dinoID, dbFrom.ID); err != nil {
+		return fmt.Errorf("transfer dino: failed to update dino: %w", err)
+	}
+	if err := s.placements.Release(ctx, tx, dbFrom.ID, dinoID, from.UpdatedAt); err != nil {
+		return fmt.Errorf("transfer dino: %w", err)
+	}
+	if err := s.placements.Assign(ctx, tx, dbTo.ID, dinoID, to.UpdatedAt); err != nil {
+		return fmt.Errorf("transfer dino: %w", err)
+	}
+	return nil
+}
+
+// List - will list a page of cages.
+func (s *Store) List(ctx context.Context, page core.Page, filters ...core.Filter) ([]cage.Cage, error) {
+	q, vals, err := listClauseBuilder(page, filters...)
+	if err != nil {
+		return nil, fmt.Errorf("list: failed to build query: %w", err)
+	}
 	var out []dbCage
 	if err := s.db.List(ctx, &out, q, vals...); err != nil {
 		return nil, fmt.Errorf("list: failed to list cages: %w", err)
@@ -149,30 +373,91 @@ func (s *Store) List(ctx context.Context, filters ...core.Filter) ([]cage.Cage,
 	return toCoreCages(out), nil
 }
 
-func listClauseBuilder(filters ...core.Filter) (string, []string) {
+// Summarize - will count the cages that are not deleted by type and status.
+func (s *Store) Summarize(ctx context.Context) ([]cage.Summary, error) {
+	const q = `
+	SELECT
+		type,
+		status,
+		COUNT(*) AS cages,
+		COALESCE(SUM(capacity), 0) AS capacity,
+		COALESCE(SUM(current_capacity), 0) AS occupied
+	FROM cage
+	WHERE deleted_at IS NULL
+	GROUP BY type, status
+	ORDER BY type, status
+	`
+	var out []dbSummary
+	if err := s.db.List(ctx, &out, q); err != nil {
+		return nil, fmt.Errorf("summarize: failed to summarize cages: %w", err)
+	}
+	return toCoreSummaries(out), nil
+}
+
+func listClauseBuilder(page core.Page, filters ...core.Filter) (string, []string, error) {
 	const q = `
 	SELECT *
 	FROM cage
 	`
 
-	if len(filters) == 0 {
-		return q, nil
+	filterMap := map[string]string{
+		"type":            "type",
+		"status":          "status",
+		"capacity":        "capacity",
+		"currentCapacity": "current_capacity",
+		"createdAt":       "created_at",
+		"updatedAt":       "updated_at",
+		"deletedAt":       "deleted_at",
 	}
 
-	filterMap := map[string]string{
-		"status": "status = $%d",
+	sortMap := map[string]string{
+		core.SortCreatedAt: "created_at",
+		core.SortUpdatedAt: "updated_at",
+	}
+
+	conds, vals, err := db.FilterClause(filters, filterMap, 1)
+	if err != nil {
+		return "", nil, fmt.Errorf("list clause builder: %w", err)
+	}
+
+	field := page.Sort.Field
+	if field == "" {
+		field = core.SortCreatedAt
+	}
+	column, ok := sortMap[field]
+	if !ok {
+		return "", nil, fmt.Errorf("list clause builder: invalid sort field %s", field)
+	}
+
+	cond, tail, pageVals, err := db.PageClause(page, column, len(vals)+1)
+	if err != nil {
+		return "", nil, fmt.Errorf("list clause builder: %w", err)
+	}
+	if cond != "" {
+		conds = append(conds, cond)
+		vals = append(vals, pageVals...)
 	}
 
-	vals := make([]string, 0, len(filters))
 	var b strings.Builder
 	b.WriteString(q)
-	b.WriteString("WHERE ")
-	for i := 0; i < len(filters); i++ {
-		c, ok := filterMap[filters[i].Key]
-		if ok {
-			b.WriteString(fmt.Sprintf(c, i+1))
-			vals = append(vals, filters[i].Value)
-		}
+	if len(conds) > 0 {
+		b.WriteString("WHERE ")
+		b.WriteString(strings.Join(conds, "\n\tAND "))
+		b.WriteString("\n\t")
 	}
-	return b.String(), vals
+	b.WriteString(tail)
+	return b.String(), vals, nil
+}
+
+// execOne - executes a conditional statement within the tx and reports a conflict when it
+// did not affect exactly one row.
+func (s *Store) execOne(ctx context.Context, tx *sqlx.Tx, q string, args ...any) error {
+	n, err := s.db.ExecTx(ctx, tx, q, args...)
+	if err != nil {
+		return fmt.Errorf("exec one: %w", err)
+	}
+	if n != 1 {
+		return core.ErrConflict
+	}
+	return nil
 }
@@ -5,6 +5,7 @@ import (
 
 	"github.com/lenguti/jppp/business/core"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestListClauseBuilder(t *testing.T) {
@@ -12,9 +13,11 @@ func TestListClauseBuilder(t *testing.T) {
 		want := `
 	SELECT *
 	FROM cage
+	ORDER BY created_at ASC, id ASC
 	`
 		var wantVals []string
-		got, gotVals := listClauseBuilder()
+		got, gotVals, err := listClauseBuilder(core.Page{})
+		require.NoError(t, err)
 		assert.Equal(t, want, got)
 		assert.Equal(t, wantVals, gotVals)
 	})
@@ -23,11 +26,63 @@ func TestListClauseBuilder(t *testing.T) {
 		want := `
 	SELECT *
 	FROM cage
-	WHERE status = $1`
+	WHERE status = $1
+	ORDER BY created_at ASC, id ASC
+	`
 
 		wantVals := []string{"ACTIVE"}
-		got, gotVals := listClauseBuilder(core.Filter{Key: "status", Value: "ACTIVE"})
+		got, gotVals, err := listClauseBuilder(core.Page{}, core.Filter{Key: "status", Value: "ACTIVE"})
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+		assert.Equal(t, wantVals, gotVals)
+	})
+
+	t.Run("multiple filters", func(t *testing.T) {
+		want := `
+	SELECT *
+	FROM cage
+	WHERE capacity >= $1
+	AND type IN ($2, $3)
+	ORDER BY created_at ASC, id ASC
+	`
+
+		wantVals := []string{"5", "CARNIVORE", "HERBIVORE"}
+		got, gotVals, err := listClauseBuilder(core.Page{},
+			core.Filter{Key: "capacity", Op: core.OpGte, Value: "5"},
+			core.Filter{Key: "type", Op: core.OpIn, Values: []string{"CARNIVORE", "HERBIVORE"}},
+		)
+		require.NoError(t, err)
 		assert.Equal(t, want, got)
 		assert.Equal(t, wantVals, gotVals)
 	})
+
+	t.Run("unknown filter", func(t *testing.T) {
+		_, _, err := listClauseBuilder(core.Page{}, core.Filter{Key: "color", Value: "red"})
+		assert.ErrorIs(t, err, core.ErrInvalidFilter)
+	})
+
+	t.Run("sorted page after cursor", func(t *testing.T) {
+		want := `
+	SELECT *
+	FROM cage
+	WHERE status = $1
+	AND (updated_at, id) < ($2, $3)
+	ORDER BY updated_at DESC, id DESC
+	LIMIT 11
+	`
+
+		sort := core.Sort{Field: core.SortUpdatedAt, Desc: true}
+		page := core.Page{Limit: 11, Sort: sort, Cursor: core.EncodeCursor(sort, "1690000000", "6f1f6bde-92c4-4d7a-a3ec-3a1e8a1b4c2d")}
+		wantVals := []string{"ACTIVE", "1690000000", "6f1f6bde-92c4-4d7a-a3ec-3a1e8a1b4c2d"}
+		got, gotVals, err := listClauseBuilder(page, core.Filter{Key: "status", Value: "ACTIVE"})
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+		assert.Equal(t, wantVals, gotVals)
+	})
+
+	t.Run("cursor for another sort", func(t *testing.T) {
+		page := core.Page{Sort: core.Sort{Field: core.SortCreatedAt}, Cursor: core.EncodeCursor(core.Sort{Field: core.SortUpdatedAt}, "1", "6f1f6bde-92c4-4d7a-a3ec-3a1e8a1b4c2d")}
+		_, _, err := listClauseBuilder(page)
+		assert.ErrorIs(t, err, core.ErrInvalidCursor)
+	})
 }
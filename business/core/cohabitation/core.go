@@ -0,0 +1,139 @@
+// Package cohabitation decides which dinosaurs may share a cage, through an ordered set of
+// configurable rules.
+package cohabitation
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lenguti/jppp/business/core"
+	"github.com/lenguti/jppp/business/core/dino"
+	"github.com/lenguti/jppp/business/core/species"
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel"
+)
+
+var tracer = otel.Tracer("github.com/lenguti/jppp/business/core/cohabitation")
+
+// Default engine settings, used when the related config field is not set.
+const (
+	defaultCacheTTL = time.Minute
+)
+
+// Storer - represents the source of the rule set.
+//
+// List returns the definitions of the rules in the order they are checked.
+type Storer interface {
+	List(ctx context.Context) ([]Definition, error)
+}
+
+// Config - represents the engine settings. The rule set is cached and reloaded from the store
+// once older than CacheTTL.
+type Config struct {
+	CacheTTL time.Duration
+}
+
+// withDefaults - returns the config with its unset fields set to their default.
+func (c Config) withDefaults() Config {
+	if c.CacheTTL <= 0 {
+		c.CacheTTL = defaultCacheTTL
+	}
+	return c
+}
+
+// Core - represents the core business logic checking placements against the rule set.
+type Core struct {
+	store   Storer
+	log     zerolog.Logger
+	species *species.Core
+	cfg     Config
+
+	mu       sync.RWMutex
+	rules    []Rule
+	loadedAt time.Time
+}
+
+// NewCore - returns a new cohabitation core with all its components initialized. The species of
+// the dinos are looked up in the registry of the species core.
+func NewCore(store Storer, log zerolog.Logger, sc *species.Core, cfg Config) *Core {
+	return &Core{
+		store:   store,
+		log:     log,
+		species: sc,
+		cfg:     cfg.withDefaults(),
+	}
+}
+
+// Rules - will return the rule set, in the order rules are checked. The lock is not held while an
+// expired rule set reloads, so callers racing the reload may each load it rather than wait.
+func (c *Core) Rules(ctx context.Context) ([]Rule, error) {
+	c.mu.RLock()
+	rules, loadedAt := c.rules, c.loadedAt
+	c.mu.RUnlock()
+	if rules != nil && time.Since(loadedAt) < c.cfg.CacheTTL {
+		return rules, nil
+	}
+
+	ctx, span := tracer.Start(ctx, "cohabitation.Rules")
+	defer span.End()
+
+	defs, err := c.store.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("rules: failed to list rules: %w", err)
+	}
+	rules, err = Build(defs...)
+	if err != nil {
+		return nil, fmt.Errorf("rules: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rules, c.loadedAt = rules, time.Now()
+	return rules, nil
+}
+
+// Check - will check the dino joining a cage of the type holding the residents against every
+// rule, returning the violated ones in order.
+func (c *Core) Check(ctx context.Context, cageType string, d dino.Dinosaur, residents []dino.Dinosaur) ([]Violation, error) {
+	ctx, span := tracer.Start(ctx, "cohabitation.Check")
+	defer span.End()
+
+	rules, err := c.Rules(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("check: %w", err)
+	}
+
+	p := Placement{CageType: cageType, Residents: make([]Resident, 0, len(residents)), At: time.Now().UTC()}
+	if p.Dino, err = c.resident(ctx, d); err != nil {
+		return nil, fmt.Errorf("check: %w", err)
+	}
+	for _, v := range residents {
+		r, err := c.resident(ctx, v)
+		if err != nil {
+			return nil, fmt.Errorf("check: %w", err)
+		}
+		p.Residents = append(p.Residents, r)
+	}
+
+	vs := Evaluate(p, rules...)
+	if len(vs) > 0 {
+		c.logger(ctx).Info().Fields(map[string]any{"dino": d.ID.String(), "violations": len(vs)}).Msg("Placement violates cohabitation rules.")
+	}
+	return vs, nil
+}
+
+// resident - returns the dino along with its species.
+func (c *Core) resident(ctx context.Context, d dino.Dinosaur) (Resident, error) {
+	sp, err := c.species.Lookup(ctx, d.Species)
+	if err != nil {
+		return Resident{}, fmt.Errorf("resident: %w", err)
+	}
+	return Resident{Dino: d, Species: sp}, nil
+}
+
+// logger - returns the request scoped logger, falling back to the core logger.
+func (c *Core) logger(ctx context.Context) *zerolog.Logger {
+	return core.Logger(ctx, c.log)
+}
@@ -0,0 +1,92 @@
+package cohabitation
+
+import (
+	"fmt"
+	"time"
+)
+
+// Rule kinds.
+const (
+	KindDiet                = "diet"
+	KindCarnivoreSpecies    = "carnivore_species"
+	KindIncompatibleSpecies = "incompatible_species"
+	KindJuvenile            = "juvenile"
+	KindSolitary            = "solitary"
+)
+
+// Definition - represents a configured rule, as read from the rules file or the database.
+//
+// Pairs lists pairs of species names: the pairs of carnivores tolerating each other for
+// carnivore_species and the pairs that cannot share a cage for incompatible_species. MaxAge is the
+// age, as a duration such as "8760h", under which a dino is a juvenile for juvenile.
+type Definition struct {
+	Kind   string     `json:"kind"`
+	Pairs  [][]string `json:"pairs,omitempty"`
+	MaxAge string     `json:"max_age,omitempty"`
+}
+
+// DefaultDefinitions - returns the rules applied unless configured otherwise: the diet must match
+// the cage type and carnivores only share a cage with their own species.
+func DefaultDefinitions() []Definition {
+	return []Definition{
+		{Kind: KindDiet},
+		{Kind: KindCarnivoreSpecies},
+	}
+}
+
+// Build - returns the rules of the definitions, in the same order.
+func Build(defs ...Definition) ([]Rule, error) {
+	rules := make([]Rule, 0, len(defs))
+	for i, d := range defs {
+		r, err := d.rule()
+		if err != nil {
+			return nil, fmt.Errorf("build: rule %d: %w", i+1, err)
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+// rule - returns the rule of the definition, validating its settings.
+func (d Definition) rule() (Rule, error) {
+	switch d.Kind {
+	case KindDiet:
+		return dietRule{}, nil
+	case KindCarnivoreSpecies:
+		ps, err := d.pairs()
+		if err != nil {
+			return nil, fmt.Errorf("rule: %w", err)
+		}
+		return carnivoreSpeciesRule{tolerated: ps}, nil
+	case KindIncompatibleSpecies:
+		ps, err := d.pairs()
+		if err != nil {
+			return nil, fmt.Errorf("rule: %w", err)
+		}
+		if len(ps) == 0 {
+			return nil, fmt.Errorf("rule: %s requires pairs", d.Kind)
+		}
+		return incompatibleSpeciesRule{incompatible: ps}, nil
+	case KindJuvenile:
+		maxAge, err := time.ParseDuration(d.MaxAge)
+		if err != nil || maxAge <= 0 {
+			return nil, fmt.Errorf("rule: %s requires a positive max_age, got %q", d.Kind, d.MaxAge)
+		}
+		return juvenileRule{maxAge: maxAge}, nil
+	case KindSolitary:
+		return solitaryRule{}, nil
+	}
+	return nil, fmt.Errorf("rule: unknown kind %q", d.Kind)
+}
+
+// pairs - returns the pairs of species names of the definition.
+func (d Definition) pairs() (pairs, error) {
+	ps := pairs{}
+	for _, p := range d.Pairs {
+		if len(p) != 2 || p[0] == "" || p[1] == "" {
+			return nil, fmt.Errorf("pairs: %s pairs must hold two species, got %q", d.Kind, p)
+		}
+		ps.add(p[0], p[1])
+	}
+	return ps, nil
+}
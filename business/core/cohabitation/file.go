@@ -0,0 +1,37 @@
+package cohabitation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+var _ Storer = (*FileStore)(nil)
+
+// FileStore - represents a rule set read from a json file holding an array of definitions, such as
+// [{"kind":"diet"},{"kind":"incompatible_species","pairs":[["Triceratops","Stegosaurus"]]}].
+// The file is read on every load, so edits apply once the cached rule set expires.
+type FileStore struct {
+	path string
+}
+
+// NewFileStore - returns a new store reading the rule set from the file at path.
+func NewFileStore(path string) *FileStore {
+	return &FileStore{
+		path: path,
+	}
+}
+
+// List - will read the definitions from the file.
+func (s *FileStore) List(ctx context.Context) ([]Definition, error) {
+	b, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("list: unable to read rules file: %w", err)
+	}
+	var defs []Definition
+	if err := json.Unmarshal(b, &defs); err != nil {
+		return nil, fmt.Errorf("list: unable to decode rules file: %w", err)
+	}
+	return defs, nil
+}
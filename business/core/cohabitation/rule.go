@@ -0,0 +1,244 @@
+package cohabitation
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lenguti/jppp/business/core"
+	"github.com/lenguti/jppp/business/core/dino"
+	"github.com/lenguti/jppp/business/core/species"
+)
+
+// Resident - represents a dino along with its species.
+type Resident struct {
+	Dino    dino.Dinosaur
+	Species species.Species
+}
+
+// Placement - represents a dino about to join a cage of the given type, which holds the residents.
+// At is the time of the placement, which ages are computed at.
+type Placement struct {
+	CageType  string
+	Dino      Resident
+	Residents []Resident
+	At        time.Time
+}
+
+// Violation - represents a rule a placement fails, explaining why. DinoIDs holds the residents the
+// dino conflicts with, if the rule concerns them.
+type Violation struct {
+	Rule        string
+	Err         core.Error
+	Explanation string
+	DinoIDs     []uuid.UUID
+}
+
+// Rule - represents a cohabitation rule, Check returns nil when the placement satisfies it.
+type Rule interface {
+	Kind() string
+	Check(p Placement) *Violation
+}
+
+// Evaluate - returns the violations of the placement, checking every rule in order.
+func Evaluate(p Placement, rules ...Rule) []Violation {
+	var vs []Violation
+	for _, r := range rules {
+		if v := r.Check(p); v != nil {
+			vs = append(vs, *v)
+		}
+	}
+	return vs
+}
+
+// RuleError - represents a placement refused by the cohabitation rules, along with every violation.
+type RuleError struct {
+	Violations []Violation
+}
+
+// Error - satisfies the error interface, listing the explanation of every violation.
+func (e *RuleError) Error() string {
+	msgs := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		msgs = append(msgs, v.Explanation)
+	}
+	return fmt.Sprintf("%s: %s", e.Unwrap(), strings.Join(msgs, " "))
+}
+
+// Unwrap - exposes the error of the first violation to errors.Is and errors.As.
+func (e *RuleError) Unwrap() error {
+	return e.Violations[0].Err
+}
+
+// dietRule - requires the dino diet to match the cage type.
+type dietRule struct{}
+
+func (dietRule) Kind() string {
+	return KindDiet
+}
+
+func (dietRule) Check(p Placement) *Violation {
+	if p.Dino.Dino.Diet.String() == p.CageType {
+		return nil
+	}
+	return &Violation{
+		Rule:        KindDiet,
+		Err:         core.ErrInvalidCageInvalidType,
+		Explanation: fmt.Sprintf("%s is a %s and cannot be placed in a %s cage.", p.Dino.Dino.Name, p.Dino.Dino.Diet, p.CageType),
+	}
+}
+
+// carnivoreSpeciesRule - requires carnivores to only share a cage with their own species, or a
+// species they tolerate.
+type carnivoreSpeciesRule struct {
+	tolerated pairs
+}
+
+func (carnivoreSpeciesRule) Kind() string {
+	return KindCarnivoreSpecies
+}
+
+func (r carnivoreSpeciesRule) Check(p Placement) *Violation {
+	conflicts := p.conflicts(func(o Resident) bool {
+		carnivore := p.Dino.Species.Diet == species.DietCarnivore || o.Species.Diet == species.DietCarnivore
+		return carnivore && o.Species.Name != p.Dino.Species.Name && !r.tolerated.has(p.Dino.Species.Name, o.Species.Name)
+	})
+	if len(conflicts) == 0 {
+		return nil
+	}
+	return &Violation{
+		Rule:        KindCarnivoreSpecies,
+		Err:         core.ErrInvalidCageInvalidSpecies,
+		Explanation: fmt.Sprintf("Carnivores only share a cage with their own species, %s the %s cannot share with %s.", p.Dino.Dino.Name, p.Dino.Species.Name, speciesOf(conflicts)),
+		DinoIDs:     ids(conflicts),
+	}
+}
+
+// incompatibleSpeciesRule - forbids the listed pairs of species from sharing a cage.
+type incompatibleSpeciesRule struct {
+	incompatible pairs
+}
+
+func (incompatibleSpeciesRule) Kind() string {
+	return KindIncompatibleSpecies
+}
+
+func (r incompatibleSpeciesRule) Check(p Placement) *Violation {
+	conflicts := p.conflicts(func(o Resident) bool {
+		return r.incompatible.has(p.Dino.Species.Name, o.Species.Name)
+	})
+	if len(conflicts) == 0 {
+		return nil
+	}
+	return &Violation{
+		Rule:        KindIncompatibleSpecies,
+		Err:         core.ErrInvalidCageIncompatibleSpecies,
+		Explanation: fmt.Sprintf("%s the %s cannot share a cage with %s.", p.Dino.Dino.Name, p.Dino.Species.Name, speciesOf(conflicts)),
+		DinoIDs:     ids(conflicts),
+	}
+}
+
+// juvenileRule - keeps juveniles, dinos younger than maxAge, apart from adults.
+type juvenileRule struct {
+	maxAge time.Duration
+}
+
+func (juvenileRule) Kind() string {
+	return KindJuvenile
+}
+
+func (r juvenileRule) Check(p Placement) *Violation {
+	juvenile := p.Dino.Dino.Juvenile(p.At, r.maxAge)
+	conflicts := p.conflicts(func(o Resident) bool {
+		return o.Dino.Juvenile(p.At, r.maxAge) != juvenile
+	})
+	if len(conflicts) == 0 {
+		return nil
+	}
+	stage, others := "an adult", "juveniles"
+	if juvenile {
+		stage, others = "a juvenile", "adults"
+	}
+	return &Violation{
+		Rule:        KindJuvenile,
+		Err:         core.ErrInvalidCageJuvenile,
+		Explanation: fmt.Sprintf("Juveniles are kept apart from adults, %s is %s and the cage holds %d %s.", p.Dino.Dino.Name, stage, len(conflicts), others),
+		DinoIDs:     ids(conflicts),
+	}
+}
+
+// solitaryRule - keeps dinos of the species flagged as solitary in the registry alone.
+type solitaryRule struct{}
+
+func (solitaryRule) Kind() string {
+	return KindSolitary
+}
+
+func (solitaryRule) Check(p Placement) *Violation {
+	conflicts := p.conflicts(func(o Resident) bool {
+		return p.Dino.Species.Solitary || o.Species.Solitary
+	})
+	if len(conflicts) == 0 {
+		return nil
+	}
+	return &Violation{
+		Rule:        KindSolitary,
+		Err:         core.ErrInvalidCageSolitary,
+		Explanation: fmt.Sprintf("Solitary species are kept alone, %s the %s cannot share with %s.", p.Dino.Dino.Name, p.Dino.Species.Name, speciesOf(conflicts)),
+		DinoIDs:     ids(conflicts),
+	}
+}
+
+// conflicts - returns the residents, other than the dino itself, the dino conflicts with.
+func (p Placement) conflicts(conflict func(o Resident) bool) []Resident {
+	var out []Resident
+	for _, o := range p.Residents {
+		if o.Dino.ID != p.Dino.Dino.ID && conflict(o) {
+			out = append(out, o)
+		}
+	}
+	return out
+}
+
+// pairs - represents a set of unordered pairs of species names.
+type pairs map[[2]string]struct{}
+
+func (ps pairs) add(a, b string) {
+	a, b = species.NormalizeName(a), species.NormalizeName(b)
+	if a > b {
+		a, b = b, a
+	}
+	ps[[2]string{a, b}] = struct{}{}
+}
+
+func (ps pairs) has(a, b string) bool {
+	if a > b {
+		a, b = b, a
+	}
+	_, ok := ps[[2]string{a, b}]
+	return ok
+}
+
+// speciesOf - returns the sorted distinct species of the residents, as a readable list.
+func speciesOf(rs []Resident) string {
+	seen := map[string]bool{}
+	var names []string
+	for _, r := range rs {
+		if !seen[r.Species.Name] {
+			seen[r.Species.Name] = true
+			names = append(names, r.Species.Name)
+		}
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+func ids(rs []Resident) []uuid.UUID {
+	out := make([]uuid.UUID, 0, len(rs))
+	for _, r := range rs {
+		out = append(out, r.Dino.ID)
+	}
+	return out
+}
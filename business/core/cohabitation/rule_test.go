@@ -0,0 +1,119 @@
+package cohabitation_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lenguti/jppp/business/core"
+	"github.com/lenguti/jppp/business/core/cohabitation"
+	"github.com/lenguti/jppp/business/core/dino"
+	"github.com/lenguti/jppp/business/core/species"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuild(t *testing.T) {
+	tests := []struct {
+		name string
+		defs []cohabitation.Definition
+		err  string
+	}{
+		{"defaults", cohabitation.DefaultDefinitions(), ""},
+		{"unknown kind", []cohabitation.Definition{{Kind: "telepathy"}}, `unknown kind "telepathy"`},
+		{"pair of one species", []cohabitation.Definition{{Kind: cohabitation.KindCarnivoreSpecies, Pairs: [][]string{{"Tyrannosaurus"}}}}, "must hold two species"},
+		{"incompatible without pairs", []cohabitation.Definition{{Kind: cohabitation.KindIncompatibleSpecies}}, "requires pairs"},
+		{"juvenile without max age", []cohabitation.Definition{{Kind: cohabitation.KindDiet}, {Kind: cohabitation.KindJuvenile, MaxAge: "-1h"}}, "rule 2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Execute.
+			rules, err := cohabitation.Build(tt.defs...)
+
+			// Validate.
+			if tt.err == "" {
+				require.NoError(t, err)
+				assert.Len(t, rules, len(tt.defs))
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	now := time.Now()
+	resident := func(name string, sp species.Species, hatchedAt time.Time) cohabitation.Resident {
+		return cohabitation.Resident{
+			Dino:    dino.Dinosaur{ID: uuid.New(), Name: name, Species: sp.Name, Diet: dino.Diet(sp.Diet), HatchedAt: hatchedAt},
+			Species: sp,
+		}
+	}
+	var (
+		rex    = species.Species{Name: dino.DinoSpeciesTyrannosaurus, Diet: species.DietCarnivore}
+		raptor = species.Species{Name: dino.DinoSpeciesVelociraptor, Diet: species.DietCarnivore}
+		trike  = species.Species{Name: dino.DinoSpeciesTriceratops, Diet: species.DietHerbivore}
+		loner  = species.Species{Name: dino.DinoSpeciesAnkylosaurus, Diet: species.DietHerbivore, Solitary: true}
+	)
+	rules, err := cohabitation.Build(
+		cohabitation.Definition{Kind: cohabitation.KindDiet},
+		cohabitation.Definition{Kind: cohabitation.KindCarnivoreSpecies},
+		cohabitation.Definition{Kind: cohabitation.KindJuvenile, MaxAge: "8760h"},
+		cohabitation.Definition{Kind: cohabitation.KindSolitary},
+	)
+	require.NoError(t, err)
+
+	t.Run("every violated rule in order", func(t *testing.T) {
+		// Setup.
+		blue := resident("Blue", raptor, now.Add(-time.Hour))
+		p := cohabitation.Placement{CageType: species.DietHerbivore, Dino: blue, Residents: []cohabitation.Resident{resident("Cera", trike, time.Time{})}, At: now}
+
+		// Execute.
+		vs := cohabitation.Evaluate(p, rules...)
+
+		// Validate.
+		require.Len(t, vs, 3)
+		assert.Equal(t, []string{cohabitation.KindDiet, cohabitation.KindCarnivoreSpecies, cohabitation.KindJuvenile}, []string{vs[0].Rule, vs[1].Rule, vs[2].Rule})
+		assert.Equal(t, core.ErrInvalidCageJuvenile, vs[2].Err)
+		assert.Equal(t, "Juveniles are kept apart from adults, Blue is a juvenile and the cage holds 1 adults.", vs[2].Explanation)
+	})
+
+	t.Run("solitary species are kept alone", func(t *testing.T) {
+		// Setup.
+		p := cohabitation.Placement{CageType: species.DietHerbivore, Dino: resident("Bumpy", loner, time.Time{}), Residents: []cohabitation.Resident{resident("Cera", trike, time.Time{})}, At: now}
+
+		// Execute.
+		vs := cohabitation.Evaluate(p, rules...)
+
+		// Validate.
+		require.Len(t, vs, 1)
+		assert.Equal(t, cohabitation.KindSolitary, vs[0].Rule)
+		assert.Equal(t, []uuid.UUID{p.Residents[0].Dino.ID}, vs[0].DinoIDs)
+	})
+
+	t.Run("dino is not checked against itself", func(t *testing.T) {
+		// Setup.
+		rexy := resident("Rexy", rex, time.Time{})
+		p := cohabitation.Placement{CageType: species.DietCarnivore, Dino: rexy, Residents: []cohabitation.Resident{rexy, resident("Rexy II", rex, time.Time{})}, At: now}
+
+		// Execute.
+		vs := cohabitation.Evaluate(p, rules...)
+
+		// Validate.
+		assert.Empty(t, vs)
+	})
+
+	t.Run("rule error unwraps to the first violation", func(t *testing.T) {
+		// Setup.
+		p := cohabitation.Placement{CageType: species.DietCarnivore, Dino: resident("Blue", raptor, time.Time{}), Residents: []cohabitation.Resident{resident("Rexy", rex, time.Time{})}, At: now}
+
+		// Execute.
+		err := error(&cohabitation.RuleError{Violations: cohabitation.Evaluate(p, rules...)})
+
+		// Validate.
+		assert.True(t, errors.Is(err, core.ErrInvalidCageInvalidSpecies))
+		assert.Contains(t, err.Error(), "Blue the Velociraptor cannot share with Tyrannosaurus.")
+	})
+}
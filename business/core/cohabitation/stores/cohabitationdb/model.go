@@ -0,0 +1,37 @@
+package cohabitationdb
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/lenguti/jppp/business/core/cohabitation"
+)
+
+type dbRule struct {
+	Position int    `db:"position"`
+	Kind     string `db:"kind"`
+	Params   []byte `db:"params"`
+}
+
+func toCoreDefinitions(dbRules []dbRule) ([]cohabitation.Definition, error) {
+	defs := make([]cohabitation.Definition, 0, len(dbRules))
+	for _, v := range dbRules {
+		d, err := toCoreDefinition(v)
+		if err != nil {
+			return nil, err
+		}
+		defs = append(defs, d)
+	}
+	return defs, nil
+}
+
+func toCoreDefinition(dbr dbRule) (cohabitation.Definition, error) {
+	var d cohabitation.Definition
+	if len(dbr.Params) > 0 {
+		if err := json.Unmarshal(dbr.Params, &d); err != nil {
+			return cohabitation.Definition{}, fmt.Errorf("to core definition: invalid params of rule %d: %w", dbr.Position, err)
+		}
+	}
+	d.Kind = dbr.Kind
+	return d, nil
+}
@@ -0,0 +1,42 @@
+package cohabitationdb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lenguti/jppp/business/core/cohabitation"
+	"github.com/lenguti/jppp/business/data/db"
+)
+
+var _ cohabitation.Storer = (*Store)(nil)
+
+// Store - manages the set of apis for cohabitation rule database access.
+type Store struct {
+	db *db.DB
+}
+
+// NewStore - constructs the api for data access.
+func NewStore(db *db.DB) *Store {
+	return &Store{
+		db: db,
+	}
+}
+
+// List - will fetch the rule definitions ordered by position, the kind specific settings of a rule
+// being held by its params.
+func (s *Store) List(ctx context.Context) ([]cohabitation.Definition, error) {
+	const q = `
+	SELECT *
+	FROM cohabitation_rule
+	ORDER BY position ASC
+	`
+	var out []dbRule
+	if err := s.db.List(ctx, &out, q); err != nil {
+		return nil, fmt.Errorf("list: failed to list rules: %w", err)
+	}
+	defs, err := toCoreDefinitions(out)
+	if err != nil {
+		return nil, fmt.Errorf("list: %w", err)
+	}
+	return defs, nil
+}
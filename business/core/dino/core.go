@@ -5,28 +5,56 @@ import (
 	"time"
 
 	"github.com/lenguti/jppp/business/core"
+	"github.com/lenguti/jppp/business/core/audit"
+	"github.com/lenguti/jppp/business/core/species"
 	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel"
 )
 
+var tracer = otel.Tracer("github.com/lenguti/jppp/business/core/dino")
+
 // Storer - represents the data layer behavior for dinos.
+//
+// Mutations receive the dino version as it should be after the change and must only
+// apply it when the stored dino is still at the preceding version, returning
+// core.ErrConflict otherwise. They also receive the audit records of the change, which
+// must be written atomically with it along with the events describing it, appended to the outbox.
+//
+// Delete soft deletes an uncaged dino and Restore undoes it. Get returns soft deleted
+// dinos, List and ListByCage only do when not filtered out by core.NotDeleted.
+//
+// Summarize groups the dinos that are not soft deleted by species and diet.
+//
+// List and ListByCage return at most page.Limit dinos ordered by page.Sort and then id, starting after page.Cursor.
 type Storer interface {
-	Create(ctx context.Context, d Dinosaur) error
-	ListByCage(ctx context.Context, cageID string, filters ...core.Filter) ([]Dinosaur, error)
+	Create(ctx context.Context, d Dinosaur, recs ...audit.Record) error
+	ListByCage(ctx context.Context, cageID string, page core.Page, filters ...core.Filter) ([]Dinosaur, error)
 	Get(ctx context.Context, id string) (Dinosaur, error)
-	List(ctx context.Context) ([]Dinosaur, error)
-	UpdateName(ctx context.Context, id, name string, ts time.Time) error
+	List(ctx context.Context, page core.Page, filters ...core.Filter) ([]Dinosaur, error)
+	UpdateName(ctx context.Context, id, name string, version int, ts time.Time, recs ...audit.Record) error
+	Delete(ctx context.Context, id string, version int, ts time.Time, recs ...audit.Record) error
+	Restore(ctx context.Context, id string, version int, ts time.Time, recs ...audit.Record) error
+	Summarize(ctx context.Context) ([]Summary, error)
 }
 
 // Core - represents the core business logic for dinos.
 type Core struct {
-	store Storer
-	log   zerolog.Logger
+	store   Storer
+	log     zerolog.Logger
+	species *species.Core
 }
 
-// NewCore - returns a new dino core with all its components initialized.
-func NewCore(store Storer, log zerolog.Logger) *Core {
+// NewCore - returns a new dino core with all its components initialized. Species are looked up in
+// the registry of the species core.
+func NewCore(store Storer, log zerolog.Logger, sc *species.Core) *Core {
 	return &Core{
-		store: store,
-		log:   log,
+		store:   store,
+		log:     log,
+		species: sc,
 	}
 }
+
+// logger - returns the request scoped logger, falling back to the core logger.
+func (c *Core) logger(ctx context.Context) *zerolog.Logger {
+	return core.Logger(ctx, c.log)
+}
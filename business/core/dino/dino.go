@@ -7,71 +7,222 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/lenguti/jppp/business/core"
+	"github.com/lenguti/jppp/business/core/audit"
 )
 
-// Create - will create a new dino.
+// Create - will create a new dino of a registered species, which must have the diet of its species.
 func (c *Core) Create(ctx context.Context, nd NewDino) (Dinosaur, error) {
+	ctx, span := tracer.Start(ctx, "dino.Create")
+	defer span.End()
+
+	if err := core.Authorize(ctx, core.PermDinoCreate); err != nil {
+		return Dinosaur{}, fmt.Errorf("create: %w", err)
+	}
+
+	sp, err := c.ParseSpecies(ctx, nd.Species)
+	if err != nil {
+		return Dinosaur{}, fmt.Errorf("create: %w", err)
+	}
+	if string(nd.Diet) != sp.Diet {
+		return Dinosaur{}, fmt.Errorf("create: diet %s does not match the %s diet of %s", nd.Diet, sp.Diet, sp.Name)
+	}
+
 	now := time.Now().UTC()
 	d := Dinosaur{
 		ID:        uuid.New(),
 		CageID:    uuid.Nil,
 		Name:      nd.Name,
-		Species:   nd.Species,
+		Species:   sp.Name,
 		Diet:      nd.Diet,
+		HatchedAt: nd.HatchedAt,
+		Version:   1,
 		CreatedAt: now,
 		UpdatedAt: now,
 	}
-	if err := c.store.Create(ctx, d); err != nil {
+	rec, err := audit.NewRecord(ctx, audit.ActionDinoCreate, audit.EntityDino, d.ID, nil, d, now)
+	if err != nil {
+		return Dinosaur{}, fmt.Errorf("create: %w", err)
+	}
+	if err := c.store.Create(ctx, d, rec); err != nil {
 		return Dinosaur{}, fmt.Errorf("create: failed to create dino: %w", err)
 	}
 	return d, nil
 }
 
-// Get - will featch a dino by its id.
+// Get - will featch a dino by its id. Soft deleted dinos are not found.
 func (c *Core) Get(ctx context.Context, id uuid.UUID) (Dinosaur, error) {
+	ctx, span := tracer.Start(ctx, "dino.Get")
+	defer span.End()
+
 	d, err := c.store.Get(ctx, id.String())
 	if err != nil {
 		return Dinosaur{}, fmt.Errorf("get: failed to fetch dino: %w", err)
 	}
+	if d.Deleted() {
+		return Dinosaur{}, fmt.Errorf("get: dino deleted: %w", core.ErrNotFound)
+	}
 	return d, nil
 }
 
 // UpdateName - will update the name of the provided dino.
-func (c *Core) UpdateName(ctx context.Context, id uuid.UUID, name string) (Dinosaur, error) {
+// A non zero version must match the current dino version.
+func (c *Core) UpdateName(ctx context.Context, id uuid.UUID, name string, version int) (Dinosaur, error) {
+	ctx, span := tracer.Start(ctx, "dino.UpdateName")
+	defer span.End()
+
+	if err := core.Authorize(ctx, core.PermDinoUpdate); err != nil {
+		return Dinosaur{}, fmt.Errorf("update name: %w", err)
+	}
+
 	d, err := c.Get(ctx, id)
 	if err != nil {
 		return Dinosaur{}, fmt.Errorf("update name: unable to fetch dinosaur: %w", err)
 	}
 
+	if version != 0 && d.Version != version {
+		return Dinosaur{}, core.ErrPreconditionFailed
+	}
+
 	if d.Name == name {
 		return d, nil
 	}
 
+	before := d
 	now := time.Now().UTC()
 	d.Name = name
+	d.Version++
 	d.UpdatedAt = now
-	if err := c.store.UpdateName(ctx, d.ID.String(), d.Name, d.UpdatedAt); err != nil {
+	rec, err := audit.NewRecord(ctx, audit.ActionDinoUpdateName, audit.EntityDino, d.ID, before, d, now)
+	if err != nil {
+		return Dinosaur{}, fmt.Errorf("update name: %w", err)
+	}
+	if err := c.store.UpdateName(ctx, d.ID.String(), d.Name, d.Version, d.UpdatedAt, rec); err != nil {
 		return Dinosaur{}, fmt.Errorf("update status: failed to update dino: %w", err)
 	}
 
 	return d, nil
 }
 
-// ListByCageID - will list all dinos for a given cage.
-func (c *Core) ListByCageID(ctx context.Context, cageID uuid.UUID, filters ...core.Filter) ([]Dinosaur, error) {
-	c.log.Info().Fields(map[string]any{"filters": filters}).Msg("Listing Dinos in cage.")
-	dinos, err := c.store.ListByCage(ctx, cageID.String(), filters...)
+// Delete - will soft delete the provided dino, which must not be in a cage.
+// A non zero version must match the current dino version.
+func (c *Core) Delete(ctx context.Context, id uuid.UUID, version int) (Dinosaur, error) {
+	ctx, span := tracer.Start(ctx, "dino.Delete")
+	defer span.End()
+
+	if err := core.Authorize(ctx, core.PermDinoDelete); err != nil {
+		return Dinosaur{}, fmt.Errorf("delete: %w", err)
+	}
+
+	d, err := c.Get(ctx, id)
 	if err != nil {
-		return nil, fmt.Errorf("list by cage: failed to list dinos: %w", err)
+		return Dinosaur{}, fmt.Errorf("delete: unable to fetch dinosaur: %w", err)
 	}
-	return dinos, nil
+
+	if version != 0 && d.Version != version {
+		return Dinosaur{}, core.ErrPreconditionFailed
+	}
+
+	if d.CageID != uuid.Nil {
+		return Dinosaur{}, core.ErrInvalidDinoCaged
+	}
+
+	before := d
+	now := time.Now().UTC()
+	d.Version++
+	d.UpdatedAt = now
+	d.DeletedAt = now
+	rec, err := audit.NewRecord(ctx, audit.ActionDinoDelete, audit.EntityDino, d.ID, before, d, now)
+	if err != nil {
+		return Dinosaur{}, fmt.Errorf("delete: %w", err)
+	}
+	if err := c.store.Delete(ctx, d.ID.String(), d.Version, now, rec); err != nil {
+		return Dinosaur{}, fmt.Errorf("delete: failed to delete dino: %w", err)
+	}
+
+	return d, nil
 }
 
-// List - will list all dinosaurs.
-func (c *Core) List(ctx context.Context) ([]Dinosaur, error) {
-	ds, err := c.store.List(ctx)
+// Restore - will restore a soft deleted dino. Restoring a dino that is not deleted is a no-op.
+// A non zero version must match the current dino version.
+func (c *Core) Restore(ctx context.Context, id uuid.UUID, version int) (Dinosaur, error) {
+	ctx, span := tracer.Start(ctx, "dino.Restore")
+	defer span.End()
+
+	if err := core.Authorize(ctx, core.PermDinoRestore); err != nil {
+		return Dinosaur{}, fmt.Errorf("restore: %w", err)
+	}
+
+	d, err := c.store.Get(ctx, id.String())
+	if err != nil {
+		return Dinosaur{}, fmt.Errorf("restore: unable to fetch dinosaur: %w", err)
+	}
+
+	if version != 0 && d.Version != version {
+		return Dinosaur{}, core.ErrPreconditionFailed
+	}
+
+	if !d.Deleted() {
+		return d, nil
+	}
+
+	before := d
+	now := time.Now().UTC()
+	d.Version++
+	d.UpdatedAt = now
+	d.DeletedAt = time.Time{}
+	rec, err := audit.NewRecord(ctx, audit.ActionDinoRestore, audit.EntityDino, d.ID, before, d, now)
+	if err != nil {
+		return Dinosaur{}, fmt.Errorf("restore: %w", err)
+	}
+	if err := c.store.Restore(ctx, d.ID.String(), d.Version, now, rec); err != nil {
+		return Dinosaur{}, fmt.Errorf("restore: failed to restore dino: %w", err)
+	}
+
+	return d, nil
+}
+
+// ListByCageID - will list a page of dinos for a given cage along with the cursor of the next page, if any.
+func (c *Core) ListByCageID(ctx context.Context, cageID uuid.UUID, page core.Page, filters ...core.Filter) ([]Dinosaur, string, error) {
+	ctx, span := tracer.Start(ctx, "dino.ListByCageID")
+	defer span.End()
+
+	c.logger(ctx).Info().Fields(map[string]any{"filters": filters, "sort": page.Sort.String(), "limit": page.Limit}).Msg("Listing Dinos in cage.")
+	dinos, err := c.store.ListByCage(ctx, cageID.String(), page.Peek(), filters...)
+	if err != nil {
+		return nil, "", fmt.Errorf("list by cage: failed to list dinos: %w", err)
+	}
+	dinos, next := core.NextPage(page, dinos, pageKey(page))
+	return dinos, next, nil
+}
+
+// List - will list a page of dinosaurs along with the cursor of the next page, if any.
+func (c *Core) List(ctx context.Context, page core.Page, filters ...core.Filter) ([]Dinosaur, string, error) {
+	ctx, span := tracer.Start(ctx, "dino.List")
+	defer span.End()
+
+	c.logger(ctx).Info().Fields(map[string]any{"filters": filters, "sort": page.Sort.String(), "limit": page.Limit}).Msg("Listing Dinos.")
+	ds, err := c.store.List(ctx, page.Peek(), filters...)
 	if err != nil {
-		return nil, fmt.Errorf("list: failed to list dinos: %w", err)
+		return nil, "", fmt.Errorf("list: failed to list dinos: %w", err)
+	}
+	ds, next := core.NextPage(page, ds, pageKey(page))
+	return ds, next, nil
+}
+
+// Summarize - will count the dinos by species and diet.
+func (c *Core) Summarize(ctx context.Context) ([]Summary, error) {
+	ctx, span := tracer.Start(ctx, "dino.Summarize")
+	defer span.End()
+
+	sums, err := c.store.Summarize(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("summarize: failed to summarize dinos: %w", err)
+	}
+	return sums, nil
+}
+
+func pageKey(page core.Page) func(Dinosaur) (string, string) {
+	return func(d Dinosaur) (string, string) {
+		return d.sortValue(page.Sort.Field), d.ID.String()
 	}
-	return ds, nil
 }
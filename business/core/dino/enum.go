@@ -1,10 +1,15 @@
 package dino
 
 import (
+	"context"
 	"fmt"
 	"strings"
+
+	"github.com/google/uuid"
+	"github.com/lenguti/jppp/business/core/species"
 )
 
+// Species registered by default.
 const (
 	DinoSpeciesTyrannosaurus = "Tyrannosaurus"
 	DinoSpeciesVelociraptor  = "Velociraptor"
@@ -17,24 +22,17 @@ const (
 	DinoSpeciesTriceratops   = "Triceratops"
 )
 
-var validDinoSpecies = map[string]Diet{
-	DinoSpeciesTyrannosaurus: DietTypeCarnivore,
-	DinoSpeciesVelociraptor:  DietTypeCarnivore,
-	DinoSpeciesSpinosaurus:   DietTypeCarnivore,
-	DinoSpeciesMegalosaurus:  DietTypeCarnivore,
-	DinoSpeciesBrachiosaurus: DietTypeHerbivore,
-	DinoSpeciesStegosaurus:   DietTypeHerbivore,
-	DinoSpeciesAnkylosaurus:  DietTypeHerbivore,
-	DinoSpeciesTriceratops:   DietTypeHerbivore,
+// ParseSpecies - will look the provided species up in the registry, returning core.ErrNotFound for unknown species.
+func (c *Core) ParseSpecies(ctx context.Context, v string) (species.Species, error) {
+	s, err := c.species.Lookup(ctx, v)
+	if err != nil {
+		return species.Species{}, fmt.Errorf("parse species: %w", err)
+	}
+	return s, nil
 }
 
-// ParseSpecies - will attempt to validate the provided species and return their diet.
-func ParseSpecies(v string) (Diet, error) {
-	d, ok := validDinoSpecies[strings.Title(v)]
-	if !ok {
-		return "", fmt.Errorf("parse species: invalid dino species")
-	}
-	return d, nil
+func normalizeSpecies(v string) (string, error) {
+	return species.NormalizeName(v), nil
 }
 
 // Diet - represents dino diet enum.
@@ -46,8 +44,8 @@ func (d Diet) String() string {
 }
 
 const (
-	DietTypeCarnivore = "CARNIVORE"
-	DietTypeHerbivore = "HERBIVORE"
+	DietTypeCarnivore = species.DietCarnivore
+	DietTypeHerbivore = species.DietHerbivore
 )
 
 var validDietTypes = map[Diet]struct{}{
@@ -63,14 +61,17 @@ func ParseDiet(v string) error {
 	return nil
 }
 
-// DinoSpeciesMapping - mapping of available species and their diets.
-var DinoSpeciesMapping = map[string]Diet{
-	DinoSpeciesTyrannosaurus: DietTypeCarnivore,
-	DinoSpeciesVelociraptor:  DietTypeCarnivore,
-	DinoSpeciesSpinosaurus:   DietTypeCarnivore,
-	DinoSpeciesMegalosaurus:  DietTypeCarnivore,
-	DinoSpeciesBrachiosaurus: DietTypeHerbivore,
-	DinoSpeciesStegosaurus:   DietTypeHerbivore,
-	DinoSpeciesAnkylosaurus:  DietTypeHerbivore,
-	DinoSpeciesTriceratops:   DietTypeHerbivore,
+func normalizeDiet(v string) (string, error) {
+	if err := ParseDiet(v); err != nil {
+		return "", err
+	}
+	return strings.ToUpper(v), nil
+}
+
+func normalizeID(v string) (string, error) {
+	id, err := uuid.Parse(v)
+	if err != nil {
+		return "", fmt.Errorf("normalize id: %w", err)
+	}
+	return id.String(), nil
 }
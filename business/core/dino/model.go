@@ -1,25 +1,73 @@
 package dino
 
 import (
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lenguti/jppp/business/core"
 )
 
-// Dinosaur - represents a business domain dinosaur.
+// Dinosaur - represents a business domain dinosaur. It is marshaled as is into audit records.
 type Dinosaur struct {
-	ID        uuid.UUID
-	CageID    uuid.UUID
-	Name      string
+	ID        uuid.UUID `json:"id"`
+	CageID    uuid.UUID `json:"cage_id"`
+	Name      string    `json:"name"`
+	Species   string    `json:"species"`
+	Diet      Diet      `json:"diet"`
+	HatchedAt time.Time `json:"hatchedAt"`
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	DeletedAt time.Time `json:"deletedAt"`
+}
+
+// SortFields - the fields dinosaurs can be sorted by.
+var SortFields = []string{core.SortCreatedAt, core.SortUpdatedAt, core.SortName}
+
+// FilterFields - the fields dinosaurs can be filtered by.
+var FilterFields = core.Fields{
+	"cage_id":   {Kind: core.KindString, Normalize: normalizeID},
+	"name":      {Kind: core.KindString},
+	"species":   {Kind: core.KindString, Normalize: normalizeSpecies},
+	"diet":      {Kind: core.KindString, Normalize: normalizeDiet},
+	"createdAt": {Kind: core.KindInt},
+	"updatedAt": {Kind: core.KindInt},
+	"deletedAt": {Kind: core.KindInt},
+}
+
+// Juvenile - reports whether the dino is younger than maxAge at the time. Dinos of unknown age are adults.
+func (d Dinosaur) Juvenile(at time.Time, maxAge time.Duration) bool {
+	return !d.HatchedAt.IsZero() && at.Sub(d.HatchedAt) < maxAge
+}
+
+// Deleted - reports whether the dino has been soft deleted.
+func (d Dinosaur) Deleted() bool {
+	return !d.DeletedAt.IsZero()
+}
+
+func (d Dinosaur) sortValue(field string) string {
+	switch field {
+	case core.SortName:
+		return d.Name
+	case core.SortUpdatedAt:
+		return strconv.FormatInt(d.UpdatedAt.Unix(), 10)
+	default:
+		return strconv.FormatInt(d.CreatedAt.Unix(), 10)
+	}
+}
+
+// Summary - represents the number of dinos of a species and diet.
+type Summary struct {
 	Species   string
 	Diet      Diet
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	Dinosaurs int
 }
 
-// NewDino - represents fields needed to create a new dinosaur.
+// NewDino - represents fields needed to create a new dinosaur. HatchedAt is optional.
 type NewDino struct {
-	Name    string
-	Species string
-	Diet    Diet
+	Name      string
+	Species   string
+	Diet      Diet
+	HatchedAt time.Time
 }
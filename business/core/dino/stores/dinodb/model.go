@@ -13,8 +13,11 @@ type dbDino struct {
 	Name      string  `db:"name"`
 	Species   string  `db:"species"`
 	Diet      string  `db:"diet"`
+	HatchedAt *int64  `db:"hatched_at"`
+	Version   int     `db:"version"`
 	CreatedAt int64   `db:"created_at"`
 	UpdatedAt int64   `db:"updated_at"`
+	DeletedAt *int64  `db:"deleted_at"`
 }
 
 func toDBDino(d dino.Dinosaur) dbDino {
@@ -23,12 +26,19 @@ func toDBDino(d dino.Dinosaur) dbDino {
 		Name:      d.Name,
 		Species:   d.Species,
 		Diet:      d.Diet.String(),
+		Version:   d.Version,
 		CreatedAt: d.CreatedAt.Unix(),
 		UpdatedAt: d.UpdatedAt.Unix(),
 	}
 	if d.CageID != uuid.Nil {
 		dbd.CageID = toStrPtr(d.CageID.String())
 	}
+	if !d.HatchedAt.IsZero() {
+		dbd.HatchedAt = toInt64Ptr(d.HatchedAt.Unix())
+	}
+	if !d.DeletedAt.IsZero() {
+		dbd.DeletedAt = toInt64Ptr(d.DeletedAt.Unix())
+	}
 	return dbd
 }
 
@@ -46,15 +56,44 @@ func toCoreDino(dbd dbDino) dino.Dinosaur {
 		Name:      dbd.Name,
 		Species:   dbd.Species,
 		Diet:      dino.Diet(dbd.Diet),
+		Version:   dbd.Version,
 		CreatedAt: time.Unix(dbd.CreatedAt, 0),
 		UpdatedAt: time.Unix(dbd.UpdatedAt, 0),
 	}
 	if dbd.CageID != nil {
 		d.CageID = uuid.MustParse(*dbd.CageID)
 	}
+	if dbd.HatchedAt != nil {
+		d.HatchedAt = time.Unix(*dbd.HatchedAt, 0)
+	}
+	if dbd.DeletedAt != nil {
+		d.DeletedAt = time.Unix(*dbd.DeletedAt, 0)
+	}
 	return d
 }
 
 func toStrPtr(v string) *string {
 	return &v
 }
+
+type dbSummary struct {
+	Species   string `db:"species"`
+	Diet      string `db:"diet"`
+	Dinosaurs int    `db:"dinosaurs"`
+}
+
+func toCoreSummaries(dbsums []dbSummary) []dino.Summary {
+	sums := make([]dino.Summary, 0, len(dbsums))
+	for _, v := range dbsums {
+		sums = append(sums, dino.Summary{
+			Species:   v.Species,
+			Diet:      dino.Diet(v.Diet),
+			Dinosaurs: v.Dinosaurs,
+		})
+	}
+	return sums
+}
+
+func toInt64Ptr(v int64) *int64 {
+	return &v
+}
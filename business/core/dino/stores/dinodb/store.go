@@ -9,24 +9,28 @@ import (
 	"time"
 
 	"github.com/lenguti/jppp/business/core"
+	"github.com/lenguti/jppp/business/core/audit"
+	"github.com/lenguti/jppp/business/core/audit/stores/auditdb"
 	"github.com/lenguti/jppp/business/core/dino"
 	"github.com/lenguti/jppp/business/data/db"
 )
 
 // Store - manages the set of apis for dino database access.
 type Store struct {
-	db *db.DB
+	db    *db.DB
+	audit *auditdb.Store
 }
 
 // NewStore - constructs the api for data access.
 func NewStore(db *db.DB) *Store {
 	return &Store{
-		db: db,
+		db:    db,
+		audit: auditdb.NewStore(db),
 	}
 }
 
 // Create - will insert a new dino record.
-func (s *Store) Create(ctx context.Context, d dino.Dinosaur) error {
+func (s *Store) Create(ctx context.Context, d dino.Dinosaur, recs ...audit.Record) error {
 	dbDino := toDBDino(d)
 	const q = `
 	INSERT INTO dinosaur (
@@ -35,6 +39,8 @@ func (s *Store) Create(ctx context.Context, d dino.Dinosaur) error {
 		name,
 		species,
 		diet,
+		hatched_at,
+		version,
 		created_at,
 		updated_at
 	) VALUES (
@@ -43,11 +49,13 @@ func (s *Store) Create(ctx context.Context, d dino.Dinosaur) error {
 		:name,
 		:species,
 		:diet,
+		:hatched_at,
+		:version,
 		:created_at,
 		:updated_at
 	)
 	`
-	if err := s.db.Exec(ctx, q, dbDino); err != nil {
+	if err := s.audit.ExecOne(ctx, q, dbDino, recs...); err != nil {
 		return fmt.Errorf("create: failed to create dino: %w", err)
 	}
 	return nil
@@ -70,37 +78,80 @@ func (s *Store) Get(ctx context.Context, id string) (dino.Dinosaur, error) {
 	return toCoreDino(out), nil
 }
 
-// List - will list all dinos.
-func (s *Store) List(ctx context.Context) ([]dino.Dinosaur, error) {
-	const q = `
-	SELECT *
-	FROM dinosaur
-	`
+// List - will list a page of dinos.
+func (s *Store) List(ctx context.Context, page core.Page, filters ...core.Filter) ([]dino.Dinosaur, error) {
+	q, vals, err := listClauseBuilder(page, filters...)
+	if err != nil {
+		return nil, fmt.Errorf("list: failed to build query: %w", err)
+	}
 	var out []dbDino
-	if err := s.db.List(ctx, &out, q); err != nil {
+	if err := s.db.List(ctx, &out, q, vals...); err != nil {
 		return nil, fmt.Errorf("list: failed to list dinos: %w", err)
 	}
 	return toCoreDinos(out), nil
 }
 
-// UpdateName - will update the name of a dino.
-func (s *Store) UpdateName(ctx context.Context, id, name string, ts time.Time) error {
+// UpdateName - will update the name of a dino, provided it is still at the version preceding the given one.
+func (s *Store) UpdateName(ctx context.Context, id, name string, version int, ts time.Time, recs ...audit.Record) error {
 	const q = `
 	UPDATE dinosaur
 	SET
 	name = :name,
+	version = :version,
 	updated_at = :updated_at
 	WHERE id = :id
+	AND version = :version - 1
 	`
-	if err := s.db.Exec(ctx, q, map[string]any{"name": name, "updated_at": ts.Unix(), "id": id}); err != nil {
+	if err := s.audit.ExecOne(ctx, q, map[string]any{"name": name, "version": version, "updated_at": ts.Unix(), "id": id}, recs...); err != nil {
 		return fmt.Errorf("update name: failed to update dino name: %w", err)
 	}
 	return nil
 }
 
-// ListByCage - will fetch all dinos associated to the provided cage ids.
-func (s *Store) ListByCage(ctx context.Context, cageID string, filters ...core.Filter) ([]dino.Dinosaur, error) {
-	q, vals := listClauseBuilder(cageID, filters...)
+// Delete - will soft delete an uncaged dino, provided it is still at the version preceding the given one.
+func (s *Store) Delete(ctx context.Context, id string, version int, ts time.Time, recs ...audit.Record) error {
+	const q = `
+	UPDATE dinosaur
+	SET
+	deleted_at = :deleted_at,
+	version = :version,
+	updated_at = :updated_at
+	WHERE id = :id
+	AND version = :version - 1
+	AND cage_id IS NULL
+	AND deleted_at IS NULL
+	`
+	if err := s.audit.ExecOne(ctx, q, map[string]any{"deleted_at": ts.Unix(), "version": version, "updated_at": ts.Unix(), "id": id}, recs...); err != nil {
+		return fmt.Errorf("delete: failed to delete dino: %w", err)
+	}
+	return nil
+}
+
+// Restore - will restore a soft deleted dino, provided it is still at the version preceding the given one.
+func (s *Store) Restore(ctx context.Context, id string, version int, ts time.Time, recs ...audit.Record) error {
+	const q = `
+	UPDATE dinosaur
+	SET
+	deleted_at = NULL,
+	version = :version,
+	updated_at = :updated_at
+	WHERE id = :id
+	AND version = :version - 1
+	AND deleted_at IS NOT NULL
+	`
+	if err := s.audit.ExecOne(ctx, q, map[string]any{"version": version, "updated_at": ts.Unix(), "id": id}, recs...); err != nil {
+		return fmt.Errorf("restore: failed to restore dino: %w", err)
+	}
+	return nil
+}
+
+// ListByCage - will fetch a page of dinos associated to the provided cage id.
+func (s *Store) ListByCage(ctx context.Context, cageID string, page core.Page, filters ...core.Filter) ([]dino.Dinosaur, error) {
+	filters = append([]core.Filter{{Key: "cage_id", Value: cageID}}, filters...)
+	q, vals, err := listClauseBuilder(page, filters...)
+	if err != nil {
+		return nil, fmt.Errorf("list by cage: failed to build query: %w", err)
+	}
 	var out []dbDino
 	if err := s.db.List(ctx, &out, q, vals...); err != nil {
 		return nil, fmt.Errorf("list by cage: failed to list dinos: %w", err)
@@ -108,30 +159,77 @@ func (s *Store) ListByCage(ctx context.Context, cageID string, filters ...core.F
 	return toCoreDinos(out), nil
 }
 
-func listClauseBuilder(cageID string, filters ...core.Filter) (string, []string) {
+// Summarize - will count the dinos that are not deleted by species and diet.
+func (s *Store) Summarize(ctx context.Context) ([]dino.Summary, error) {
 	const q = `
-	SELECT *
+	SELECT
+		species,
+		diet,
+		COUNT(*) AS dinosaurs
 	FROM dinosaur
-	WHERE cage_id = $1
+	WHERE deleted_at IS NULL
+	GROUP BY species, diet
+	ORDER BY species, diet
 	`
-	vals := []string{cageID}
-	if len(filters) == 0 {
-		return q, vals
+	var out []dbSummary
+	if err := s.db.List(ctx, &out, q); err != nil {
+		return nil, fmt.Errorf("summarize: failed to summarize dinos: %w", err)
 	}
+	return toCoreSummaries(out), nil
+}
+
+func listClauseBuilder(page core.Page, filters ...core.Filter) (string, []string, error) {
+	const q = `
+	SELECT *
+	FROM dinosaur
+	`
 
 	filterMap := map[string]string{
-		"species": "species = $%d",
+		"cage_id":   "cage_id",
+		"name":      "name",
+		"species":   "species",
+		"diet":      "diet",
+		"createdAt": "created_at",
+		"updatedAt": "updated_at",
+		"deletedAt": "deleted_at",
+	}
+
+	sortMap := map[string]string{
+		core.SortCreatedAt: "created_at",
+		core.SortUpdatedAt: "updated_at",
+		core.SortName:      "name",
+	}
+
+	conds, vals, err := db.FilterClause(filters, filterMap, 1)
+	if err != nil {
+		return "", nil, fmt.Errorf("list clause builder: %w", err)
+	}
+
+	field := page.Sort.Field
+	if field == "" {
+		field = core.SortCreatedAt
+	}
+	column, ok := sortMap[field]
+	if !ok {
+		return "", nil, fmt.Errorf("list clause builder: invalid sort field %s", field)
+	}
+
+	cond, tail, pageVals, err := db.PageClause(page, column, len(vals)+1)
+	if err != nil {
+		return "", nil, fmt.Errorf("list clause builder: %w", err)
+	}
+	if cond != "" {
+		conds = append(conds, cond)
+		vals = append(vals, pageVals...)
 	}
 
 	var b strings.Builder
 	b.WriteString(q)
-	b.WriteString("AND ")
-	for i := 0; i < len(filters); i++ {
-		c, ok := filterMap[filters[i].Key]
-		if ok {
-			b.WriteString(fmt.Sprintf(c, i+2))
-			vals = append(vals, filters[i].Value)
-		}
+	if len(conds) > 0 {
+		b.WriteString("WHERE ")
+		b.WriteString(strings.Join(conds, "\n\tAND "))
+		b.WriteString("\n\t")
 	}
-	return b.String(), vals
+	b.WriteString(tail)
+	return b.String(), vals, nil
 }
@@ -6,6 +6,7 @@ import (
 	"github.com/lenguti/jppp/business/core"
 	"github.com/lenguti/jppp/business/core/dino"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestListClauseBuilder(t *testing.T) {
@@ -14,10 +15,12 @@ func TestListClauseBuilder(t *testing.T) {
 	SELECT *
 	FROM dinosaur
 	WHERE cage_id = $1
+	ORDER BY created_at ASC, id ASC
 	`
 		cageID := "foo"
 		wantVals := []string{cageID}
-		got, gotVals := listClauseBuilder(cageID)
+		got, gotVals, err := listClauseBuilder(core.Page{}, core.Filter{Key: "cage_id", Value: cageID})
+		require.NoError(t, err)
 		assert.Equal(t, want, got)
 		assert.Equal(t, wantVals, gotVals)
 	})
@@ -27,10 +30,30 @@ func TestListClauseBuilder(t *testing.T) {
 	SELECT *
 	FROM dinosaur
 	WHERE cage_id = $1
-	AND species = $2`
+	AND species = $2
+	ORDER BY created_at ASC, id ASC
+	`
 		cageID := "foo"
 		wantVals := []string{cageID, dino.DinoSpeciesAnkylosaurus}
-		got, gotVals := listClauseBuilder(cageID, core.Filter{Key: "species", Value: dino.DinoSpeciesAnkylosaurus})
+		got, gotVals, err := listClauseBuilder(core.Page{}, core.Filter{Key: "cage_id", Value: cageID}, core.Filter{Key: "species", Value: dino.DinoSpeciesAnkylosaurus})
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+		assert.Equal(t, wantVals, gotVals)
+	})
+
+	t.Run("name sorted page after cursor", func(t *testing.T) {
+		want := `
+	SELECT *
+	FROM dinosaur
+	WHERE (name, id) > ($1, $2)
+	ORDER BY name ASC, id ASC
+	LIMIT 3
+	`
+		sort := core.Sort{Field: core.SortName}
+		page := core.Page{Limit: 3, Sort: sort, Cursor: core.EncodeCursor(sort, "Blue", "6f1f6bde-92c4-4d7a-a3ec-3a1e8a1b4c2d")}
+		wantVals := []string{"Blue", "6f1f6bde-92c4-4d7a-a3ec-3a1e8a1b4c2d"}
+		got, gotVals, err := listClauseBuilder(page)
+		require.NoError(t, err)
 		assert.Equal(t, want, got)
 		assert.Equal(t, wantVals, gotVals)
 	})
@@ -24,9 +24,51 @@ const (
 	// ErrInvalidCageInvalidSpecies represents an unable to add dino with species conflict error.
 	ErrInvalidCageInvalidSpecies = Error("unable to add dinosaurs to cage with different species")
 
+	// ErrInvalidCageIncompatibleSpecies represents an unable to add dino with a species it may not share a cage with error.
+	ErrInvalidCageIncompatibleSpecies = Error("unable to add dinosaurs to cage with incompatible species")
+
+	// ErrInvalidCageJuvenile represents an unable to add juvenile and adult dinos to the same cage error.
+	ErrInvalidCageJuvenile = Error("unable to add juvenile and adult dinosaurs to the same cage")
+
+	// ErrInvalidCageSolitary represents an unable to add dino to share a cage with a solitary species error.
+	ErrInvalidCageSolitary = Error("unable to add dinosaurs to cage with a solitary species")
+
 	// ErrInvalidCageInvalidRemoval represents an unable to remove dino from cage error.
 	ErrInvalidCageInvalidRemoval = Error("unable to remove dinosaurs from an empty cage")
 
+	// ErrInvalidCageDinoCaged represents an unable to add a dino that is already in a cage error.
+	ErrInvalidCageDinoCaged = Error("unable to add dinosaurs that are already in a cage")
+
+	// ErrInvalidCageDinoNotCaged represents an unable to remove a dino that is not in the cage error.
+	ErrInvalidCageDinoNotCaged = Error("unable to remove dinosaurs that are not in the cage")
+
+	// ErrInvalidTransferSameCage represents an unable to transfer dino into the cage it is in error.
+	ErrInvalidTransferSameCage = Error("unable to transfer dinosaurs to the cage they are in")
+
+	// ErrInvalidCageNotEmpty represents an unable to delete a cage holding dinos error.
+	ErrInvalidCageNotEmpty = Error("unable to delete cage with active dinosaurs")
+
+	// ErrInvalidDinoCaged represents an unable to delete a dino that is in a cage error.
+	ErrInvalidDinoCaged = Error("unable to delete dinosaurs that are in a cage")
+
+	// ErrInvalidSpeciesExists represents an unable to create a species whose name is taken error.
+	ErrInvalidSpeciesExists = Error("unable to create species that already exist")
+
+	// ErrInvalidSpeciesInUse represents an unable to delete a species dinos belong to error.
+	ErrInvalidSpeciesInUse = Error("unable to delete species with dinosaurs")
+
+	// ErrPreconditionFailed represents a mismatch between the expected and current version of an item.
+	ErrPreconditionFailed = Error("item version does not match")
+
+	// ErrConflict represents a concurrent modification of the underlying state.
+	ErrConflict = Error("state changed while processing the request")
+
+	// ErrInvalidCursor represents a malformed page cursor or one issued for a different sort.
+	ErrInvalidCursor = Error("invalid page cursor")
+
+	// ErrInvalidFilter represents a filter on an unknown field or with an unsupported operator or value.
+	ErrInvalidFilter = Error("invalid filter")
+
 	// ErrNotFound represents an item not found.
 	ErrNotFound = Error("item not found")
 )
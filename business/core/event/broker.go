@@ -0,0 +1,145 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// subscriptionBuffer - the number of events a subscriber may lag behind before being dropped.
+const subscriptionBuffer = 64
+
+// ErrBrokerClosed - returned when subscribing to or publishing on a closed broker.
+var ErrBrokerClosed = errors.New("broker closed")
+
+var _ Publisher = (*Broker)(nil)
+
+// Broker - represents an in-process publisher fanning events out to subscribers. It numbers the
+// events it publishes and keeps the latest ones so subscribers can resume after the last one they saw.
+type Broker struct {
+	mu     sync.Mutex
+	seq    uint64
+	replay []Event
+	size   int
+	subs   map[*Subscription]struct{}
+	closed bool
+}
+
+// NewBroker - returns a new broker keeping the latest size events for replay.
+func NewBroker(size int) *Broker {
+	return &Broker{
+		size: size,
+		subs: map[*Subscription]struct{}{},
+	}
+}
+
+// Publish - numbers the events and delivers them to the matching subscribers. Subscribers too slow
+// to keep up are dropped, their channel closed, rather than slowing down the publisher.
+func (b *Broker) Publish(ctx context.Context, evs ...Event) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return ErrBrokerClosed
+	}
+	for _, e := range evs {
+		b.seq++
+		e.ID = b.seq
+		if b.size > 0 {
+			if len(b.replay) == b.size {
+				b.replay = b.replay[1:]
+			}
+			b.replay = append(b.replay, e)
+		}
+
+		for s := range b.subs {
+			if !s.filter.Match(e) {
+				continue
+			}
+			select {
+			case s.c <- e:
+			default:
+				b.drop(s)
+			}
+		}
+	}
+	return nil
+}
+
+// Subscribe - returns a subscription to the events matching the filter. When lastID is set, the
+// kept events published after it are replayed first. Truncated reports whether some of the events
+// published after it can no longer be replayed.
+func (b *Broker) Subscribe(lastID uint64, f Filter) (*Subscription, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil, ErrBrokerClosed
+	}
+
+	var replay []Event
+	if lastID > 0 {
+		for _, e := range b.replay {
+			if e.ID > lastID && f.Match(e) {
+				replay = append(replay, e)
+			}
+		}
+	}
+
+	s := &Subscription{
+		b:      b,
+		filter: f,
+		c:      make(chan Event, subscriptionBuffer+len(replay)),
+	}
+	s.C = s.c
+	switch {
+	case lastID > b.seq:
+		// The id was handed out by a previous broker, such as before a restart.
+		s.Truncated = true
+	case lastID > 0 && lastID < b.seq:
+		s.Truncated = len(b.replay) == 0 || b.replay[0].ID > lastID+1
+	}
+	for _, e := range replay {
+		s.c <- e
+	}
+	b.subs[s] = struct{}{}
+	return s, nil
+}
+
+// Close - ends every subscription and rejects new ones.
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for s := range b.subs {
+		b.drop(s)
+	}
+}
+
+// drop - removes the subscription and closes its channel. The broker lock must be held.
+func (b *Broker) drop(s *Subscription) {
+	if _, ok := b.subs[s]; !ok {
+		return
+	}
+	delete(b.subs, s)
+	close(s.c)
+}
+
+// Subscription - represents a subscriber to a broker. C is closed once the subscription ends.
+type Subscription struct {
+	C         <-chan Event
+	Truncated bool
+
+	b      *Broker
+	filter Filter
+	c      chan Event
+}
+
+// Close - ends the subscription.
+func (s *Subscription) Close() {
+	s.b.mu.Lock()
+	defer s.b.mu.Unlock()
+
+	s.b.drop(s)
+}
@@ -0,0 +1,215 @@
+package event_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lenguti/jppp/business/core/audit"
+	"github.com/lenguti/jppp/business/core/dino"
+	"github.com/lenguti/jppp/business/core/event"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromRecords(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	t.Run("transfer leaves a cage and enters another", func(t *testing.T) {
+		// Setup.
+		from, to := uuid.New(), uuid.New()
+		before := dino.Dinosaur{ID: uuid.New(), CageID: from}
+		after := before
+		after.CageID = to
+		rec, err := audit.NewRecord(ctx, audit.ActionDinoTransfer, audit.EntityDino, before.ID, before, after, now)
+		require.NoError(t, err)
+
+		// Execute.
+		evs, err := event.FromRecords(rec)
+
+		// Validate.
+		require.NoError(t, err)
+		require.Len(t, evs, 2)
+		assert.Equal(t, event.TypeDinoUncaged, evs[0].Type)
+		assert.Equal(t, from, evs[0].CageID)
+		assert.Equal(t, event.TypeDinoCaged, evs[1].Type)
+		assert.Equal(t, to, evs[1].CageID)
+	})
+
+	t.Run("cage events are about the cage", func(t *testing.T) {
+		// Setup.
+		id := uuid.New()
+		rec, err := audit.NewRecord(ctx, audit.ActionCageUpdateStatus, audit.EntityCage, id, nil, map[string]string{"status": "DOWN"}, now)
+		require.NoError(t, err)
+
+		// Execute.
+		evs, err := event.FromRecords(rec)
+
+		// Validate.
+		require.NoError(t, err)
+		require.Len(t, evs, 1)
+		assert.Equal(t, event.TypeCageStatusChanged, evs[0].Type)
+		assert.Equal(t, id, evs[0].CageID)
+		assert.JSONEq(t, `{"status":"DOWN"}`, string(evs[0].Data))
+	})
+}
+
+func TestBroker(t *testing.T) {
+	ctx := context.Background()
+	cageID := uuid.New()
+	evs := []event.Event{
+		{Type: event.TypeCageCreated, EntityType: audit.EntityCage, CageID: cageID},
+		{Type: event.TypeDinoCreated, EntityType: audit.EntityDino},
+		{Type: event.TypeDinoCaged, EntityType: audit.EntityDino, CageID: cageID},
+	}
+	recv := func(t *testing.T, sub *event.Subscription) []uint64 {
+		t.Helper()
+		var ids []uint64
+		for {
+			select {
+			case e := <-sub.C:
+				ids = append(ids, e.ID)
+			default:
+				return ids
+			}
+		}
+	}
+
+	t.Run("delivers matching events", func(t *testing.T) {
+		// Setup.
+		b := event.NewBroker(10)
+		sub, err := b.Subscribe(0, event.Filter{CageID: cageID})
+		require.NoError(t, err)
+
+		// Execute.
+		require.NoError(t, b.Publish(ctx, evs...))
+
+		// Validate.
+		assert.Equal(t, []uint64{1, 3}, recv(t, sub))
+	})
+
+	t.Run("replays events after the last id", func(t *testing.T) {
+		// Setup.
+		b := event.NewBroker(10)
+		require.NoError(t, b.Publish(ctx, evs...))
+
+		// Execute.
+		sub, err := b.Subscribe(1, event.Filter{EntityTypes: []string{audit.EntityDino}})
+
+		// Validate.
+		require.NoError(t, err)
+		assert.False(t, sub.Truncated)
+		assert.Equal(t, []uint64{2, 3}, recv(t, sub))
+	})
+
+	t.Run("reports replays beyond the buffer", func(t *testing.T) {
+		// Setup.
+		b := event.NewBroker(1)
+		require.NoError(t, b.Publish(ctx, evs...))
+
+		// Execute.
+		sub, err := b.Subscribe(1, event.Filter{})
+
+		// Validate.
+		require.NoError(t, err)
+		assert.True(t, sub.Truncated)
+		assert.Equal(t, []uint64{3}, recv(t, sub))
+	})
+
+	t.Run("drops slow subscribers", func(t *testing.T) {
+		// Setup.
+		b := event.NewBroker(0)
+		sub, err := b.Subscribe(0, event.Filter{})
+		require.NoError(t, err)
+
+		// Execute.
+		for i := 0; i < 100; i++ {
+			require.NoError(t, b.Publish(ctx, evs[0]))
+		}
+
+		// Validate.
+		n := 0
+		for range sub.C {
+			n++
+		}
+		assert.Less(t, n, 100)
+	})
+
+	t.Run("close ends subscriptions", func(t *testing.T) {
+		// Setup.
+		b := event.NewBroker(0)
+		sub, err := b.Subscribe(0, event.Filter{})
+		require.NoError(t, err)
+
+		// Execute.
+		b.Close()
+
+		// Validate.
+		_, ok := <-sub.C
+		assert.False(t, ok)
+		_, err = b.Subscribe(0, event.Filter{})
+		assert.ErrorIs(t, err, event.ErrBrokerClosed)
+	})
+}
+
+func TestFilePublisher(t *testing.T) {
+	ctx := context.Background()
+	cageID := uuid.New()
+	evs := []event.Event{
+		{ID: 1, Type: event.TypeCageCreated, EntityType: audit.EntityCage, EntityID: cageID, CageID: cageID, Data: json.RawMessage(`{"status":"ACTIVE"}`)},
+		{ID: 2, Type: event.TypeDinoCreated, EntityType: audit.EntityDino, EntityID: uuid.New(), RequestID: "req-1"},
+	}
+	lines := func(t *testing.T, path string) []event.FileLine {
+		t.Helper()
+		f, err := os.Open(path)
+		require.NoError(t, err)
+		defer f.Close()
+		var ls []event.FileLine
+		sc := bufio.NewScanner(f)
+		for sc.Scan() {
+			var l event.FileLine
+			require.NoError(t, json.Unmarshal(sc.Bytes(), &l))
+			ls = append(ls, l)
+		}
+		require.NoError(t, sc.Err())
+		return ls
+	}
+
+	t.Run("appends a line per event", func(t *testing.T) {
+		// Setup.
+		path := filepath.Join(t.TempDir(), "events.ndjson")
+		p := event.NewFilePublisher(path)
+
+		// Execute.
+		require.NoError(t, p.Publish(ctx, evs[0]))
+		require.NoError(t, p.Publish(ctx, evs[1]))
+
+		// Validate.
+		ls := lines(t, path)
+		require.Len(t, ls, 2)
+		assert.Equal(t, uint64(1), ls[0].ID)
+		assert.Equal(t, event.TypeCageCreated, ls[0].Type)
+		assert.Equal(t, cageID.String(), ls[0].CageID)
+		assert.JSONEq(t, `{"status":"ACTIVE"}`, string(ls[0].Data))
+		assert.Equal(t, event.TypeDinoCreated, ls[1].Type)
+		assert.Empty(t, ls[1].CageID)
+		assert.Equal(t, "req-1", ls[1].RequestID)
+	})
+
+	t.Run("missing directory fails", func(t *testing.T) {
+		// Setup.
+		p := event.NewFilePublisher(filepath.Join(t.TempDir(), "missing", "events.ndjson"))
+
+		// Execute.
+		err := p.Publish(ctx, evs...)
+
+		// Validate.
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,88 @@
+package event
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+var _ Publisher = (*FilePublisher)(nil)
+
+// FileLine - represents an event as written by the file publisher, one json object per line.
+type FileLine struct {
+	ID         uint64          `json:"id"`
+	Type       string          `json:"type"`
+	EntityType string          `json:"entity_type"`
+	EntityID   string          `json:"entity_id"`
+	CageID     string          `json:"cage_id,omitempty"`
+	Data       json.RawMessage `json:"data"`
+	RequestID  string          `json:"request_id,omitempty"`
+	CreatedAt  int64           `json:"createdAt"`
+}
+
+// FilePublisher - represents a publisher appending events to a file as newline delimited json,
+// for consumers tailing the file in place of a message broker. The file is opened for each batch
+// of events, so it may be rotated by moving it away.
+type FilePublisher struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFilePublisher - returns a new publisher appending to the file at path, created if missing.
+func NewFilePublisher(path string) *FilePublisher {
+	return &FilePublisher{
+		path: path,
+	}
+}
+
+// Publish - appends a line for every event to the file in a single write.
+func (p *FilePublisher) Publish(ctx context.Context, evs ...Event) error {
+	if len(evs) == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, e := range evs {
+		if err := enc.Encode(toFileLine(e)); err != nil {
+			return fmt.Errorf("publish: unable to encode event: %w", err)
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	f, err := os.OpenFile(p.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("publish: unable to open file: %w", err)
+	}
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		f.Close()
+		return fmt.Errorf("publish: unable to write file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("publish: unable to close file: %w", err)
+	}
+	return nil
+}
+
+func toFileLine(e Event) FileLine {
+	l := FileLine{
+		ID:         e.ID,
+		Type:       e.Type,
+		EntityType: e.EntityType,
+		EntityID:   e.EntityID.String(),
+		Data:       e.Data,
+		RequestID:  e.RequestID,
+		CreatedAt:  e.CreatedAt.Unix(),
+	}
+	if e.CageID != uuid.Nil {
+		l.CageID = e.CageID.String()
+	}
+	return l
+}
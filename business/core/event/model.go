@@ -0,0 +1,164 @@
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lenguti/jppp/business/core/audit"
+)
+
+// Event types.
+const (
+	TypeCageCreated          = "cage.created"
+	TypeCageStatusChanged    = "cage.status_changed"
+	TypeCageOccupancyChanged = "cage.occupancy_changed"
+	TypeCageDeleted          = "cage.deleted"
+	TypeCageRestored         = "cage.restored"
+	TypeDinoCreated          = "dino.created"
+	TypeDinoRenamed          = "dino.renamed"
+	TypeDinoCaged            = "dino.caged"
+	TypeDinoUncaged          = "dino.uncaged"
+	TypeDinoDeleted          = "dino.deleted"
+	TypeDinoRestored         = "dino.restored"
+)
+
+// Types - every event type.
+var Types = []string{
+	TypeCageCreated,
+	TypeCageStatusChanged,
+	TypeCageOccupancyChanged,
+	TypeCageDeleted,
+	TypeCageRestored,
+	TypeDinoCreated,
+	TypeDinoRenamed,
+	TypeDinoCaged,
+	TypeDinoUncaged,
+	TypeDinoDeleted,
+	TypeDinoRestored,
+}
+
+// ValidType - reports whether the type is a known event type.
+func ValidType(typ string) bool {
+	return contains(Types, typ)
+}
+
+// Event - represents a business domain change to a cage or dino. CageID is the cage the event
+// is about, the cage itself for cage events and the cage of the dino, if any, for dino events.
+// Data holds the state of the entity after the change. ID is assigned by the outbox, in the order
+// the events were appended, and the broker numbers its own copies.
+type Event struct {
+	ID         uint64
+	Type       string
+	EntityType string
+	EntityID   uuid.UUID
+	CageID     uuid.UUID
+	Data       json.RawMessage
+	RequestID  string
+	CreatedAt  time.Time
+}
+
+// Filter - represents the events a subscriber is interested in. Empty fields match every event.
+type Filter struct {
+	Types       []string
+	EntityTypes []string
+	CageID      uuid.UUID
+}
+
+// Match - reports whether the event satisfies the filter.
+func (f Filter) Match(e Event) bool {
+	if len(f.Types) > 0 && !contains(f.Types, e.Type) {
+		return false
+	}
+	if len(f.EntityTypes) > 0 && !contains(f.EntityTypes, e.EntityType) {
+		return false
+	}
+	return f.CageID == uuid.Nil || f.CageID == e.CageID
+}
+
+// FromRecords - returns the events describing the changes recorded in the audit records. A transfer
+// is described as the dino leaving a cage and entering another one.
+func FromRecords(recs ...audit.Record) ([]Event, error) {
+	var evs []Event
+	for _, r := range recs {
+		var before, after struct {
+			CageID uuid.UUID `json:"cage_id"`
+		}
+		if err := unmarshal(r.Before, &before); err != nil {
+			return nil, fmt.Errorf("from records: %w", err)
+		}
+		if err := unmarshal(r.After, &after); err != nil {
+			return nil, fmt.Errorf("from records: %w", err)
+		}
+
+		e := Event{
+			EntityType: r.EntityType,
+			EntityID:   r.EntityID,
+			CageID:     after.CageID,
+			Data:       r.After,
+			RequestID:  r.RequestID,
+			CreatedAt:  r.CreatedAt,
+		}
+		if r.EntityType == audit.EntityCage {
+			e.CageID = r.EntityID
+		}
+		with := func(typ string, cageID uuid.UUID) Event {
+			e := e
+			e.Type, e.CageID = typ, cageID
+			return e
+		}
+
+		switch r.Action {
+		case audit.ActionCageCreate:
+			evs = append(evs, with(TypeCageCreated, e.CageID))
+		case audit.ActionCageUpdateStatus:
+			evs = append(evs, with(TypeCageStatusChanged, e.CageID))
+		case audit.ActionCageDelete:
+			evs = append(evs, with(TypeCageDeleted, e.CageID))
+		case audit.ActionCageRestore:
+			evs = append(evs, with(TypeCageRestored, e.CageID))
+		case audit.ActionDinoCreate:
+			evs = append(evs, with(TypeDinoCreated, e.CageID))
+		case audit.ActionDinoUpdateName:
+			evs = append(evs, with(TypeDinoRenamed, e.CageID))
+		case audit.ActionDinoDelete:
+			evs = append(evs, with(TypeDinoDeleted, e.CageID))
+		case audit.ActionDinoRestore:
+			evs = append(evs, with(TypeDinoRestored, e.CageID))
+		case audit.ActionCageAddDino, audit.ActionCageRemoveDino, audit.ActionDinoTransfer:
+			if r.EntityType == audit.EntityCage {
+				evs = append(evs, with(TypeCageOccupancyChanged, e.CageID))
+				continue
+			}
+			if before.CageID != uuid.Nil {
+				evs = append(evs, with(TypeDinoUncaged, before.CageID))
+			}
+			if after.CageID != uuid.Nil {
+				evs = append(evs, with(TypeDinoCaged, after.CageID))
+			}
+		default:
+			return nil, fmt.Errorf("from records: unknown action %s", r.Action)
+		}
+	}
+	return evs, nil
+}
+
+func unmarshal(data json.RawMessage, v any) error {
+	if len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("unmarshal: %w", err)
+	}
+	return nil
+}
+
+func contains(vs []string, v string) bool {
+	for _, s := range vs {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
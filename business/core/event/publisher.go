@@ -0,0 +1,30 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Publisher - represents the behavior of publishing events to subscribers.
+type Publisher interface {
+	Publish(ctx context.Context, evs ...Event) error
+}
+
+// Publishers - represents a publisher publishing events to each of its publishers in turn. Every
+// publisher is given the events even when a previous one failed.
+type Publishers []Publisher
+
+// Publish - publishes the events to every publisher, returning their joined errors.
+func (ps Publishers) Publish(ctx context.Context, evs ...Event) error {
+	var errs []error
+	for _, p := range ps {
+		if err := p.Publish(ctx, evs...); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("publish: %w", err)
+	}
+	return nil
+}
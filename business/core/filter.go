@@ -1,7 +1,191 @@
 package core
 
-// Filter - represents queryable fields.
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Operator - represents a filter comparison.
+type Operator string
+
+const (
+	OpEq     Operator = "eq"
+	OpNe     Operator = "ne"
+	OpIn     Operator = "in"
+	OpGt     Operator = "gt"
+	OpGte    Operator = "gte"
+	OpLt     Operator = "lt"
+	OpLte    Operator = "lte"
+	OpPrefix Operator = "prefix"
+	OpNull   Operator = "null"
+)
+
+// Filter - represents a condition on a queryable field. Multiple filters are combined with AND.
+// An empty Op is treated as OpEq, OpIn matches any of Values and OpNull matches unset
+// fields when Value is "true" and set ones when it is "false".
 type Filter struct {
-	Key   string
-	Value string
+	Key    string
+	Op     Operator
+	Value  string
+	Values []string
+}
+
+// FieldDeletedAt - the queryable field holding when an item was soft deleted.
+const FieldDeletedAt = "deletedAt"
+
+// NotDeleted - filters out soft deleted items.
+var NotDeleted = Filter{Key: FieldDeletedAt, Op: OpNull, Value: "true"}
+
+// Operator - returns the filter operator, defaulting to equality.
+func (f Filter) Operator() Operator {
+	if f.Op == "" {
+		return OpEq
+	}
+	return f.Op
+}
+
+// Kind - represents the type of a queryable field.
+type Kind int
+
+const (
+	KindString Kind = iota
+	KindInt
+)
+
+var kindOperators = map[Kind][]Operator{
+	KindString: {OpEq, OpNe, OpIn, OpPrefix},
+	KindInt:    {OpEq, OpNe, OpIn, OpGt, OpGte, OpLt, OpLte},
+}
+
+// Field - represents a queryable field. Normalize, when set, validates a value and
+// returns its canonical form.
+type Field struct {
+	Kind      Kind
+	Normalize func(string) (string, error)
+}
+
+// Fields - represents the set of queryable fields of an entity keyed by name.
+type Fields map[string]Field
+
+// Parse - will validate a filter against the fields, returning it with normalized values.
+func (fs Fields) Parse(key string, op Operator, raw string) (Filter, error) {
+	fd, ok := fs[key]
+	if !ok {
+		return Filter{}, fmt.Errorf("parse: unknown field %s: %w", key, ErrInvalidFilter)
+	}
+
+	f := Filter{Key: key, Op: op}
+	if !fd.allows(f.Operator()) {
+		return Filter{}, fmt.Errorf("parse: operator %s not supported for %s: %w", f.Operator(), key, ErrInvalidFilter)
+	}
+
+	if f.Operator() == OpNull {
+		isNull, err := strconv.ParseBool(strings.TrimSpace(raw))
+		if err != nil {
+			return Filter{}, fmt.Errorf("parse: invalid value for %s: %w", key, ErrInvalidFilter)
+		}
+		f.Value = strconv.FormatBool(isNull)
+		return f, nil
+	}
+
+	vals := []string{raw}
+	if f.Operator() == OpIn {
+		vals = strings.Split(raw, ",")
+	}
+	for i := range vals {
+		v, err := fd.normalize(vals[i])
+		if err != nil {
+			return Filter{}, fmt.Errorf("parse: invalid value for %s: %w", key, ErrInvalidFilter)
+		}
+		vals[i] = v
+	}
+
+	if f.Operator() == OpIn {
+		f.Values = vals
+		return f, nil
+	}
+	f.Value = vals[0]
+	return f, nil
+}
+
+// Match - reports whether the value of the filtered field satisfies the filter. An empty
+// value represents an unset field which, as in SQL, only satisfies OpNull.
+func (fs Fields) Match(f Filter, v string) (bool, error) {
+	fd, ok := fs[f.Key]
+	if !ok {
+		return false, fmt.Errorf("match: unknown field %s: %w", f.Key, ErrInvalidFilter)
+	}
+
+	if f.Operator() == OpNull {
+		return (v == "") == (f.Value == "true"), nil
+	}
+	if v == "" {
+		return false, nil
+	}
+
+	cmp := func(want string) int {
+		if fd.Kind == KindInt {
+			a, _ := strconv.ParseInt(v, 10, 64)
+			b, _ := strconv.ParseInt(want, 10, 64)
+			switch {
+			case a < b:
+				return -1
+			case a > b:
+				return 1
+			}
+			return 0
+		}
+		return strings.Compare(v, want)
+	}
+
+	switch f.Operator() {
+	case OpEq:
+		return cmp(f.Value) == 0, nil
+	case OpNe:
+		return cmp(f.Value) != 0, nil
+	case OpGt:
+		return cmp(f.Value) > 0, nil
+	case OpGte:
+		return cmp(f.Value) >= 0, nil
+	case OpLt:
+		return cmp(f.Value) < 0, nil
+	case OpLte:
+		return cmp(f.Value) <= 0, nil
+	case OpPrefix:
+		return strings.HasPrefix(v, f.Value), nil
+	case OpIn:
+		for _, want := range f.Values {
+			if cmp(want) == 0 {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	return false, fmt.Errorf("match: unknown operator %s: %w", f.Op, ErrInvalidFilter)
+}
+
+func (fd Field) allows(op Operator) bool {
+	if op == OpNull {
+		return true
+	}
+	for _, v := range kindOperators[fd.Kind] {
+		if v == op {
+			return true
+		}
+	}
+	return false
+}
+
+func (fd Field) normalize(v string) (string, error) {
+	v = strings.TrimSpace(v)
+	if fd.Kind == KindInt {
+		if _, err := strconv.ParseInt(v, 10, 64); err != nil {
+			return "", err
+		}
+	}
+	if fd.Normalize != nil {
+		return fd.Normalize(v)
+	}
+	return v, nil
 }
@@ -0,0 +1,85 @@
+package core_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lenguti/jppp/business/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFieldsParse(t *testing.T) {
+	fields := core.Fields{
+		"capacity": {Kind: core.KindInt},
+		"type": {Kind: core.KindString, Normalize: func(v string) (string, error) {
+			return strings.ToUpper(v), nil
+		}},
+	}
+
+	t.Run("default operator", func(t *testing.T) {
+		got, err := fields.Parse("capacity", "", "5")
+		require.NoError(t, err)
+		assert.Equal(t, core.OpEq, got.Operator())
+		assert.Equal(t, "5", got.Value)
+	})
+
+	t.Run("in operator normalizes values", func(t *testing.T) {
+		got, err := fields.Parse("type", core.OpIn, "carnivore, herbivore")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"CARNIVORE", "HERBIVORE"}, got.Values)
+	})
+
+	t.Run("unknown field", func(t *testing.T) {
+		_, err := fields.Parse("color", core.OpEq, "red")
+		assert.ErrorIs(t, err, core.ErrInvalidFilter)
+	})
+
+	t.Run("unsupported operator", func(t *testing.T) {
+		_, err := fields.Parse("capacity", core.OpPrefix, "5")
+		assert.ErrorIs(t, err, core.ErrInvalidFilter)
+	})
+
+	t.Run("null operator on any kind", func(t *testing.T) {
+		got, err := fields.Parse("capacity", core.OpNull, "TRUE")
+		require.NoError(t, err)
+		assert.Equal(t, "true", got.Value)
+
+		_, err = fields.Parse("type", core.OpNull, "maybe")
+		assert.ErrorIs(t, err, core.ErrInvalidFilter)
+	})
+
+	t.Run("invalid int value", func(t *testing.T) {
+		_, err := fields.Parse("capacity", core.OpGte, "five")
+		assert.ErrorIs(t, err, core.ErrInvalidFilter)
+	})
+}
+
+func TestFieldsMatch(t *testing.T) {
+	fields := core.Fields{
+		"capacity": {Kind: core.KindInt},
+		"name":     {Kind: core.KindString},
+	}
+
+	tests := []struct {
+		name   string
+		filter core.Filter
+		value  string
+		want   bool
+	}{
+		{name: "gte numeric", filter: core.Filter{Key: "capacity", Op: core.OpGte, Value: "5"}, value: "10", want: true},
+		{name: "lt numeric", filter: core.Filter{Key: "capacity", Op: core.OpLt, Value: "5"}, value: "10", want: false},
+		{name: "ne", filter: core.Filter{Key: "name", Op: core.OpNe, Value: "Blue"}, value: "Blue", want: false},
+		{name: "in", filter: core.Filter{Key: "capacity", Op: core.OpIn, Values: []string{"1", "2"}}, value: "2", want: true},
+		{name: "prefix", filter: core.Filter{Key: "name", Op: core.OpPrefix, Value: "Re"}, value: "Rexy", want: true},
+		{name: "null unset", filter: core.Filter{Key: "capacity", Op: core.OpNull, Value: "true"}, value: "", want: true},
+		{name: "not null unset", filter: core.Filter{Key: "capacity", Op: core.OpNull, Value: "false"}, value: "", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := fields.Match(tt.filter, tt.value)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
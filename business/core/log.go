@@ -0,0 +1,16 @@
+package core
+
+import (
+	"context"
+
+	"github.com/rs/zerolog"
+)
+
+// Logger - returns the request scoped logger carried by the context, which is tagged with the
+// request id, falling back to log when the context carries none.
+func Logger(ctx context.Context, log zerolog.Logger) *zerolog.Logger {
+	if l := zerolog.Ctx(ctx); l.GetLevel() != zerolog.Disabled {
+		return l
+	}
+	return &log
+}
@@ -0,0 +1,65 @@
+// Package outbox relays the events appended to the outbox, within the tx of the change they
+// describe, to the event publishers.
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"github.com/lenguti/jppp/business/core/event"
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel"
+)
+
+var tracer = otel.Tracer("github.com/lenguti/jppp/business/core/outbox")
+
+// Default relay settings, used when the related config field is not set.
+const (
+	defaultPollInterval = time.Second
+	defaultBatchSize    = 100
+)
+
+// Storer - represents the data layer behavior for the outbox. Events are appended by the cage and
+// dino stores along with the change they describe.
+//
+// Relay passes at most limit pending events, in the order they were appended, to fn and marks
+// them as sent once it returns nil. They are left pending when fn fails. Events being relayed by a
+// concurrent caller are skipped.
+type Storer interface {
+	Relay(ctx context.Context, limit int, fn func(evs []event.Event) error) (int, error)
+}
+
+// Config - represents the relay settings.
+type Config struct {
+	PollInterval time.Duration
+	BatchSize    int
+}
+
+// withDefaults - returns the config with its unset fields set to their default.
+func (c Config) withDefaults() Config {
+	if c.PollInterval <= 0 {
+		c.PollInterval = defaultPollInterval
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = defaultBatchSize
+	}
+	return c
+}
+
+// Core - represents the core business logic relaying the outbox to the publisher.
+type Core struct {
+	store Storer
+	log   zerolog.Logger
+	pub   event.Publisher
+	cfg   Config
+}
+
+// NewCore - returns a new outbox core with all its components initialized.
+func NewCore(store Storer, log zerolog.Logger, pub event.Publisher, cfg Config) *Core {
+	return &Core{
+		store: store,
+		log:   log,
+		pub:   pub,
+		cfg:   cfg.withDefaults(),
+	}
+}
@@ -0,0 +1,50 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lenguti/jppp/business/core/event"
+)
+
+// Run - relays the pending events until the context is done, polling every poll interval once
+// the outbox is drained.
+func (c *Core) Run(ctx context.Context) {
+	t := time.NewTicker(c.cfg.PollInterval)
+	defer t.Stop()
+	for {
+		n, err := c.Relay(ctx)
+		if err != nil {
+			c.log.Err(err).Msg("Unable to relay outbox.")
+		}
+		if err == nil && n >= c.cfg.BatchSize {
+			// More events may be pending, keep going unless stopped.
+			if ctx.Err() != nil {
+				return
+			}
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// Relay - publishes a batch of the pending events, returning how many were published. Events are
+// published at least once: they stay pending when the publisher fails and are published again,
+// to every publisher, by a later relay.
+func (c *Core) Relay(ctx context.Context) (int, error) {
+	ctx, span := tracer.Start(ctx, "outbox.Relay")
+	defer span.End()
+
+	n, err := c.store.Relay(ctx, c.cfg.BatchSize, func(evs []event.Event) error {
+		return c.pub.Publish(ctx, evs...)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("relay: failed to relay events: %w", err)
+	}
+	return n, nil
+}
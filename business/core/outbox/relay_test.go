@@ -0,0 +1,135 @@
+package outbox_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lenguti/jppp/business/core/cage"
+	"github.com/lenguti/jppp/business/core/cohabitation"
+	"github.com/lenguti/jppp/business/core/dino"
+	"github.com/lenguti/jppp/business/core/event"
+	"github.com/lenguti/jppp/business/core/outbox"
+	"github.com/lenguti/jppp/business/core/outbox/stores/outboxdb"
+	"github.com/lenguti/jppp/business/core/species"
+	"github.com/lenguti/jppp/business/data/dbtest"
+	"github.com/lenguti/jppp/business/data/memstore"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recorder - represents a publisher recording the events it was given, failing with err when set.
+type recorder struct {
+	evs []event.Event
+	err error
+}
+
+func (r *recorder) Publish(ctx context.Context, evs ...event.Event) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.evs = append(r.evs, evs...)
+	return nil
+}
+
+func TestRelay(t *testing.T) {
+	ctx := context.Background()
+
+	// setup - returns a cage core and the outbox store backed by the same in-memory store.
+	setup := func() (*cage.Core, *memstore.OutboxStore) {
+		ms := memstore.New()
+		sc := species.NewCore(memstore.NewSpeciesStore(ms), zerolog.Nop(), species.Config{})
+		dc := dino.NewCore(memstore.NewDinoStore(ms), zerolog.Nop(), sc)
+		rc := cohabitation.NewCore(memstore.NewCohabitationStore(ms), zerolog.Nop(), sc, cohabitation.Config{})
+		return cage.NewCore(memstore.NewCageStore(ms), zerolog.Nop(), dc, rc), memstore.NewOutboxStore(ms)
+	}
+	newCage := cage.NewCage{Type: cage.CageTypeHerbivore, Capacity: 2, Status: cage.CageStatusActive}
+
+	t.Run("publishes pending events once", func(t *testing.T) {
+		// Setup.
+		cc, store := setup()
+		c, err := cc.Create(ctx, newCage)
+		require.NoError(t, err)
+		_, err = cc.UpdateStatus(ctx, c.ID, cage.CageStatusDown, 0)
+		require.NoError(t, err)
+		pub := &recorder{}
+		oc := outbox.NewCore(store, zerolog.Nop(), pub, outbox.Config{})
+
+		// Execute.
+		n, err := oc.Relay(ctx)
+		require.NoError(t, err)
+		again, err := oc.Relay(ctx)
+
+		// Validate.
+		require.NoError(t, err)
+		assert.Equal(t, 2, n)
+		assert.Zero(t, again)
+		require.Len(t, pub.evs, 2)
+		assert.Equal(t, event.TypeCageCreated, pub.evs[0].Type)
+		assert.Equal(t, event.TypeCageStatusChanged, pub.evs[1].Type)
+		assert.Equal(t, []uint64{1, 2}, []uint64{pub.evs[0].ID, pub.evs[1].ID})
+		assert.Equal(t, c.ID, pub.evs[1].EntityID)
+	})
+
+	t.Run("publishes in batches", func(t *testing.T) {
+		// Setup.
+		cc, store := setup()
+		for i := 0; i < 3; i++ {
+			_, err := cc.Create(ctx, newCage)
+			require.NoError(t, err)
+		}
+		pub := &recorder{}
+		oc := outbox.NewCore(store, zerolog.Nop(), pub, outbox.Config{BatchSize: 2})
+
+		// Execute.
+		first, err := oc.Relay(ctx)
+		require.NoError(t, err)
+		second, err := oc.Relay(ctx)
+
+		// Validate.
+		require.NoError(t, err)
+		assert.Equal(t, 2, first)
+		assert.Equal(t, 1, second)
+		require.Len(t, pub.evs, 3)
+		assert.Equal(t, uint64(3), pub.evs[2].ID)
+	})
+
+	t.Run("failed publish keeps events pending", func(t *testing.T) {
+		// Setup.
+		cc, store := setup()
+		_, err := cc.Create(ctx, newCage)
+		require.NoError(t, err)
+		pub := &recorder{err: errors.New("broker down")}
+		oc := outbox.NewCore(store, zerolog.Nop(), pub, outbox.Config{})
+
+		// Execute.
+		_, err = oc.Relay(ctx)
+		require.Error(t, err)
+		pub.err = nil
+		n, err := oc.Relay(ctx)
+
+		// Validate.
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+		require.Len(t, pub.evs, 1)
+		assert.Equal(t, uint64(1), pub.evs[0].ID)
+	})
+	t.Run("closed db keeps running", func(t *testing.T) {
+		// Setup.
+		pub := &recorder{}
+		oc := outbox.NewCore(outboxdb.NewStore(dbtest.Closed(t)), zerolog.Nop(), pub, outbox.Config{PollInterval: time.Millisecond})
+		runCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+		defer cancel()
+
+		// Execute.
+		var err error
+		require.NotPanics(t, func() { _, err = oc.Relay(ctx) })
+		require.NotPanics(t, func() { oc.Run(runCtx) })
+
+		// Validate.
+		assert.ErrorContains(t, err, "relay: failed to relay events")
+		assert.Empty(t, pub.evs)
+	})
+}
@@ -0,0 +1,61 @@
+package outboxdb
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lenguti/jppp/business/core/event"
+)
+
+type dbEvent struct {
+	ID         uint64         `db:"id"`
+	EventType  string         `db:"event_type"`
+	EntityType string         `db:"entity_type"`
+	EntityID   string         `db:"entity_id"`
+	CageID     sql.NullString `db:"cage_id"`
+	Data       []byte         `db:"data"`
+	RequestID  string         `db:"request_id"`
+	CreatedAt  int64          `db:"created_at"`
+	SentAt     sql.NullInt64  `db:"sent_at"`
+}
+
+func toDBEvent(e event.Event) dbEvent {
+	dbe := dbEvent{
+		EventType:  e.Type,
+		EntityType: e.EntityType,
+		EntityID:   e.EntityID.String(),
+		Data:       e.Data,
+		RequestID:  e.RequestID,
+		CreatedAt:  e.CreatedAt.Unix(),
+	}
+	if e.CageID != uuid.Nil {
+		dbe.CageID = sql.NullString{String: e.CageID.String(), Valid: true}
+	}
+	return dbe
+}
+
+func toCoreEvents(dbEvents []dbEvent) []event.Event {
+	evs := make([]event.Event, 0, len(dbEvents))
+	for _, v := range dbEvents {
+		evs = append(evs, toCoreEvent(v))
+	}
+	return evs
+}
+
+func toCoreEvent(dbe dbEvent) event.Event {
+	e := event.Event{
+		ID:         dbe.ID,
+		Type:       dbe.EventType,
+		EntityType: dbe.EntityType,
+		EntityID:   uuid.MustParse(dbe.EntityID),
+		Data:       json.RawMessage(dbe.Data),
+		RequestID:  dbe.RequestID,
+		CreatedAt:  time.Unix(dbe.CreatedAt, 0),
+	}
+	if dbe.CageID.Valid {
+		e.CageID = uuid.MustParse(dbe.CageID.String)
+	}
+	return e
+}
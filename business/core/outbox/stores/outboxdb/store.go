@@ -0,0 +1,105 @@
+package outboxdb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lenguti/jppp/business/core/event"
+	"github.com/lenguti/jppp/business/core/outbox"
+	"github.com/lenguti/jppp/business/data/db"
+	"github.com/lib/pq"
+)
+
+var _ outbox.Storer = (*Store)(nil)
+
+// Store - manages the set of apis for outbox database access.
+type Store struct {
+	db *db.DB
+}
+
+// NewStore - constructs the api for data access.
+func NewStore(db *db.DB) *Store {
+	return &Store{
+		db: db,
+	}
+}
+
+// Append - will append the events to the outbox within the tx of the change they describe.
+func (s *Store) Append(ctx context.Context, tx *sqlx.Tx, evs ...event.Event) error {
+	const q = `
+	INSERT INTO outbox (
+		event_type,
+		entity_type,
+		entity_id,
+		cage_id,
+		data,
+		request_id,
+		created_at
+	) VALUES (
+		:event_type,
+		:entity_type,
+		:entity_id,
+		:cage_id,
+		:data,
+		:request_id,
+		:created_at
+	)
+	`
+	for _, e := range evs {
+		if _, err := s.db.NamedExecTx(ctx, tx, q, toDBEvent(e)); err != nil {
+			return fmt.Errorf("append: failed to append event: %w", err)
+		}
+	}
+	return nil
+}
+
+// Relay - will lock a batch of pending events, skipping the ones locked by a concurrent relay, and
+// mark them as sent in the same tx once fn succeeds. The lock is released on failure.
+func (s *Store) Relay(ctx context.Context, limit int, fn func(evs []event.Event) error) (int, error) {
+	const (
+		selectQ = `
+		SELECT *
+		FROM outbox
+		WHERE sent_at IS NULL
+		ORDER BY id ASC
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED
+		`
+		sentQ = `
+		UPDATE outbox
+		SET sent_at = $1
+		WHERE id = ANY($2)
+		`
+	)
+
+	var n int
+	err := s.db.WithTx(ctx, func(tx *sqlx.Tx) error {
+		var out []dbEvent
+		if err := s.db.SelectTx(ctx, tx, &out, selectQ, limit); err != nil {
+			return fmt.Errorf("failed to lock events: %w", err)
+		}
+		if len(out) == 0 {
+			return nil
+		}
+
+		if err := fn(toCoreEvents(out)); err != nil {
+			return err
+		}
+
+		ids := make([]int64, 0, len(out))
+		for _, v := range out {
+			ids = append(ids, int64(v.ID))
+		}
+		if _, err := s.db.ExecTx(ctx, tx, sentQ, time.Now().UTC().Unix(), pq.Array(ids)); err != nil {
+			return fmt.Errorf("failed to mark events sent: %w", err)
+		}
+		n = len(out)
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("relay: %w", err)
+	}
+	return n, nil
+}
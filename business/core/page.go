@@ -0,0 +1,124 @@
+package core
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// DefaultLimit - the page size used when none is requested.
+	DefaultLimit = 50
+
+	// MaxLimit - the largest page size that can be requested.
+	MaxLimit = 500
+)
+
+const (
+	SortCreatedAt = "createdAt"
+	SortUpdatedAt = "updatedAt"
+	SortName      = "name"
+)
+
+// Sort - represents the ordering of a list. Ties are always broken by id.
+type Sort struct {
+	Field string
+	Desc  bool
+}
+
+// String - returns string representation of sort.
+func (s Sort) String() string {
+	if s.Desc {
+		return "-" + s.Field
+	}
+	return s.Field
+}
+
+// ParseSort - will attempt to parse a sort expression such as "-updatedAt" against the allowed fields.
+func ParseSort(v string, allowed ...string) (Sort, error) {
+	if v == "" {
+		return Sort{Field: SortCreatedAt}, nil
+	}
+
+	s := Sort{Field: strings.TrimPrefix(v, "-"), Desc: strings.HasPrefix(v, "-")}
+	for _, f := range allowed {
+		if f == s.Field {
+			return s, nil
+		}
+	}
+	return Sort{}, fmt.Errorf("parse sort: invalid sort field %s", s.Field)
+}
+
+// Page - represents a window into a sorted list. A zero limit means no limit.
+type Page struct {
+	Limit  int
+	Cursor string
+	Sort   Sort
+}
+
+// Peek - returns the page to request from a store, one item larger so the
+// presence of a next page can be detected.
+func (p Page) Peek() Page {
+	if p.Limit > 0 {
+		p.Limit++
+	}
+	return p
+}
+
+// Cursor - represents the position of the last item of a page.
+type Cursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    string `json:"id"`
+}
+
+// EncodeCursor - returns the opaque token for the item with the provided sort value and id.
+func EncodeCursor(s Sort, value, id string) string {
+	bs, _ := json.Marshal(Cursor{Sort: s.String(), Value: value, ID: id})
+	return base64.RawURLEncoding.EncodeToString(bs)
+}
+
+// DecodeCursor - will decode the page cursor, validating it was issued for the page sort and holds
+// an item id along with a unix timestamp, as every sort but name orders by one.
+// The returned bool is false when the page has no cursor.
+func DecodeCursor(p Page) (Cursor, bool, error) {
+	if p.Cursor == "" {
+		return Cursor{}, false, nil
+	}
+
+	bs, err := base64.RawURLEncoding.DecodeString(p.Cursor)
+	if err != nil {
+		return Cursor{}, false, ErrInvalidCursor
+	}
+
+	var c Cursor
+	if err := json.Unmarshal(bs, &c); err != nil || c.Sort != p.Sort.String() {
+		return Cursor{}, false, ErrInvalidCursor
+	}
+	if _, err := uuid.Parse(c.ID); err != nil {
+		return Cursor{}, false, ErrInvalidCursor
+	}
+	if p.Sort.Field != SortName {
+		if _, err := strconv.ParseInt(c.Value, 10, 64); err != nil {
+			return Cursor{}, false, ErrInvalidCursor
+		}
+	}
+	return c, true, nil
+}
+
+// NextPage - trims items fetched with page.Peek down to the page limit and returns the
+// cursor for the following page, or an empty string on the last page. key returns the
+// sort value and id of an item.
+func NextPage[T any](page Page, items []T, key func(T) (string, string)) ([]T, string) {
+	if page.Limit <= 0 || len(items) <= page.Limit {
+		return items, ""
+	}
+
+	items = items[:page.Limit]
+	value, id := key(items[len(items)-1])
+	return items, EncodeCursor(page.Sort, value, id)
+}
@@ -0,0 +1,43 @@
+package placement
+
+import (
+	"context"
+	"time"
+
+	"github.com/lenguti/jppp/business/core"
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel"
+)
+
+var tracer = otel.Tracer("github.com/lenguti/jppp/business/core/placement")
+
+// Storer - represents the data layer behavior for placements.
+//
+// Placements are opened and closed by the cage store as dinos are added to, removed from and
+// transferred between cages, along with the change.
+//
+// List returns at most page.Limit placements ordered by page.Sort and then id, starting after page.Cursor.
+// ListAt returns every placement that was ongoing at the given time, ordered by assignment.
+type Storer interface {
+	List(ctx context.Context, page core.Page, filters ...core.Filter) ([]Placement, error)
+	ListAt(ctx context.Context, at time.Time, filters ...core.Filter) ([]Placement, error)
+}
+
+// Core - represents the core business logic for placements.
+type Core struct {
+	store Storer
+	log   zerolog.Logger
+}
+
+// NewCore - returns a new placement core with all its components initialized.
+func NewCore(store Storer, log zerolog.Logger) *Core {
+	return &Core{
+		store: store,
+		log:   log,
+	}
+}
+
+// logger - returns the request scoped logger, falling back to the core logger.
+func (c *Core) logger(ctx context.Context) *zerolog.Logger {
+	return core.Logger(ctx, c.log)
+}
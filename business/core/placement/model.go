@@ -0,0 +1,57 @@
+package placement
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lenguti/jppp/business/core"
+)
+
+// Placement - represents a business domain stay of a dino in a cage. The stay is still
+// ongoing while ReleasedAt is zero.
+type Placement struct {
+	ID         uuid.UUID
+	CageID     uuid.UUID
+	DinoID     uuid.UUID
+	AssignedAt time.Time
+	ReleasedAt time.Time
+}
+
+// SortAssignedAt - sorts placements by when the dino was assigned to the cage, which is also
+// what placements sorted by core.SortCreatedAt, the default, are ordered by.
+const SortAssignedAt = "assignedAt"
+
+// SortFields - the fields placements can be sorted by.
+var SortFields = []string{SortAssignedAt}
+
+// FilterFields - the fields placements can be filtered by.
+var FilterFields = core.Fields{
+	"cage_id":     {Kind: core.KindString, Normalize: normalizeID},
+	"dinosaur_id": {Kind: core.KindString, Normalize: normalizeID},
+	"assignedAt":  {Kind: core.KindInt},
+	"releasedAt":  {Kind: core.KindInt},
+}
+
+// Released - reports whether the dino has left the cage.
+func (p Placement) Released() bool {
+	return !p.ReleasedAt.IsZero()
+}
+
+// ActiveAt - reports whether the dino was in the cage at the given time, to the second.
+func (p Placement) ActiveAt(at time.Time) bool {
+	sec := at.Unix()
+	return p.AssignedAt.Unix() <= sec && (!p.Released() || p.ReleasedAt.Unix() > sec)
+}
+
+func (p Placement) sortValue() string {
+	return strconv.FormatInt(p.AssignedAt.Unix(), 10)
+}
+
+func normalizeID(v string) (string, error) {
+	id, err := uuid.Parse(v)
+	if err != nil {
+		return "", err
+	}
+	return id.String(), nil
+}
@@ -0,0 +1,72 @@
+package placement
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lenguti/jppp/business/core"
+)
+
+// CageHistory - will list a page of the placements of dinos in the cage along with the cursor of the next page, if any.
+func (c *Core) CageHistory(ctx context.Context, cageID uuid.UUID, page core.Page, filters ...core.Filter) ([]Placement, string, error) {
+	ctx, span := tracer.Start(ctx, "placement.CageHistory")
+	defer span.End()
+
+	ps, next, err := c.list(ctx, page, append(filters, core.Filter{Key: "cage_id", Value: cageID.String()})...)
+	if err != nil {
+		return nil, "", fmt.Errorf("cage history: %w", err)
+	}
+	return ps, next, nil
+}
+
+// DinoHistory - will list a page of the placements of the dino along with the cursor of the next page, if any.
+func (c *Core) DinoHistory(ctx context.Context, dinoID uuid.UUID, page core.Page, filters ...core.Filter) ([]Placement, string, error) {
+	ctx, span := tracer.Start(ctx, "placement.DinoHistory")
+	defer span.End()
+
+	ps, next, err := c.list(ctx, page, append(filters, core.Filter{Key: "dinosaur_id", Value: dinoID.String()})...)
+	if err != nil {
+		return nil, "", fmt.Errorf("dino history: %w", err)
+	}
+	return ps, next, nil
+}
+
+// CageRoster - will list the placements of the dinos that were in the cage at the given time.
+func (c *Core) CageRoster(ctx context.Context, cageID uuid.UUID, at time.Time) ([]Placement, error) {
+	ctx, span := tracer.Start(ctx, "placement.CageRoster")
+	defer span.End()
+
+	c.logger(ctx).Info().Fields(map[string]any{"cage_id": cageID, "at": at.Unix()}).Msg("Listing cage roster.")
+	ps, err := c.store.ListAt(ctx, at, core.Filter{Key: "cage_id", Value: cageID.String()})
+	if err != nil {
+		return nil, fmt.Errorf("cage roster: failed to list placements: %w", err)
+	}
+	return ps, nil
+}
+
+// DinoPlacement - will list the placement of the dino at the given time, which holds at most one.
+func (c *Core) DinoPlacement(ctx context.Context, dinoID uuid.UUID, at time.Time) ([]Placement, error) {
+	ctx, span := tracer.Start(ctx, "placement.DinoPlacement")
+	defer span.End()
+
+	c.logger(ctx).Info().Fields(map[string]any{"dinosaur_id": dinoID, "at": at.Unix()}).Msg("Listing dino placement.")
+	ps, err := c.store.ListAt(ctx, at, core.Filter{Key: "dinosaur_id", Value: dinoID.String()})
+	if err != nil {
+		return nil, fmt.Errorf("dino placement: failed to list placements: %w", err)
+	}
+	return ps, nil
+}
+
+func (c *Core) list(ctx context.Context, page core.Page, filters ...core.Filter) ([]Placement, string, error) {
+	c.logger(ctx).Info().Fields(map[string]any{"filters": filters, "sort": page.Sort.String(), "limit": page.Limit}).Msg("Listing placements.")
+	ps, err := c.store.List(ctx, page.Peek(), filters...)
+	if err != nil {
+		return nil, "", fmt.Errorf("list: failed to list placements: %w", err)
+	}
+	ps, next := core.NextPage(page, ps, func(p Placement) (string, string) {
+		return p.sortValue(), p.ID.String()
+	})
+	return ps, next, nil
+}
@@ -0,0 +1,37 @@
+package placementdb
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lenguti/jppp/business/core/placement"
+)
+
+type dbPlacement struct {
+	ID         string `db:"id"`
+	CageID     string `db:"cage_id"`
+	DinoID     string `db:"dinosaur_id"`
+	AssignedAt int64  `db:"assigned_at"`
+	ReleasedAt *int64 `db:"released_at"`
+}
+
+func toCorePlacements(dbPlacements []dbPlacement) []placement.Placement {
+	ps := make([]placement.Placement, 0, len(dbPlacements))
+	for _, v := range dbPlacements {
+		ps = append(ps, toCorePlacement(v))
+	}
+	return ps
+}
+
+func toCorePlacement(dbp dbPlacement) placement.Placement {
+	p := placement.Placement{
+		ID:         uuid.MustParse(dbp.ID),
+		CageID:     uuid.MustParse(dbp.CageID),
+		DinoID:     uuid.MustParse(dbp.DinoID),
+		AssignedAt: time.Unix(dbp.AssignedAt, 0),
+	}
+	if dbp.ReleasedAt != nil {
+		p.ReleasedAt = time.Unix(*dbp.ReleasedAt, 0)
+	}
+	return p
+}
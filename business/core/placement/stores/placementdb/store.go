@@ -0,0 +1,167 @@
+package placementdb
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lenguti/jppp/business/core"
+	"github.com/lenguti/jppp/business/core/placement"
+	"github.com/lenguti/jppp/business/data/db"
+)
+
+// Store - manages the set of apis for placement database access.
+type Store struct {
+	db *db.DB
+}
+
+// NewStore - constructs the api for data access.
+func NewStore(db *db.DB) *Store {
+	return &Store{
+		db: db,
+	}
+}
+
+// Assign - will open the placement of the dino in the cage within the tx of the change placing it.
+func (s *Store) Assign(ctx context.Context, tx *sqlx.Tx, cageID, dinoID string, ts time.Time) error {
+	const q = `
+	INSERT INTO placement (
+		id,
+		cage_id,
+		dinosaur_id,
+		assigned_at
+	) VALUES (
+		$1,
+		$2,
+		$3,
+		$4
+	)
+	`
+	if _, err := s.db.ExecTx(ctx, tx, q, uuid.NewString(), cageID, dinoID, ts.Unix()); err != nil {
+		return fmt.Errorf("assign: failed to insert placement: %w", err)
+	}
+	return nil
+}
+
+// Release - will close the ongoing placement of the dino in the cage within the tx of the change
+// removing it. Dinos caged before placements were recorded have none to close.
+func (s *Store) Release(ctx context.Context, tx *sqlx.Tx, cageID, dinoID string, ts time.Time) error {
+	const q = `
+	UPDATE placement
+	SET
+	released_at = $1
+	WHERE cage_id = $2
+	AND dinosaur_id = $3
+	AND released_at IS NULL
+	`
+	if _, err := s.db.ExecTx(ctx, tx, q, ts.Unix(), cageID, dinoID); err != nil {
+		return fmt.Errorf("release: failed to update placement: %w", err)
+	}
+	return nil
+}
+
+// List - will list a page of placements.
+func (s *Store) List(ctx context.Context, page core.Page, filters ...core.Filter) ([]placement.Placement, error) {
+	q, vals, err := listClauseBuilder(page, filters...)
+	if err != nil {
+		return nil, fmt.Errorf("list: failed to build query: %w", err)
+	}
+	var out []dbPlacement
+	if err := s.db.List(ctx, &out, q, vals...); err != nil {
+		return nil, fmt.Errorf("list: failed to list placements: %w", err)
+	}
+	return toCorePlacements(out), nil
+}
+
+// ListAt - will list the placements ongoing at the given time.
+func (s *Store) ListAt(ctx context.Context, at time.Time, filters ...core.Filter) ([]placement.Placement, error) {
+	q, vals, err := listAtClauseBuilder(at, filters...)
+	if err != nil {
+		return nil, fmt.Errorf("list at: failed to build query: %w", err)
+	}
+	var out []dbPlacement
+	if err := s.db.List(ctx, &out, q, vals...); err != nil {
+		return nil, fmt.Errorf("list at: failed to list placements: %w", err)
+	}
+	return toCorePlacements(out), nil
+}
+
+var filterMap = map[string]string{
+	"cage_id":     "cage_id",
+	"dinosaur_id": "dinosaur_id",
+	"assignedAt":  "assigned_at",
+	"releasedAt":  "released_at",
+}
+
+func listClauseBuilder(page core.Page, filters ...core.Filter) (string, []string, error) {
+	const q = `
+	SELECT *
+	FROM placement
+	`
+
+	sortMap := map[string]string{
+		core.SortCreatedAt:       "assigned_at",
+		placement.SortAssignedAt: "assigned_at",
+	}
+
+	conds, vals, err := db.FilterClause(filters, filterMap, 1)
+	if err != nil {
+		return "", nil, fmt.Errorf("list clause builder: %w", err)
+	}
+
+	field := page.Sort.Field
+	if field == "" {
+		field = core.SortCreatedAt
+	}
+	column, ok := sortMap[field]
+	if !ok {
+		return "", nil, fmt.Errorf("list clause builder: invalid sort field %s", field)
+	}
+
+	cond, tail, pageVals, err := db.PageClause(page, column, len(vals)+1)
+	if err != nil {
+		return "", nil, fmt.Errorf("list clause builder: %w", err)
+	}
+	if cond != "" {
+		conds = append(conds, cond)
+		vals = append(vals, pageVals...)
+	}
+
+	var b strings.Builder
+	b.WriteString(q)
+	if len(conds) > 0 {
+		b.WriteString("WHERE ")
+		b.WriteString(strings.Join(conds, "\n\tAND "))
+		b.WriteString("\n\t")
+	}
+	b.WriteString(tail)
+	return b.String(), vals, nil
+}
+
+func listAtClauseBuilder(at time.Time, filters ...core.Filter) (string, []string, error) {
+	const q = `
+	SELECT *
+	FROM placement
+	WHERE assigned_at <= $1
+	AND (released_at IS NULL OR released_at > $1)
+	`
+
+	conds, vals, err := db.FilterClause(filters, filterMap, 2)
+	if err != nil {
+		return "", nil, fmt.Errorf("list at clause builder: %w", err)
+	}
+
+	var b strings.Builder
+	b.WriteString(q)
+	for _, c := range conds {
+		b.WriteString("AND ")
+		b.WriteString(c)
+		b.WriteString("\n\t")
+	}
+	b.WriteString("ORDER BY assigned_at ASC, id ASC\n\t")
+	return b.String(), append([]string{strconv.FormatInt(at.Unix(), 10)}, vals...), nil
+}
@@ -0,0 +1,51 @@
+package placementdb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lenguti/jppp/business/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListClauseBuilder(t *testing.T) {
+	t.Run("cage filter", func(t *testing.T) {
+		want := `
+	SELECT *
+	FROM placement
+	WHERE cage_id = $1
+	ORDER BY assigned_at ASC, id ASC
+	`
+
+		wantVals := []string{"9c0b5d2e-8a47-4b1c-9f0e-3d4c5b6a7e8f"}
+		got, gotVals, err := listClauseBuilder(core.Page{}, core.Filter{Key: "cage_id", Value: "9c0b5d2e-8a47-4b1c-9f0e-3d4c5b6a7e8f"})
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+		assert.Equal(t, wantVals, gotVals)
+	})
+
+	t.Run("unknown filter", func(t *testing.T) {
+		_, _, err := listClauseBuilder(core.Page{}, core.Filter{Key: "deletedAt", Op: core.OpNull, Value: "true"})
+		assert.ErrorIs(t, err, core.ErrInvalidFilter)
+	})
+}
+
+func TestListAtClauseBuilder(t *testing.T) {
+	t.Run("cage roster", func(t *testing.T) {
+		want := `
+	SELECT *
+	FROM placement
+	WHERE assigned_at <= $1
+	AND (released_at IS NULL OR released_at > $1)
+	AND cage_id = $2
+	ORDER BY assigned_at ASC, id ASC
+	`
+
+		wantVals := []string{"1690000000", "9c0b5d2e-8a47-4b1c-9f0e-3d4c5b6a7e8f"}
+		got, gotVals, err := listAtClauseBuilder(time.Unix(1690000000, 0), core.Filter{Key: "cage_id", Value: "9c0b5d2e-8a47-4b1c-9f0e-3d4c5b6a7e8f"})
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+		assert.Equal(t, wantVals, gotVals)
+	})
+}
@@ -0,0 +1,16 @@
+package core
+
+import "context"
+
+type requestIDKey struct{}
+
+// WithRequestID - returns a copy of the context carrying the id of the request being served.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID - returns the request id carried by the context, if any.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
@@ -0,0 +1,81 @@
+// Package species manages the registry of the species dinosaurs may belong to.
+package species
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/lenguti/jppp/business/core"
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel"
+)
+
+var tracer = otel.Tracer("github.com/lenguti/jppp/business/core/species")
+
+// Default registry settings, used when the related config field is not set.
+const (
+	defaultCacheTTL = time.Minute
+)
+
+// Storer - represents the data layer behavior for species.
+//
+// Create returns core.ErrInvalidSpeciesExists when a species of the same name exists. Delete
+// returns core.ErrInvalidSpeciesInUse while dinosaurs, soft deleted ones included, belong to it.
+//
+// List returns at most page.Limit species ordered by page.Sort and then id, starting after page.Cursor.
+// ListAll returns every species ordered by name.
+type Storer interface {
+	Create(ctx context.Context, s Species) error
+	Get(ctx context.Context, name string) (Species, error)
+	List(ctx context.Context, page core.Page, filters ...core.Filter) ([]Species, error)
+	ListAll(ctx context.Context) ([]Species, error)
+	Update(ctx context.Context, s Species) error
+	Delete(ctx context.Context, name string) error
+}
+
+// Config - represents the registry settings. Lookups are served from a cache of every species,
+// reloaded once older than CacheTTL and whenever this core changes a species.
+type Config struct {
+	CacheTTL time.Duration
+}
+
+// withDefaults - returns the config with its unset fields set to their default.
+func (c Config) withDefaults() Config {
+	if c.CacheTTL <= 0 {
+		c.CacheTTL = defaultCacheTTL
+	}
+	return c
+}
+
+// Core - represents the core business logic for species.
+type Core struct {
+	store Storer
+	log   zerolog.Logger
+	cfg   Config
+
+	mu    sync.RWMutex
+	gen   uint64
+	cache *registry
+}
+
+// registry - represents the cached species.
+type registry struct {
+	all      []Species
+	byName   map[string]Species
+	loadedAt time.Time
+}
+
+// NewCore - returns a new species core with all its components initialized.
+func NewCore(store Storer, log zerolog.Logger, cfg Config) *Core {
+	return &Core{
+		store: store,
+		log:   log,
+		cfg:   cfg.withDefaults(),
+	}
+}
+
+// logger - returns the request scoped logger, falling back to the core logger.
+func (c *Core) logger(ctx context.Context) *zerolog.Logger {
+	return core.Logger(ctx, c.log)
+}
@@ -0,0 +1,151 @@
+package species
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lenguti/jppp/business/core"
+)
+
+// Diets.
+const (
+	DietCarnivore = "CARNIVORE"
+	DietHerbivore = "HERBIVORE"
+)
+
+// Size classes, from the smallest to the largest.
+const (
+	SizeSmall  = "SMALL"
+	SizeMedium = "MEDIUM"
+	SizeLarge  = "LARGE"
+	SizeHuge   = "HUGE"
+)
+
+// Temperaments, from the calmest to the most dangerous.
+const (
+	TemperamentDocile      = "DOCILE"
+	TemperamentSkittish    = "SKITTISH"
+	TemperamentTerritorial = "TERRITORIAL"
+	TemperamentAggressive  = "AGGRESSIVE"
+)
+
+var (
+	validDiets        = []string{DietCarnivore, DietHerbivore}
+	validSizeClasses  = []string{SizeSmall, SizeMedium, SizeLarge, SizeHuge}
+	validTemperaments = []string{TemperamentDocile, TemperamentSkittish, TemperamentTerritorial, TemperamentAggressive}
+)
+
+// Species - represents a business domain species, identified by its unique name. Herd species
+// should be kept with others of their kind, solitary ones alone.
+type Species struct {
+	ID          uuid.UUID
+	Name        string
+	Diet        string
+	SizeClass   string
+	Temperament string
+	Herd        bool
+	Solitary    bool
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// NewSpecies - represents the input for creating a species.
+type NewSpecies struct {
+	Name        string
+	Diet        string
+	SizeClass   string
+	Temperament string
+	Herd        bool
+	Solitary    bool
+}
+
+// UpdateSpecies - represents the input for updating a species, unset fields are left as is. The name
+// and diet of a species are fixed as its dinosaurs were created with them.
+type UpdateSpecies struct {
+	SizeClass   *string
+	Temperament *string
+	Herd        *bool
+	Solitary    *bool
+}
+
+// SortFields - the fields species can be sorted by.
+var SortFields = []string{core.SortCreatedAt, core.SortName}
+
+// FilterFields - the fields species can be filtered by.
+var FilterFields = core.Fields{
+	"diet":        {Kind: core.KindString, Normalize: normalizeDiet},
+	"size_class":  {Kind: core.KindString, Normalize: normalizeSizeClass},
+	"temperament": {Kind: core.KindString, Normalize: normalizeTemperament},
+	"createdAt":   {Kind: core.KindInt},
+}
+
+func (s Species) sortValue(field string) string {
+	switch field {
+	case core.SortName:
+		return s.Name
+	default:
+		return strconv.FormatInt(s.CreatedAt.Unix(), 10)
+	}
+}
+
+// NormalizeName - returns the name species are registered under, title cased.
+func NormalizeName(v string) string {
+	return strings.Title(strings.ToLower(strings.TrimSpace(v)))
+}
+
+// ParseDiet - will attempt to validate the provided diet.
+func ParseDiet(v string) error {
+	if !contains(validDiets, strings.ToUpper(v)) {
+		return fmt.Errorf("parse diet: invalid diet")
+	}
+	return nil
+}
+
+// ParseSizeClass - will attempt to validate the provided size class.
+func ParseSizeClass(v string) error {
+	if !contains(validSizeClasses, strings.ToUpper(v)) {
+		return fmt.Errorf("parse size class: invalid size class")
+	}
+	return nil
+}
+
+// ParseTemperament - will attempt to validate the provided temperament.
+func ParseTemperament(v string) error {
+	if !contains(validTemperaments, strings.ToUpper(v)) {
+		return fmt.Errorf("parse temperament: invalid temperament")
+	}
+	return nil
+}
+
+func normalizeDiet(v string) (string, error) {
+	if err := ParseDiet(v); err != nil {
+		return "", err
+	}
+	return strings.ToUpper(v), nil
+}
+
+func normalizeSizeClass(v string) (string, error) {
+	if err := ParseSizeClass(v); err != nil {
+		return "", err
+	}
+	return strings.ToUpper(v), nil
+}
+
+func normalizeTemperament(v string) (string, error) {
+	if err := ParseTemperament(v); err != nil {
+		return "", err
+	}
+	return strings.ToUpper(v), nil
+}
+
+func contains(vs []string, v string) bool {
+	for _, s := range vs {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
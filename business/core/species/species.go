@@ -0,0 +1,215 @@
+package species
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lenguti/jppp/business/core"
+)
+
+// Create - will register a new species.
+func (c *Core) Create(ctx context.Context, ns NewSpecies) (Species, error) {
+	ctx, span := tracer.Start(ctx, "species.Create")
+	defer span.End()
+
+	if err := core.Authorize(ctx, core.PermSpeciesManage); err != nil {
+		return Species{}, fmt.Errorf("create: %w", err)
+	}
+
+	now := time.Now().UTC()
+	s := Species{
+		ID:          uuid.New(),
+		Name:        NormalizeName(ns.Name),
+		Diet:        strings.ToUpper(ns.Diet),
+		SizeClass:   strings.ToUpper(ns.SizeClass),
+		Temperament: strings.ToUpper(ns.Temperament),
+		Herd:        ns.Herd,
+		Solitary:    ns.Solitary,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := s.validate(); err != nil {
+		return Species{}, fmt.Errorf("create: %w", err)
+	}
+	if err := c.store.Create(ctx, s); err != nil {
+		return Species{}, fmt.Errorf("create: failed to create species: %w", err)
+	}
+	c.invalidate()
+	c.logger(ctx).Info().Fields(map[string]any{"species": s.Name, "diet": s.Diet}).Msg("Created species.")
+	return s, nil
+}
+
+// Get - will fetch a species by its name.
+func (c *Core) Get(ctx context.Context, name string) (Species, error) {
+	ctx, span := tracer.Start(ctx, "species.Get")
+	defer span.End()
+
+	if err := core.Authorize(ctx, core.PermSpeciesRead); err != nil {
+		return Species{}, fmt.Errorf("get: %w", err)
+	}
+
+	s, err := c.store.Get(ctx, NormalizeName(name))
+	if err != nil {
+		return Species{}, fmt.Errorf("get: failed to fetch species: %w", err)
+	}
+	return s, nil
+}
+
+// List - will list a page of species along with the cursor of the next page, if any.
+func (c *Core) List(ctx context.Context, page core.Page, filters ...core.Filter) ([]Species, string, error) {
+	ctx, span := tracer.Start(ctx, "species.List")
+	defer span.End()
+
+	if err := core.Authorize(ctx, core.PermSpeciesRead); err != nil {
+		return nil, "", fmt.Errorf("list: %w", err)
+	}
+
+	c.logger(ctx).Info().Fields(map[string]any{"filters": filters, "sort": page.Sort.String(), "limit": page.Limit}).Msg("Listing species.")
+	ss, err := c.store.List(ctx, page.Peek(), filters...)
+	if err != nil {
+		return nil, "", fmt.Errorf("list: failed to list species: %w", err)
+	}
+	ss, next := core.NextPage(page, ss, func(s Species) (string, string) {
+		return s.sortValue(page.Sort.Field), s.ID.String()
+	})
+	return ss, next, nil
+}
+
+// Update - will update the set fields of the species.
+func (c *Core) Update(ctx context.Context, name string, us UpdateSpecies) (Species, error) {
+	ctx, span := tracer.Start(ctx, "species.Update")
+	defer span.End()
+
+	if err := core.Authorize(ctx, core.PermSpeciesManage); err != nil {
+		return Species{}, fmt.Errorf("update: %w", err)
+	}
+
+	s, err := c.store.Get(ctx, NormalizeName(name))
+	if err != nil {
+		return Species{}, fmt.Errorf("update: failed to fetch species: %w", err)
+	}
+	if us.SizeClass != nil {
+		s.SizeClass = strings.ToUpper(*us.SizeClass)
+	}
+	if us.Temperament != nil {
+		s.Temperament = strings.ToUpper(*us.Temperament)
+	}
+	if us.Herd != nil {
+		s.Herd = *us.Herd
+	}
+	if us.Solitary != nil {
+		s.Solitary = *us.Solitary
+	}
+	if err := s.validate(); err != nil {
+		return Species{}, fmt.Errorf("update: %w", err)
+	}
+
+	s.UpdatedAt = time.Now().UTC()
+	if err := c.store.Update(ctx, s); err != nil {
+		return Species{}, fmt.Errorf("update: failed to update species: %w", err)
+	}
+	c.invalidate()
+	c.logger(ctx).Info().Str("species", s.Name).Msg("Updated species.")
+	return s, nil
+}
+
+// Delete - will delete the species, provided no dinosaur belongs to it.
+func (c *Core) Delete(ctx context.Context, name string) (Species, error) {
+	ctx, span := tracer.Start(ctx, "species.Delete")
+	defer span.End()
+
+	if err := core.Authorize(ctx, core.PermSpeciesManage); err != nil {
+		return Species{}, fmt.Errorf("delete: %w", err)
+	}
+
+	s, err := c.store.Get(ctx, NormalizeName(name))
+	if err != nil {
+		return Species{}, fmt.Errorf("delete: failed to fetch species: %w", err)
+	}
+	if err := c.store.Delete(ctx, s.Name); err != nil {
+		return Species{}, fmt.Errorf("delete: failed to delete species: %w", err)
+	}
+	c.invalidate()
+	c.logger(ctx).Info().Str("species", s.Name).Msg("Deleted species.")
+	return s, nil
+}
+
+// Lookup - will fetch a species by its name from the cache, returning core.ErrNotFound for unknown species.
+func (c *Core) Lookup(ctx context.Context, name string) (Species, error) {
+	r, err := c.load(ctx)
+	if err != nil {
+		return Species{}, fmt.Errorf("lookup: %w", err)
+	}
+	s, ok := r.byName[NormalizeName(name)]
+	if !ok {
+		return Species{}, fmt.Errorf("lookup: unknown species %q: %w", name, core.ErrNotFound)
+	}
+	return s, nil
+}
+
+// All - will return every species from the cache, ordered by name.
+func (c *Core) All(ctx context.Context) ([]Species, error) {
+	r, err := c.load(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("all: %w", err)
+	}
+	return append([]Species(nil), r.all...), nil
+}
+
+// load - returns the cached registry, reloading it from the store once expired.
+func (c *Core) load(ctx context.Context) (*registry, error) {
+	c.mu.RLock()
+	r, gen := c.cache, c.gen
+	c.mu.RUnlock()
+	if r != nil && time.Since(r.loadedAt) < c.cfg.CacheTTL {
+		return r, nil
+	}
+
+	ctx, span := tracer.Start(ctx, "species.load")
+	defer span.End()
+
+	ss, err := c.store.ListAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load: failed to list species: %w", err)
+	}
+	r = &registry{all: ss, byName: make(map[string]Species, len(ss)), loadedAt: time.Now()}
+	for _, s := range ss {
+		r.byName[s.Name] = s
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// A registry loaded while a species changed may miss the change, the next lookup loads it again.
+	if gen == c.gen {
+		c.cache = r
+	}
+	return r, nil
+}
+
+// invalidate - drops the cached registry so the next lookup reloads it.
+func (c *Core) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gen++
+	c.cache = nil
+}
+
+// validate - reports the first invalid field of the species.
+func (s Species) validate() error {
+	switch {
+	case s.Name == "":
+		return fmt.Errorf("validate: name is required")
+	case ParseDiet(s.Diet) != nil:
+		return fmt.Errorf("validate: invalid diet %q", s.Diet)
+	case ParseSizeClass(s.SizeClass) != nil:
+		return fmt.Errorf("validate: invalid size class %q", s.SizeClass)
+	case ParseTemperament(s.Temperament) != nil:
+		return fmt.Errorf("validate: invalid temperament %q", s.Temperament)
+	case s.Herd && s.Solitary:
+		return fmt.Errorf("validate: species cannot be both herd and solitary")
+	}
+	return nil
+}
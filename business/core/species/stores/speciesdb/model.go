@@ -0,0 +1,56 @@
+package speciesdb
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lenguti/jppp/business/core/species"
+)
+
+type dbSpecies struct {
+	ID          string `db:"id"`
+	Name        string `db:"name"`
+	Diet        string `db:"diet"`
+	SizeClass   string `db:"size_class"`
+	Temperament string `db:"temperament"`
+	Herd        bool   `db:"herd"`
+	Solitary    bool   `db:"solitary"`
+	CreatedAt   int64  `db:"created_at"`
+	UpdatedAt   int64  `db:"updated_at"`
+}
+
+func toDBSpecies(s species.Species) dbSpecies {
+	return dbSpecies{
+		ID:          s.ID.String(),
+		Name:        s.Name,
+		Diet:        s.Diet,
+		SizeClass:   s.SizeClass,
+		Temperament: s.Temperament,
+		Herd:        s.Herd,
+		Solitary:    s.Solitary,
+		CreatedAt:   s.CreatedAt.Unix(),
+		UpdatedAt:   s.UpdatedAt.Unix(),
+	}
+}
+
+func toCoreSpeciesList(dbSpecies []dbSpecies) []species.Species {
+	ss := make([]species.Species, 0, len(dbSpecies))
+	for _, v := range dbSpecies {
+		ss = append(ss, toCoreSpecies(v))
+	}
+	return ss
+}
+
+func toCoreSpecies(dbs dbSpecies) species.Species {
+	return species.Species{
+		ID:          uuid.MustParse(dbs.ID),
+		Name:        dbs.Name,
+		Diet:        dbs.Diet,
+		SizeClass:   dbs.SizeClass,
+		Temperament: dbs.Temperament,
+		Herd:        dbs.Herd,
+		Solitary:    dbs.Solitary,
+		CreatedAt:   time.Unix(dbs.CreatedAt, 0),
+		UpdatedAt:   time.Unix(dbs.UpdatedAt, 0),
+	}
+}
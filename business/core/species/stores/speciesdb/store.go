@@ -0,0 +1,220 @@
+package speciesdb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lenguti/jppp/business/core"
+	"github.com/lenguti/jppp/business/core/species"
+	"github.com/lenguti/jppp/business/data/db"
+)
+
+var _ species.Storer = (*Store)(nil)
+
+// Store - manages the set of apis for species database access.
+type Store struct {
+	db *db.DB
+}
+
+// NewStore - constructs the api for data access.
+func NewStore(db *db.DB) *Store {
+	return &Store{
+		db: db,
+	}
+}
+
+// Create - will insert a new species record, unless one of the same name exists.
+func (s *Store) Create(ctx context.Context, sp species.Species) error {
+	const q = `
+	INSERT INTO species (
+		id,
+		name,
+		diet,
+		size_class,
+		temperament,
+		herd,
+		solitary,
+		created_at,
+		updated_at
+	) VALUES (
+		:id,
+		:name,
+		:diet,
+		:size_class,
+		:temperament,
+		:herd,
+		:solitary,
+		:created_at,
+		:updated_at
+	)
+	ON CONFLICT (name) DO NOTHING
+	`
+	n, err := s.db.ExecAffected(ctx, q, toDBSpecies(sp))
+	if err != nil {
+		return fmt.Errorf("create: failed to create species: %w", err)
+	}
+	if n == 0 {
+		return core.ErrInvalidSpeciesExists
+	}
+	return nil
+}
+
+// Get - will fetch a species by its name.
+func (s *Store) Get(ctx context.Context, name string) (species.Species, error) {
+	const q = `
+	SELECT *
+	FROM species
+	WHERE name = $1
+	`
+	var out dbSpecies
+	if err := s.db.Get(ctx, &out, q, name); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return species.Species{}, core.ErrNotFound
+		}
+		return species.Species{}, fmt.Errorf("get: failed to fetch species: %w", err)
+	}
+	return toCoreSpecies(out), nil
+}
+
+// List - will list a page of species.
+func (s *Store) List(ctx context.Context, page core.Page, filters ...core.Filter) ([]species.Species, error) {
+	q, vals, err := listClauseBuilder(page, filters...)
+	if err != nil {
+		return nil, fmt.Errorf("list: failed to build query: %w", err)
+	}
+	var out []dbSpecies
+	if err := s.db.List(ctx, &out, q, vals...); err != nil {
+		return nil, fmt.Errorf("list: failed to list species: %w", err)
+	}
+	return toCoreSpeciesList(out), nil
+}
+
+// ListAll - will list every species ordered by name.
+func (s *Store) ListAll(ctx context.Context) ([]species.Species, error) {
+	const q = `
+	SELECT *
+	FROM species
+	ORDER BY name ASC
+	`
+	var out []dbSpecies
+	if err := s.db.List(ctx, &out, q); err != nil {
+		return nil, fmt.Errorf("list all: failed to list species: %w", err)
+	}
+	return toCoreSpeciesList(out), nil
+}
+
+// Update - will update the species record.
+func (s *Store) Update(ctx context.Context, sp species.Species) error {
+	const q = `
+	UPDATE species
+	SET
+	size_class = :size_class,
+	temperament = :temperament,
+	herd = :herd,
+	solitary = :solitary,
+	updated_at = :updated_at
+	WHERE name = :name
+	`
+	n, err := s.db.ExecAffected(ctx, q, toDBSpecies(sp))
+	if err != nil {
+		return fmt.Errorf("update: failed to update species: %w", err)
+	}
+	if n == 0 {
+		return core.ErrNotFound
+	}
+	return nil
+}
+
+// Delete - will delete the species record, unless a dino belongs to it. The species is locked
+// first so dinos of the species cannot be created meanwhile.
+func (s *Store) Delete(ctx context.Context, name string) error {
+	const (
+		lockQ = `
+		SELECT id
+		FROM species
+		WHERE name = $1
+		FOR UPDATE
+		`
+		usedQ = `
+		SELECT id
+		FROM dinosaur
+		WHERE species = $1
+		LIMIT 1
+		`
+		deleteQ = `
+		DELETE FROM species
+		WHERE name = $1
+		`
+	)
+	return s.db.WithTx(ctx, func(tx *sqlx.Tx) error {
+		var ids []string
+		if err := s.db.SelectTx(ctx, tx, &ids, lockQ, name); err != nil {
+			return fmt.Errorf("delete: failed to lock species: %w", err)
+		}
+		if len(ids) == 0 {
+			return core.ErrNotFound
+		}
+		var used []string
+		if err := s.db.SelectTx(ctx, tx, &used, usedQ, name); err != nil {
+			return fmt.Errorf("delete: failed to find dinos of species: %w", err)
+		}
+		if len(used) > 0 {
+			return core.ErrInvalidSpeciesInUse
+		}
+		if _, err := s.db.ExecTx(ctx, tx, deleteQ, name); err != nil {
+			return fmt.Errorf("delete: failed to delete species: %w", err)
+		}
+		return nil
+	})
+}
+
+var speciesFilterMap = map[string]string{
+	"diet":        "diet",
+	"size_class":  "size_class",
+	"temperament": "temperament",
+	"createdAt":   "created_at",
+}
+
+func listClauseBuilder(page core.Page, filters ...core.Filter) (string, []string, error) {
+	sortMap := map[string]string{
+		core.SortCreatedAt: "created_at",
+		core.SortName:      "name",
+	}
+
+	conds, vals, err := db.FilterClause(filters, speciesFilterMap, 1)
+	if err != nil {
+		return "", nil, fmt.Errorf("list clause builder: %w", err)
+	}
+
+	field := page.Sort.Field
+	if field == "" {
+		field = core.SortCreatedAt
+	}
+	column, ok := sortMap[field]
+	if !ok {
+		return "", nil, fmt.Errorf("list clause builder: invalid sort field %s", field)
+	}
+
+	cond, tail, pageVals, err := db.PageClause(page, column, len(vals)+1)
+	if err != nil {
+		return "", nil, fmt.Errorf("list clause builder: %w", err)
+	}
+	if cond != "" {
+		conds = append(conds, cond)
+		vals = append(vals, pageVals...)
+	}
+
+	var b strings.Builder
+	b.WriteString("\n\tSELECT *\n\tFROM species\n\t")
+	if len(conds) > 0 {
+		b.WriteString("WHERE ")
+		b.WriteString(strings.Join(conds, "\n\tAND "))
+		b.WriteString("\n\t")
+	}
+	b.WriteString(tail)
+	return b.String(), vals, nil
+}
@@ -0,0 +1,48 @@
+package speciesdb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lenguti/jppp/business/core"
+	"github.com/lenguti/jppp/business/core/species"
+	"github.com/lenguti/jppp/business/data/dbtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListClauseBuilder(t *testing.T) {
+	t.Run("carnivores by name", func(t *testing.T) {
+		want := `
+	SELECT *
+	FROM species
+	WHERE diet = $1
+	ORDER BY name ASC, id ASC
+	LIMIT 10
+	`
+
+		wantVals := []string{species.DietCarnivore}
+		got, gotVals, err := listClauseBuilder(core.Page{Limit: 10, Sort: core.Sort{Field: core.SortName}},
+			core.Filter{Key: "diet", Value: species.DietCarnivore},
+		)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+		assert.Equal(t, wantVals, gotVals)
+	})
+
+	t.Run("unknown filter", func(t *testing.T) {
+		_, _, err := listClauseBuilder(core.Page{}, core.NotDeleted)
+		assert.ErrorIs(t, err, core.ErrInvalidFilter)
+	})
+}
+
+func TestClosedDB(t *testing.T) {
+	// Setup.
+	s := NewStore(dbtest.Closed(t))
+
+	// Execute.
+	err := s.Delete(context.Background(), "Dilophosaurus")
+
+	// Validate.
+	assert.ErrorContains(t, err, "begin tx")
+}
@@ -0,0 +1,104 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/lenguti/jppp/business/core"
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel"
+)
+
+var tracer = otel.Tracer("github.com/lenguti/jppp/business/core/webhook")
+
+// Default delivery settings, used when the related config field is not set.
+const (
+	defaultMaxAttempts  = 8
+	defaultBackoff      = 30 * time.Second
+	defaultMaxBackoff   = time.Hour
+	defaultTimeout      = 10 * time.Second
+	defaultPollInterval = 5 * time.Second
+	defaultBatchSize    = 50
+)
+
+// Storer - represents the data layer behavior for webhooks and their deliveries.
+//
+// Delete soft deletes a webhook and cancels its pending deliveries. Get returns deleted webhooks,
+// List only does when not filtered out by core.NotDeleted, ListActive never does.
+//
+// ClaimDue returns at most limit pending deliveries whose next attempt is due at now, in the order
+// they are due, and postpones their next attempt to until so concurrent callers skip them while
+// they are being attempted. Deliveries claimed by a caller that stops before updating them are
+// claimed again once until is due.
+//
+// List and ListDeliveries return at most page.Limit items ordered by page.Sort and then id, starting after page.Cursor.
+type Storer interface {
+	Create(ctx context.Context, w Webhook) error
+	Get(ctx context.Context, id string) (Webhook, error)
+	List(ctx context.Context, page core.Page, filters ...core.Filter) ([]Webhook, error)
+	ListActive(ctx context.Context) ([]Webhook, error)
+	Delete(ctx context.Context, id string, ts time.Time) error
+	Enqueue(ctx context.Context, ds ...Delivery) error
+	ClaimDue(ctx context.Context, now, until time.Time, limit int) ([]Delivery, error)
+	UpdateDelivery(ctx context.Context, d Delivery) error
+	ListDeliveries(ctx context.Context, page core.Page, filters ...core.Filter) ([]Delivery, error)
+}
+
+// Config - represents the delivery settings. Failed attempts are retried after Backoff, doubled
+// after each attempt up to MaxBackoff, until MaxAttempts attempts were made.
+type Config struct {
+	MaxAttempts  int
+	Backoff      time.Duration
+	MaxBackoff   time.Duration
+	Timeout      time.Duration
+	PollInterval time.Duration
+	BatchSize    int
+}
+
+// withDefaults - returns the config with its unset fields set to their default.
+func (c Config) withDefaults() Config {
+	if c.MaxAttempts <= 0 {
+		c.MaxAttempts = defaultMaxAttempts
+	}
+	if c.Backoff <= 0 {
+		c.Backoff = defaultBackoff
+	}
+	if c.MaxBackoff <= 0 {
+		c.MaxBackoff = defaultMaxBackoff
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = defaultTimeout
+	}
+	if c.PollInterval <= 0 {
+		c.PollInterval = defaultPollInterval
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = defaultBatchSize
+	}
+	return c
+}
+
+// Core - represents the core business logic for webhooks.
+type Core struct {
+	store  Storer
+	log    zerolog.Logger
+	cfg    Config
+	client *http.Client
+}
+
+// NewCore - returns a new webhook core with all its components initialized.
+func NewCore(store Storer, log zerolog.Logger, cfg Config) *Core {
+	cfg = cfg.withDefaults()
+	return &Core{
+		store:  store,
+		log:    log,
+		cfg:    cfg,
+		client: &http.Client{Timeout: cfg.Timeout},
+	}
+}
+
+// logger - returns the request scoped logger, falling back to the core logger.
+func (c *Core) logger(ctx context.Context) *zerolog.Logger {
+	return core.Logger(ctx, c.log)
+}
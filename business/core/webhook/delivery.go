@@ -0,0 +1,214 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lenguti/jppp/business/core/event"
+)
+
+// Headers set on every delivery.
+const (
+	// SignatureHeader - holds the time the delivery was signed at and the signature, as t=<unix>,v1=<hex>.
+	SignatureHeader = "X-JPPP-Signature"
+	// EventHeader - holds the event type.
+	EventHeader = "X-JPPP-Event"
+	// DeliveryHeader - holds the delivery id, kept across retries.
+	DeliveryHeader = "X-JPPP-Delivery"
+)
+
+// maxErrorLength - the length the error of an attempt is truncated to.
+const maxErrorLength = 512
+
+var _ event.Publisher = (*Core)(nil)
+
+// Sign - returns the hex encoded HMAC-SHA256 of "<ts>.<body>" keyed with the secret. Receivers
+// compute it from the timestamp of the signature header and compare it with v1.
+func Sign(secret string, ts time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(ts.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Publish - enqueues a delivery of every event to each webhook subscribed to its type.
+func (c *Core) Publish(ctx context.Context, evs ...event.Event) error {
+	ctx, span := tracer.Start(ctx, "webhook.Publish")
+	defer span.End()
+
+	ws, err := c.store.ListActive(ctx)
+	if err != nil {
+		return fmt.Errorf("publish: failed to list webhooks: %w", err)
+	}
+
+	now := time.Now().UTC()
+	var ds []Delivery
+	for _, e := range evs {
+		for _, w := range ws {
+			if !w.Subscribed(e.Type) {
+				continue
+			}
+			d := Delivery{
+				ID:            uuid.New(),
+				WebhookID:     w.ID,
+				EventType:     e.Type,
+				Status:        StatusPending,
+				NextAttemptAt: now,
+				CreatedAt:     now,
+				UpdatedAt:     now,
+			}
+			if d.Payload, err = json.Marshal(newPayload(d.ID, e)); err != nil {
+				return fmt.Errorf("publish: unable to marshal payload: %w", err)
+			}
+			ds = append(ds, d)
+		}
+	}
+	if len(ds) == 0 {
+		return nil
+	}
+	if err := c.store.Enqueue(ctx, ds...); err != nil {
+		return fmt.Errorf("publish: failed to enqueue deliveries: %w", err)
+	}
+	return nil
+}
+
+// Run - attempts the due deliveries every poll interval until the context is done.
+func (c *Core) Run(ctx context.Context) {
+	t := time.NewTicker(c.cfg.PollInterval)
+	defer t.Stop()
+	for {
+		if _, err := c.Deliver(ctx); err != nil {
+			c.log.Err(err).Msg("Unable to deliver webhooks.")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// Deliver - attempts a batch of the deliveries due now, returning how many were attempted. Failed
+// attempts are retried with an exponential backoff until the deliveries run out of attempts.
+func (c *Core) Deliver(ctx context.Context) (int, error) {
+	ctx, span := tracer.Start(ctx, "webhook.Deliver")
+	defer span.End()
+
+	// The claim outlasts the attempts of the whole batch, each bounded by the timeout.
+	now := time.Now().UTC()
+	lease := c.cfg.Timeout * time.Duration(c.cfg.BatchSize+1)
+	ds, err := c.store.ClaimDue(ctx, now, now.Add(lease), c.cfg.BatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("deliver: failed to claim deliveries: %w", err)
+	}
+
+	webhooks := map[uuid.UUID]Webhook{}
+	for _, d := range ds {
+		w, ok := webhooks[d.WebhookID]
+		if !ok {
+			if w, err = c.store.Get(ctx, d.WebhookID.String()); err != nil {
+				return 0, fmt.Errorf("deliver: failed to fetch webhook: %w", err)
+			}
+			webhooks[d.WebhookID] = w
+		}
+
+		d = c.attempt(ctx, w, d)
+		if ctx.Err() != nil {
+			// Interrupted attempts are not counted, the delivery is claimed again once its claim expires.
+			return len(ds), nil
+		}
+		if err := c.store.UpdateDelivery(ctx, d); err != nil {
+			return 0, fmt.Errorf("deliver: failed to update delivery: %w", err)
+		}
+	}
+	return len(ds), nil
+}
+
+// attempt - posts the delivery to the webhook and returns it updated with the outcome.
+func (c *Core) attempt(ctx context.Context, w Webhook, d Delivery) Delivery {
+	log := c.log.With().Str("webhook_id", w.ID.String()).Str("delivery_id", d.ID.String()).Logger()
+
+	d.UpdatedAt = time.Now().UTC()
+	if w.Deleted() {
+		d.Status, d.Error = StatusCanceled, "webhook deleted"
+		return d
+	}
+
+	d.Attempts++
+	code, err := c.post(ctx, w, d)
+	d.ResponseCode, d.Error = code, ""
+	if err != nil {
+		d.Error = truncate(err.Error(), maxErrorLength)
+	} else {
+		d.Status = StatusSucceeded
+	}
+
+	d.UpdatedAt = time.Now().UTC()
+	switch {
+	case d.Status != StatusPending:
+	case d.Attempts >= c.cfg.MaxAttempts:
+		d.Status = StatusFailed
+		log.Error().Str("error", d.Error).Int("attempts", d.Attempts).Msg("Webhook delivery failed.")
+	default:
+		d.NextAttemptAt = d.UpdatedAt.Add(c.backoff(d.Attempts))
+		log.Warn().Str("error", d.Error).Int("attempts", d.Attempts).Time("next_attempt_at", d.NextAttemptAt).Msg("Webhook delivery attempt failed.")
+	}
+	return d
+}
+
+// post - posts the signed payload of the delivery to the webhook, returning the response status code
+// and an error unless it is a 2xx one.
+func (c *Core) post(ctx context.Context, w Webhook, d Delivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("post: unable to create request: %w", err)
+	}
+	now := time.Now()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "jppp-webhooks")
+	req.Header.Set(EventHeader, d.EventType)
+	req.Header.Set(DeliveryHeader, d.ID.String())
+	req.Header.Set(SignatureHeader, fmt.Sprintf("t=%d,v1=%s", now.Unix(), Sign(w.Secret, now, d.Payload)))
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("post: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("post: unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff - returns the delay before the attempt following the given number of attempts.
+func (c *Core) backoff(attempts int) time.Duration {
+	d := c.cfg.Backoff
+	for i := 1; i < attempts && d < c.cfg.MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > c.cfg.MaxBackoff {
+		return c.cfg.MaxBackoff
+	}
+	return d
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
@@ -0,0 +1,177 @@
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lenguti/jppp/business/core"
+	"github.com/lenguti/jppp/business/core/event"
+	"github.com/lenguti/jppp/business/core/webhook"
+	"github.com/lenguti/jppp/business/data/memstore"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeliver(t *testing.T) {
+	ctx := context.Background()
+	cageCreated := event.Event{
+		Type:       event.TypeCageCreated,
+		EntityType: "cage",
+		EntityID:   uuid.New(),
+		Data:       json.RawMessage(`{"status":"ACTIVE"}`),
+		CreatedAt:  time.Unix(1690000000, 0),
+	}
+
+	// receiver - returns a server answering with status and recording the requests it received.
+	receiver := func(t *testing.T, status int) (*httptest.Server, *[]*http.Request, *[][]byte) {
+		var (
+			reqs   []*http.Request
+			bodies [][]byte
+		)
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			reqs, bodies = append(reqs, r), append(bodies, body)
+			w.WriteHeader(status)
+		}))
+		t.Cleanup(srv.Close)
+		return srv, &reqs, &bodies
+	}
+
+	deliveries := func(t *testing.T, wc *webhook.Core, id uuid.UUID) []webhook.Delivery {
+		ds, _, err := wc.ListDeliveries(ctx, id, core.Page{})
+		require.NoError(t, err)
+		return ds
+	}
+
+	t.Run("signed delivery", func(t *testing.T) {
+		// Setup.
+		srv, reqs, bodies := receiver(t, http.StatusNoContent)
+		wc := webhook.NewCore(memstore.NewWebhookStore(memstore.New()), zerolog.Nop(), webhook.Config{})
+		w, err := wc.Create(ctx, webhook.NewWebhook{URL: srv.URL, Secret: "s3cr3t"})
+		require.NoError(t, err)
+		require.NoError(t, wc.Publish(ctx, cageCreated))
+
+		// Execute.
+		n, err := wc.Deliver(ctx)
+
+		// Validate.
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+		require.Len(t, *reqs, 1)
+		r, body := (*reqs)[0], (*bodies)[0]
+		assert.Equal(t, event.TypeCageCreated, r.Header.Get(webhook.EventHeader))
+
+		var ts int64
+		var sig string
+		_, err = fmt.Sscanf(strings.Replace(r.Header.Get(webhook.SignatureHeader), ",", " ", 1), "t=%d v1=%s", &ts, &sig)
+		require.NoError(t, err)
+		assert.Equal(t, webhook.Sign("s3cr3t", time.Unix(ts, 0), body), sig)
+
+		var p webhook.Payload
+		require.NoError(t, json.Unmarshal(body, &p))
+		assert.Equal(t, r.Header.Get(webhook.DeliveryHeader), p.ID)
+		assert.Equal(t, cageCreated.EntityID.String(), p.EntityID)
+		assert.JSONEq(t, `{"status":"ACTIVE"}`, string(p.Data))
+
+		ds := deliveries(t, wc, w.ID)
+		require.Len(t, ds, 1)
+		assert.Equal(t, webhook.StatusSucceeded, ds[0].Status)
+		assert.Equal(t, http.StatusNoContent, ds[0].ResponseCode)
+		assert.Equal(t, 1, ds[0].Attempts)
+	})
+
+	t.Run("unsubscribed types are not delivered", func(t *testing.T) {
+		// Setup.
+		wc := webhook.NewCore(memstore.NewWebhookStore(memstore.New()), zerolog.Nop(), webhook.Config{})
+		w, err := wc.Create(ctx, webhook.NewWebhook{URL: "http://vet.jppp.test", Types: []string{event.TypeDinoCaged}})
+		require.NoError(t, err)
+
+		// Execute.
+		err = wc.Publish(ctx, cageCreated)
+
+		// Validate.
+		require.NoError(t, err)
+		assert.Empty(t, deliveries(t, wc, w.ID))
+	})
+
+	t.Run("failed attempt backs off", func(t *testing.T) {
+		// Setup.
+		srv, reqs, _ := receiver(t, http.StatusInternalServerError)
+		wc := webhook.NewCore(memstore.NewWebhookStore(memstore.New()), zerolog.Nop(), webhook.Config{Backoff: time.Hour})
+		w, err := wc.Create(ctx, webhook.NewWebhook{URL: srv.URL})
+		require.NoError(t, err)
+		require.NoError(t, wc.Publish(ctx, cageCreated))
+
+		// Execute.
+		_, err = wc.Deliver(ctx)
+		require.NoError(t, err)
+		n, err := wc.Deliver(ctx)
+
+		// Validate.
+		require.NoError(t, err)
+		assert.Zero(t, n)
+		assert.Len(t, *reqs, 1)
+		ds := deliveries(t, wc, w.ID)
+		require.Len(t, ds, 1)
+		assert.Equal(t, webhook.StatusPending, ds[0].Status)
+		assert.Equal(t, 1, ds[0].Attempts)
+		assert.Equal(t, http.StatusInternalServerError, ds[0].ResponseCode)
+		assert.Contains(t, ds[0].Error, strconv.Itoa(http.StatusInternalServerError))
+		assert.Equal(t, time.Hour, ds[0].NextAttemptAt.Sub(ds[0].UpdatedAt))
+	})
+
+	t.Run("fails after max attempts", func(t *testing.T) {
+		// Setup.
+		srv, reqs, _ := receiver(t, http.StatusBadGateway)
+		wc := webhook.NewCore(memstore.NewWebhookStore(memstore.New()), zerolog.Nop(), webhook.Config{MaxAttempts: 2, Backoff: time.Nanosecond})
+		w, err := wc.Create(ctx, webhook.NewWebhook{URL: srv.URL})
+		require.NoError(t, err)
+		require.NoError(t, wc.Publish(ctx, cageCreated))
+
+		// Execute.
+		for i := 0; i < 3; i++ {
+			time.Sleep(time.Millisecond)
+			_, err := wc.Deliver(ctx)
+			require.NoError(t, err)
+		}
+
+		// Validate.
+		assert.Len(t, *reqs, 2)
+		ds := deliveries(t, wc, w.ID)
+		require.Len(t, ds, 1)
+		assert.Equal(t, webhook.StatusFailed, ds[0].Status)
+		assert.Equal(t, 2, ds[0].Attempts)
+	})
+
+	t.Run("deleted webhook cancels pending deliveries", func(t *testing.T) {
+		// Setup.
+		srv, reqs, _ := receiver(t, http.StatusOK)
+		wc := webhook.NewCore(memstore.NewWebhookStore(memstore.New()), zerolog.Nop(), webhook.Config{})
+		w, err := wc.Create(ctx, webhook.NewWebhook{URL: srv.URL})
+		require.NoError(t, err)
+		require.NoError(t, wc.Publish(ctx, cageCreated))
+
+		// Execute.
+		_, err = wc.Delete(ctx, w.ID)
+		require.NoError(t, err)
+		n, err := wc.Deliver(ctx)
+
+		// Validate.
+		require.NoError(t, err)
+		assert.Zero(t, n)
+		assert.Empty(t, *reqs)
+		ds := deliveries(t, wc, w.ID)
+		require.Len(t, ds, 1)
+		assert.Equal(t, webhook.StatusCanceled, ds[0].Status)
+	})
+}
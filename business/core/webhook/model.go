@@ -0,0 +1,148 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lenguti/jppp/business/core"
+	"github.com/lenguti/jppp/business/core/event"
+)
+
+// Delivery statuses. Pending deliveries are attempted until they succeed or run out of attempts,
+// deliveries of deleted webhooks are canceled.
+const (
+	StatusPending   = "pending"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+	StatusCanceled  = "canceled"
+)
+
+// Webhook - represents a business domain webhook subscription. Empty Types subscribe to every event type.
+type Webhook struct {
+	ID        uuid.UUID
+	URL       string
+	Types     []string
+	Secret    string
+	CreatedAt time.Time
+	DeletedAt time.Time
+}
+
+// NewWebhook - represents the input for creating a webhook. A secret is generated when none is set.
+type NewWebhook struct {
+	URL    string
+	Types  []string
+	Secret string
+}
+
+// SortFields - the fields webhooks and deliveries can be sorted by.
+var SortFields = []string{core.SortCreatedAt}
+
+// FilterFields - the fields webhooks can be filtered by.
+var FilterFields = core.Fields{
+	"url":       {Kind: core.KindString},
+	"createdAt": {Kind: core.KindInt},
+	"deletedAt": {Kind: core.KindInt},
+}
+
+// Deleted - reports whether the webhook has been deleted.
+func (w Webhook) Deleted() bool {
+	return !w.DeletedAt.IsZero()
+}
+
+// Subscribed - reports whether the webhook is subscribed to the event type.
+func (w Webhook) Subscribed(typ string) bool {
+	if len(w.Types) == 0 {
+		return true
+	}
+	for _, t := range w.Types {
+		if t == typ {
+			return true
+		}
+	}
+	return false
+}
+
+func (w Webhook) sortValue() string {
+	return strconv.FormatInt(w.CreatedAt.Unix(), 10)
+}
+
+// Delivery - represents the delivery of an event to a webhook. Payload is the body posted to the webhook,
+// ResponseCode and Error describe the outcome of the last attempt.
+type Delivery struct {
+	ID            uuid.UUID
+	WebhookID     uuid.UUID
+	EventType     string
+	Payload       json.RawMessage
+	Status        string
+	Attempts      int
+	NextAttemptAt time.Time
+	ResponseCode  int
+	Error         string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// DeliveryFilterFields - the fields deliveries can be filtered by.
+var DeliveryFilterFields = core.Fields{
+	"webhook_id": {Kind: core.KindString, Normalize: normalizeID},
+	"status":     {Kind: core.KindString},
+	"event_type": {Kind: core.KindString},
+	"createdAt":  {Kind: core.KindInt},
+}
+
+func (d Delivery) sortValue() string {
+	return strconv.FormatInt(d.CreatedAt.Unix(), 10)
+}
+
+// Payload - represents the body posted to webhooks. ID identifies the delivery, which keeps it
+// across retries so receivers can discard the events they already handled.
+type Payload struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	EntityType string          `json:"entity_type"`
+	EntityID   string          `json:"entity_id"`
+	CageID     string          `json:"cage_id,omitempty"`
+	Data       json.RawMessage `json:"data"`
+	RequestID  string          `json:"request_id,omitempty"`
+	CreatedAt  int64           `json:"createdAt"`
+}
+
+func newPayload(deliveryID uuid.UUID, e event.Event) Payload {
+	p := Payload{
+		ID:         deliveryID.String(),
+		Type:       e.Type,
+		EntityType: e.EntityType,
+		EntityID:   e.EntityID.String(),
+		Data:       e.Data,
+		RequestID:  e.RequestID,
+		CreatedAt:  e.CreatedAt.Unix(),
+	}
+	if e.CageID != uuid.Nil {
+		p.CageID = e.CageID.String()
+	}
+	return p
+}
+
+// ValidateURL - returns an error unless the url is an absolute http or https url.
+func ValidateURL(v string) error {
+	u, err := url.Parse(v)
+	if err != nil {
+		return fmt.Errorf("validate url: %w", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("validate url: %q is not an absolute http url", v)
+	}
+	return nil
+}
+
+func normalizeID(v string) (string, error) {
+	id, err := uuid.Parse(v)
+	if err != nil {
+		return "", fmt.Errorf("normalize id: %w", err)
+	}
+	return id.String(), nil
+}
@@ -0,0 +1,112 @@
+package webhookdb
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lenguti/jppp/business/core/webhook"
+	"github.com/lib/pq"
+)
+
+type dbWebhook struct {
+	ID         string         `db:"id"`
+	URL        string         `db:"url"`
+	EventTypes pq.StringArray `db:"event_types"`
+	Secret     string         `db:"secret"`
+	CreatedAt  int64          `db:"created_at"`
+	DeletedAt  *int64         `db:"deleted_at"`
+}
+
+func toDBWebhook(w webhook.Webhook) dbWebhook {
+	dbw := dbWebhook{
+		ID:         w.ID.String(),
+		URL:        w.URL,
+		EventTypes: pq.StringArray(w.Types),
+		Secret:     w.Secret,
+		CreatedAt:  w.CreatedAt.Unix(),
+	}
+	if dbw.EventTypes == nil {
+		dbw.EventTypes = pq.StringArray{}
+	}
+	if !w.DeletedAt.IsZero() {
+		ts := w.DeletedAt.Unix()
+		dbw.DeletedAt = &ts
+	}
+	return dbw
+}
+
+func toCoreWebhooks(dbWebhooks []dbWebhook) []webhook.Webhook {
+	ws := make([]webhook.Webhook, 0, len(dbWebhooks))
+	for _, v := range dbWebhooks {
+		ws = append(ws, toCoreWebhook(v))
+	}
+	return ws
+}
+
+func toCoreWebhook(dbw dbWebhook) webhook.Webhook {
+	w := webhook.Webhook{
+		ID:        uuid.MustParse(dbw.ID),
+		URL:       dbw.URL,
+		Types:     []string(dbw.EventTypes),
+		Secret:    dbw.Secret,
+		CreatedAt: time.Unix(dbw.CreatedAt, 0),
+	}
+	if dbw.DeletedAt != nil {
+		w.DeletedAt = time.Unix(*dbw.DeletedAt, 0)
+	}
+	return w
+}
+
+type dbDelivery struct {
+	ID            string `db:"id"`
+	WebhookID     string `db:"webhook_id"`
+	EventType     string `db:"event_type"`
+	Payload       []byte `db:"payload"`
+	Status        string `db:"status"`
+	Attempts      int    `db:"attempts"`
+	NextAttemptAt int64  `db:"next_attempt_at"`
+	ResponseCode  int    `db:"response_code"`
+	Error         string `db:"error"`
+	CreatedAt     int64  `db:"created_at"`
+	UpdatedAt     int64  `db:"updated_at"`
+}
+
+func toDBDelivery(d webhook.Delivery) dbDelivery {
+	return dbDelivery{
+		ID:            d.ID.String(),
+		WebhookID:     d.WebhookID.String(),
+		EventType:     d.EventType,
+		Payload:       d.Payload,
+		Status:        d.Status,
+		Attempts:      d.Attempts,
+		NextAttemptAt: d.NextAttemptAt.Unix(),
+		ResponseCode:  d.ResponseCode,
+		Error:         d.Error,
+		CreatedAt:     d.CreatedAt.Unix(),
+		UpdatedAt:     d.UpdatedAt.Unix(),
+	}
+}
+
+func toCoreDeliveries(dbDeliveries []dbDelivery) []webhook.Delivery {
+	ds := make([]webhook.Delivery, 0, len(dbDeliveries))
+	for _, v := range dbDeliveries {
+		ds = append(ds, toCoreDelivery(v))
+	}
+	return ds
+}
+
+func toCoreDelivery(dbd dbDelivery) webhook.Delivery {
+	return webhook.Delivery{
+		ID:            uuid.MustParse(dbd.ID),
+		WebhookID:     uuid.MustParse(dbd.WebhookID),
+		EventType:     dbd.EventType,
+		Payload:       dbd.Payload,
+		Status:        dbd.Status,
+		Attempts:      dbd.Attempts,
+		NextAttemptAt: time.Unix(dbd.NextAttemptAt, 0),
+		ResponseCode:  dbd.ResponseCode,
+		Error:         dbd.Error,
+		CreatedAt:     time.Unix(dbd.CreatedAt, 0),
+		UpdatedAt:     time.Unix(dbd.UpdatedAt, 0),
+	}
+}
@@ -0,0 +1,288 @@
+package webhookdb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lenguti/jppp/business/core"
+	"github.com/lenguti/jppp/business/core/webhook"
+	"github.com/lenguti/jppp/business/data/db"
+)
+
+// Store - manages the set of apis for webhook and delivery database access.
+type Store struct {
+	db *db.DB
+}
+
+// NewStore - constructs the api for data access.
+func NewStore(db *db.DB) *Store {
+	return &Store{
+		db: db,
+	}
+}
+
+// Create - will insert a new webhook record.
+func (s *Store) Create(ctx context.Context, w webhook.Webhook) error {
+	const q = `
+	INSERT INTO webhook (
+		id,
+		url,
+		event_types,
+		secret,
+		created_at
+	) VALUES (
+		:id,
+		:url,
+		:event_types,
+		:secret,
+		:created_at
+	)
+	`
+	if err := s.db.Exec(ctx, q, toDBWebhook(w)); err != nil {
+		return fmt.Errorf("create: failed to create webhook: %w", err)
+	}
+	return nil
+}
+
+// Get - will fetch a webhook by its id.
+func (s *Store) Get(ctx context.Context, id string) (webhook.Webhook, error) {
+	const q = `
+	SELECT *
+	FROM webhook
+	WHERE id = $1
+	`
+	var out dbWebhook
+	if err := s.db.Get(ctx, &out, q, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return webhook.Webhook{}, core.ErrNotFound
+		}
+		return webhook.Webhook{}, fmt.Errorf("get: failed to fetch webhook: %w", err)
+	}
+	return toCoreWebhook(out), nil
+}
+
+// List - will list a page of webhooks.
+func (s *Store) List(ctx context.Context, page core.Page, filters ...core.Filter) ([]webhook.Webhook, error) {
+	q, vals, err := listClauseBuilder("webhook", webhookFilterMap, page, filters...)
+	if err != nil {
+		return nil, fmt.Errorf("list: failed to build query: %w", err)
+	}
+	var out []dbWebhook
+	if err := s.db.List(ctx, &out, q, vals...); err != nil {
+		return nil, fmt.Errorf("list: failed to list webhooks: %w", err)
+	}
+	return toCoreWebhooks(out), nil
+}
+
+// ListActive - will list every webhook that is not deleted.
+func (s *Store) ListActive(ctx context.Context) ([]webhook.Webhook, error) {
+	const q = `
+	SELECT *
+	FROM webhook
+	WHERE deleted_at IS NULL
+	`
+	var out []dbWebhook
+	if err := s.db.List(ctx, &out, q); err != nil {
+		return nil, fmt.Errorf("list active: failed to list webhooks: %w", err)
+	}
+	return toCoreWebhooks(out), nil
+}
+
+// Delete - will delete the webhook, unless it already is, and cancel its pending deliveries.
+func (s *Store) Delete(ctx context.Context, id string, ts time.Time) error {
+	const (
+		q = `
+	UPDATE webhook
+	SET deleted_at = $1
+	WHERE id = $2
+	AND deleted_at IS NULL
+	`
+		cancelQ = `
+	UPDATE webhook_delivery
+	SET
+	status = $1,
+	error = 'webhook deleted',
+	updated_at = $2
+	WHERE webhook_id = $3
+	AND status = $4
+	`
+	)
+	return s.db.WithTx(ctx, func(tx *sqlx.Tx) error {
+		n, err := s.db.ExecTx(ctx, tx, q, ts.Unix(), id)
+		if err != nil {
+			return fmt.Errorf("delete: failed to delete webhook: %w", err)
+		}
+		if n != 1 {
+			return core.ErrNotFound
+		}
+		if _, err := s.db.ExecTx(ctx, tx, cancelQ, webhook.StatusCanceled, ts.Unix(), id, webhook.StatusPending); err != nil {
+			return fmt.Errorf("delete: failed to cancel deliveries: %w", err)
+		}
+		return nil
+	})
+}
+
+// Enqueue - will insert the deliveries in a single tx.
+func (s *Store) Enqueue(ctx context.Context, ds ...webhook.Delivery) error {
+	const q = `
+	INSERT INTO webhook_delivery (
+		id,
+		webhook_id,
+		event_type,
+		payload,
+		status,
+		attempts,
+		next_attempt_at,
+		response_code,
+		error,
+		created_at,
+		updated_at
+	) VALUES (
+		:id,
+		:webhook_id,
+		:event_type,
+		:payload,
+		:status,
+		:attempts,
+		:next_attempt_at,
+		:response_code,
+		:error,
+		:created_at,
+		:updated_at
+	)
+	`
+	return s.db.WithTx(ctx, func(tx *sqlx.Tx) error {
+		for _, d := range ds {
+			if _, err := s.db.NamedExecTx(ctx, tx, q, toDBDelivery(d)); err != nil {
+				return fmt.Errorf("enqueue: failed to insert delivery: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// ClaimDue - will claim the pending deliveries due at now, postponing their next attempt to until.
+// Deliveries locked by a concurrent claim are skipped.
+func (s *Store) ClaimDue(ctx context.Context, now, until time.Time, limit int) ([]webhook.Delivery, error) {
+	const q = `
+	UPDATE webhook_delivery
+	SET next_attempt_at = $1
+	WHERE id IN (
+		SELECT id
+		FROM webhook_delivery
+		WHERE status = $2
+		AND next_attempt_at <= $3
+		ORDER BY next_attempt_at ASC, id ASC
+		LIMIT $4
+		FOR UPDATE SKIP LOCKED
+	)
+	RETURNING *
+	`
+	var out []dbDelivery
+	vals := []string{
+		strconv.FormatInt(until.Unix(), 10),
+		webhook.StatusPending,
+		strconv.FormatInt(now.Unix(), 10),
+		strconv.Itoa(limit),
+	}
+	if err := s.db.List(ctx, &out, q, vals...); err != nil {
+		return nil, fmt.Errorf("claim due: failed to claim deliveries: %w", err)
+	}
+	return toCoreDeliveries(out), nil
+}
+
+// UpdateDelivery - will update the outcome of the delivery.
+func (s *Store) UpdateDelivery(ctx context.Context, d webhook.Delivery) error {
+	const q = `
+	UPDATE webhook_delivery
+	SET
+	status = :status,
+	attempts = :attempts,
+	next_attempt_at = :next_attempt_at,
+	response_code = :response_code,
+	error = :error,
+	updated_at = :updated_at
+	WHERE id = :id
+	`
+	n, err := s.db.ExecAffected(ctx, q, toDBDelivery(d))
+	if err != nil {
+		return fmt.Errorf("update delivery: failed to update delivery: %w", err)
+	}
+	if n != 1 {
+		return core.ErrNotFound
+	}
+	return nil
+}
+
+// ListDeliveries - will list a page of deliveries.
+func (s *Store) ListDeliveries(ctx context.Context, page core.Page, filters ...core.Filter) ([]webhook.Delivery, error) {
+	q, vals, err := listClauseBuilder("webhook_delivery", deliveryFilterMap, page, filters...)
+	if err != nil {
+		return nil, fmt.Errorf("list deliveries: failed to build query: %w", err)
+	}
+	var out []dbDelivery
+	if err := s.db.List(ctx, &out, q, vals...); err != nil {
+		return nil, fmt.Errorf("list deliveries: failed to list deliveries: %w", err)
+	}
+	return toCoreDeliveries(out), nil
+}
+
+var webhookFilterMap = map[string]string{
+	"url":       "url",
+	"createdAt": "created_at",
+	"deletedAt": "deleted_at",
+}
+
+var deliveryFilterMap = map[string]string{
+	"webhook_id": "webhook_id",
+	"status":     "status",
+	"event_type": "event_type",
+	"createdAt":  "created_at",
+}
+
+func listClauseBuilder(table string, filterMap map[string]string, page core.Page, filters ...core.Filter) (string, []string, error) {
+	sortMap := map[string]string{
+		core.SortCreatedAt: "created_at",
+	}
+
+	conds, vals, err := db.FilterClause(filters, filterMap, 1)
+	if err != nil {
+		return "", nil, fmt.Errorf("list clause builder: %w", err)
+	}
+
+	field := page.Sort.Field
+	if field == "" {
+		field = core.SortCreatedAt
+	}
+	column, ok := sortMap[field]
+	if !ok {
+		return "", nil, fmt.Errorf("list clause builder: invalid sort field %s", field)
+	}
+
+	cond, tail, pageVals, err := db.PageClause(page, column, len(vals)+1)
+	if err != nil {
+		return "", nil, fmt.Errorf("list clause builder: %w", err)
+	}
+	if cond != "" {
+		conds = append(conds, cond)
+		vals = append(vals, pageVals...)
+	}
+
+	var b strings.Builder
+	b.WriteString("\n\tSELECT *\n\tFROM ")
+	b.WriteString(table)
+	b.WriteString("\n\t")
+	if len(conds) > 0 {
+		b.WriteString("WHERE ")
+		b.WriteString(strings.Join(conds, "\n\tAND "))
+		b.WriteString("\n\t")
+	}
+	b.WriteString(tail)
+	return b.String(), vals, nil
+}
@@ -0,0 +1,75 @@
+package webhookdb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lenguti/jppp/business/core"
+	"github.com/lenguti/jppp/business/core/webhook"
+	"github.com/lenguti/jppp/business/data/dbtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListClauseBuilder(t *testing.T) {
+	t.Run("active webhooks", func(t *testing.T) {
+		want := `
+	SELECT *
+	FROM webhook
+	WHERE deleted_at IS NULL
+	ORDER BY created_at ASC, id ASC
+	`
+
+		got, gotVals, err := listClauseBuilder("webhook", webhookFilterMap, core.Page{}, core.NotDeleted)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+		assert.Empty(t, gotVals)
+	})
+
+	t.Run("failed deliveries of a webhook", func(t *testing.T) {
+		want := `
+	SELECT *
+	FROM webhook_delivery
+	WHERE status = $1
+	AND webhook_id = $2
+	ORDER BY created_at ASC, id ASC
+	`
+
+		wantVals := []string{"failed", "9c0b5d2e-8a47-4b1c-9f0e-3d4c5b6a7e8f"}
+		got, gotVals, err := listClauseBuilder("webhook_delivery", deliveryFilterMap, core.Page{},
+			core.Filter{Key: "status", Value: "failed"},
+			core.Filter{Key: "webhook_id", Value: "9c0b5d2e-8a47-4b1c-9f0e-3d4c5b6a7e8f"},
+		)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+		assert.Equal(t, wantVals, gotVals)
+	})
+
+	t.Run("unknown filter", func(t *testing.T) {
+		_, _, err := listClauseBuilder("webhook_delivery", deliveryFilterMap, core.Page{}, core.NotDeleted)
+		assert.ErrorIs(t, err, core.ErrInvalidFilter)
+	})
+}
+
+func TestClosedDB(t *testing.T) {
+	ctx := context.Background()
+	s := NewStore(dbtest.Closed(t))
+
+	t.Run("enqueue", func(t *testing.T) {
+		// Execute.
+		err := s.Enqueue(ctx, webhook.Delivery{ID: uuid.New(), WebhookID: uuid.New()})
+
+		// Validate.
+		assert.ErrorContains(t, err, "begin tx")
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		// Execute.
+		err := s.Delete(ctx, uuid.NewString(), time.Now())
+
+		// Validate.
+		assert.ErrorContains(t, err, "begin tx")
+	})
+}
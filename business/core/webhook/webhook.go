@@ -0,0 +1,150 @@
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lenguti/jppp/business/core"
+	"github.com/lenguti/jppp/business/core/event"
+)
+
+// Create - will create a new webhook, generating its secret when none is set.
+func (c *Core) Create(ctx context.Context, nw NewWebhook) (Webhook, error) {
+	ctx, span := tracer.Start(ctx, "webhook.Create")
+	defer span.End()
+
+	if err := core.Authorize(ctx, core.PermWebhookManage); err != nil {
+		return Webhook{}, fmt.Errorf("create: %w", err)
+	}
+	if err := ValidateURL(nw.URL); err != nil {
+		return Webhook{}, fmt.Errorf("create: %w", err)
+	}
+	for _, t := range nw.Types {
+		if !event.ValidType(t) {
+			return Webhook{}, fmt.Errorf("create: unknown event type %q", t)
+		}
+	}
+
+	secret := nw.Secret
+	if secret == "" {
+		var err error
+		if secret, err = newSecret(); err != nil {
+			return Webhook{}, fmt.Errorf("create: %w", err)
+		}
+	}
+
+	w := Webhook{
+		ID:        uuid.New(),
+		URL:       nw.URL,
+		Types:     nw.Types,
+		Secret:    secret,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := c.store.Create(ctx, w); err != nil {
+		return Webhook{}, fmt.Errorf("create: failed to create webhook: %w", err)
+	}
+	c.logger(ctx).Info().Fields(map[string]any{"webhook_id": w.ID, "url": w.URL, "types": w.Types}).Msg("Created webhook.")
+	return w, nil
+}
+
+// Get - will fetch a webhook by its id. Deleted webhooks are not found.
+func (c *Core) Get(ctx context.Context, id uuid.UUID) (Webhook, error) {
+	ctx, span := tracer.Start(ctx, "webhook.Get")
+	defer span.End()
+
+	if err := core.Authorize(ctx, core.PermWebhookRead); err != nil {
+		return Webhook{}, fmt.Errorf("get: %w", err)
+	}
+
+	w, err := c.store.Get(ctx, id.String())
+	if err != nil {
+		return Webhook{}, fmt.Errorf("get: failed to fetch webhook: %w", err)
+	}
+	if w.Deleted() {
+		return Webhook{}, fmt.Errorf("get: webhook deleted: %w", core.ErrNotFound)
+	}
+	return w, nil
+}
+
+// List - will list a page of webhooks along with the cursor of the next page, if any.
+func (c *Core) List(ctx context.Context, page core.Page, filters ...core.Filter) ([]Webhook, string, error) {
+	ctx, span := tracer.Start(ctx, "webhook.List")
+	defer span.End()
+
+	if err := core.Authorize(ctx, core.PermWebhookRead); err != nil {
+		return nil, "", fmt.Errorf("list: %w", err)
+	}
+
+	c.logger(ctx).Info().Fields(map[string]any{"filters": filters, "sort": page.Sort.String(), "limit": page.Limit}).Msg("Listing webhooks.")
+	ws, err := c.store.List(ctx, page.Peek(), filters...)
+	if err != nil {
+		return nil, "", fmt.Errorf("list: failed to list webhooks: %w", err)
+	}
+	ws, next := core.NextPage(page, ws, func(w Webhook) (string, string) {
+		return w.sortValue(), w.ID.String()
+	})
+	return ws, next, nil
+}
+
+// Delete - will delete the webhook, canceling its pending deliveries. Its delivery log is kept.
+func (c *Core) Delete(ctx context.Context, id uuid.UUID) (Webhook, error) {
+	ctx, span := tracer.Start(ctx, "webhook.Delete")
+	defer span.End()
+
+	if err := core.Authorize(ctx, core.PermWebhookManage); err != nil {
+		return Webhook{}, fmt.Errorf("delete: %w", err)
+	}
+
+	w, err := c.store.Get(ctx, id.String())
+	if err != nil {
+		return Webhook{}, fmt.Errorf("delete: failed to fetch webhook: %w", err)
+	}
+	if w.Deleted() {
+		return Webhook{}, fmt.Errorf("delete: webhook deleted: %w", core.ErrNotFound)
+	}
+
+	w.DeletedAt = time.Now().UTC()
+	if err := c.store.Delete(ctx, id.String(), w.DeletedAt); err != nil {
+		return Webhook{}, fmt.Errorf("delete: failed to delete webhook: %w", err)
+	}
+	c.logger(ctx).Info().Str("webhook_id", id.String()).Msg("Deleted webhook.")
+	return w, nil
+}
+
+// ListDeliveries - will list a page of the deliveries to the webhook along with the cursor of the next page, if any.
+// Deliveries of deleted webhooks are listed.
+func (c *Core) ListDeliveries(ctx context.Context, webhookID uuid.UUID, page core.Page, filters ...core.Filter) ([]Delivery, string, error) {
+	ctx, span := tracer.Start(ctx, "webhook.ListDeliveries")
+	defer span.End()
+
+	if err := core.Authorize(ctx, core.PermWebhookRead); err != nil {
+		return nil, "", fmt.Errorf("list deliveries: %w", err)
+	}
+	if _, err := c.store.Get(ctx, webhookID.String()); err != nil {
+		return nil, "", fmt.Errorf("list deliveries: failed to fetch webhook: %w", err)
+	}
+
+	filters = append(filters, core.Filter{Key: "webhook_id", Value: webhookID.String()})
+	c.logger(ctx).Info().Fields(map[string]any{"filters": filters, "sort": page.Sort.String(), "limit": page.Limit}).Msg("Listing webhook deliveries.")
+	ds, err := c.store.ListDeliveries(ctx, page.Peek(), filters...)
+	if err != nil {
+		return nil, "", fmt.Errorf("list deliveries: failed to list deliveries: %w", err)
+	}
+	ds, next := core.NextPage(page, ds, func(d Delivery) (string, string) {
+		return d.sortValue(), d.ID.String()
+	})
+	return ds, next, nil
+}
+
+// newSecret - returns a random hex encoded 32 byte secret.
+func newSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("new secret: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
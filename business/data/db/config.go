@@ -1,25 +1,99 @@
 package db
 
-import "net/url"
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"time"
+)
 
-// Config - represents db configurations.
+// SSL modes supported by the postgres driver.
+const (
+	SSLModeDisable    = "disable"
+	SSLModeRequire    = "require"
+	SSLModeVerifyCA   = "verify-ca"
+	SSLModeVerifyFull = "verify-full"
+)
+
+// Config - represents db configurations. Zero durations disable the related timeout or limit.
+// Schema, when set, replaces the search path so unqualified tables resolve within it.
 type Config struct {
-	User         string
-	Password     string
-	Name         string
-	MaxIdleConns int
-	MaxOpenConns int
+	User             string
+	Password         string
+	Name             string
+	Host             string
+	Port             int
+	Schema           string
+	SSLMode          string
+	SSLRootCert      string
+	ConnectTimeout   time.Duration
+	StatementTimeout time.Duration
+	MaxIdleConns     int
+	MaxOpenConns     int
+	ConnMaxLifetime  time.Duration
+	ConnMaxIdleTime  time.Duration
+}
+
+// Validate - reports the first invalid setting or combination of settings.
+func (c Config) Validate() error {
+	switch {
+	case c.User == "", c.Password == "", c.Name == "":
+		return errors.New("validate: user, password and name are required")
+	case c.Host == "":
+		return errors.New("validate: host is required")
+	case c.Port < 1 || c.Port > 65535:
+		return fmt.Errorf("validate: invalid port %d", c.Port)
+	}
+
+	switch c.SSLMode {
+	case SSLModeDisable:
+		if c.SSLRootCert != "" {
+			return fmt.Errorf("validate: ssl root cert requires an sslmode other than %s", SSLModeDisable)
+		}
+	case SSLModeRequire, SSLModeVerifyCA, SSLModeVerifyFull:
+	default:
+		return fmt.Errorf("validate: invalid sslmode %q", c.SSLMode)
+	}
+
+	switch {
+	case c.ConnectTimeout < 0, c.StatementTimeout < 0, c.ConnMaxLifetime < 0, c.ConnMaxIdleTime < 0:
+		return errors.New("validate: timeouts and lifetimes must not be negative")
+	case c.ConnectTimeout > 0 && c.ConnectTimeout%time.Second != 0:
+		return fmt.Errorf("validate: connect timeout %s must be a whole number of seconds", c.ConnectTimeout)
+	case c.StatementTimeout%time.Millisecond != 0:
+		return fmt.Errorf("validate: statement timeout %s must be a whole number of milliseconds", c.StatementTimeout)
+	case c.MaxIdleConns < 0, c.MaxOpenConns < 0:
+		return errors.New("validate: connection counts must not be negative")
+	case c.MaxOpenConns > 0 && c.MaxIdleConns > c.MaxOpenConns:
+		return fmt.Errorf("validate: max idle conns %d exceeds max open conns %d", c.MaxIdleConns, c.MaxOpenConns)
+	}
+
+	return nil
 }
 
 func (c Config) dbString() string {
 	q := make(url.Values)
-	q.Set("sslmode", "disable")
+	q.Set("sslmode", c.SSLMode)
 	q.Set("timezone", "utc")
+	if c.SSLRootCert != "" {
+		q.Set("sslrootcert", c.SSLRootCert)
+	}
+	if c.Schema != "" {
+		q.Set("search_path", c.Schema)
+	}
+	if c.ConnectTimeout > 0 {
+		q.Set("connect_timeout", strconv.Itoa(int(c.ConnectTimeout/time.Second)))
+	}
+	if c.StatementTimeout > 0 {
+		q.Set("statement_timeout", strconv.FormatInt(c.StatementTimeout.Milliseconds(), 10))
+	}
 
 	u := url.URL{
 		Scheme:   "postgres",
 		User:     url.UserPassword(c.User, c.Password),
-		Host:     "db:5432",
+		Host:     net.JoinHostPort(c.Host, strconv.Itoa(c.Port)),
 		Path:     c.Name,
 		RawQuery: q.Encode(),
 	}
@@ -0,0 +1,75 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig(t *testing.T) {
+	valid := Config{
+		User:         "foo",
+		Password:     "bar",
+		Name:         "baz",
+		Host:         "db",
+		Port:         5432,
+		SSLMode:      SSLModeDisable,
+		MaxIdleConns: 10,
+		MaxOpenConns: 10,
+	}
+
+	t.Run("default db string", func(t *testing.T) {
+		// Execute.
+		err := valid.Validate()
+
+		// Validate.
+		require.NoError(t, err)
+		assert.Equal(t, "postgres://foo:bar@db:5432/baz?sslmode=disable&timezone=utc", valid.dbString())
+	})
+
+	t.Run("db string with ssl and timeouts", func(t *testing.T) {
+		// Setup.
+		cfg := valid
+		cfg.Host = "::1"
+		cfg.SSLMode = SSLModeVerifyFull
+		cfg.SSLRootCert = "/certs/root.crt"
+		cfg.ConnectTimeout = 5 * time.Second
+		cfg.StatementTimeout = 1500 * time.Millisecond
+		cfg.Schema = "test"
+
+		// Execute.
+		err := cfg.Validate()
+
+		// Validate.
+		require.NoError(t, err)
+		assert.Equal(t, "postgres://foo:bar@[::1]:5432/baz?connect_timeout=5&search_path=test&sslmode=verify-full&sslrootcert=%2Fcerts%2Froot.crt&statement_timeout=1500&timezone=utc", cfg.dbString())
+	})
+
+	tests := []struct {
+		name   string
+		modify func(*Config)
+	}{
+		{name: "missing host", modify: func(c *Config) { c.Host = "" }},
+		{name: "invalid port", modify: func(c *Config) { c.Port = 70000 }},
+		{name: "unknown sslmode", modify: func(c *Config) { c.SSLMode = "prefer" }},
+		{name: "root cert without ssl", modify: func(c *Config) { c.SSLRootCert = "/certs/root.crt" }},
+		{name: "sub second connect timeout", modify: func(c *Config) { c.ConnectTimeout = 500 * time.Millisecond }},
+		{name: "negative lifetime", modify: func(c *Config) { c.ConnMaxLifetime = -time.Second }},
+		{name: "more idle than open conns", modify: func(c *Config) { c.MaxIdleConns = 20 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup.
+			cfg := valid
+			tt.modify(&cfg)
+
+			// Execute.
+			err := cfg.Validate()
+
+			// Validate.
+			assert.Error(t, err)
+		})
+	}
+}
@@ -15,12 +15,17 @@ type DB struct {
 
 // New - returns an initialzed db.
 func New(cfg Config) (*DB, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("new: invalid config: %w", err)
+	}
 	db, err := sqlx.Open("postgres", cfg.dbString())
 	if err != nil {
 		return nil, err
 	}
 	db.SetMaxIdleConns(cfg.MaxIdleConns)
 	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
 	return &DB{
 		sql: db,
 		cfg: cfg,
@@ -35,21 +40,110 @@ func (db *DB) Connect() error {
 	return nil
 }
 
+// Close - closes the db connections, after which every statement fails.
+func (db *DB) Close() error {
+	return db.sql.Close()
+}
+
 // Exec - execute db statements.
-func (db *DB) Exec(ctx context.Context, query string, data any) error {
+func (db *DB) Exec(ctx context.Context, query string, data any) (err error) {
+	ctx, span := startSpan(ctx, query)
+	defer func() { endSpan(span, err) }()
+
 	if _, err := db.sql.NamedExecContext(ctx, query, data); err != nil {
 		return fmt.Errorf("exec: unable to named exec: %w", err)
 	}
 	return nil
 }
 
+// ExecAffected - execute db statements and return the number of affected rows.
+func (db *DB) ExecAffected(ctx context.Context, query string, data any) (_ int64, err error) {
+	ctx, span := startSpan(ctx, query)
+	defer func() { endSpan(span, err) }()
+
+	res, err := db.sql.NamedExecContext(ctx, query, data)
+	if err != nil {
+		return 0, fmt.Errorf("exec affected: unable to named exec: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("exec affected: unable to read rows affected: %w", err)
+	}
+	return n, nil
+}
+
+// ExecTx - execute a statement within the tx and return the number of affected rows.
+func (db *DB) ExecTx(ctx context.Context, tx *sqlx.Tx, query string, args ...any) (_ int64, err error) {
+	ctx, span := startSpan(ctx, query)
+	defer func() { endSpan(span, err) }()
+
+	res, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("exec tx: unable to exec: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("exec tx: unable to read rows affected: %w", err)
+	}
+	return n, nil
+}
+
+// NamedExecTx - execute a named statement within the tx and return the number of affected rows.
+func (db *DB) NamedExecTx(ctx context.Context, tx *sqlx.Tx, query string, data any) (_ int64, err error) {
+	ctx, span := startSpan(ctx, query)
+	defer func() { endSpan(span, err) }()
+
+	res, err := tx.NamedExecContext(ctx, query, data)
+	if err != nil {
+		return 0, fmt.Errorf("named exec tx: unable to named exec: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("named exec tx: unable to read rows affected: %w", err)
+	}
+	return n, nil
+}
+
+// SelectTx - select db items within the tx.
+func (db *DB) SelectTx(ctx context.Context, tx *sqlx.Tx, data any, query string, args ...any) (err error) {
+	ctx, span := startSpan(ctx, query)
+	defer func() { endSpan(span, err) }()
+
+	if err := tx.SelectContext(ctx, data, query, args...); err != nil {
+		return fmt.Errorf("select tx: unable to select: %w", err)
+	}
+	return nil
+}
+
+// WithTx - runs fn within a db transaction, committed when fn succeeds and rolled back otherwise.
+func (db *DB) WithTx(ctx context.Context, fn func(tx *sqlx.Tx) error) error {
+	tx, err := db.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("with tx: %w", err)
+	}
+	defer tx.Rollback()
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if err := db.CommitTx(tx); err != nil {
+		return fmt.Errorf("with tx: unable to commit tx: %w", err)
+	}
+	return nil
+}
+
 // Get - fetch db item.
-func (db *DB) Get(ctx context.Context, data any, query string, val string) error {
+func (db *DB) Get(ctx context.Context, data any, query string, val string) (err error) {
+	ctx, span := startSpan(ctx, query)
+	defer func() { endSpan(span, err) }()
+
 	return db.sql.GetContext(ctx, data, query, val)
 }
 
 // List - list db items.
-func (db *DB) List(ctx context.Context, data any, query string, vals ...string) error {
+func (db *DB) List(ctx context.Context, data any, query string, vals ...string) (err error) {
+	ctx, span := startSpan(ctx, query)
+	defer func() { endSpan(span, err) }()
+
 	var ivals []any
 	for i := range vals {
 		ivals = append(ivals, vals[i])
@@ -57,9 +151,16 @@ func (db *DB) List(ctx context.Context, data any, query string, vals ...string)
 	return db.sql.SelectContext(ctx, data, query, ivals...)
 }
 
-// BeginTX - starts a db transaction.
-func (db *DB) BeginTx(ctx context.Context) *sqlx.Tx {
-	return db.sql.MustBeginTx(ctx, nil)
+// BeginTx - starts a db transaction.
+func (db *DB) BeginTx(ctx context.Context) (_ *sqlx.Tx, err error) {
+	_, span := startSpan(ctx, "BEGIN")
+	defer func() { endSpan(span, err) }()
+
+	tx, err := db.sql.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: unable to begin: %w", err)
+	}
+	return tx, nil
 }
 
 // CommitTx - commits a db transaction.
@@ -0,0 +1,31 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithTx(t *testing.T) {
+	t.Run("closed db", func(t *testing.T) {
+		// Setup.
+		db, err := New(Config{User: "foo", Password: "bar", Name: "baz", Host: "localhost", Port: 5432, SSLMode: SSLModeDisable})
+		require.NoError(t, err)
+		require.NoError(t, db.Close())
+		called := false
+
+		// Execute.
+		err = db.WithTx(context.Background(), func(tx *sqlx.Tx) error {
+			called = true
+			return nil
+		})
+
+		// Validate.
+		assert.ErrorContains(t, err, "with tx: begin tx")
+		assert.False(t, called)
+	})
+}
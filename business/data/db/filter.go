@@ -0,0 +1,66 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lenguti/jppp/business/core"
+)
+
+var operatorSQL = map[core.Operator]string{
+	core.OpEq:  "=",
+	core.OpNe:  "<>",
+	core.OpGt:  ">",
+	core.OpGte: ">=",
+	core.OpLt:  "<",
+	core.OpLte: "<=",
+}
+
+// FilterClause - returns the conditions and values for the filters, mapping filter keys to
+// columns. Positional arguments start at argIdx. Filters on keys without a column are rejected.
+func FilterClause(filters []core.Filter, columns map[string]string, argIdx int) ([]string, []string, error) {
+	var (
+		conds = make([]string, 0, len(filters))
+		vals  []string
+	)
+	for _, f := range filters {
+		column, ok := columns[f.Key]
+		if !ok {
+			return nil, nil, fmt.Errorf("filter clause: unknown field %s: %w", f.Key, core.ErrInvalidFilter)
+		}
+
+		switch op := f.Operator(); op {
+		case core.OpIn:
+			if len(f.Values) == 0 {
+				return nil, nil, fmt.Errorf("filter clause: empty in filter for %s: %w", f.Key, core.ErrInvalidFilter)
+			}
+			params := make([]string, 0, len(f.Values))
+			for _, v := range f.Values {
+				params = append(params, fmt.Sprintf("$%d", argIdx+len(vals)))
+				vals = append(vals, v)
+			}
+			conds = append(conds, fmt.Sprintf("%s IN (%s)", column, strings.Join(params, ", ")))
+		case core.OpNull:
+			if f.Value == "true" {
+				conds = append(conds, fmt.Sprintf("%s IS NULL", column))
+			} else {
+				conds = append(conds, fmt.Sprintf("%s IS NOT NULL", column))
+			}
+		case core.OpPrefix:
+			conds = append(conds, fmt.Sprintf("%s LIKE $%d", column, argIdx+len(vals)))
+			vals = append(vals, escapeLike(f.Value)+"%")
+		default:
+			sqlOp, ok := operatorSQL[op]
+			if !ok {
+				return nil, nil, fmt.Errorf("filter clause: unknown operator %s: %w", op, core.ErrInvalidFilter)
+			}
+			conds = append(conds, fmt.Sprintf("%s %s $%d", column, sqlOp, argIdx+len(vals)))
+			vals = append(vals, f.Value)
+		}
+	}
+	return conds, vals, nil
+}
+
+func escapeLike(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(v)
+}
@@ -0,0 +1,46 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/lenguti/jppp/business/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterClause(t *testing.T) {
+	columns := map[string]string{
+		"capacity": "capacity",
+		"type":     "type",
+		"name":     "name",
+	}
+
+	t.Run("operators", func(t *testing.T) {
+		filters := []core.Filter{
+			{Key: "capacity", Op: core.OpGte, Value: "5"},
+			{Key: "type", Op: core.OpIn, Values: []string{"CARNIVORE", "HERBIVORE"}},
+			{Key: "name", Op: core.OpPrefix, Value: "50%_off"},
+		}
+		conds, vals, err := FilterClause(filters, columns, 2)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"capacity >= $2", "type IN ($3, $4)", "name LIKE $5"}, conds)
+		assert.Equal(t, []string{"5", "CARNIVORE", "HERBIVORE", `50\%\_off%`}, vals)
+	})
+
+	t.Run("null operator takes no value", func(t *testing.T) {
+		filters := []core.Filter{
+			{Key: "name", Op: core.OpNull, Value: "true"},
+			{Key: "capacity", Op: core.OpNull, Value: "false"},
+			{Key: "type", Value: "CARNIVORE"},
+		}
+		conds, vals, err := FilterClause(filters, columns, 1)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"name IS NULL", "capacity IS NOT NULL", "type = $1"}, conds)
+		assert.Equal(t, []string{"CARNIVORE"}, vals)
+	})
+
+	t.Run("unknown field", func(t *testing.T) {
+		_, _, err := FilterClause([]core.Filter{{Key: "color", Value: "red"}}, columns, 1)
+		assert.ErrorIs(t, err, core.ErrInvalidFilter)
+	})
+}
@@ -0,0 +1,77 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lenguti/jppp/business/data/migrations"
+	"github.com/pressly/goose/v3"
+)
+
+// Migration commands.
+const (
+	MigrateUp     = "up"
+	MigrateDown   = "down"
+	MigrateStatus = "status"
+	MigrateRedo   = "redo"
+)
+
+// migrateLockID - the advisory lock key held while migrating, shared by every replica.
+const migrateLockID = 20230720170318
+
+func init() {
+	goose.SetBaseFS(migrations.FS)
+	if err := goose.SetDialect("postgres"); err != nil {
+		panic(err)
+	}
+}
+
+// Migrate - runs the migration command against the embedded migrations.
+func (db *DB) Migrate(ctx context.Context, command string) error {
+	var fn func() error
+	switch command {
+	case MigrateUp:
+		fn = func() error { return goose.Up(db.sql.DB, ".") }
+	case MigrateDown:
+		fn = func() error { return goose.Down(db.sql.DB, ".") }
+	case MigrateStatus:
+		fn = func() error { return goose.Status(db.sql.DB, ".") }
+	case MigrateRedo:
+		fn = func() error { return goose.Redo(db.sql.DB, ".") }
+	default:
+		return fmt.Errorf("migrate: unknown command %q", command)
+	}
+
+	if err := db.withAdvisoryLock(ctx, migrateLockID, fn); err != nil {
+		return fmt.Errorf("migrate: unable to run %s: %w", command, err)
+	}
+	return nil
+}
+
+// MigrateUpTo - runs the embedded migrations up to and including the version.
+func (db *DB) MigrateUpTo(ctx context.Context, version int64) error {
+	fn := func() error { return goose.UpTo(db.sql.DB, ".", version) }
+	if err := db.withAdvisoryLock(ctx, migrateLockID, fn); err != nil {
+		return fmt.Errorf("migrate up to: unable to run up to %d: %w", version, err)
+	}
+	return nil
+}
+
+// withAdvisoryLock - runs fn while holding a session level advisory lock, waiting for any
+// other holder of the lock to release it first.
+func (db *DB) withAdvisoryLock(ctx context.Context, id int64, fn func() error) error {
+	conn, err := db.sql.Connx(ctx)
+	if err != nil {
+		return fmt.Errorf("with advisory lock: unable to acquire connection: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", id); err != nil {
+		return fmt.Errorf("with advisory lock: unable to acquire lock: %w", err)
+	}
+	defer func() {
+		_, _ = conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", id)
+	}()
+
+	return fn()
+}
@@ -0,0 +1,36 @@
+package db
+
+import (
+	"context"
+	"io/fs"
+	"testing"
+
+	"github.com/lenguti/jppp/business/data/migrations"
+	"github.com/pressly/goose/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrations(t *testing.T) {
+	t.Run("every sql file is embedded and parsed", func(t *testing.T) {
+		// Setup.
+		files, err := fs.Glob(migrations.FS, "*.sql")
+		require.NoError(t, err)
+
+		// Execute.
+		ms, err := goose.CollectMigrations(".", 0, goose.MaxVersion)
+
+		// Validate.
+		require.NoError(t, err)
+		require.NotEmpty(t, files)
+		assert.Len(t, ms, len(files))
+	})
+
+	t.Run("unknown command", func(t *testing.T) {
+		// Execute.
+		err := (&DB{}).Migrate(context.Background(), "sideways")
+
+		// Validate.
+		assert.ErrorContains(t, err, "unknown command")
+	})
+}
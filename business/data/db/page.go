@@ -0,0 +1,33 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/lenguti/jppp/business/core"
+)
+
+// PageClause - returns the keyset condition and the ordering and limit clauses for a page
+// sorted by the provided column, with ties broken by id. Positional arguments for the
+// condition start at argIdx.
+func PageClause(page core.Page, column string, argIdx int) (string, string, []string, error) {
+	dir, cmp := "ASC", ">"
+	if page.Sort.Desc {
+		dir, cmp = "DESC", "<"
+	}
+
+	tail := fmt.Sprintf("ORDER BY %s %s, id %s\n\t", column, dir, dir)
+	if page.Limit > 0 {
+		tail += fmt.Sprintf("LIMIT %d\n\t", page.Limit)
+	}
+
+	c, ok, err := core.DecodeCursor(page)
+	if err != nil {
+		return "", "", nil, fmt.Errorf("page clause: %w", err)
+	}
+	if !ok {
+		return "", tail, nil, nil
+	}
+
+	cond := fmt.Sprintf("(%s, id) %s ($%d, $%d)", column, cmp, argIdx, argIdx+1)
+	return cond, tail, []string{c.Value, c.ID}, nil
+}
@@ -0,0 +1,62 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/lenguti/jppp/business/data/db")
+
+// startSpan - starts a client span for the statement, named after its operation and table.
+func startSpan(ctx context.Context, query string) (context.Context, trace.Span) {
+	op, table := statementName(query)
+	name := op
+	if table != "" {
+		name += " " + table
+	}
+	return tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemPostgreSQL,
+			semconv.DBOperation(op),
+			semconv.DBSQLTable(table),
+			semconv.DBStatement(strings.TrimSpace(query)),
+		),
+	)
+}
+
+// endSpan - ends the span, recording err unless it only reports that no rows matched.
+func endSpan(span trace.Span, err error) {
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// statementName - returns the operation of the statement, such as SELECT, and the first table it
+// reads from or writes to, if any.
+func statementName(query string) (string, string) {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return "", ""
+	}
+	op := strings.ToUpper(fields[0])
+	for i, f := range fields[:len(fields)-1] {
+		switch strings.ToUpper(f) {
+		case "FROM", "INTO", "UPDATE":
+			table := strings.Trim(fields[i+1], "(),;")
+			if table != "" && !strings.HasPrefix(table, "$") {
+				return op, table
+			}
+		}
+	}
+	return op, ""
+}
@@ -0,0 +1,33 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatementName(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		query string
+		op    string
+		table string
+	}{
+		{name: "select", query: "\n\tSELECT *\n\tFROM cage\n\tWHERE id = $1", op: "SELECT", table: "cage"},
+		{name: "insert", query: "INSERT INTO dinosaur (id, name) VALUES (:id, :name)", op: "INSERT", table: "dinosaur"},
+		{name: "update", query: "update cage set status = :status", op: "UPDATE", table: "cage"},
+		{name: "delete", query: "DELETE FROM cage WHERE id = $1", op: "DELETE", table: "cage"},
+		{name: "no table", query: "SELECT pg_advisory_lock($1)", op: "SELECT"},
+		{name: "begin", query: "BEGIN", op: "BEGIN"},
+		{name: "empty", query: "  "},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Execute.
+			op, table := statementName(tc.query)
+
+			// Validate.
+			assert.Equal(t, tc.op, op)
+			assert.Equal(t, tc.table, table)
+		})
+	}
+}
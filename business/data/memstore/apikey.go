@@ -0,0 +1,65 @@
+package memstore
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lenguti/jppp/business/core"
+	"github.com/lenguti/jppp/business/core/apikey"
+)
+
+var _ apikey.Storer = (*APIKeyStore)(nil)
+
+// APIKeyStore - manages the set of apis for in-memory api key access.
+type APIKeyStore struct {
+	s *Store
+}
+
+// NewAPIKeyStore - constructs the api for in-memory api key access.
+func NewAPIKeyStore(s *Store) *APIKeyStore {
+	return &APIKeyStore{
+		s: s,
+	}
+}
+
+// Create - will insert a new api key record.
+func (ks *APIKeyStore) Create(ctx context.Context, k apikey.APIKey) error {
+	ks.s.mu.Lock()
+	defer ks.s.mu.Unlock()
+
+	for _, v := range ks.s.apiKeys {
+		if v.ID == k.ID || v.Hash == k.Hash {
+			return fmt.Errorf("create: api key %s already exists", k.ID)
+		}
+	}
+	ks.s.apiKeys[k.ID.String()] = k
+	return nil
+}
+
+// GetByHash - will fetch an api key by the hash of the key.
+func (ks *APIKeyStore) GetByHash(ctx context.Context, hash string) (apikey.APIKey, error) {
+	ks.s.mu.RLock()
+	defer ks.s.mu.RUnlock()
+
+	for _, k := range ks.s.apiKeys {
+		if k.Hash == hash {
+			return k, nil
+		}
+	}
+	return apikey.APIKey{}, core.ErrNotFound
+}
+
+// Revoke - will revoke the api key, unless it already is.
+func (ks *APIKeyStore) Revoke(ctx context.Context, id string, ts time.Time) error {
+	ks.s.mu.Lock()
+	defer ks.s.mu.Unlock()
+
+	k, ok := ks.s.apiKeys[id]
+	if !ok || k.Revoked() {
+		return core.ErrNotFound
+	}
+	k.RevokedAt = ts
+	ks.s.apiKeys[id] = k
+	return nil
+}
@@ -0,0 +1,67 @@
+package memstore
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/lenguti/jppp/business/core"
+	"github.com/lenguti/jppp/business/core/audit"
+)
+
+var _ audit.Storer = (*AuditStore)(nil)
+
+// AuditStore - manages the set of apis for in-memory audit record access. Records are
+// appended by the cage and dino stores along with the change they describe.
+type AuditStore struct {
+	s *Store
+}
+
+// NewAuditStore - constructs the api for in-memory audit record access.
+func NewAuditStore(s *Store) *AuditStore {
+	return &AuditStore{
+		s: s,
+	}
+}
+
+// List - will list a page of audit records.
+func (as *AuditStore) List(ctx context.Context, page core.Page, filters ...core.Filter) ([]audit.Record, error) {
+	as.s.mu.RLock()
+	defer as.s.mu.RUnlock()
+
+	out := make([]audit.Record, 0, len(as.s.audit))
+	for _, r := range as.s.audit {
+		ok, err := match(audit.FilterFields, auditFieldValue(r), filters...)
+		if err != nil {
+			return nil, fmt.Errorf("list: %w", err)
+		}
+		if ok {
+			out = append(out, r)
+		}
+	}
+	return paginate(out, page, auditSortKey)
+}
+
+func auditSortKey(r audit.Record, field string) sortKey {
+	return sortKey{num: r.CreatedAt.Unix(), id: r.ID.String()}
+}
+
+func auditFieldValue(r audit.Record) func(string) string {
+	return func(key string) string {
+		switch key {
+		case "entity_id":
+			return r.EntityID.String()
+		case "entity_type":
+			return r.EntityType
+		case "actor":
+			return r.Actor
+		case "action":
+			return r.Action
+		case "request_id":
+			return r.RequestID
+		case "createdAt":
+			return strconv.FormatInt(r.CreatedAt.Unix(), 10)
+		}
+		return ""
+	}
+}
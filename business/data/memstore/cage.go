@@ -0,0 +1,411 @@
+package memstore
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lenguti/jppp/business/core"
+	"github.com/lenguti/jppp/business/core/audit"
+	"github.com/lenguti/jppp/business/core/cage"
+	"github.com/lenguti/jppp/business/core/dino"
+	"github.com/lenguti/jppp/business/core/placement"
+)
+
+var _ cage.Storer = (*CageStore)(nil)
+
+// CageStore - manages the set of apis for in-memory cage access.
+type CageStore struct {
+	s *Store
+}
+
+// NewCageStore - constructs the api for in-memory cage access.
+func NewCageStore(s *Store) *CageStore {
+	return &CageStore{
+		s: s,
+	}
+}
+
+// Create - will insert a new cage record.
+func (cs *CageStore) Create(ctx context.Context, c cage.Cage, recs ...audit.Record) error {
+	cs.s.mu.Lock()
+	defer cs.s.mu.Unlock()
+
+	id := c.ID.String()
+	if _, ok := cs.s.cages[id]; ok {
+		return fmt.Errorf("create: cage %s already exists", id)
+	}
+	cs.s.cages[id] = c
+	cs.s.record(recs...)
+	return nil
+}
+
+// Get - will fetch a cage by its id.
+func (cs *CageStore) Get(ctx context.Context, id string) (cage.Cage, error) {
+	cs.s.mu.RLock()
+	defer cs.s.mu.RUnlock()
+
+	c, ok := cs.s.cages[id]
+	if !ok {
+		return cage.Cage{}, core.ErrNotFound
+	}
+	return c, nil
+}
+
+// List - will list a page of cages.
+func (cs *CageStore) List(ctx context.Context, page core.Page, filters ...core.Filter) ([]cage.Cage, error) {
+	cs.s.mu.RLock()
+	defer cs.s.mu.RUnlock()
+
+	out := make([]cage.Cage, 0, len(cs.s.cages))
+	for _, c := range cs.s.cages {
+		ok, err := match(cage.FilterFields, cageFieldValue(c), filters...)
+		if err != nil {
+			return nil, fmt.Errorf("list: %w", err)
+		}
+		if ok {
+			out = append(out, c)
+		}
+	}
+	return paginate(out, page, cageSortKey)
+}
+
+// UpdateStatus - will update the status of a cage, provided it is still at the version preceding the given one.
+func (cs *CageStore) UpdateStatus(ctx context.Context, id, status string, version int, ts time.Time, recs ...audit.Record) error {
+	cs.s.mu.Lock()
+	defer cs.s.mu.Unlock()
+
+	c, ok := cs.s.cages[id]
+	if !ok {
+		return core.ErrNotFound
+	}
+	if c.Version != version-1 {
+		return core.ErrConflict
+	}
+	c.Status = cage.Status(status)
+	c.Version = version
+	c.UpdatedAt = ts
+	cs.s.cages[id] = c
+	cs.s.record(recs...)
+	return nil
+}
+
+// AddDino - will update the cage current capacity, updated ts and the dinos cage identifier.
+// The cage is only updated if it is still active, has room and is still at the version the
+// caller observed.
+func (cs *CageStore) AddDino(ctx context.Context, c cage.Cage, dinoID string, recs ...audit.Record) error {
+	cs.s.mu.Lock()
+	defer cs.s.mu.Unlock()
+
+	if err := cs.addDino(c, dinoID); err != nil {
+		return err
+	}
+	cs.s.record(recs...)
+	return nil
+}
+
+// RemoveDino - will update the cage current capacity, updated ts and the dinos cage identifier.
+// The cage is only updated if it is still at the version the caller observed and the dino is still in it.
+func (cs *CageStore) RemoveDino(ctx context.Context, c cage.Cage, dinoID string, recs ...audit.Record) error {
+	cs.s.mu.Lock()
+	defer cs.s.mu.Unlock()
+
+	stored, d, err := cs.lookup(c.ID.String(), dinoID)
+	if err != nil {
+		return err
+	}
+	if d.CageID != stored.ID || !canRelease(stored, c) {
+		return core.ErrConflict
+	}
+
+	cs.release(stored, c.UpdatedAt)
+	cs.move(d, uuid.Nil, c.UpdatedAt)
+	cs.unassign(stored.ID, d.ID, c.UpdatedAt)
+	cs.s.record(recs...)
+	return nil
+}
+
+// TransferDino - will move the dino between cages, updating both cages and the dino at once.
+func (cs *CageStore) TransferDino(ctx context.Context, from, to cage.Cage, dinoID string, recs ...audit.Record) error {
+	cs.s.mu.Lock()
+	defer cs.s.mu.Unlock()
+
+	if err := cs.transferDino(from, to, dinoID); err != nil {
+		return err
+	}
+	cs.s.record(recs...)
+	return nil
+}
+
+// Apply - will apply the steps in order, restoring the cages, dinos and placements as they were
+// when one fails.
+func (cs *CageStore) Apply(ctx context.Context, steps []cage.Step, recs ...audit.Record) error {
+	cs.s.mu.Lock()
+	defer cs.s.mu.Unlock()
+
+	restore := cs.snapshot()
+	for i, st := range steps {
+		var err error
+		switch {
+		case st.DinoID == uuid.Nil:
+			err = cs.updateStatus(st.Cage)
+		case st.From == nil:
+			err = cs.addDino(st.Cage, st.DinoID.String())
+		default:
+			err = cs.transferDino(*st.From, st.Cage, st.DinoID.String())
+		}
+		if err != nil {
+			restore()
+			return fmt.Errorf("apply: step %d: %w", i+1, err)
+		}
+	}
+	cs.s.record(recs...)
+	return nil
+}
+
+// Delete - will soft delete an empty cage, provided it is still at the version preceding the given one.
+func (cs *CageStore) Delete(ctx context.Context, id string, version int, ts time.Time, recs ...audit.Record) error {
+	cs.s.mu.Lock()
+	defer cs.s.mu.Unlock()
+
+	c, ok := cs.s.cages[id]
+	if !ok {
+		return core.ErrNotFound
+	}
+	if c.Version != version-1 || c.CurrentCapacity != 0 || c.Deleted() {
+		return core.ErrConflict
+	}
+	c.DeletedAt = ts
+	c.Version = version
+	c.UpdatedAt = ts
+	cs.s.cages[id] = c
+	cs.s.record(recs...)
+	return nil
+}
+
+// Restore - will restore a soft deleted cage, provided it is still at the version preceding the given one.
+func (cs *CageStore) Restore(ctx context.Context, id string, version int, ts time.Time, recs ...audit.Record) error {
+	cs.s.mu.Lock()
+	defer cs.s.mu.Unlock()
+
+	c, ok := cs.s.cages[id]
+	if !ok {
+		return core.ErrNotFound
+	}
+	if c.Version != version-1 || !c.Deleted() {
+		return core.ErrConflict
+	}
+	c.DeletedAt = time.Time{}
+	c.Version = version
+	c.UpdatedAt = ts
+	cs.s.cages[id] = c
+	cs.s.record(recs...)
+	return nil
+}
+
+// Summarize - will count the cages that are not deleted by type and status.
+func (cs *CageStore) Summarize(ctx context.Context) ([]cage.Summary, error) {
+	cs.s.mu.RLock()
+	defer cs.s.mu.RUnlock()
+
+	type key struct {
+		typ    cage.Type
+		status cage.Status
+	}
+	byKey := map[key]*cage.Summary{}
+	for _, c := range cs.s.cages {
+		if c.Deleted() {
+			continue
+		}
+		k := key{c.Type, c.Status}
+		sum, ok := byKey[k]
+		if !ok {
+			sum = &cage.Summary{Type: c.Type, Status: c.Status}
+			byKey[k] = sum
+		}
+		sum.Cages++
+		sum.Capacity += c.Capacity
+		sum.Occupied += c.CurrentCapacity
+	}
+
+	out := make([]cage.Summary, 0, len(byKey))
+	for _, sum := range byKey {
+		out = append(out, *sum)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Type != out[j].Type {
+			return out[i].Type < out[j].Type
+		}
+		return out[i].Status < out[j].Status
+	})
+	return out, nil
+}
+
+// updateStatus - sets the status of the cage, provided it is still at the version preceding the given one.
+func (cs *CageStore) updateStatus(c cage.Cage) error {
+	stored, ok := cs.s.cages[c.ID.String()]
+	if !ok {
+		return core.ErrNotFound
+	}
+	if stored.Version != c.Version-1 {
+		return core.ErrConflict
+	}
+	stored.Status = c.Status
+	stored.Version = c.Version
+	stored.UpdatedAt = c.UpdatedAt
+	cs.s.cages[c.ID.String()] = stored
+	return nil
+}
+
+// addDino - moves the dino into the cage, provided the cage can still take it.
+func (cs *CageStore) addDino(c cage.Cage, dinoID string) error {
+	stored, d, err := cs.lookup(c.ID.String(), dinoID)
+	if err != nil {
+		return err
+	}
+	if d.CageID != uuid.Nil || d.Deleted() || !canTake(stored, c) {
+		return core.ErrConflict
+	}
+
+	cs.take(stored, c.UpdatedAt)
+	cs.move(d, stored.ID, c.UpdatedAt)
+	cs.assign(stored.ID, d.ID, c.UpdatedAt)
+	return nil
+}
+
+// transferDino - moves the dino between cages, provided it is still in the source cage and the
+// destination can still take it.
+func (cs *CageStore) transferDino(from, to cage.Cage, dinoID string) error {
+	storedFrom, d, err := cs.lookup(from.ID.String(), dinoID)
+	if err != nil {
+		return err
+	}
+	storedTo, ok := cs.s.cages[to.ID.String()]
+	if !ok {
+		return core.ErrNotFound
+	}
+	if d.CageID != storedFrom.ID || !canRelease(storedFrom, from) || !canTake(storedTo, to) {
+		return core.ErrConflict
+	}
+
+	cs.release(storedFrom, from.UpdatedAt)
+	cs.take(storedTo, to.UpdatedAt)
+	cs.move(d, storedTo.ID, to.UpdatedAt)
+	cs.unassign(storedFrom.ID, d.ID, from.UpdatedAt)
+	cs.assign(storedTo.ID, d.ID, to.UpdatedAt)
+	return nil
+}
+
+// snapshot - returns a func restoring the cages, dinos and placements as they are now. The store lock must be held.
+func (cs *CageStore) snapshot() func() {
+	cages := make(map[string]cage.Cage, len(cs.s.cages))
+	for k, v := range cs.s.cages {
+		cages[k] = v
+	}
+	dinos := make(map[string]dino.Dinosaur, len(cs.s.dinos))
+	for k, v := range cs.s.dinos {
+		dinos[k] = v
+	}
+	placements := append([]placement.Placement(nil), cs.s.placements...)
+	return func() {
+		cs.s.cages = cages
+		cs.s.dinos = dinos
+		cs.s.placements = placements
+	}
+}
+
+func (cs *CageStore) lookup(id, dinoID string) (cage.Cage, dino.Dinosaur, error) {
+	c, ok := cs.s.cages[id]
+	if !ok {
+		return cage.Cage{}, dino.Dinosaur{}, core.ErrNotFound
+	}
+	d, ok := cs.s.dinos[dinoID]
+	if !ok {
+		return cage.Cage{}, dino.Dinosaur{}, core.ErrNotFound
+	}
+	return c, d, nil
+}
+
+// canTake - reports whether the stored cage can still take a dino given the state the caller observed.
+func canTake(stored, observed cage.Cage) bool {
+	return stored.Status == cage.CageStatusActive &&
+		stored.Version == observed.Version-1 &&
+		stored.CurrentCapacity < stored.Capacity
+}
+
+// canRelease - reports whether the stored cage can still release a dino given the state the caller observed.
+func canRelease(stored, observed cage.Cage) bool {
+	return stored.Version == observed.Version-1 && stored.CurrentCapacity > 0
+}
+
+func (cs *CageStore) take(c cage.Cage, ts time.Time) {
+	c.CurrentCapacity++
+	c.Version++
+	c.UpdatedAt = ts
+	cs.s.cages[c.ID.String()] = c
+}
+
+func (cs *CageStore) release(c cage.Cage, ts time.Time) {
+	c.CurrentCapacity--
+	c.Version++
+	c.UpdatedAt = ts
+	cs.s.cages[c.ID.String()] = c
+}
+
+func (cs *CageStore) move(d dino.Dinosaur, cageID uuid.UUID, ts time.Time) {
+	d.CageID = cageID
+	d.Version++
+	d.UpdatedAt = ts
+	cs.s.dinos[d.ID.String()] = d
+}
+
+func (cs *CageStore) assign(cageID, dinoID uuid.UUID, ts time.Time) {
+	cs.s.placements = append(cs.s.placements, placement.Placement{
+		ID:         uuid.New(),
+		CageID:     cageID,
+		DinoID:     dinoID,
+		AssignedAt: ts,
+	})
+}
+
+func (cs *CageStore) unassign(cageID, dinoID uuid.UUID, ts time.Time) {
+	for i, p := range cs.s.placements {
+		if p.CageID == cageID && p.DinoID == dinoID && !p.Released() {
+			cs.s.placements[i].ReleasedAt = ts
+		}
+	}
+}
+
+func cageSortKey(c cage.Cage, field string) sortKey {
+	switch field {
+	case core.SortUpdatedAt:
+		return sortKey{num: c.UpdatedAt.Unix(), id: c.ID.String()}
+	default:
+		return sortKey{num: c.CreatedAt.Unix(), id: c.ID.String()}
+	}
+}
+
+func cageFieldValue(c cage.Cage) func(string) string {
+	return func(key string) string {
+		switch key {
+		case "type":
+			return c.Type.String()
+		case "status":
+			return c.Status.String()
+		case "capacity":
+			return strconv.Itoa(c.Capacity)
+		case "currentCapacity":
+			return strconv.Itoa(c.CurrentCapacity)
+		case "createdAt":
+			return strconv.FormatInt(c.CreatedAt.Unix(), 10)
+		case "updatedAt":
+			return strconv.FormatInt(c.UpdatedAt.Unix(), 10)
+		case "deletedAt":
+			return unixOrEmpty(c.DeletedAt)
+		}
+		return ""
+	}
+}
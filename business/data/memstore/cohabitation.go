@@ -0,0 +1,29 @@
+package memstore
+
+import (
+	"context"
+
+	"github.com/lenguti/jppp/business/core/cohabitation"
+)
+
+var _ cohabitation.Storer = (*CohabitationStore)(nil)
+
+// CohabitationStore - manages the set of apis for in-memory cohabitation rule access.
+type CohabitationStore struct {
+	s *Store
+}
+
+// NewCohabitationStore - constructs the api for in-memory cohabitation rule access.
+func NewCohabitationStore(s *Store) *CohabitationStore {
+	return &CohabitationStore{
+		s: s,
+	}
+}
+
+// List - will fetch the rule definitions in order.
+func (cs *CohabitationStore) List(ctx context.Context) ([]cohabitation.Definition, error) {
+	cs.s.mu.RLock()
+	defer cs.s.mu.RUnlock()
+
+	return append([]cohabitation.Definition(nil), cs.s.rules...), nil
+}
@@ -0,0 +1,216 @@
+package memstore
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lenguti/jppp/business/core"
+	"github.com/lenguti/jppp/business/core/audit"
+	"github.com/lenguti/jppp/business/core/dino"
+)
+
+var _ dino.Storer = (*DinoStore)(nil)
+
+// DinoStore - manages the set of apis for in-memory dino access.
+type DinoStore struct {
+	s *Store
+}
+
+// NewDinoStore - constructs the api for in-memory dino access.
+func NewDinoStore(s *Store) *DinoStore {
+	return &DinoStore{
+		s: s,
+	}
+}
+
+// Create - will insert a new dino record.
+func (ds *DinoStore) Create(ctx context.Context, d dino.Dinosaur, recs ...audit.Record) error {
+	ds.s.mu.Lock()
+	defer ds.s.mu.Unlock()
+
+	id := d.ID.String()
+	if _, ok := ds.s.dinos[id]; ok {
+		return fmt.Errorf("create: dino %s already exists", id)
+	}
+	ds.s.dinos[id] = d
+	ds.s.record(recs...)
+	return nil
+}
+
+// Get - will fetch a dino by its id.
+func (ds *DinoStore) Get(ctx context.Context, id string) (dino.Dinosaur, error) {
+	ds.s.mu.RLock()
+	defer ds.s.mu.RUnlock()
+
+	d, ok := ds.s.dinos[id]
+	if !ok {
+		return dino.Dinosaur{}, core.ErrNotFound
+	}
+	return d, nil
+}
+
+// List - will list a page of dinos.
+func (ds *DinoStore) List(ctx context.Context, page core.Page, filters ...core.Filter) ([]dino.Dinosaur, error) {
+	ds.s.mu.RLock()
+	defer ds.s.mu.RUnlock()
+
+	out := make([]dino.Dinosaur, 0, len(ds.s.dinos))
+	for _, d := range ds.s.dinos {
+		ok, err := match(dino.FilterFields, dinoFieldValue(d), filters...)
+		if err != nil {
+			return nil, fmt.Errorf("list: %w", err)
+		}
+		if ok {
+			out = append(out, d)
+		}
+	}
+	return paginate(out, page, dinoSortKey)
+}
+
+// UpdateName - will update the name of a dino, provided it is still at the version preceding the given one.
+func (ds *DinoStore) UpdateName(ctx context.Context, id, name string, version int, ts time.Time, recs ...audit.Record) error {
+	ds.s.mu.Lock()
+	defer ds.s.mu.Unlock()
+
+	d, ok := ds.s.dinos[id]
+	if !ok {
+		return core.ErrNotFound
+	}
+	if d.Version != version-1 {
+		return core.ErrConflict
+	}
+	d.Name = name
+	d.Version = version
+	d.UpdatedAt = ts
+	ds.s.dinos[id] = d
+	ds.s.record(recs...)
+	return nil
+}
+
+// Delete - will soft delete an uncaged dino, provided it is still at the version preceding the given one.
+func (ds *DinoStore) Delete(ctx context.Context, id string, version int, ts time.Time, recs ...audit.Record) error {
+	ds.s.mu.Lock()
+	defer ds.s.mu.Unlock()
+
+	d, ok := ds.s.dinos[id]
+	if !ok {
+		return core.ErrNotFound
+	}
+	if d.Version != version-1 || d.CageID != uuid.Nil || d.Deleted() {
+		return core.ErrConflict
+	}
+	d.DeletedAt = ts
+	d.Version = version
+	d.UpdatedAt = ts
+	ds.s.dinos[id] = d
+	ds.s.record(recs...)
+	return nil
+}
+
+// Restore - will restore a soft deleted dino, provided it is still at the version preceding the given one.
+func (ds *DinoStore) Restore(ctx context.Context, id string, version int, ts time.Time, recs ...audit.Record) error {
+	ds.s.mu.Lock()
+	defer ds.s.mu.Unlock()
+
+	d, ok := ds.s.dinos[id]
+	if !ok {
+		return core.ErrNotFound
+	}
+	if d.Version != version-1 || !d.Deleted() {
+		return core.ErrConflict
+	}
+	d.DeletedAt = time.Time{}
+	d.Version = version
+	d.UpdatedAt = ts
+	ds.s.dinos[id] = d
+	ds.s.record(recs...)
+	return nil
+}
+
+// ListByCage - will fetch a page of dinos associated to the provided cage id.
+func (ds *DinoStore) ListByCage(ctx context.Context, cageID string, page core.Page, filters ...core.Filter) ([]dino.Dinosaur, error) {
+	ds.s.mu.RLock()
+	defer ds.s.mu.RUnlock()
+
+	var out []dino.Dinosaur
+	for _, d := range ds.s.dinos {
+		if d.CageID.String() != cageID {
+			continue
+		}
+		ok, err := match(dino.FilterFields, dinoFieldValue(d), filters...)
+		if err != nil {
+			return nil, fmt.Errorf("list by cage: %w", err)
+		}
+		if ok {
+			out = append(out, d)
+		}
+	}
+	return paginate(out, page, dinoSortKey)
+}
+
+// Summarize - will count the dinos that are not deleted by species and diet.
+func (ds *DinoStore) Summarize(ctx context.Context) ([]dino.Summary, error) {
+	ds.s.mu.RLock()
+	defer ds.s.mu.RUnlock()
+
+	byKey := map[dino.Summary]int{}
+	for _, d := range ds.s.dinos {
+		if d.Deleted() {
+			continue
+		}
+		byKey[dino.Summary{Species: d.Species, Diet: d.Diet}]++
+	}
+
+	out := make([]dino.Summary, 0, len(byKey))
+	for sum, n := range byKey {
+		sum.Dinosaurs = n
+		out = append(out, sum)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Species != out[j].Species {
+			return out[i].Species < out[j].Species
+		}
+		return out[i].Diet < out[j].Diet
+	})
+	return out, nil
+}
+
+func dinoFieldValue(d dino.Dinosaur) func(string) string {
+	return func(key string) string {
+		switch key {
+		case "cage_id":
+			if d.CageID == uuid.Nil {
+				return ""
+			}
+			return d.CageID.String()
+		case "name":
+			return d.Name
+		case "species":
+			return d.Species
+		case "diet":
+			return d.Diet.String()
+		case "createdAt":
+			return strconv.FormatInt(d.CreatedAt.Unix(), 10)
+		case "updatedAt":
+			return strconv.FormatInt(d.UpdatedAt.Unix(), 10)
+		case "deletedAt":
+			return unixOrEmpty(d.DeletedAt)
+		}
+		return ""
+	}
+}
+
+func dinoSortKey(d dino.Dinosaur, field string) sortKey {
+	switch field {
+	case core.SortName:
+		return sortKey{str: d.Name, id: d.ID.String()}
+	case core.SortUpdatedAt:
+		return sortKey{num: d.UpdatedAt.Unix(), id: d.ID.String()}
+	default:
+		return sortKey{num: d.CreatedAt.Unix(), id: d.ID.String()}
+	}
+}
@@ -0,0 +1,59 @@
+// Package memstore provides in-memory implementations of the cage, dino, api key, audit, placement, webhook, outbox, species and cohabitation rule storers.
+package memstore
+
+import (
+	"sync"
+
+	"github.com/lenguti/jppp/business/core/apikey"
+	"github.com/lenguti/jppp/business/core/audit"
+	"github.com/lenguti/jppp/business/core/cage"
+	"github.com/lenguti/jppp/business/core/cohabitation"
+	"github.com/lenguti/jppp/business/core/dino"
+	"github.com/lenguti/jppp/business/core/placement"
+	"github.com/lenguti/jppp/business/core/species"
+	"github.com/lenguti/jppp/business/core/webhook"
+)
+
+// Store - represents the shared in-memory state backing the cage, dino, api key, audit, placement, webhook, outbox, species and cohabitation rule stores.
+type Store struct {
+	mu    sync.RWMutex
+	cages map[string]cage.Cage
+	dinos map[string]dino.Dinosaur
+
+	apiKeys map[string]apikey.APIKey
+	audit   []audit.Record
+
+	placements []placement.Placement
+
+	webhooks   map[string]webhook.Webhook
+	deliveries []webhook.Delivery
+
+	// outbox holds the records of the changes whose events are pending, relaying is serialized by relayMu.
+	relayMu   sync.Mutex
+	outbox    []audit.Record
+	outboxSeq uint64
+
+	species map[string]species.Species
+	rules   []cohabitation.Definition
+}
+
+// New - returns a new in-memory store, empty but for the default species and cohabitation rules.
+func New() *Store {
+	return &Store{
+		cages: map[string]cage.Cage{},
+		dinos: map[string]dino.Dinosaur{},
+
+		apiKeys: map[string]apikey.APIKey{},
+
+		webhooks: map[string]webhook.Webhook{},
+
+		species: newDefaultSpecies(),
+		rules:   cohabitation.DefaultDefinitions(),
+	}
+}
+
+// record - appends the audit records of a change to the audit log and the outbox. The store lock must be held.
+func (s *Store) record(recs ...audit.Record) {
+	s.audit = append(s.audit, recs...)
+	s.outbox = append(s.outbox, recs...)
+}
@@ -0,0 +1,541 @@
+package memstore
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lenguti/jppp/business/core"
+	"github.com/lenguti/jppp/business/core/audit"
+	"github.com/lenguti/jppp/business/core/cage"
+	"github.com/lenguti/jppp/business/core/dino"
+	"github.com/lenguti/jppp/business/core/placement"
+	"github.com/lenguti/jppp/business/core/species"
+	"github.com/lenguti/jppp/business/core/webhook"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCageStore(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("get not found", func(t *testing.T) {
+		// Setup.
+		cs := NewCageStore(New())
+
+		// Execute.
+		_, err := cs.Get(ctx, uuid.NewString())
+
+		// Validate.
+		assert.ErrorIs(t, err, core.ErrNotFound)
+	})
+
+	t.Run("list status filter", func(t *testing.T) {
+		// Setup.
+		cs := NewCageStore(New())
+		active := newCage(cage.CageStatusActive, 1)
+		down := newCage(cage.CageStatusDown, 1)
+		require.NoError(t, cs.Create(ctx, active))
+		require.NoError(t, cs.Create(ctx, down))
+
+		// Execute.
+		got, err := cs.List(ctx, core.Page{}, core.Filter{Key: "status", Value: cage.CageStatusDown})
+
+		// Validate.
+		require.NoError(t, err)
+		require.Len(t, got, 1)
+		assert.Equal(t, down.ID, got[0].ID)
+	})
+
+	t.Run("add and remove dino", func(t *testing.T) {
+		// Setup.
+		ms := New()
+		cs, ds := NewCageStore(ms), NewDinoStore(ms)
+		c := newCage(cage.CageStatusActive, 1)
+		d := newDino()
+		require.NoError(t, cs.Create(ctx, c))
+		require.NoError(t, ds.Create(ctx, d))
+
+		// Execute.
+		c.CurrentCapacity++
+		c.Version++
+		require.NoError(t, cs.AddDino(ctx, c, d.ID.String()))
+
+		// Validate.
+		caged, err := ds.ListByCage(ctx, c.ID.String(), core.Page{})
+		require.NoError(t, err)
+		require.Len(t, caged, 1)
+		assert.Equal(t, c.ID, caged[0].CageID)
+
+		// Execute.
+		c.CurrentCapacity--
+		c.Version++
+		require.NoError(t, cs.RemoveDino(ctx, c, d.ID.String()))
+
+		// Validate.
+		got, err := cs.Get(ctx, c.ID.String())
+		require.NoError(t, err)
+		assert.Equal(t, 0, got.CurrentCapacity)
+		gotDino, err := ds.Get(ctx, d.ID.String())
+		require.NoError(t, err)
+		assert.Equal(t, uuid.Nil, gotDino.CageID)
+	})
+
+	t.Run("add dino stale version", func(t *testing.T) {
+		// Setup.
+		ms := New()
+		cs, ds := NewCageStore(ms), NewDinoStore(ms)
+		c := newCage(cage.CageStatusActive, 2)
+		d := newDino()
+		require.NoError(t, cs.Create(ctx, c))
+		require.NoError(t, ds.Create(ctx, d))
+
+		// Execute.
+		c.CurrentCapacity++
+		err := cs.AddDino(ctx, c, d.ID.String())
+
+		// Validate.
+		assert.ErrorIs(t, err, core.ErrConflict)
+		gotDino, err := ds.Get(ctx, d.ID.String())
+		require.NoError(t, err)
+		assert.Equal(t, uuid.Nil, gotDino.CageID)
+	})
+
+	t.Run("apply steps", func(t *testing.T) {
+		// Setup.
+		ms := New()
+		cs, ds := NewCageStore(ms), NewDinoStore(ms)
+		c := newCage(cage.CageStatusDown, 2)
+		d1, d2 := newDino(), newDino()
+		require.NoError(t, cs.Create(ctx, c))
+		require.NoError(t, ds.Create(ctx, d1))
+		require.NoError(t, ds.Create(ctx, d2))
+
+		// Execute.
+		up := c
+		up.Status = cage.CageStatusActive
+		up.Version++
+		first := up
+		first.CurrentCapacity++
+		first.Version++
+		second := first
+		second.CurrentCapacity++
+		second.Version++
+		err := cs.Apply(ctx, []cage.Step{{Cage: up}, {Cage: first, DinoID: d1.ID}, {Cage: second, DinoID: d2.ID}}, audit.Record{ID: uuid.New()})
+
+		// Validate.
+		require.NoError(t, err)
+		got, err := cs.Get(ctx, c.ID.String())
+		require.NoError(t, err)
+		assert.Equal(t, second, got)
+		assert.Len(t, ms.audit, 1)
+	})
+
+	t.Run("apply rolls back every step on conflict", func(t *testing.T) {
+		// Setup.
+		ms := New()
+		cs, ds := NewCageStore(ms), NewDinoStore(ms)
+		c := newCage(cage.CageStatusActive, 2)
+		d := newDino()
+		require.NoError(t, cs.Create(ctx, c))
+		require.NoError(t, ds.Create(ctx, d))
+
+		// Execute.
+		first := c
+		first.CurrentCapacity++
+		first.Version++
+		err := cs.Apply(ctx, []cage.Step{{Cage: first, DinoID: d.ID}, {Cage: first, DinoID: d.ID}}, audit.Record{ID: uuid.New()})
+
+		// Validate.
+		assert.ErrorIs(t, err, core.ErrConflict)
+		got, err := cs.Get(ctx, c.ID.String())
+		require.NoError(t, err)
+		assert.Equal(t, c, got)
+		gotDino, err := ds.Get(ctx, d.ID.String())
+		require.NoError(t, err)
+		assert.Equal(t, uuid.Nil, gotDino.CageID)
+		assert.Empty(t, ms.placements)
+		assert.Empty(t, ms.audit)
+	})
+
+	t.Run("delete and restore", func(t *testing.T) {
+		// Setup.
+		cs := NewCageStore(New())
+		c := newCage(cage.CageStatusActive, 1)
+		require.NoError(t, cs.Create(ctx, c))
+		now := time.Now().UTC()
+
+		// Execute.
+		err := cs.Delete(ctx, c.ID.String(), c.Version+1, now)
+
+		// Validate.
+		require.NoError(t, err)
+		got, err := cs.List(ctx, core.Page{}, core.NotDeleted)
+		require.NoError(t, err)
+		assert.Empty(t, got)
+		assert.ErrorIs(t, cs.Delete(ctx, c.ID.String(), c.Version+2, now), core.ErrConflict)
+
+		// Execute.
+		err = cs.Restore(ctx, c.ID.String(), c.Version+2, now)
+
+		// Validate.
+		require.NoError(t, err)
+		got, err = cs.List(ctx, core.Page{}, core.NotDeleted)
+		require.NoError(t, err)
+		assert.Len(t, got, 1)
+	})
+
+	t.Run("summarize skips deleted", func(t *testing.T) {
+		// Setup.
+		cs := NewCageStore(New())
+		kept, deleted := newCage(cage.CageStatusActive, 4), newCage(cage.CageStatusActive, 2)
+		kept.CurrentCapacity = 1
+		deleted.DeletedAt = time.Now().UTC()
+		require.NoError(t, cs.Create(ctx, kept))
+		require.NoError(t, cs.Create(ctx, deleted))
+
+		// Execute.
+		got, err := cs.Summarize(ctx)
+
+		// Validate.
+		require.NoError(t, err)
+		require.Len(t, got, 1)
+		assert.Equal(t, cage.Summary{Type: kept.Type, Status: cage.CageStatusActive, Cages: 1, Capacity: 4, Occupied: 1}, got[0])
+	})
+
+	t.Run("delete cage holding dinos", func(t *testing.T) {
+		// Setup.
+		cs := NewCageStore(New())
+		c := newCage(cage.CageStatusActive, 2)
+		c.CurrentCapacity = 1
+		require.NoError(t, cs.Create(ctx, c))
+
+		// Execute.
+		err := cs.Delete(ctx, c.ID.String(), c.Version+1, time.Now().UTC())
+
+		// Validate.
+		assert.ErrorIs(t, err, core.ErrConflict)
+	})
+}
+
+func TestDinoStore(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("update name", func(t *testing.T) {
+		// Setup.
+		ds := NewDinoStore(New())
+		d := newDino()
+		require.NoError(t, ds.Create(ctx, d))
+
+		// Execute.
+		err := ds.UpdateName(ctx, d.ID.String(), "Blue", d.Version+1, time.Now().UTC())
+
+		// Validate.
+		require.NoError(t, err)
+		got, err := ds.Get(ctx, d.ID.String())
+		require.NoError(t, err)
+		assert.Equal(t, "Blue", got.Name)
+		assert.Equal(t, d.Version+1, got.Version)
+	})
+
+	t.Run("update name stale version", func(t *testing.T) {
+		// Setup.
+		ds := NewDinoStore(New())
+		d := newDino()
+		require.NoError(t, ds.Create(ctx, d))
+
+		// Execute.
+		err := ds.UpdateName(ctx, d.ID.String(), "Blue", d.Version, time.Now().UTC())
+
+		// Validate.
+		assert.ErrorIs(t, err, core.ErrConflict)
+	})
+
+	t.Run("update name not found", func(t *testing.T) {
+		// Setup.
+		ds := NewDinoStore(New())
+
+		// Execute.
+		err := ds.UpdateName(ctx, uuid.NewString(), "Blue", 2, time.Now().UTC())
+
+		// Validate.
+		assert.ErrorIs(t, err, core.ErrNotFound)
+	})
+}
+
+func TestAuditStore(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("records written with the change", func(t *testing.T) {
+		// Setup.
+		ms := New()
+		cs, as := NewCageStore(ms), NewAuditStore(ms)
+		c := newCage(cage.CageStatusActive, 1)
+		rec, err := audit.NewRecord(ctx, audit.ActionCageCreate, audit.EntityCage, c.ID, nil, c, c.CreatedAt)
+		require.NoError(t, err)
+
+		// Execute.
+		require.NoError(t, cs.Create(ctx, c, rec))
+		got, err := as.List(ctx, core.Page{}, core.Filter{Key: "entity_id", Value: c.ID.String()})
+
+		// Validate.
+		require.NoError(t, err)
+		require.Len(t, got, 1)
+		assert.Equal(t, rec, got[0])
+		assert.Equal(t, audit.SystemActor, got[0].Actor)
+	})
+
+	t.Run("records dropped on conflict", func(t *testing.T) {
+		// Setup.
+		ms := New()
+		cs, as := NewCageStore(ms), NewAuditStore(ms)
+		c := newCage(cage.CageStatusActive, 1)
+		require.NoError(t, cs.Create(ctx, c))
+		rec, err := audit.NewRecord(ctx, audit.ActionCageUpdateStatus, audit.EntityCage, c.ID, c, c, c.UpdatedAt)
+		require.NoError(t, err)
+
+		// Execute.
+		err = cs.UpdateStatus(ctx, c.ID.String(), cage.CageStatusDown, 3, time.Now().UTC(), rec)
+
+		// Validate.
+		assert.ErrorIs(t, err, core.ErrConflict)
+		got, err := as.List(ctx, core.Page{})
+		require.NoError(t, err)
+		assert.Empty(t, got)
+	})
+}
+
+func TestPlacementStore(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("transfer closes and opens placements", func(t *testing.T) {
+		// Setup.
+		ms := New()
+		cs, ds, ps := NewCageStore(ms), NewDinoStore(ms), NewPlacementStore(ms)
+		from, to := newCage(cage.CageStatusActive, 1), newCage(cage.CageStatusActive, 1)
+		d := newDino()
+		require.NoError(t, cs.Create(ctx, from))
+		require.NoError(t, cs.Create(ctx, to))
+		require.NoError(t, ds.Create(ctx, d))
+
+		added := time.Unix(1690000000, 0)
+		from.CurrentCapacity++
+		from.Version++
+		from.UpdatedAt = added
+		require.NoError(t, cs.AddDino(ctx, from, d.ID.String()))
+
+		// Execute.
+		moved := added.Add(time.Hour)
+		from.CurrentCapacity--
+		from.Version++
+		from.UpdatedAt = moved
+		to.CurrentCapacity++
+		to.Version++
+		to.UpdatedAt = moved
+		require.NoError(t, cs.TransferDino(ctx, from, to, d.ID.String()))
+
+		// Validate.
+		got, err := ps.List(ctx, core.Page{}, core.Filter{Key: "dinosaur_id", Value: d.ID.String()})
+		require.NoError(t, err)
+		require.Len(t, got, 2)
+		assert.Equal(t, from.ID, got[0].CageID)
+		assert.Equal(t, added, got[0].AssignedAt)
+		assert.Equal(t, moved, got[0].ReleasedAt)
+		assert.Equal(t, to.ID, got[1].CageID)
+		assert.False(t, got[1].Released())
+
+		for _, tc := range []struct {
+			at   time.Time
+			want []uuid.UUID
+		}{
+			{added.Add(-time.Second), nil},
+			{added, []uuid.UUID{from.ID}},
+			{moved, []uuid.UUID{to.ID}},
+		} {
+			roster, err := ps.ListAt(ctx, tc.at, core.Filter{Key: "dinosaur_id", Value: d.ID.String()})
+			require.NoError(t, err)
+			var cages []uuid.UUID
+			for _, p := range roster {
+				cages = append(cages, p.CageID)
+			}
+			assert.Equal(t, tc.want, cages, tc.at)
+		}
+	})
+
+	t.Run("conflicting add opens no placement", func(t *testing.T) {
+		// Setup.
+		ms := New()
+		cs, ds, ps := NewCageStore(ms), NewDinoStore(ms), NewPlacementStore(ms)
+		c := newCage(cage.CageStatusActive, 1)
+		d := newDino()
+		require.NoError(t, cs.Create(ctx, c))
+		require.NoError(t, ds.Create(ctx, d))
+
+		// Execute.
+		c.CurrentCapacity++
+		c.Version += 2
+		err := cs.AddDino(ctx, c, d.ID.String())
+
+		// Validate.
+		assert.ErrorIs(t, err, core.ErrConflict)
+		got, err := ps.List(ctx, core.Page{})
+		require.NoError(t, err)
+		assert.Equal(t, []placement.Placement{}, got)
+	})
+}
+
+func newCage(status cage.Status, capacity int) cage.Cage {
+	now := time.Now().UTC()
+	return cage.Cage{
+		ID:        uuid.New(),
+		Type:      cage.CageTypeCarnivore,
+		Capacity:  capacity,
+		Status:    status,
+		Version:   1,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+func newDino() dino.Dinosaur {
+	now := time.Now().UTC()
+	return dino.Dinosaur{
+		ID:        uuid.New(),
+		Name:      "Rexy",
+		Species:   dino.DinoSpeciesTyrannosaurus,
+		Diet:      dino.DietTypeCarnivore,
+		Version:   1,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+func TestWebhookStore(t *testing.T) {
+	ctx := context.Background()
+
+	newDelivery := func(w webhook.Webhook, due time.Time) webhook.Delivery {
+		return webhook.Delivery{
+			ID:            uuid.New(),
+			WebhookID:     w.ID,
+			EventType:     "cage.created",
+			Payload:       []byte(`{}`),
+			Status:        webhook.StatusPending,
+			NextAttemptAt: due,
+			CreatedAt:     due,
+			UpdatedAt:     due,
+		}
+	}
+
+	t.Run("claim due deliveries", func(t *testing.T) {
+		// Setup.
+		ws := NewWebhookStore(New())
+		w := webhook.Webhook{ID: uuid.New(), URL: "http://vet.jppp.test", CreatedAt: time.Now()}
+		require.NoError(t, ws.Create(ctx, w))
+
+		now := time.Unix(1690000000, 0)
+		late, early, later := newDelivery(w, now.Add(-time.Second)), newDelivery(w, now.Add(-time.Minute)), newDelivery(w, now.Add(time.Second))
+		done := newDelivery(w, now.Add(-time.Hour))
+		done.Status = webhook.StatusSucceeded
+		require.NoError(t, ws.Enqueue(ctx, late, early, later, done))
+
+		// Execute.
+		until := now.Add(time.Minute)
+		got, err := ws.ClaimDue(ctx, now, until, 10)
+		require.NoError(t, err)
+		again, err := ws.ClaimDue(ctx, now, until, 10)
+		require.NoError(t, err)
+
+		// Validate.
+		require.Len(t, got, 2)
+		assert.Equal(t, early.ID, got[0].ID)
+		assert.Equal(t, late.ID, got[1].ID)
+		assert.Equal(t, until, got[0].NextAttemptAt)
+		assert.Empty(t, again)
+
+		expired, err := ws.ClaimDue(ctx, until, until.Add(time.Minute), 1)
+		require.NoError(t, err)
+		assert.Len(t, expired, 1)
+	})
+
+	t.Run("delete cancels pending deliveries", func(t *testing.T) {
+		// Setup.
+		ws := NewWebhookStore(New())
+		w := webhook.Webhook{ID: uuid.New(), URL: "http://vet.jppp.test", CreatedAt: time.Now()}
+		require.NoError(t, ws.Create(ctx, w))
+		now := time.Now()
+		pending, done := newDelivery(w, now), newDelivery(w, now)
+		done.Status = webhook.StatusSucceeded
+		require.NoError(t, ws.Enqueue(ctx, pending, done))
+
+		// Execute.
+		err := ws.Delete(ctx, w.ID.String(), now)
+
+		// Validate.
+		require.NoError(t, err)
+		active, err := ws.ListActive(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, active)
+
+		got, err := ws.ListDeliveries(ctx, core.Page{}, core.Filter{Key: "status", Value: webhook.StatusCanceled})
+		require.NoError(t, err)
+		require.Len(t, got, 1)
+		assert.Equal(t, pending.ID, got[0].ID)
+
+		assert.ErrorIs(t, ws.Delete(ctx, w.ID.String(), now), core.ErrNotFound)
+	})
+}
+
+func TestPaginate(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("pages through dinos by name", func(t *testing.T) {
+		// Setup.
+		ms := New()
+		dc := dino.NewCore(NewDinoStore(ms), zerolog.Nop(), species.NewCore(NewSpeciesStore(ms), zerolog.Nop(), species.Config{}))
+		for _, name := range []string{"Echo", "Blue", "Delta", "Charlie", "Rexy"} {
+			_, err := dc.Create(ctx, dino.NewDino{Name: name, Species: dino.DinoSpeciesVelociraptor, Diet: dino.DietTypeCarnivore})
+			require.NoError(t, err)
+		}
+
+		// Execute.
+		page := core.Page{Limit: 2, Sort: core.Sort{Field: core.SortName}}
+		var names []string
+		for {
+			ds, next, err := dc.List(ctx, page)
+			require.NoError(t, err)
+			for _, d := range ds {
+				names = append(names, d.Name)
+			}
+			if next == "" {
+				break
+			}
+			page.Cursor = next
+		}
+
+		// Validate.
+		assert.Equal(t, []string{"Blue", "Charlie", "Delta", "Echo", "Rexy"}, names)
+	})
+
+	t.Run("descending created at with ties", func(t *testing.T) {
+		// Setup.
+		ms := New()
+		cs := NewCageStore(ms)
+		for i := 0; i < 5; i++ {
+			require.NoError(t, cs.Create(ctx, newCage(cage.CageStatusActive, 1)))
+		}
+		all, err := cs.List(ctx, core.Page{Sort: core.Sort{Field: core.SortCreatedAt, Desc: true}})
+		require.NoError(t, err)
+
+		// Execute.
+		sort := core.Sort{Field: core.SortCreatedAt, Desc: true}
+		last := all[1]
+		got, err := cs.List(ctx, core.Page{Limit: 10, Sort: sort, Cursor: core.EncodeCursor(sort, strconv.FormatInt(last.CreatedAt.Unix(), 10), last.ID.String())})
+
+		// Validate.
+		require.NoError(t, err)
+		assert.Equal(t, all[2:], got)
+	})
+}
@@ -0,0 +1,61 @@
+package memstore
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lenguti/jppp/business/core/audit"
+	"github.com/lenguti/jppp/business/core/event"
+	"github.com/lenguti/jppp/business/core/outbox"
+)
+
+var _ outbox.Storer = (*OutboxStore)(nil)
+
+// OutboxStore - manages the set of apis for in-memory outbox access. The events are appended by the
+// cage and dino stores along with the change they describe, as the audit records of the change.
+type OutboxStore struct {
+	s *Store
+}
+
+// NewOutboxStore - constructs the api for in-memory outbox access.
+func NewOutboxStore(s *Store) *OutboxStore {
+	return &OutboxStore{
+		s: s,
+	}
+}
+
+// Relay - will pass the events of at most limit pending changes to fn, removing them from the outbox
+// once it succeeds. The store is not locked while fn runs, so fn may use it.
+func (ob *OutboxStore) Relay(ctx context.Context, limit int, fn func(evs []event.Event) error) (int, error) {
+	ob.s.relayMu.Lock()
+	defer ob.s.relayMu.Unlock()
+
+	// Changes are only ever appended, so the pending ones read here stay first until removed.
+	ob.s.mu.RLock()
+	recs := make([]audit.Record, 0, limit)
+	for i := 0; i < len(ob.s.outbox) && i < limit; i++ {
+		recs = append(recs, ob.s.outbox[i])
+	}
+	seq := ob.s.outboxSeq
+	ob.s.mu.RUnlock()
+	if len(recs) == 0 {
+		return 0, nil
+	}
+
+	evs, err := event.FromRecords(recs...)
+	if err != nil {
+		return 0, fmt.Errorf("relay: %w", err)
+	}
+	for i := range evs {
+		evs[i].ID = seq + uint64(i) + 1
+	}
+	if err := fn(evs); err != nil {
+		return 0, fmt.Errorf("relay: %w", err)
+	}
+
+	ob.s.mu.Lock()
+	defer ob.s.mu.Unlock()
+	ob.s.outbox = ob.s.outbox[len(recs):]
+	ob.s.outboxSeq += uint64(len(evs))
+	return len(evs), nil
+}
@@ -0,0 +1,83 @@
+package memstore
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lenguti/jppp/business/core"
+	"github.com/lenguti/jppp/business/core/placement"
+)
+
+var _ placement.Storer = (*PlacementStore)(nil)
+
+// PlacementStore - manages the set of apis for in-memory placement access. Placements are
+// opened and closed by the cage store as dinos move between cages.
+type PlacementStore struct {
+	s *Store
+}
+
+// NewPlacementStore - constructs the api for in-memory placement access.
+func NewPlacementStore(s *Store) *PlacementStore {
+	return &PlacementStore{
+		s: s,
+	}
+}
+
+// List - will list a page of placements.
+func (ps *PlacementStore) List(ctx context.Context, page core.Page, filters ...core.Filter) ([]placement.Placement, error) {
+	out, err := ps.filter(func(placement.Placement) bool { return true }, filters...)
+	if err != nil {
+		return nil, fmt.Errorf("list: %w", err)
+	}
+	return paginate(out, page, placementSortKey)
+}
+
+// ListAt - will list the placements ongoing at the given time.
+func (ps *PlacementStore) ListAt(ctx context.Context, at time.Time, filters ...core.Filter) ([]placement.Placement, error) {
+	out, err := ps.filter(func(p placement.Placement) bool { return p.ActiveAt(at) }, filters...)
+	if err != nil {
+		return nil, fmt.Errorf("list at: %w", err)
+	}
+	return paginate(out, core.Page{}, placementSortKey)
+}
+
+func (ps *PlacementStore) filter(keep func(placement.Placement) bool, filters ...core.Filter) ([]placement.Placement, error) {
+	ps.s.mu.RLock()
+	defer ps.s.mu.RUnlock()
+
+	out := make([]placement.Placement, 0, len(ps.s.placements))
+	for _, p := range ps.s.placements {
+		if !keep(p) {
+			continue
+		}
+		ok, err := match(placement.FilterFields, placementFieldValue(p), filters...)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			out = append(out, p)
+		}
+	}
+	return out, nil
+}
+
+func placementSortKey(p placement.Placement, field string) sortKey {
+	return sortKey{num: p.AssignedAt.Unix(), id: p.ID.String()}
+}
+
+func placementFieldValue(p placement.Placement) func(string) string {
+	return func(key string) string {
+		switch key {
+		case "cage_id":
+			return p.CageID.String()
+		case "dinosaur_id":
+			return p.DinoID.String()
+		case "assignedAt":
+			return unixOrEmpty(p.AssignedAt)
+		case "releasedAt":
+			return unixOrEmpty(p.ReleasedAt)
+		}
+		return ""
+	}
+}
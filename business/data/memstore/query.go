@@ -0,0 +1,95 @@
+package memstore
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lenguti/jppp/business/core"
+)
+
+// sortKey - represents the position of an item in a sorted list.
+type sortKey struct {
+	num int64
+	str string
+	id  string
+}
+
+func (k sortKey) compare(o sortKey) int {
+	switch {
+	case k.num != o.num:
+		if k.num < o.num {
+			return -1
+		}
+		return 1
+	case k.str != o.str:
+		return strings.Compare(k.str, o.str)
+	}
+	return strings.Compare(k.id, o.id)
+}
+
+// unixOrEmpty - returns the unix timestamp of t, or an empty value for an unset field.
+func unixOrEmpty(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return strconv.FormatInt(t.Unix(), 10)
+}
+
+// match - reports whether the item, whose field values are returned by value, satisfies every filter.
+func match(fields core.Fields, value func(string) string, filters ...core.Filter) (bool, error) {
+	for _, f := range filters {
+		ok, err := fields.Match(f, value(f.Key))
+		if err != nil {
+			return false, err
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// paginate - sorts the items by the page sort and then id, returning at most page.Limit
+// items that come after the page cursor. key returns the sort key of an item for a field.
+func paginate[T any](items []T, page core.Page, key func(T, string) sortKey) ([]T, error) {
+	field := page.Sort.Field
+	if field == "" {
+		field = core.SortCreatedAt
+	}
+
+	less := func(a, b sortKey) bool {
+		if page.Sort.Desc {
+			return a.compare(b) > 0
+		}
+		return a.compare(b) < 0
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return less(key(items[i], field), key(items[j], field))
+	})
+
+	c, ok, err := core.DecodeCursor(page)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		after := sortKey{str: c.Value, id: c.ID}
+		if field != core.SortName {
+			n, err := strconv.ParseInt(c.Value, 10, 64)
+			if err != nil {
+				return nil, core.ErrInvalidCursor
+			}
+			after = sortKey{num: n, id: c.ID}
+		}
+		start := sort.Search(len(items), func(i int) bool {
+			return less(after, key(items[i], field))
+		})
+		items = items[start:]
+	}
+
+	if page.Limit > 0 && len(items) > page.Limit {
+		items = items[:page.Limit]
+	}
+	return items, nil
+}
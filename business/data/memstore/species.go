@@ -0,0 +1,161 @@
+package memstore
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lenguti/jppp/business/core"
+	"github.com/lenguti/jppp/business/core/dino"
+	"github.com/lenguti/jppp/business/core/species"
+)
+
+var _ species.Storer = (*SpeciesStore)(nil)
+
+// defaultSpecies - the species registered in a new store, as seeded by the species migration.
+var defaultSpecies = []species.Species{
+	{Name: dino.DinoSpeciesTyrannosaurus, Diet: species.DietCarnivore, SizeClass: species.SizeHuge, Temperament: species.TemperamentAggressive},
+	{Name: dino.DinoSpeciesVelociraptor, Diet: species.DietCarnivore, SizeClass: species.SizeSmall, Temperament: species.TemperamentAggressive, Herd: true},
+	{Name: dino.DinoSpeciesSpinosaurus, Diet: species.DietCarnivore, SizeClass: species.SizeHuge, Temperament: species.TemperamentAggressive},
+	{Name: dino.DinoSpeciesMegalosaurus, Diet: species.DietCarnivore, SizeClass: species.SizeLarge, Temperament: species.TemperamentAggressive},
+	{Name: dino.DinoSpeciesBrachiosaurus, Diet: species.DietHerbivore, SizeClass: species.SizeHuge, Temperament: species.TemperamentDocile, Herd: true},
+	{Name: dino.DinoSpeciesStegosaurus, Diet: species.DietHerbivore, SizeClass: species.SizeLarge, Temperament: species.TemperamentDocile, Herd: true},
+	{Name: dino.DinoSpeciesAnkylosaurus, Diet: species.DietHerbivore, SizeClass: species.SizeLarge, Temperament: species.TemperamentTerritorial},
+	{Name: dino.DinoSpeciesTriceratops, Diet: species.DietHerbivore, SizeClass: species.SizeLarge, Temperament: species.TemperamentTerritorial, Herd: true},
+}
+
+// SpeciesStore - manages the set of apis for in-memory species access.
+type SpeciesStore struct {
+	s *Store
+}
+
+// NewSpeciesStore - constructs the api for in-memory species access.
+func NewSpeciesStore(s *Store) *SpeciesStore {
+	return &SpeciesStore{
+		s: s,
+	}
+}
+
+// Create - will insert a new species record.
+func (ss *SpeciesStore) Create(ctx context.Context, sp species.Species) error {
+	ss.s.mu.Lock()
+	defer ss.s.mu.Unlock()
+
+	if _, ok := ss.s.species[sp.Name]; ok {
+		return core.ErrInvalidSpeciesExists
+	}
+	ss.s.species[sp.Name] = sp
+	return nil
+}
+
+// Get - will fetch a species by its name.
+func (ss *SpeciesStore) Get(ctx context.Context, name string) (species.Species, error) {
+	ss.s.mu.RLock()
+	defer ss.s.mu.RUnlock()
+
+	sp, ok := ss.s.species[name]
+	if !ok {
+		return species.Species{}, core.ErrNotFound
+	}
+	return sp, nil
+}
+
+// List - will list a page of species.
+func (ss *SpeciesStore) List(ctx context.Context, page core.Page, filters ...core.Filter) ([]species.Species, error) {
+	ss.s.mu.RLock()
+	defer ss.s.mu.RUnlock()
+
+	out := make([]species.Species, 0, len(ss.s.species))
+	for _, sp := range ss.s.species {
+		ok, err := match(species.FilterFields, speciesFieldValue(sp), filters...)
+		if err != nil {
+			return nil, fmt.Errorf("list: %w", err)
+		}
+		if ok {
+			out = append(out, sp)
+		}
+	}
+	return paginate(out, page, speciesSortKey)
+}
+
+// ListAll - will list every species ordered by name.
+func (ss *SpeciesStore) ListAll(ctx context.Context) ([]species.Species, error) {
+	ss.s.mu.RLock()
+	defer ss.s.mu.RUnlock()
+
+	out := make([]species.Species, 0, len(ss.s.species))
+	for _, sp := range ss.s.species {
+		out = append(out, sp)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+// Update - will update the species record.
+func (ss *SpeciesStore) Update(ctx context.Context, sp species.Species) error {
+	ss.s.mu.Lock()
+	defer ss.s.mu.Unlock()
+
+	if _, ok := ss.s.species[sp.Name]; !ok {
+		return core.ErrNotFound
+	}
+	ss.s.species[sp.Name] = sp
+	return nil
+}
+
+// Delete - will delete the species record, unless a dino belongs to it.
+func (ss *SpeciesStore) Delete(ctx context.Context, name string) error {
+	ss.s.mu.Lock()
+	defer ss.s.mu.Unlock()
+
+	if _, ok := ss.s.species[name]; !ok {
+		return core.ErrNotFound
+	}
+	for _, d := range ss.s.dinos {
+		if d.Species == name {
+			return core.ErrInvalidSpeciesInUse
+		}
+	}
+	delete(ss.s.species, name)
+	return nil
+}
+
+func speciesSortKey(sp species.Species, field string) sortKey {
+	switch field {
+	case core.SortName:
+		return sortKey{str: sp.Name, id: sp.ID.String()}
+	default:
+		return sortKey{num: sp.CreatedAt.Unix(), id: sp.ID.String()}
+	}
+}
+
+func speciesFieldValue(sp species.Species) func(string) string {
+	return func(key string) string {
+		switch key {
+		case "diet":
+			return sp.Diet
+		case "size_class":
+			return sp.SizeClass
+		case "temperament":
+			return sp.Temperament
+		case "createdAt":
+			return strconv.FormatInt(sp.CreatedAt.Unix(), 10)
+		}
+		return ""
+	}
+}
+
+// newDefaultSpecies - returns the default species keyed by name, created now.
+func newDefaultSpecies() map[string]species.Species {
+	now := time.Now().UTC()
+	out := make(map[string]species.Species, len(defaultSpecies))
+	for _, sp := range defaultSpecies {
+		sp.ID = uuid.New()
+		sp.CreatedAt, sp.UpdatedAt = now, now
+		out[sp.Name] = sp
+	}
+	return out
+}
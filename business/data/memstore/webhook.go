@@ -0,0 +1,209 @@
+package memstore
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/lenguti/jppp/business/core"
+	"github.com/lenguti/jppp/business/core/webhook"
+)
+
+var _ webhook.Storer = (*WebhookStore)(nil)
+
+// WebhookStore - manages the set of apis for in-memory webhook and delivery access.
+type WebhookStore struct {
+	s *Store
+}
+
+// NewWebhookStore - constructs the api for in-memory webhook and delivery access.
+func NewWebhookStore(s *Store) *WebhookStore {
+	return &WebhookStore{
+		s: s,
+	}
+}
+
+// Create - will insert a new webhook record.
+func (ws *WebhookStore) Create(ctx context.Context, w webhook.Webhook) error {
+	ws.s.mu.Lock()
+	defer ws.s.mu.Unlock()
+
+	if _, ok := ws.s.webhooks[w.ID.String()]; ok {
+		return fmt.Errorf("create: webhook %s already exists", w.ID)
+	}
+	w.Types = append([]string(nil), w.Types...)
+	ws.s.webhooks[w.ID.String()] = w
+	return nil
+}
+
+// Get - will fetch a webhook by its id.
+func (ws *WebhookStore) Get(ctx context.Context, id string) (webhook.Webhook, error) {
+	ws.s.mu.RLock()
+	defer ws.s.mu.RUnlock()
+
+	w, ok := ws.s.webhooks[id]
+	if !ok {
+		return webhook.Webhook{}, core.ErrNotFound
+	}
+	return w, nil
+}
+
+// List - will list a page of webhooks.
+func (ws *WebhookStore) List(ctx context.Context, page core.Page, filters ...core.Filter) ([]webhook.Webhook, error) {
+	ws.s.mu.RLock()
+	defer ws.s.mu.RUnlock()
+
+	out := make([]webhook.Webhook, 0, len(ws.s.webhooks))
+	for _, w := range ws.s.webhooks {
+		ok, err := match(webhook.FilterFields, webhookFieldValue(w), filters...)
+		if err != nil {
+			return nil, fmt.Errorf("list: %w", err)
+		}
+		if ok {
+			out = append(out, w)
+		}
+	}
+	return paginate(out, page, webhookSortKey)
+}
+
+// ListActive - will list every webhook that is not deleted.
+func (ws *WebhookStore) ListActive(ctx context.Context) ([]webhook.Webhook, error) {
+	ws.s.mu.RLock()
+	defer ws.s.mu.RUnlock()
+
+	var out []webhook.Webhook
+	for _, w := range ws.s.webhooks {
+		if !w.Deleted() {
+			out = append(out, w)
+		}
+	}
+	return out, nil
+}
+
+// Delete - will delete the webhook, unless it already is, and cancel its pending deliveries.
+func (ws *WebhookStore) Delete(ctx context.Context, id string, ts time.Time) error {
+	ws.s.mu.Lock()
+	defer ws.s.mu.Unlock()
+
+	w, ok := ws.s.webhooks[id]
+	if !ok || w.Deleted() {
+		return core.ErrNotFound
+	}
+	w.DeletedAt = ts
+	ws.s.webhooks[id] = w
+
+	for i, d := range ws.s.deliveries {
+		if d.WebhookID == w.ID && d.Status == webhook.StatusPending {
+			d.Status, d.Error, d.UpdatedAt = webhook.StatusCanceled, "webhook deleted", ts
+			ws.s.deliveries[i] = d
+		}
+	}
+	return nil
+}
+
+// Enqueue - will insert the deliveries.
+func (ws *WebhookStore) Enqueue(ctx context.Context, ds ...webhook.Delivery) error {
+	ws.s.mu.Lock()
+	defer ws.s.mu.Unlock()
+
+	ws.s.deliveries = append(ws.s.deliveries, ds...)
+	return nil
+}
+
+// ClaimDue - will claim the pending deliveries due at now, postponing their next attempt to until.
+func (ws *WebhookStore) ClaimDue(ctx context.Context, now, until time.Time, limit int) ([]webhook.Delivery, error) {
+	ws.s.mu.Lock()
+	defer ws.s.mu.Unlock()
+
+	var due []int
+	for i, d := range ws.s.deliveries {
+		if d.Status == webhook.StatusPending && !d.NextAttemptAt.After(now) {
+			due = append(due, i)
+		}
+	}
+	sort.SliceStable(due, func(i, j int) bool {
+		return ws.s.deliveries[due[i]].NextAttemptAt.Before(ws.s.deliveries[due[j]].NextAttemptAt)
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	out := make([]webhook.Delivery, 0, len(due))
+	for _, i := range due {
+		ws.s.deliveries[i].NextAttemptAt = until
+		out = append(out, ws.s.deliveries[i])
+	}
+	return out, nil
+}
+
+// UpdateDelivery - will update the outcome of the delivery.
+func (ws *WebhookStore) UpdateDelivery(ctx context.Context, d webhook.Delivery) error {
+	ws.s.mu.Lock()
+	defer ws.s.mu.Unlock()
+
+	for i, v := range ws.s.deliveries {
+		if v.ID == d.ID {
+			ws.s.deliveries[i] = d
+			return nil
+		}
+	}
+	return core.ErrNotFound
+}
+
+// ListDeliveries - will list a page of deliveries.
+func (ws *WebhookStore) ListDeliveries(ctx context.Context, page core.Page, filters ...core.Filter) ([]webhook.Delivery, error) {
+	ws.s.mu.RLock()
+	defer ws.s.mu.RUnlock()
+
+	out := make([]webhook.Delivery, 0, len(ws.s.deliveries))
+	for _, d := range ws.s.deliveries {
+		ok, err := match(webhook.DeliveryFilterFields, deliveryFieldValue(d), filters...)
+		if err != nil {
+			return nil, fmt.Errorf("list deliveries: %w", err)
+		}
+		if ok {
+			out = append(out, d)
+		}
+	}
+	return paginate(out, page, deliverySortKey)
+}
+
+func webhookSortKey(w webhook.Webhook, field string) sortKey {
+	return sortKey{num: w.CreatedAt.Unix(), id: w.ID.String()}
+}
+
+func webhookFieldValue(w webhook.Webhook) func(string) string {
+	return func(key string) string {
+		switch key {
+		case "url":
+			return w.URL
+		case "createdAt":
+			return strconv.FormatInt(w.CreatedAt.Unix(), 10)
+		case "deletedAt":
+			return unixOrEmpty(w.DeletedAt)
+		}
+		return ""
+	}
+}
+
+func deliverySortKey(d webhook.Delivery, field string) sortKey {
+	return sortKey{num: d.CreatedAt.Unix(), id: d.ID.String()}
+}
+
+func deliveryFieldValue(d webhook.Delivery) func(string) string {
+	return func(key string) string {
+		switch key {
+		case "webhook_id":
+			return d.WebhookID.String()
+		case "status":
+			return d.Status
+		case "event_type":
+			return d.EventType
+		case "createdAt":
+			return strconv.FormatInt(d.CreatedAt.Unix(), 10)
+		}
+		return ""
+	}
+}
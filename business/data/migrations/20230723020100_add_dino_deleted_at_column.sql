@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE dinosaur
+  ADD deleted_at int NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE dinosaur
+  DROP deleted_at;
+-- +goose StatementEnd
@@ -0,0 +1,41 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE audit_log (
+  id uuid NOT NULL,
+  actor text NOT NULL,
+  action text NOT NULL,
+  entity_type text NOT NULL,
+  entity_id text NOT NULL,
+  before jsonb NULL,
+  after jsonb NULL,
+  request_id text NOT NULL DEFAULT '',
+  created_at bigint NOT NULL,
+  PRIMARY KEY (id)
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX audit_log_created_at_idx ON audit_log (created_at, id);
+CREATE INDEX audit_log_entity_id_idx ON audit_log (entity_id, created_at);
+CREATE INDEX audit_log_actor_idx ON audit_log (actor, created_at);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+BEGIN
+  RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER audit_log_append_only
+  BEFORE UPDATE OR DELETE ON audit_log
+  FOR EACH ROW EXECUTE FUNCTION audit_log_append_only();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE audit_log;
+DROP FUNCTION audit_log_append_only();
+-- +goose StatementEnd
@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE placement (
+  id uuid NOT NULL,
+  cage_id uuid NOT NULL,
+  dinosaur_id uuid NOT NULL,
+  assigned_at bigint NOT NULL,
+  released_at bigint NULL,
+  PRIMARY KEY (id),
+  FOREIGN KEY(cage_id) REFERENCES cage(id),
+  FOREIGN KEY(dinosaur_id) REFERENCES dinosaur(id)
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX placement_cage_id_idx ON placement (cage_id, assigned_at);
+CREATE INDEX placement_dinosaur_id_idx ON placement (dinosaur_id, assigned_at);
+CREATE UNIQUE INDEX placement_ongoing_idx ON placement (dinosaur_id) WHERE released_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO placement (id, cage_id, dinosaur_id, assigned_at)
+SELECT gen_random_uuid(), cage_id, id, COALESCE(updated_at, 0)
+FROM dinosaur
+WHERE cage_id IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE placement;
+-- +goose StatementEnd
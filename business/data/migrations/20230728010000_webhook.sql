@@ -0,0 +1,41 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE webhook (
+  id uuid NOT NULL,
+  url text NOT NULL,
+  event_types text[] NOT NULL DEFAULT '{}',
+  secret text NOT NULL,
+  created_at bigint NOT NULL,
+  deleted_at bigint NULL,
+  PRIMARY KEY (id)
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE webhook_delivery (
+  id uuid NOT NULL,
+  webhook_id uuid NOT NULL,
+  event_type text NOT NULL,
+  payload jsonb NOT NULL,
+  status text NOT NULL,
+  attempts integer NOT NULL DEFAULT 0,
+  next_attempt_at bigint NOT NULL,
+  response_code integer NOT NULL DEFAULT 0,
+  error text NOT NULL DEFAULT '',
+  created_at bigint NOT NULL,
+  updated_at bigint NOT NULL,
+  PRIMARY KEY (id),
+  FOREIGN KEY(webhook_id) REFERENCES webhook(id)
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX webhook_delivery_due_idx ON webhook_delivery (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX webhook_delivery_webhook_id_idx ON webhook_delivery (webhook_id, created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE webhook_delivery;
+DROP TABLE webhook;
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE outbox (
+  id bigserial NOT NULL,
+  event_type text NOT NULL,
+  entity_type text NOT NULL,
+  entity_id uuid NOT NULL,
+  cage_id uuid NULL,
+  data jsonb NULL,
+  request_id text NOT NULL DEFAULT '',
+  created_at bigint NOT NULL,
+  sent_at bigint NULL,
+  PRIMARY KEY (id)
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX outbox_pending_idx ON outbox (id) WHERE sent_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE outbox;
+-- +goose StatementEnd
@@ -0,0 +1,46 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE species (
+  id uuid NOT NULL,
+  name text NOT NULL,
+  diet text NOT NULL,
+  size_class text NOT NULL,
+  temperament text NOT NULL,
+  herd boolean NOT NULL DEFAULT false,
+  solitary boolean NOT NULL DEFAULT false,
+  created_at bigint NOT NULL,
+  updated_at bigint NOT NULL,
+  PRIMARY KEY (id),
+  UNIQUE (name)
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO species (id, name, diet, size_class, temperament, herd, solitary, created_at, updated_at)
+SELECT gen_random_uuid(), v.name, v.diet, v.size_class, v.temperament, v.herd, false, extract(epoch FROM now())::bigint, extract(epoch FROM now())::bigint
+FROM (VALUES
+  ('Tyrannosaurus', 'CARNIVORE', 'HUGE', 'AGGRESSIVE', false),
+  ('Velociraptor', 'CARNIVORE', 'SMALL', 'AGGRESSIVE', true),
+  ('Spinosaurus', 'CARNIVORE', 'HUGE', 'AGGRESSIVE', false),
+  ('Megalosaurus', 'CARNIVORE', 'LARGE', 'AGGRESSIVE', false),
+  ('Brachiosaurus', 'HERBIVORE', 'HUGE', 'DOCILE', true),
+  ('Stegosaurus', 'HERBIVORE', 'LARGE', 'DOCILE', true),
+  ('Ankylosaurus', 'HERBIVORE', 'LARGE', 'TERRITORIAL', false),
+  ('Triceratops', 'HERBIVORE', 'LARGE', 'TERRITORIAL', true)
+) AS v (name, diet, size_class, temperament, herd);
+-- +goose StatementEnd
+
+-- Species were stored as sent, in any case, so they are normalized to the registry names first.
+-- +goose StatementBegin
+UPDATE dinosaur SET species = initcap(lower(trim(species))) WHERE species IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE dinosaur ADD CONSTRAINT dinosaur_species_fkey FOREIGN KEY (species) REFERENCES species(name);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE dinosaur DROP CONSTRAINT dinosaur_species_fkey;
+DROP TABLE species;
+-- +goose StatementEnd
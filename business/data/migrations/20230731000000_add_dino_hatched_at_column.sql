@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE dinosaur
+  ADD hatched_at bigint NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE dinosaur
+  DROP hatched_at;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE cohabitation_rule (
+  position int NOT NULL,
+  kind text NOT NULL,
+  params jsonb NOT NULL DEFAULT '{}',
+  PRIMARY KEY (position)
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO cohabitation_rule (position, kind) VALUES
+  (1, 'diet'),
+  (2, 'carnivore_species');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE cohabitation_rule;
+-- +goose StatementEnd
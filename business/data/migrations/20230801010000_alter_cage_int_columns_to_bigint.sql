@@ -0,0 +1,20 @@
+-- Filters and cursors accept any 64 bit integer, which int columns reject as out of range.
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE cage
+  ALTER capacity TYPE bigint,
+  ALTER current_capacity TYPE bigint,
+  ALTER created_at TYPE bigint,
+  ALTER updated_at TYPE bigint,
+  ALTER deleted_at TYPE bigint;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE cage
+  ALTER capacity TYPE int,
+  ALTER current_capacity TYPE int,
+  ALTER created_at TYPE int,
+  ALTER updated_at TYPE int,
+  ALTER deleted_at TYPE int;
+-- +goose StatementEnd
@@ -0,0 +1,9 @@
+// Package migrations - holds the goose sql migrations embedded into the binary.
+package migrations
+
+import "embed"
+
+// FS - the embedded sql migration files.
+//
+//go:embed *.sql
+var FS embed.FS
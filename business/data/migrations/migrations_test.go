@@ -0,0 +1,48 @@
+package migrations_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/lenguti/jppp/business/data/db"
+	"github.com/lenguti/jppp/business/data/dbtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrations(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("species are normalized to the registry names", func(t *testing.T) {
+		// Setup.
+		const beforeSpecies = 20230729010000
+		d := dbtest.Open(t)
+		require.NoError(t, d.MigrateUpTo(ctx, beforeSpecies))
+		id := uuid.NewString()
+		require.NoError(t, dbtest.Exec(d, `INSERT INTO dinosaur (id, name, species, diet) VALUES ($1, 'Rexy', ' tyrannosaurus', 'CARNIVORE')`, id))
+
+		// Execute.
+		err := d.Migrate(ctx, db.MigrateUp)
+
+		// Validate.
+		require.NoError(t, err)
+		var species string
+		require.NoError(t, d.Get(ctx, &species, `SELECT species FROM dinosaur WHERE id = $1`, id))
+		assert.Equal(t, "Tyrannosaurus", species)
+	})
+
+	t.Run("filtered columns take any 64 bit integer", func(t *testing.T) {
+		// Setup.
+		d := dbtest.New(t)
+
+		// Execute.
+		var cages, dinos []string
+		cageErr := d.List(ctx, &cages, `SELECT id FROM cage WHERE capacity >= $1 AND current_capacity >= $1 AND created_at > $2 AND updated_at > $2 AND deleted_at > $2`, "3000000000", "99999999999")
+		dinoErr := d.List(ctx, &dinos, `SELECT id FROM dinosaur WHERE created_at > $1 AND updated_at > $1 AND deleted_at > $1`, "99999999999")
+
+		// Validate.
+		assert.NoError(t, cageErr)
+		assert.NoError(t, dinoErr)
+	})
+}
@@ -0,0 +1,193 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+// APIKeyHeader - the header carrying static api keys.
+const APIKeyHeader = "X-API-Key"
+
+// RoleClaim - the jwt claim carrying the role of the principal.
+const RoleClaim = "role"
+
+// Authentication methods a principal can be authenticated with.
+const (
+	AuthMethodAPIKey = "api_key"
+	AuthMethodJWT    = "jwt"
+)
+
+// apiKeyPrefix - prefixes generated api keys so they are easy to spot, in leaked logs for instance.
+const apiKeyPrefix = "jppp_"
+
+var (
+	// ErrUnknownAPIKey - returned by key stores when no active key matches the hash.
+	ErrUnknownAPIKey = errors.New("unknown api key")
+
+	// ErrMissingCredentials - returned when a request carries neither an api key nor a bearer token.
+	ErrMissingCredentials = errors.New("missing credentials")
+)
+
+// Principal - represents the authenticated caller of a request.
+type Principal struct {
+	// Subject - the api key name or the jwt subject.
+	Subject string
+
+	// Method - how the principal was authenticated, one of AuthMethodAPIKey or AuthMethodJWT.
+	Method string
+
+	// Role - the role granted to the api key or carried by the jwt role claim, if any.
+	Role string
+}
+
+type principalKey struct{}
+
+// WithPrincipal - returns a copy of the context carrying the principal.
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFrom - returns the principal carried by the context, if any.
+func PrincipalFrom(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
+
+// KeyStore - represents a source of api keys, looked up by their sha256 hash so keys are never stored in clear.
+type KeyStore interface {
+	LookupAPIKey(ctx context.Context, hash string) (Principal, error)
+}
+
+// StaticKeys - an api key store backed by a map of key hashes to principals, such as loaded from config.
+type StaticKeys map[string]Principal
+
+// LookupAPIKey - satisfies the KeyStore interface.
+func (s StaticKeys) LookupAPIKey(ctx context.Context, hash string) (Principal, error) {
+	p, ok := s[hash]
+	if !ok {
+		return Principal{}, ErrUnknownAPIKey
+	}
+	p.Method = AuthMethodAPIKey
+	return p, nil
+}
+
+// KeyStores - an api key store looking keys up in each store in turn.
+type KeyStores []KeyStore
+
+// LookupAPIKey - satisfies the KeyStore interface.
+func (ks KeyStores) LookupAPIKey(ctx context.Context, hash string) (Principal, error) {
+	for _, s := range ks {
+		p, err := s.LookupAPIKey(ctx, hash)
+		switch {
+		case err == nil:
+			return p, nil
+		case !errors.Is(err, ErrUnknownAPIKey):
+			return Principal{}, fmt.Errorf("lookup api key: %w", err)
+		}
+	}
+	return Principal{}, ErrUnknownAPIKey
+}
+
+// HashAPIKey - returns the hex encoded sha256 hash api keys are stored and looked up by.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// NewAPIKey - returns a new random api key along with its hash.
+func NewAPIKey() (string, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", fmt.Errorf("new api key: unable to read random bytes: %w", err)
+	}
+	key := apiKeyPrefix + base64.RawURLEncoding.EncodeToString(b)
+	return key, HashAPIKey(key), nil
+}
+
+// Authenticator - authenticates requests through the X-API-Key header or an
+// Authorization: Bearer jwt. Either may be left unset to disable it.
+type Authenticator struct {
+	Keys KeyStore
+	JWT  *JWTVerifier
+}
+
+// Authenticate - returns the principal of the request.
+func (a Authenticator) Authenticate(r *http.Request) (Principal, error) {
+	if key := r.Header.Get(APIKeyHeader); key != "" {
+		if a.Keys == nil {
+			return Principal{}, fmt.Errorf("authenticate: %w", ErrUnknownAPIKey)
+		}
+		p, err := a.Keys.LookupAPIKey(r.Context(), HashAPIKey(key))
+		if err != nil {
+			return Principal{}, fmt.Errorf("authenticate: %w", err)
+		}
+		return p, nil
+	}
+
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return Principal{}, fmt.Errorf("authenticate: %w", ErrMissingCredentials)
+	}
+	if !a.JWT.Enabled() {
+		return Principal{}, fmt.Errorf("authenticate: jwt disabled: %w", ErrInvalidToken)
+	}
+	claims, err := a.JWT.Verify(token)
+	if err != nil {
+		return Principal{}, fmt.Errorf("authenticate: %w", err)
+	}
+	role, _ := claims.Raw[RoleClaim].(string)
+	return Principal{Subject: claims.Subject, Method: AuthMethodJWT, Role: role}, nil
+}
+
+// Auth - returns a middleware rejecting unauthenticated requests with a 401 and putting the
+// principal of the others in the context and the request scoped logger. Public routes, given
+// as route patterns such as /v1/status, are let through unauthenticated.
+func Auth(a Authenticator, public ...string) Middleware {
+	open := make(map[string]bool, len(public))
+	for _, p := range public {
+		open[p] = true
+	}
+
+	return func(next Handler) Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			if open[Route(ctx)] {
+				return next(ctx, w, r)
+			}
+
+			p, err := a.Authenticate(r.WithContext(ctx))
+			if err != nil {
+				if isLookupFailure(err) {
+					return InternalServerError("Unable to authenticate request.", err, nil)
+				}
+				zerolog.Ctx(ctx).Info().Err(err).Msg("Rejected unauthenticated request.")
+				w.Header().Set("WWW-Authenticate", `Bearer realm="jppp"`)
+				return UnauthorizedError("Missing or invalid credentials.", nil, nil)
+			}
+
+			zerolog.Ctx(ctx).UpdateContext(func(c zerolog.Context) zerolog.Context {
+				return c.Str("principal", p.Subject).Str("auth_method", p.Method).Str("role", p.Role)
+			})
+			ctx = WithPrincipal(ctx, p)
+			return next(ctx, w, r.WithContext(ctx))
+		}
+	}
+}
+
+// isLookupFailure - reports whether the error comes from a key store failing rather than bad credentials.
+func isLookupFailure(err error) bool {
+	for _, e := range []error{ErrUnknownAPIKey, ErrInvalidToken, ErrMissingCredentials} {
+		if errors.Is(err, e) {
+			return false
+		}
+	}
+	return true
+}
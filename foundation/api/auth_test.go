@@ -0,0 +1,174 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func signToken(t *testing.T, alg string, key any, claims map[string]any) string {
+	t.Helper()
+	enc := func(v any) string {
+		b, err := json.Marshal(v)
+		require.NoError(t, err)
+		return base64.RawURLEncoding.EncodeToString(b)
+	}
+	signed := enc(map[string]string{"alg": alg, "typ": "JWT"}) + "." + enc(claims)
+
+	var sig []byte
+	switch k := key.(type) {
+	case []byte:
+		mac := hmac.New(sha256.New, k)
+		mac.Write([]byte(signed))
+		sig = mac.Sum(nil)
+	case *rsa.PrivateKey:
+		sum := sha256.Sum256([]byte(signed))
+		var err error
+		sig, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, sum[:])
+		require.NoError(t, err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func TestJWTVerifier(t *testing.T) {
+	now := time.Unix(1690000000, 0)
+	secret := []byte("secret")
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	require.NoError(t, err)
+	pub, err := ParseRSAPublicKey(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	require.NoError(t, err)
+
+	v := &JWTVerifier{
+		HMACSecret:   secret,
+		RSAPublicKey: pub,
+		Issuer:       "iss",
+		Audience:     "jppp",
+		Leeway:       time.Minute,
+		now:          func() time.Time { return now },
+	}
+	claims := func(overrides map[string]any) map[string]any {
+		c := map[string]any{"sub": "alan", "iss": "iss", "aud": "jppp", "exp": now.Add(time.Hour).Unix()}
+		for k, val := range overrides {
+			c[k] = val
+		}
+		return c
+	}
+
+	for _, tc := range []struct {
+		name  string
+		token string
+		valid bool
+	}{
+		{name: "hs256", token: signToken(t, AlgHS256, secret, claims(nil)), valid: true},
+		{name: "rs256", token: signToken(t, AlgRS256, rsaKey, claims(nil)), valid: true},
+		{name: "audience list", token: signToken(t, AlgHS256, secret, claims(map[string]any{"aud": []string{"a", "jppp"}})), valid: true},
+		{name: "expired within leeway", token: signToken(t, AlgHS256, secret, claims(map[string]any{"exp": now.Add(-30 * time.Second).Unix()})), valid: true},
+		{name: "expired", token: signToken(t, AlgHS256, secret, claims(map[string]any{"exp": now.Add(-time.Hour).Unix()})), valid: false},
+		{name: "missing exp", token: signToken(t, AlgHS256, secret, claims(map[string]any{"exp": nil})), valid: false},
+		{name: "not valid yet", token: signToken(t, AlgHS256, secret, claims(map[string]any{"nbf": now.Add(time.Hour).Unix()})), valid: false},
+		{name: "wrong issuer", token: signToken(t, AlgHS256, secret, claims(map[string]any{"iss": "other"})), valid: false},
+		{name: "wrong audience", token: signToken(t, AlgHS256, secret, claims(map[string]any{"aud": "other"})), valid: false},
+		{name: "missing subject", token: signToken(t, AlgHS256, secret, claims(map[string]any{"sub": ""})), valid: false},
+		{name: "wrong secret", token: signToken(t, AlgHS256, []byte("other"), claims(nil)), valid: false},
+		{name: "alg none", token: signToken(t, "none", nil, claims(nil)), valid: false},
+		{name: "malformed", token: "abc.def", valid: false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Execute.
+			c, err := v.Verify(tc.token)
+
+			// Validate.
+			if !tc.valid {
+				assert.ErrorIs(t, err, ErrInvalidToken)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "alan", c.Subject)
+		})
+	}
+}
+
+func TestAuth(t *testing.T) {
+	var buf bytes.Buffer
+	keys := StaticKeys{HashAPIKey("key"): {Subject: "ops", Role: "keeper"}}
+	router := NewRouter(Logger(zerolog.New(&buf)), Errors(), Auth(Authenticator{Keys: keys}, "/v1/public"))
+
+	var got Principal
+	h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		got, _ = PrincipalFrom(ctx)
+		return Respond(w, http.StatusOK, nil)
+	}
+	router.Handle(http.MethodGet, "v1", "/private", h)
+	router.Handle(http.MethodGet, "v1", "/public", h)
+
+	t.Run("puts the principal in the context and access log", func(t *testing.T) {
+		// Setup.
+		buf.Reset()
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/v1/private", nil)
+		r.Header.Set(APIKeyHeader, "key")
+
+		// Execute.
+		router.ServeHTTP(w, r)
+
+		// Validate.
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, Principal{Subject: "ops", Method: AuthMethodAPIKey, Role: "keeper"}, got)
+		var entry map[string]any
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+		assert.Equal(t, "ops", entry["principal"])
+	})
+
+	t.Run("lets public routes through", func(t *testing.T) {
+		// Setup.
+		got = Principal{}
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/v1/public", nil)
+
+		// Execute.
+		router.ServeHTTP(w, r)
+
+		// Validate.
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, got.Subject)
+	})
+
+	t.Run("key store failure", func(t *testing.T) {
+		// Setup.
+		a := Authenticator{Keys: KeyStores{failingKeys{}}}
+		r := httptest.NewRequest(http.MethodGet, "/v1/private", nil)
+		r.Header.Set(APIKeyHeader, "key")
+
+		// Execute.
+		_, err := a.Authenticate(r)
+
+		// Validate.
+		require.Error(t, err)
+		assert.True(t, isLookupFailure(err))
+	})
+}
+
+type failingKeys struct{}
+
+func (failingKeys) LookupAPIKey(ctx context.Context, hash string) (Principal, error) {
+	return Principal{}, errors.New("db down")
+}
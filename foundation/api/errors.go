@@ -7,8 +7,15 @@ import (
 
 const (
 	BadRequest     = "BAD_REQUEST"
+	Conflict       = "CONFLICT"
 	InternalServer = "INTERNAL_SERVER_ERROR"
 	NotFound       = "NOT_FOUND"
+	Unauthorized   = "UNAUTHORIZED"
+	Forbidden      = "FORBIDDEN"
+
+	PreconditionFailed  = "PRECONDITION_FAILED"
+	UnprocessableEntity = "UNPROCESSABLE_ENTITY"
+	ServiceUnavailable  = "SERVICE_UNAVAILABLE"
 )
 
 // HTTPError - represnts a standard error structure for the api.
@@ -108,6 +115,42 @@ func NotFoundError(msg string, err error, details map[string]any) HTTPError {
 	return buildError(http.StatusNotFound, NotFound, msg, err, details)
 }
 
+// UnauthorizedError - returns a new instance of the error with an unauthorized error message and status codes.
+func UnauthorizedError(msg string, err error, details map[string]any) HTTPError {
+	return buildError(http.StatusUnauthorized, Unauthorized, msg, err, details)
+}
+
+// ForbiddenError - returns a new instance of the error with a forbidden error message and status codes.
+func ForbiddenError(msg string, err error, details map[string]any) HTTPError {
+	return buildError(http.StatusForbidden, Forbidden, msg, err, details)
+}
+
+// ConflictError - returns a new instance of the error with a conflict error message and status codes.
+func ConflictError(msg string, err error, details map[string]any) HTTPError {
+	return buildError(http.StatusConflict, Conflict, msg, err, details)
+}
+
+// PreconditionFailedError - returns a new instance of the error with a precondition failed error message and status codes.
+func PreconditionFailedError(msg string, err error, details map[string]any) HTTPError {
+	return buildError(http.StatusPreconditionFailed, PreconditionFailed, msg, err, details)
+}
+
+// UnprocessableEntityError - returns a new instance of the error with an unprocessable entity error message and status codes.
+func UnprocessableEntityError(msg string, err error, details map[string]any) HTTPError {
+	return buildError(http.StatusUnprocessableEntity, UnprocessableEntity, msg, err, details)
+}
+
+// ServiceUnavailableError - returns a new instance of the error with a service unavailable error message and status codes.
+func ServiceUnavailableError(msg string, err error, details map[string]any) HTTPError {
+	return buildError(http.StatusServiceUnavailable, ServiceUnavailable, msg, err, details)
+}
+
+// CodedError - returns a new instance of the error with the provided status and a more specific
+// machine readable code than the ones above.
+func CodedError(statusCode int, code, msg string, err error, details map[string]any) HTTPError {
+	return buildError(statusCode, code, msg, err, details)
+}
+
 func buildError(statusCode int, code, msg string, err error, details map[string]any) HTTPError {
 	if details == nil {
 		details = map[string]any{}
@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/dimfeld/httptreemux"
 )
@@ -33,6 +35,24 @@ func PathParam(r *http.Request, key string) string {
 	return m[key]
 }
 
+// SetETag - sets the strong entity tag header on the response.
+func SetETag(w http.ResponseWriter, tag string) {
+	w.Header().Set("ETag", fmt.Sprintf("%q", tag))
+}
+
+// IfMatch - returns the unquoted entity tag from the If-Match header, or an empty string when absent or "*".
+func IfMatch(r *http.Request) (string, error) {
+	v := strings.TrimSpace(r.Header.Get("If-Match"))
+	if v == "" || v == "*" {
+		return "", nil
+	}
+	tag, err := strconv.Unquote(v)
+	if err != nil || !strings.HasPrefix(v, `"`) {
+		return "", fmt.Errorf("if match: invalid entity tag %s", v)
+	}
+	return tag, nil
+}
+
 // QueryParam returns the query parameters from the request.
 func QueryParam(r *http.Request, key string) string {
 	q := r.URL.Query()
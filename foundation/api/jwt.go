@@ -0,0 +1,210 @@
+package api
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Supported jwt signing algorithms.
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+)
+
+// ErrInvalidToken - returned when a jwt is malformed, badly signed, expired or not meant for us.
+var ErrInvalidToken = errors.New("invalid token")
+
+// Claims - represents the registered jwt claims we rely on, along with the raw claim set.
+type Claims struct {
+	Subject   string
+	Issuer    string
+	Audience  []string
+	ExpiresAt time.Time
+	NotBefore time.Time
+	IssuedAt  time.Time
+
+	// Raw - every claim of the token, including private ones.
+	Raw map[string]any
+}
+
+// JWTVerifier - verifies HS256 and RS256 signed jwts against the configured keys, issuer and audience.
+// A zero Issuer or Audience skips the related check.
+type JWTVerifier struct {
+	HMACSecret   []byte
+	RSAPublicKey *rsa.PublicKey
+	Issuer       string
+	Audience     string
+
+	// Leeway - tolerated clock skew on the exp and nbf claims.
+	Leeway time.Duration
+
+	// now - returns the current time, overridden in tests.
+	now func() time.Time
+}
+
+// Enabled - reports whether any verification key is configured.
+func (v *JWTVerifier) Enabled() bool {
+	return v != nil && (len(v.HMACSecret) > 0 || v.RSAPublicKey != nil)
+}
+
+// Verify - returns the claims of the token once its signature and claims are checked.
+func (v *JWTVerifier) Verify(token string) (Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return Claims{}, fmt.Errorf("verify: malformed token: %w", ErrInvalidToken)
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return Claims{}, fmt.Errorf("verify: unable to decode header: %w", err)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return Claims{}, fmt.Errorf("verify: malformed signature: %w", ErrInvalidToken)
+	}
+	if err := v.verifySignature(header.Alg, parts[0]+"."+parts[1], sig); err != nil {
+		return Claims{}, fmt.Errorf("verify: %w", err)
+	}
+
+	var raw map[string]any
+	if err := decodeSegment(parts[1], &raw); err != nil {
+		return Claims{}, fmt.Errorf("verify: unable to decode claims: %w", err)
+	}
+	claims, err := toClaims(raw)
+	if err != nil {
+		return Claims{}, fmt.Errorf("verify: %w", err)
+	}
+	if err := v.validate(claims); err != nil {
+		return Claims{}, fmt.Errorf("verify: %w", err)
+	}
+	return claims, nil
+}
+
+func (v *JWTVerifier) verifySignature(alg, signed string, sig []byte) error {
+	switch {
+	case alg == AlgHS256 && len(v.HMACSecret) > 0:
+		mac := hmac.New(sha256.New, v.HMACSecret)
+		mac.Write([]byte(signed))
+		if !hmac.Equal(sig, mac.Sum(nil)) {
+			return fmt.Errorf("verify signature: signature mismatch: %w", ErrInvalidToken)
+		}
+		return nil
+	case alg == AlgRS256 && v.RSAPublicKey != nil:
+		sum := sha256.Sum256([]byte(signed))
+		if err := rsa.VerifyPKCS1v15(v.RSAPublicKey, crypto.SHA256, sum[:], sig); err != nil {
+			return fmt.Errorf("verify signature: signature mismatch: %w", ErrInvalidToken)
+		}
+		return nil
+	default:
+		return fmt.Errorf("verify signature: unsupported alg %q: %w", alg, ErrInvalidToken)
+	}
+}
+
+func (v *JWTVerifier) validate(c Claims) error {
+	now := time.Now()
+	if v.now != nil {
+		now = v.now()
+	}
+
+	if c.ExpiresAt.IsZero() || !now.Before(c.ExpiresAt.Add(v.Leeway)) {
+		return fmt.Errorf("validate: token expired: %w", ErrInvalidToken)
+	}
+	if !c.NotBefore.IsZero() && now.Add(v.Leeway).Before(c.NotBefore) {
+		return fmt.Errorf("validate: token not valid yet: %w", ErrInvalidToken)
+	}
+	if c.Subject == "" {
+		return fmt.Errorf("validate: missing subject: %w", ErrInvalidToken)
+	}
+	if v.Issuer != "" && c.Issuer != v.Issuer {
+		return fmt.Errorf("validate: unexpected issuer %q: %w", c.Issuer, ErrInvalidToken)
+	}
+	if v.Audience != "" && !contains(c.Audience, v.Audience) {
+		return fmt.Errorf("validate: unexpected audience: %w", ErrInvalidToken)
+	}
+	return nil
+}
+
+// ParseRSAPublicKey - parses a PEM encoded PKIX or PKCS1 rsa public key.
+func ParseRSAPublicKey(data []byte) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("parse rsa public key: no pem block found")
+	}
+	if key, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse rsa public key: %w", err)
+	}
+	rsaKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("parse rsa public key: not an rsa key")
+	}
+	return rsaKey, nil
+}
+
+func decodeSegment(seg string, v any) error {
+	b, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return fmt.Errorf("decode segment: %w", ErrInvalidToken)
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return fmt.Errorf("decode segment: %w", ErrInvalidToken)
+	}
+	return nil
+}
+
+func toClaims(raw map[string]any) (Claims, error) {
+	c := Claims{Raw: raw}
+	c.Subject, _ = raw["sub"].(string)
+	c.Issuer, _ = raw["iss"].(string)
+
+	switch aud := raw["aud"].(type) {
+	case nil:
+	case string:
+		c.Audience = []string{aud}
+	case []any:
+		for _, a := range aud {
+			s, ok := a.(string)
+			if !ok {
+				return Claims{}, fmt.Errorf("to claims: invalid aud: %w", ErrInvalidToken)
+			}
+			c.Audience = append(c.Audience, s)
+		}
+	default:
+		return Claims{}, fmt.Errorf("to claims: invalid aud: %w", ErrInvalidToken)
+	}
+
+	for key, dst := range map[string]*time.Time{"exp": &c.ExpiresAt, "nbf": &c.NotBefore, "iat": &c.IssuedAt} {
+		switch n := raw[key].(type) {
+		case nil:
+		case float64:
+			*dst = time.Unix(int64(n), 0)
+		default:
+			return Claims{}, fmt.Errorf("to claims: invalid %s: %w", key, ErrInvalidToken)
+		}
+	}
+	return c, nil
+}
+
+func contains(vals []string, want string) bool {
+	for _, v := range vals {
+		if v == want {
+			return true
+		}
+	}
+	return false
+}
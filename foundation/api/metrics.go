@@ -0,0 +1,46 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics - returns a middleware recording the latency and status of every request by method
+// and route, registering its collectors with reg.
+func Metrics(reg prometheus.Registerer) Middleware {
+	labels := []string{"method", "route", "status"}
+	requests := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "Number of http requests served.",
+	}, labels)
+	latency := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Latency of http requests.",
+		Buckets: prometheus.DefBuckets,
+	}, labels)
+	reg.MustRegister(requests, latency)
+
+	return func(next Handler) Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			start := time.Now()
+			rw := &responseRecorder{ResponseWriter: w}
+			err := next(ctx, rw, r)
+
+			status := rw.Status()
+			if err != nil {
+				status = http.StatusInternalServerError
+				if e, ok := err.(HTTPError); ok {
+					status = e.Err.StatusCode
+				}
+			}
+			lvs := []string{r.Method, Route(ctx), strconv.Itoa(status)}
+			requests.WithLabelValues(lvs...).Inc()
+			latency.WithLabelValues(lvs...).Observe(time.Since(start).Seconds())
+			return err
+		}
+	}
+}
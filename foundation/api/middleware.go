@@ -0,0 +1,161 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestIDHeader - the header carrying the request id in both directions.
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLen - incoming request ids longer than this are replaced by a generated one.
+const maxRequestIDLen = 128
+
+// Middleware - represents a function wrapping a handler with extra behavior.
+type Middleware func(Handler) Handler
+
+// wrap - returns the handler wrapped by the middlewares, the first one being the outermost.
+func wrap(h Handler, mw ...Middleware) Handler {
+	for i := len(mw) - 1; i >= 0; i-- {
+		if mw[i] != nil {
+			h = mw[i](h)
+		}
+	}
+	return h
+}
+
+type requestIDKey struct{}
+
+// RequestID - returns the request id carried by the context, if any.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// RequestIDs - returns a middleware propagating the X-Request-ID header, generating one when
+// missing, through the context and back on the response.
+func RequestIDs() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			id := r.Header.Get(RequestIDHeader)
+			if id == "" || len(id) > maxRequestIDLen {
+				id = uuid.NewString()
+			}
+			w.Header().Set(RequestIDHeader, id)
+
+			ctx = context.WithValue(ctx, requestIDKey{}, id)
+			return next(ctx, w, r.WithContext(ctx))
+		}
+	}
+}
+
+// Logger - returns a middleware adding a request scoped logger, tagged with the request and trace ids, to
+// the context and writing an access log with the status, bytes and latency of every request.
+func Logger(log zerolog.Logger) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			start := time.Now()
+			lc := log.With().Str("request_id", RequestID(ctx))
+			if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+				lc = lc.Str("trace_id", sc.TraceID().String())
+			}
+			l := lc.Logger()
+			ctx = l.WithContext(ctx)
+
+			rw := &responseRecorder{ResponseWriter: w}
+			err := next(ctx, rw, r.WithContext(ctx))
+
+			// The context logger rather than l, so fields added downstream, such as the principal, are logged.
+			zerolog.Ctx(ctx).Info().
+				Str("method", r.Method).
+				Str("path", r.URL.Path).
+				Int("status", rw.Status()).
+				Int("bytes", rw.bytes).
+				Dur("latency", time.Since(start)).
+				Msg("Request completed.")
+			return err
+		}
+	}
+}
+
+// Errors - returns a middleware responding with the standard error envelope for handler errors.
+func Errors() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			if err := next(ctx, w, r); err != nil {
+				respondError(w, err)
+			}
+			return nil
+		}
+	}
+}
+
+// Panics - returns a middleware recovering from handler panics, which are logged with their
+// stack and reported as internal server errors.
+func Panics() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) (err error) {
+			defer func() {
+				if rec := recover(); rec != nil {
+					zerolog.Ctx(ctx).Error().Str("stack", string(debug.Stack())).Msgf("Recovered from panic: %v.", rec)
+					err = InternalServerError("Error.", fmt.Errorf("panic: %v", rec), nil)
+				}
+			}()
+			return next(ctx, w, r)
+		}
+	}
+}
+
+// respondError - writes the error envelope for HTTPErrors and a bare internal server error otherwise.
+func respondError(w http.ResponseWriter, err error) {
+	if e, ok := err.(HTTPError); ok {
+		_ = Respond(w, e.Err.StatusCode, e)
+		return
+	}
+	_ = Respond(w, http.StatusInternalServerError, nil)
+}
+
+// responseRecorder - records the status and number of bytes written to a response.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (rr *responseRecorder) WriteHeader(status int) {
+	if rr.status == 0 {
+		rr.status = status
+	}
+	rr.ResponseWriter.WriteHeader(status)
+}
+
+func (rr *responseRecorder) Write(b []byte) (int, error) {
+	if rr.status == 0 {
+		rr.status = http.StatusOK
+	}
+	n, err := rr.ResponseWriter.Write(b)
+	rr.bytes += n
+	return n, err
+}
+
+// Flush - flushes the buffered response to the client, allowing handlers to stream it.
+func (rr *responseRecorder) Flush() {
+	if f, ok := rr.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Status - returns the response status, defaulting to 200 when nothing was written.
+func (rr *responseRecorder) Status() int {
+	if rr.status == 0 {
+		return http.StatusOK
+	}
+	return rr.status
+}
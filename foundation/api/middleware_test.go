@@ -0,0 +1,118 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	log := zerolog.New(&buf)
+	router := NewRouter(RequestIDs(), Logger(log), Errors(), Panics())
+
+	var gotRequestID string
+	router.Handle(http.MethodGet, "v1", "/ok", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		zerolog.Ctx(ctx).Info().Msg("Handling.")
+		gotRequestID = RequestID(ctx)
+		return Respond(w, http.StatusCreated, map[string]string{"status": "ok"})
+	})
+	router.Handle(http.MethodGet, "v1", "/missing", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return NotFoundError("Item not found.", errors.New("missing"), nil)
+	})
+	router.Handle(http.MethodGet, "v1", "/panic", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		panic("boom")
+	})
+
+	logs := func() []map[string]any {
+		var out []map[string]any
+		dec := json.NewDecoder(&buf)
+		for dec.More() {
+			var m map[string]any
+			require.NoError(t, dec.Decode(&m))
+			out = append(out, m)
+		}
+		return out
+	}
+
+	t.Run("propagates request id", func(t *testing.T) {
+		// Setup.
+		buf.Reset()
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/v1/ok", nil)
+		r.Header.Set(RequestIDHeader, "abc-123")
+
+		// Execute.
+		router.ServeHTTP(w, r)
+
+		// Validate.
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Equal(t, "abc-123", w.Header().Get(RequestIDHeader))
+		assert.Equal(t, "abc-123", gotRequestID)
+		entries := logs()
+		require.Len(t, entries, 2)
+		for _, e := range entries {
+			assert.Equal(t, "abc-123", e["request_id"])
+		}
+		assert.EqualValues(t, http.StatusCreated, entries[1]["status"])
+		assert.EqualValues(t, w.Body.Len(), entries[1]["bytes"])
+	})
+
+	t.Run("generates request id", func(t *testing.T) {
+		// Setup.
+		buf.Reset()
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/v1/ok", nil)
+
+		// Execute.
+		router.ServeHTTP(w, r)
+
+		// Validate.
+		assert.NotEmpty(t, w.Header().Get(RequestIDHeader))
+		assert.Equal(t, w.Header().Get(RequestIDHeader), gotRequestID)
+	})
+
+	t.Run("logs error status", func(t *testing.T) {
+		// Setup.
+		buf.Reset()
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/v1/missing", nil)
+
+		// Execute.
+		router.ServeHTTP(w, r)
+
+		// Validate.
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		entries := logs()
+		require.Len(t, entries, 1)
+		assert.EqualValues(t, http.StatusNotFound, entries[0]["status"])
+	})
+
+	t.Run("recovers from panic", func(t *testing.T) {
+		// Setup.
+		buf.Reset()
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/v1/panic", nil)
+
+		// Execute.
+		router.ServeHTTP(w, r)
+
+		// Validate.
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+		var resp HTTPError
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.Equal(t, InternalServer, resp.Err.Code)
+		entries := logs()
+		require.Len(t, entries, 2)
+		assert.Contains(t, entries[0]["stack"], "panic")
+		assert.EqualValues(t, http.StatusInternalServerError, entries[1]["status"])
+	})
+}
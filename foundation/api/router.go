@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 
@@ -10,19 +11,27 @@ import (
 // Router - represents api router.
 type Router struct {
 	mux *httptreemux.ContextMux
+	mw  []Middleware
 }
 
-// NewRouter - initialized new router.
-func NewRouter() *Router {
+// NewRouter - initialized new router. The middlewares wrap every handler registered
+// through Handle, the first one being the outermost.
+func NewRouter(mw ...Middleware) *Router {
 	m := httptreemux.NewContextMux()
 
 	m.GET("/healthcheck", healthCheck)
 
 	return &Router{
 		mux: m,
+		mw:  mw,
 	}
 }
 
+// Use - appends middlewares wrapping the handlers registered from then on.
+func (rr *Router) Use(mw ...Middleware) {
+	rr.mw = append(rr.mw, mw...)
+}
+
 func healthCheck(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	_, _ = w.Write([]byte("ok"))
@@ -39,17 +48,29 @@ func (rr *Router) Handle(method, group, path string, h Handler) {
 	rr.handle(method, p, h)
 }
 
+// HandleHTTP - registers a plain http handler, such as the metrics handler, outside of any group.
+func (rr *Router) HandleHTTP(method, path string, h http.Handler) {
+	rr.handle(method, path, func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		h.ServeHTTP(w, r.WithContext(ctx))
+		return nil
+	})
+}
+
 func (rr *Router) handle(method string, path string, h Handler) {
+	h = wrap(h, rr.mw...)
 	hh := func(w http.ResponseWriter, r *http.Request) {
-		if err := h(r.Context(), w, r); err != nil {
-			if e, ok := err.(HTTPError); ok {
-				fmt.Println(e.Err.StatusCode)
-				Respond(w, e.Err.StatusCode, e)
-				return
-			}
-			Respond(w, http.StatusInternalServerError, nil)
-			return
+		ctx := context.WithValue(r.Context(), routeKey{}, path)
+		if err := h(ctx, w, r.WithContext(ctx)); err != nil {
+			respondError(w, err)
 		}
 	}
 	rr.mux.Handle(method, path, hh)
 }
+
+type routeKey struct{}
+
+// Route - returns the path pattern, such as /v1/cages/:id, of the route serving the request.
+func Route(ctx context.Context) string {
+	route, _ := ctx.Value(routeKey{}).(string)
+	return route
+}
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/lenguti/jppp/foundation/api")
+
+// Trace - returns a middleware starting a server span for every request, continuing the trace
+// of an incoming W3C traceparent header if any.
+func Trace() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			ctx = otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(r.Header))
+
+			route := Route(ctx)
+			ctx, span := tracer.Start(ctx, r.Method+" "+route,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					semconv.HTTPMethod(r.Method),
+					semconv.HTTPRoute(route),
+					attribute.String("http.target", r.URL.RequestURI()),
+				),
+			)
+			defer span.End()
+
+			rw := &responseRecorder{ResponseWriter: w}
+			err := next(ctx, rw, r.WithContext(ctx))
+
+			status := rw.Status()
+			if err != nil {
+				status = http.StatusInternalServerError
+				if e, ok := err.(HTTPError); ok {
+					status = e.Err.StatusCode
+				}
+			}
+			span.SetAttributes(semconv.HTTPStatusCode(status))
+			if status >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(status))
+			}
+			return err
+		}
+	}
+}
@@ -0,0 +1,72 @@
+// Package tracing - sets up the OpenTelemetry tracer provider and W3C trace context propagation.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+)
+
+// Supported exporters.
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+// Config - represents tracing configurations.
+type Config struct {
+	// ServiceName - the service name spans are reported under.
+	ServiceName string
+
+	// Exporter - one of none, stdout or otlp. The otlp exporter is configured through the
+	// standard OTEL_EXPORTER_OTLP_* environment variables.
+	Exporter string
+}
+
+// New - returns a tracer provider exporting spans as configured, installed as the global provider
+// along with the W3C trace context propagator. It must be shut down to flush pending spans.
+func New(ctx context.Context, cfg Config) (*sdktrace.TracerProvider, error) {
+	var exp sdktrace.SpanExporter
+	switch cfg.Exporter {
+	case "", ExporterNone:
+	case ExporterStdout:
+		e, err := stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+		if err != nil {
+			return nil, fmt.Errorf("new: unable to create stdout exporter: %w", err)
+		}
+		exp = e
+	case ExporterOTLP:
+		e, err := otlptracehttp.New(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("new: unable to create otlp exporter: %w", err)
+		}
+		exp = e
+	default:
+		return nil, fmt.Errorf("new: unknown exporter %q", cfg.Exporter)
+	}
+
+	var opts []sdktrace.TracerProviderOption
+	if exp != nil {
+		opts = append(opts, sdktrace.WithBatcher(exp))
+	}
+	return Install(cfg.ServiceName, opts...), nil
+}
+
+// Install - returns a tracer provider built with the options, installed as the global provider
+// along with the W3C trace context propagator. Tests use it with an in-memory exporter.
+func Install(serviceName string, opts ...sdktrace.TracerProviderOption) *sdktrace.TracerProvider {
+	res := resource.NewSchemaless(semconv.ServiceName(serviceName))
+	tp := sdktrace.NewTracerProvider(append([]sdktrace.TracerProviderOption{sdktrace.WithResource(res)}, opts...)...)
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	return tp
+}
@@ -2,13 +2,22 @@ package main
 
 import (
 	"context"
+	"flag"
+	"fmt"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"github.com/google/uuid"
 	v1 "github.com/lenguti/jppp/app/api/handlers/v1"
+	"github.com/lenguti/jppp/business/core"
+	"github.com/lenguti/jppp/business/core/apikey"
+	"github.com/lenguti/jppp/business/core/apikey/stores/apikeydb"
+	"github.com/lenguti/jppp/business/data/db"
+	"github.com/lenguti/jppp/foundation/api"
+	"github.com/lenguti/jppp/foundation/tracing"
 	_ "github.com/lib/pq"
 	"github.com/rs/zerolog"
 )
@@ -16,11 +25,42 @@ import (
 func main() {
 	log := zerolog.New(os.Stdout).With().Timestamp().Logger()
 
-	cfg, err := v1.NewConfig()
+	memStore := flag.Bool("memstore", false, "run the api against an in-memory store instead of postgres")
+	autoMigrate := flag.Bool("auto-migrate", false, "apply pending migrations on startup, one replica at a time")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags]\n       %s migrate up|down|status|redo\n       %s apikey create <name> [role]|revoke <id>\n", os.Args[0], os.Args[0], os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.Arg(0) == "migrate" {
+		if err := migrate(context.Background(), flag.Arg(1)); err != nil {
+			log.Error().Err(err).Msg("Unable to run migrations.")
+			os.Exit(1)
+		}
+		return
+	}
+
+	if flag.Arg(0) == "apikey" {
+		if err := apiKey(context.Background(), log, flag.Arg(1), flag.Arg(2), flag.Arg(3)); err != nil {
+			log.Error().Err(err).Msg("Unable to manage api key.")
+			os.Exit(1)
+		}
+		return
+	}
+
+	cfg, err := v1.NewConfig(*memStore)
 	if err != nil {
 		log.Error().Err(err).Msg("Unable to create new config.")
 		os.Exit(1)
 	}
+	cfg.AutoMigrate = *autoMigrate
+
+	tp, err := tracing.New(context.Background(), cfg.TracingConfig())
+	if err != nil {
+		log.Error().Err(err).Msg("Unable to create tracer provider.")
+		os.Exit(1)
+	}
 
 	ctrl, err := v1.NewController(log, cfg)
 	if err != nil {
@@ -32,6 +72,14 @@ func main() {
 		Addr:    ":8000",
 		Handler: ctrl.Routes(),
 	}
+	srv.RegisterOnShutdown(ctrl.Close)
+
+	runCtx, stopRun := context.WithCancel(context.Background())
+	ran := make(chan struct{})
+	go func() {
+		defer close(ran)
+		ctrl.Run(runCtx)
+	}()
 
 	go func() {
 		log.Info().Msg("Starting web server.")
@@ -53,5 +101,73 @@ func main() {
 		log.Error().Err(err).Msg("Error shutting down server.")
 	}
 
+	stopRun()
+	<-ran
+
+	if err := tp.Shutdown(ctx); err != nil {
+		log.Error().Err(err).Msg("Error shutting down tracer provider.")
+	}
+
 	log.Info().Msg("Server gracefully shut down.")
 }
+
+// migrate - runs the migration command against the configured db.
+func migrate(ctx context.Context, command string) error {
+	switch command {
+	case db.MigrateUp, db.MigrateDown, db.MigrateStatus, db.MigrateRedo:
+	default:
+		flag.Usage()
+		return fmt.Errorf("migrate: unknown command %q", command)
+	}
+
+	cfg, err := v1.NewConfig(false)
+	if err != nil {
+		return fmt.Errorf("migrate: unable to create new config: %w", err)
+	}
+
+	ddb, err := db.New(cfg.DBConfig())
+	if err != nil {
+		return fmt.Errorf("migrate: unable to initialize new db: %w", err)
+	}
+
+	return ddb.Migrate(ctx, command)
+}
+
+// apiKey - creates an api key named arg with the role, viewer by default, printing the key which is not stored
+// in clear, or revokes the api key with id arg.
+func apiKey(ctx context.Context, log zerolog.Logger, command, arg, role string) error {
+	if (command != "create" && command != "revoke") || arg == "" {
+		flag.Usage()
+		return fmt.Errorf("api key: unknown command %q", command)
+	}
+
+	cfg, err := v1.NewConfig(false)
+	if err != nil {
+		return fmt.Errorf("api key: unable to create new config: %w", err)
+	}
+
+	ddb, err := db.New(cfg.DBConfig())
+	if err != nil {
+		return fmt.Errorf("api key: unable to initialize new db: %w", err)
+	}
+	kc := apikey.NewCore(apikeydb.NewStore(ddb), log)
+
+	if command == "revoke" {
+		id, err := uuid.Parse(arg)
+		if err != nil {
+			return fmt.Errorf("api key: invalid id %q: %w", arg, err)
+		}
+		return kc.Revoke(ctx, id)
+	}
+
+	key, hash, err := api.NewAPIKey()
+	if err != nil {
+		return fmt.Errorf("api key: %w", err)
+	}
+	k, err := kc.Create(ctx, apikey.NewAPIKey{Name: arg, Hash: hash, Role: core.Role(role)})
+	if err != nil {
+		return fmt.Errorf("api key: %w", err)
+	}
+	fmt.Printf("id: %s\nrole: %s\nkey: %s\n", k.ID, k.Role, key)
+	return nil
+}